@@ -0,0 +1,73 @@
+package sat
+
+import (
+	"io"
+)
+
+// ModelEnumerator lists the models of a formula projected onto a set of
+// variables, each projected model once. After every model a blocking
+// clause excluding its projection is added to an incremental CDCLSolver,
+// so the enumeration ends when the blocked formula becomes unsatisfiable.
+//
+// Projection variables that do not occur in the formula are free; they
+// are enumerated like the others.
+type ModelEnumerator struct {
+	solver     *CDCLSolver
+	projection []string
+	found      int
+	done       bool
+}
+
+// NewModelEnumerator creates an enumerator for the models of cnf projected
+// onto projection. A nil projection means every variable of cnf.
+func NewModelEnumerator(cnf *CNF, projection []string) *ModelEnumerator {
+	if projection == nil {
+		projection = cnf.Variables
+	}
+	seen := make(map[string]bool, len(projection))
+	names := make([]string, 0, len(projection))
+	for _, name := range projection {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	solver := NewCDCLSolver()
+	copyLiveClauses(nil, cnf.Clauses, nil, func(_ int, clause *Clause) {
+		solver.AddClause(clause)
+	})
+	return &ModelEnumerator{solver: solver, projection: names}
+}
+
+// Next returns the next projected model, an assignment to exactly the
+// projection variables. It returns io.EOF once every model has been
+// returned.
+func (e *ModelEnumerator) Next() (Assignment, error) {
+	if e.done {
+		return nil, io.EOF
+	}
+	result := e.solver.SolveAssuming(nil)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if !result.Satisfiable {
+		e.done = true
+		return nil, io.EOF
+	}
+
+	model := make(Assignment, len(e.projection))
+	block := make([]Literal, len(e.projection))
+	for i, name := range e.projection {
+		model[name] = result.Assignment[name]
+		block[i] = Literal{Variable: name, Negated: model[name]}
+	}
+	e.solver.AddClause(NewClause(block...))
+	e.found++
+	return model, nil
+}
+
+// Count returns the number of models returned so far
+func (e *ModelEnumerator) Count() int {
+	return e.found
+}
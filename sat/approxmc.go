@@ -0,0 +1,370 @@
+package sat
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/xDarkicex/logic/core"
+	"github.com/xDarkicex/memory"
+)
+
+// ApproxCountResult reports an approximate projected model count
+type ApproxCountResult struct {
+	Count       *big.Int
+	Exact       bool // Fewer models than the cell threshold, all enumerated
+	Iterations  int  // Hashing rounds whose median is Count
+	SolverCalls int
+}
+
+// ApproxCounter estimates projected model counts in the style of ApproxMC
+// (Chakraborty, Meel & Vardi, 2013, 2016): random XOR constraints over the
+// projection split the solutions into cells of about equal size, the
+// solutions of one small cell are enumerated with blocking clauses, and
+// the cell size times the number of cells estimates the count. The
+// median over enough rounds is within a factor 1+ε of the true count with
+// probability at least 1-δ.
+//
+// The hashes are XORClauses of an ExtendedCNF, so SolveExtended handles
+// them with Gaussian elimination. The XOR constraints of each round are
+// nested, so the number of hashes is found by galloping from the previous
+// round's number and then bisecting. A counter can be reused but not
+// shared between goroutines.
+type ApproxCounter struct {
+	epsilon float64
+	delta   float64
+	rng     *rand.Rand
+	timeout time.Duration
+
+	solverCalls int
+}
+
+// NewApproxCounter creates a counter with tolerance epsilon and confidence
+// 1-delta. ApproxMC's defaults are 0.8 and 0.2.
+func NewApproxCounter(epsilon, delta float64) *ApproxCounter {
+	return &ApproxCounter{
+		epsilon: epsilon,
+		delta:   delta,
+		rng:     rand.New(rand.NewSource(1)),
+	}
+}
+
+// ApproxCountModels estimates the models of ecnf projected onto
+// projection; see ApproxCounter.Count
+func ApproxCountModels(ecnf *ExtendedCNF, projection []string, epsilon, delta float64) (*ApproxCountResult, error) {
+	return NewApproxCounter(epsilon, delta).Count(ecnf, projection)
+}
+
+// SetSeed reseeds the random hashes, which are deterministic by default
+func (a *ApproxCounter) SetSeed(seed int64) {
+	a.rng = rand.New(rand.NewSource(seed))
+}
+
+// SetTimeout limits each solve call; zero means no limit
+func (a *ApproxCounter) SetTimeout(timeout time.Duration) {
+	a.timeout = timeout
+}
+
+// Count estimates the number of assignments to the projection variables
+// that extend to a model of ecnf. A nil projection means every variable
+// of ecnf. The formula is not modified.
+func (a *ApproxCounter) Count(ecnf *ExtendedCNF, projection []string) (*ApproxCountResult, error) {
+	if a.epsilon <= 0 || a.delta <= 0 || a.delta >= 1 {
+		return nil, core.NewLogicError("sat", "ApproxCounter.Count",
+			fmt.Sprintf("need epsilon > 0 and 0 < delta < 1, got %g and %g", a.epsilon, a.delta))
+	}
+	a.solverCalls = 0
+	formula, projection := hashingFormula(ecnf, projection)
+	threshold := approxThreshold(a.epsilon)
+
+	cell, err := a.cell(formula, projection, nil, threshold)
+	if err != nil {
+		return nil, err
+	}
+	if len(cell) < threshold {
+		return &ApproxCountResult{
+			Count:       big.NewInt(int64(len(cell))),
+			Exact:       true,
+			SolverCalls: a.solverCalls,
+		}, nil
+	}
+
+	rounds := int(math.Ceil(17 * math.Log2(3/a.delta)))
+	estimates := make([]*big.Int, 0, rounds)
+	previous := 1
+	for round := 0; round < rounds; round++ {
+		hashes := a.randomHashes(projection, len(projection))
+		m, size, err := a.search(formula, projection, hashes, threshold, previous)
+		if err != nil {
+			return nil, err
+		}
+		previous = max(m, 1)
+		estimate := new(big.Int).Lsh(big.NewInt(int64(size)), uint(m))
+		estimates = append(estimates, estimate)
+	}
+	sort.Slice(estimates, func(i, j int) bool { return estimates[i].Cmp(estimates[j]) < 0 })
+	return &ApproxCountResult{
+		Count:       estimates[len(estimates)/2],
+		Iterations:  rounds,
+		SolverCalls: a.solverCalls,
+	}, nil
+}
+
+// approxThreshold returns ApproxMC's cell size bound for tolerance epsilon
+func approxThreshold(epsilon float64) int {
+	return int(math.Ceil(1 + 9.84*(1+epsilon/(1+epsilon))*(1+1/epsilon)*(1+1/epsilon)))
+}
+
+// search returns the least m for which the first m hashes leave a cell
+// with fewer than threshold solutions, and that cell's size. Cell sizes
+// only shrink as m grows. The first probes are previous and its
+// neighbour, where the answer usually is; the rest bisect. If even all
+// hashes leave a cell that large, the answer is all of them.
+func (a *ApproxCounter) search(formula *ExtendedCNF, projection []string, hashes []*XORClause, threshold, previous int) (int, int, error) {
+	// Invariant: the cell of lo is too large, the cell of hi is small
+	lo, hi := 0, len(hashes)+1
+	sizes := make(map[int]int)
+	for probe := 0; hi-lo > 1; probe++ {
+		m := (lo + hi) / 2
+		switch {
+		case probe == 0:
+			m = min(max(previous, lo+1), hi-1)
+		case probe == 1 && hi == previous:
+			m = hi - 1
+		case probe == 1 && lo == previous:
+			m = lo + 1
+		}
+		cell, err := a.cell(formula, projection, hashes[:m], threshold)
+		if err != nil {
+			return 0, 0, err
+		}
+		sizes[m] = len(cell)
+		if len(cell) < threshold {
+			hi = m
+		} else {
+			lo = m
+		}
+	}
+	if hi > len(hashes) {
+		return lo, sizes[lo], nil
+	}
+	return hi, sizes[hi], nil
+}
+
+// randomHashes returns n random XOR constraints over projection, each
+// variable taking part with probability 1/2
+func (a *ApproxCounter) randomHashes(projection []string, n int) []*XORClause {
+	hashes := make([]*XORClause, n)
+	for i := range hashes {
+		var variables []string
+		for _, name := range projection {
+			if a.rng.Intn(2) == 0 {
+				variables = append(variables, name)
+			}
+		}
+		hashes[i] = NewXORClause(variables, a.rng.Intn(2) == 0)
+	}
+	return hashes
+}
+
+// cell enumerates up to limit solutions of formula and hashes, projected
+// onto projection, blocking each one found
+func (a *ApproxCounter) cell(formula *ExtendedCNF, projection []string, hashes []*XORClause, limit int) ([]Assignment, error) {
+	// The cell's clauses and solver live in a pool freed with the cell
+	pool := mustCreatePool()
+	defer pool.Free()
+	cell := copyExtendedCNF(pool, formula)
+	for _, hash := range hashes {
+		if len(hash.Variables) == 0 {
+			if hash.Parity {
+				return nil, nil // 0 = 1: the cell is empty
+			}
+			continue
+		}
+		cell.AddXORClause(NewXORClause(hash.Variables, hash.Parity))
+	}
+
+	solver := newHashingSolver(pool)
+	var models []Assignment
+	for len(models) < limit {
+		a.solverCalls++
+		result := solver.SolveExtendedWithTimeout(cell, a.timeout)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if !result.Satisfiable {
+			break
+		}
+		model := make(Assignment, len(projection))
+		block := make([]Literal, len(projection))
+		for i, name := range projection {
+			model[name] = result.Assignment[name]
+			block[i] = Literal{Variable: name, Negated: model[name]}
+		}
+		models = append(models, model)
+		if len(block) == 0 {
+			break
+		}
+		cell.AddClause(cell.NewClause(block...))
+	}
+	return models, nil
+}
+
+// hashingFormula returns a copy of ecnf that mentions every projection
+// variable, and the projection without duplicates
+func hashingFormula(ecnf *ExtendedCNF, projection []string) (*ExtendedCNF, []string) {
+	formula := copyExtendedCNF(nil, ecnf)
+	if projection == nil {
+		projection = ecnf.Variables
+	}
+	seen := make(map[string]bool, len(projection))
+	names := make([]string, 0, len(projection))
+	for _, name := range projection {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+		if !formula.containsVariable(name) {
+			formula.Variables = append(formula.Variables, name)
+		}
+	}
+	return formula, names
+}
+
+// newHashingSolver returns a solver allocating from pool that never
+// eliminates variables. A cell without hashes is a plain CNF, which
+// SolveExtended would simplify by elimination, and blocking clauses added
+// afterwards would then mention eliminated variables.
+func newHashingSolver(pool *memory.Pool) *CDCLSolver {
+	solver := NewCDCLSolverWithPool(pool)
+	solver.SetInprocessConfig(solver.inprocessConfig.withoutElimination())
+	return solver
+}
+
+// UniformSampler draws witnesses of a formula projected onto a set of
+// variables almost uniformly, in the style of UniGen (Chakraborty et al.,
+// 2014, 2015): an ApproxCounter estimate fixes how many random XOR hashes
+// leave cells of a size between loThresh and hiThresh, and each sample is
+// drawn uniformly from such a cell. Every projected witness is returned
+// with probability within a factor 1+ε of uniform. Formulas with at most
+// hiThresh projected witnesses are sampled exactly uniformly.
+//
+// A sampler can be reused but not shared between goroutines.
+type UniformSampler struct {
+	epsilon float64
+	counter *ApproxCounter
+}
+
+// NewUniformSampler creates a sampler with tolerance epsilon, which
+// UniGen's analysis needs to exceed 1.71
+func NewUniformSampler(epsilon float64) *UniformSampler {
+	return &UniformSampler{
+		epsilon: epsilon,
+		counter: NewApproxCounter(0.8, 0.2),
+	}
+}
+
+// SetSeed reseeds the random hashes and choices
+func (s *UniformSampler) SetSeed(seed int64) {
+	s.counter.SetSeed(seed)
+}
+
+// SetTimeout limits each solve call; zero means no limit
+func (s *UniformSampler) SetTimeout(timeout time.Duration) {
+	s.counter.SetTimeout(timeout)
+}
+
+// samplerRetries bounds the consecutive cells of the wrong size tried
+// for one sample; each try fails with probability at most about 0.52
+const samplerRetries = 64
+
+// Sample returns n witnesses of ecnf projected onto projection, each an
+// assignment to exactly the projection variables and drawn independently.
+// A nil projection means every variable of ecnf. An unsatisfiable formula
+// has no witnesses and gives an empty result.
+func (s *UniformSampler) Sample(ecnf *ExtendedCNF, projection []string, n int) ([]Assignment, error) {
+	if s.epsilon <= 1.71 {
+		return nil, core.NewLogicError("sat", "UniformSampler.Sample",
+			fmt.Sprintf("need epsilon > 1.71, got %g", s.epsilon))
+	}
+	a := s.counter
+	a.solverCalls = 0
+	formula, projection := hashingFormula(ecnf, projection)
+	kappa := samplerKappa(s.epsilon)
+	pivot := math.Ceil(4.03 * (1 + 1/kappa) * (1 + 1/kappa))
+	hiThresh := int(1 + math.Ceil(math.Sqrt2*(1+kappa)*pivot))
+	loThresh := int(pivot / (math.Sqrt2 * (1 + kappa)))
+
+	// Few witnesses: choose among all of them
+	all, err := a.cell(formula, projection, nil, hiThresh+1)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]Assignment, 0, n)
+	if len(all) <= hiThresh {
+		for len(all) > 0 && len(samples) < n {
+			samples = append(samples, all[a.rng.Intn(len(all))].Clone())
+		}
+		return samples, nil
+	}
+
+	estimate, err := a.Count(ecnf, projection)
+	if err != nil {
+		return nil, err
+	}
+	count, _ := new(big.Float).SetInt(estimate.Count).Float64()
+	q := int(math.Ceil(math.Log2(count) + math.Log2(1.8) - math.Log2(pivot)))
+	for failures := 0; len(samples) < n; {
+		sample, err := s.sample(formula, projection, q, loThresh, hiThresh)
+		if err != nil {
+			return nil, err
+		}
+		if sample == nil {
+			if failures++; failures == samplerRetries {
+				return nil, core.NewLogicError("sat", "UniformSampler.Sample",
+					fmt.Sprintf("no cell of %d to %d witnesses after %d tries", loThresh, hiThresh, failures))
+			}
+			continue
+		}
+		samples = append(samples, sample)
+		failures = 0
+	}
+	return samples, nil
+}
+
+// sample draws one hash with up to q XORs and tries its prefixes of
+// q-3 to q XORs for a cell of between loThresh and hiThresh witnesses,
+// returning a uniform choice from the first one found, or nil
+func (s *UniformSampler) sample(formula *ExtendedCNF, projection []string, q, loThresh, hiThresh int) (Assignment, error) {
+	a := s.counter
+	hashes := a.randomHashes(projection, max(q, 0))
+	for m := max(q-3, 0); m <= max(q, 0); m++ {
+		cell, err := a.cell(formula, projection, hashes[:m], hiThresh+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(cell) >= loThresh && len(cell) <= hiThresh {
+			return cell[a.rng.Intn(len(cell))], nil
+		}
+	}
+	return nil, nil
+}
+
+// samplerKappa solves ε = (1+κ)(2.23 + 0.48/(1-κ)²) - 1 for κ in (0, 1)
+// by bisection; the right side grows with κ
+func samplerKappa(epsilon float64) float64 {
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 60; i++ {
+		kappa := (lo + hi) / 2
+		if (1+kappa)*(2.23+0.48/((1-kappa)*(1-kappa)))-1 < epsilon {
+			lo = kappa
+		} else {
+			hi = kappa
+		}
+	}
+	return (lo + hi) / 2
+}
@@ -0,0 +1,207 @@
+package sat
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// extendedFrom copies the clauses of cnf into an extended formula
+func extendedFrom(cnf *CNF) *ExtendedCNF {
+	ecnf := NewExtendedCNF()
+	for _, clause := range cnf.Clauses {
+		ecnf.AddClause(NewClause(clause.Literals...))
+	}
+	return ecnf
+}
+
+func TestApproxCounter_Estimate(t *testing.T) {
+	x := make([]Literal, 12)
+	names := make([]string, len(x))
+	for i := range x {
+		names[i] = fmt.Sprintf("x%d", i)
+		x[i] = L(names[i], false)
+	}
+	// pairs requires at least one of each pair x[from], x[from+1], ...
+	pairs := func(from, to int) [][]Literal {
+		var clauses [][]Literal
+		for i := from; i+1 < to; i += 2 {
+			clauses = append(clauses, []Literal{x[i], x[i+1]})
+		}
+		return clauses
+	}
+
+	testCases := []struct {
+		description string
+		clauses     [][]Literal
+		projection  []string
+		expected    int64
+	}{
+		{"at least one of each pair",
+			pairs(0, 12), names, 729},
+		{"an implication chain beside pairs",
+			append([][]Literal{{x[0].Negate(), x[1]}, {x[1].Negate(), x[2]}, {x[2].Negate(), x[3]}}, pairs(4, 12)...),
+			names, 405},
+		{"projection hides the variables that satisfy every clause",
+			[][]Literal{{x[0], x[10]}, {x[1], x[10]}, {x[2], x[11]}, {x[3], x[10]}, {x[4], x[10]}, {x[5], x[11]},
+				{x[6], x[10]}, {x[7], x[10]}, {x[8], x[11]}, {x[9], x[10]}, {x[10], x[11]}},
+			names[:10], 1024},
+	}
+
+	const epsilon = 2.0
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ecnf := NewExtendedCNF()
+			for _, clause := range tc.clauses {
+				ecnf.AddClause(NewClause(clause...))
+			}
+			counter := NewApproxCounter(epsilon, 0.5)
+			counter.SetTimeout(10 * time.Second)
+			result, err := counter.Count(ecnf, tc.projection)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Exact {
+				t.Fatalf("%d models should need hashing", tc.expected)
+			}
+
+			// expected/(1+ε) ≤ count ≤ expected·(1+ε)
+			low := new(big.Float).Quo(big.NewFloat(float64(tc.expected)), big.NewFloat(1+epsilon))
+			high := new(big.Float).Mul(big.NewFloat(float64(tc.expected)), big.NewFloat(1+epsilon))
+			count := new(big.Float).SetInt(result.Count)
+			if count.Cmp(low) < 0 || count.Cmp(high) > 0 {
+				t.Errorf("Estimate %v is not within a factor %g of %d", result.Count, 1+epsilon, tc.expected)
+			}
+			if result.Iterations == 0 || result.SolverCalls == 0 {
+				t.Errorf("Expected hashing rounds, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestApproxCounter_Exact(t *testing.T) {
+	// (A ∨ B) ∧ (¬A ∨ C) has three models on {A, B}, below any threshold
+	ecnf := NewExtendedCNF()
+	ecnf.AddClause(NewClause(L("A", false), L("B", false)))
+	ecnf.AddClause(NewClause(L("A", true), L("C", false)))
+	result, err := ApproxCountModels(ecnf, []string{"A", "B", "A"}, 0.8, 0.2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Exact || result.Count.Int64() != 3 {
+		t.Fatalf("Expected exactly 3, got %+v", result)
+	}
+
+	// The XOR constraints of the formula count too: A ⊕ B = 0 leaves AB
+	ecnf.AddXORClause(NewXORClause([]string{"A", "B"}, false))
+	if result, err = ApproxCountModels(ecnf, []string{"A", "B"}, 0.8, 0.2); err != nil || result.Count.Int64() != 1 {
+		t.Fatalf("Expected 1, got %+v (%v)", result, err)
+	}
+	if len(ecnf.XORClauses) != 1 || len(ecnf.Clauses) != 2 {
+		t.Fatal("The formula was modified")
+	}
+}
+
+func TestApproxCounter_Errors(t *testing.T) {
+	ecnf := NewExtendedCNF()
+	for _, p := range [][2]float64{{0, 0.2}, {0.8, 0}, {0.8, 1}} {
+		if _, err := ApproxCountModels(ecnf, nil, p[0], p[1]); err == nil {
+			t.Errorf("Expected an error for epsilon %g and delta %g", p[0], p[1])
+		}
+	}
+	if _, err := NewUniformSampler(1.5).Sample(ecnf, nil, 1); err == nil {
+		t.Error("Expected an error for epsilon 1.5")
+	}
+}
+
+func TestUniformSampler_Small(t *testing.T) {
+	// A ∨ B over {A, B}: three witnesses, sampled exactly uniformly
+	ecnf := NewExtendedCNF()
+	ecnf.AddClause(NewClause(L("A", false), L("B", false), L("C", false)))
+	ecnf.AddClause(NewClause(L("A", false), L("B", false), L("C", true)))
+	samples, err := NewUniformSampler(6).Sample(ecnf, []string{"A", "B"}, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]int)
+	for _, s := range samples {
+		if len(s) != 2 || !(s["A"] || s["B"]) {
+			t.Fatalf("Sample %v is not a witness", s)
+		}
+		seen[fmt.Sprint(s["A"], s["B"])]++
+	}
+	if len(samples) != 300 || len(seen) != 3 {
+		t.Fatalf("Expected 300 samples of 3 witnesses, got %v", seen)
+	}
+	for witness, n := range seen {
+		if n < 60 || n > 140 {
+			t.Errorf("Witness %s drawn %d times of 300", witness, n)
+		}
+	}
+
+	// An unsatisfiable formula has no witnesses
+	ecnf.AddClause(NewClause(L("A", true)))
+	ecnf.AddClause(NewClause(L("B", true)))
+	if samples, err = NewUniformSampler(6).Sample(ecnf, []string{"A", "B"}, 5); err != nil || len(samples) != 0 {
+		t.Fatalf("Expected no samples, got %v (%v)", samples, err)
+	}
+}
+
+func TestUniformSampler_SamplesAreIndependent(t *testing.T) {
+	// One witness is drawn every time, but each sample is its own copy
+	ecnf := NewExtendedCNF()
+	ecnf.AddClause(NewClause(L("A", false)))
+	samples, err := NewUniformSampler(6).Sample(ecnf, []string{"A"}, 2)
+	if err != nil || len(samples) != 2 {
+		t.Fatalf("Expected 2 samples, got %v (%v)", samples, err)
+	}
+	samples[0]["A"] = false
+	if !samples[1]["A"] {
+		t.Error("Changing one sample changed another")
+	}
+}
+
+func TestUniformSampler_Hashing(t *testing.T) {
+	// x0 ∨ x1 over ten variables: 768 witnesses, too many to enumerate
+	names := make([]string, 10)
+	for i := range names {
+		names[i] = fmt.Sprintf("x%d", i)
+	}
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("x0", false), L("x1", false)))
+	ecnf := extendedFrom(cnf)
+	ecnf.Variables = append(ecnf.Variables[:0], names...)
+
+	sampler := NewUniformSampler(6)
+	sampler.SetSeed(7)
+	sampler.SetTimeout(10 * time.Second)
+	samples, err := sampler.Sample(ecnf, names, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 60 {
+		t.Fatalf("Expected 60 samples, got %d", len(samples))
+	}
+	seen := make(map[string]bool)
+	ones := 0
+	for _, s := range samples {
+		if len(s) != len(names) || CheckModel(cnf, s) != nil {
+			t.Fatalf("Sample %v is not a witness", s)
+		}
+		seen[fmt.Sprint(s)] = true
+		for _, name := range names[2:] {
+			if s[name] {
+				ones++
+			}
+		}
+	}
+	// Sixty draws from 768 witnesses rarely repeat, and the free
+	// variables are true about half the time
+	if len(seen) < 50 {
+		t.Errorf("Expected mostly distinct samples, got %d of 60", len(seen))
+	}
+	if ones < 180 || ones > 300 {
+		t.Errorf("Free variables true %d times of 480", ones)
+	}
+}
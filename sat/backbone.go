@@ -0,0 +1,270 @@
+package sat
+
+import (
+	"sort"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+// BackboneResult reports the literals forced in every model of a formula
+// under a set of assumptions
+type BackboneResult struct {
+	Satisfiable bool
+
+	// Backbone lists the literals true in every model, sorted by variable.
+	// It includes the assumptions themselves and is nil if the formula is
+	// unsatisfiable under them.
+	Backbone []Literal
+
+	SolverCalls int // SolveAssuming calls made by this computation
+	Probed      int // Backbone literals found by failed-literal probing
+	Reused      int // Backbone literals carried over from an earlier Compute
+}
+
+// BackboneExtractor computes the backbone of a formula: the literals that
+// hold in every model. Candidates start as the first model and are
+// filtered by every later model; each remaining candidate l is tested by
+// assuming ¬l, several at once. An UNSAT answer whose failed assumptions
+// name a single candidate proves it, so one call can settle a candidate
+// without testing the others of its chunk (core-based pruning). A failed
+// literal probing pass over the formula finds cheap backbone literals
+// before the first test.
+//
+// Assumptions model the choices of an interactive configurator. Adding
+// one can only grow the backbone, so literals proven earlier are kept;
+// retracting one drops everything except the literals the formula forces
+// on its own, which are also added to the solver as unit clauses. The
+// queries share one incremental CDCLSolver, so an extractor can be reused
+// but not shared between goroutines.
+type BackboneExtractor struct {
+	solver      *CDCLSolver
+	clauses     [][]Literal
+	variables   []string
+	assumptions []Literal
+	probing     bool
+
+	forced map[Literal]bool // Backbone of the formula alone
+	known  map[Literal]bool // Backbone under the current assumptions
+}
+
+// backboneChunk is the largest number of candidates tested by one call
+const backboneChunk = 32
+
+// NewBackboneExtractor creates an extractor for the backbone of cnf. Later
+// changes to cnf are not seen.
+func NewBackboneExtractor(cnf *CNF) *BackboneExtractor {
+	b := &BackboneExtractor{
+		solver:    NewCDCLSolver(),
+		clauses:   make([][]Literal, 0, len(cnf.Clauses)),
+		variables: append([]string(nil), cnf.Variables...),
+		probing:   true,
+		forced:    make(map[Literal]bool),
+		known:     make(map[Literal]bool),
+	}
+	copyLiveClauses(nil, cnf.Clauses, nil, func(i int, clause *Clause) {
+		b.clauses = append(b.clauses, append([]Literal(nil), cnf.Clauses[i].Literals...))
+		b.solver.AddClause(clause)
+	})
+	return b
+}
+
+// ComputeBackbone returns the backbone of cnf
+func ComputeBackbone(cnf *CNF) (*BackboneResult, error) {
+	return NewBackboneExtractor(cnf).Compute()
+}
+
+// Assume adds unit assumptions for the following Compute calls
+func (b *BackboneExtractor) Assume(lits ...Literal) {
+	b.assumptions = append(b.assumptions, lits...)
+}
+
+// Retract removes every assumption equal to one of lits
+func (b *BackboneExtractor) Retract(lits ...Literal) {
+	kept := b.assumptions[:0]
+	for _, a := range b.assumptions {
+		retracted := false
+		for _, lit := range lits {
+			retracted = retracted || a == lit
+		}
+		if !retracted {
+			kept = append(kept, a)
+		}
+	}
+	if len(kept) == len(b.assumptions) {
+		return
+	}
+	b.assumptions = kept
+	b.known = make(map[Literal]bool, len(b.forced))
+	for lit := range b.forced {
+		b.known[lit] = true
+	}
+}
+
+// Assumptions returns the current assumptions
+func (b *BackboneExtractor) Assumptions() []Literal {
+	return append([]Literal(nil), b.assumptions...)
+}
+
+// SetProbing turns the failed literal probing pass on or off. It is on by
+// default; on large formulas it can cost more than the tests it saves.
+func (b *BackboneExtractor) SetProbing(enabled bool) {
+	b.probing = enabled
+}
+
+// Compute returns the backbone under the current assumptions
+func (b *BackboneExtractor) Compute() (*BackboneResult, error) {
+	result := &BackboneResult{Reused: len(b.known)}
+	first := b.solve(nil, result)
+	if first.Error != nil {
+		return nil, first.Error
+	}
+	if !first.Satisfiable {
+		return result, nil
+	}
+	result.Satisfiable = true
+
+	assumed := make(map[Literal]bool, len(b.assumptions))
+	for _, a := range b.assumptions {
+		assumed[a] = true
+	}
+	if b.probing {
+		b.probe(result, assumed)
+	}
+
+	candidates := make([]Literal, 0, len(b.variables))
+	for _, name := range b.variables {
+		lit := Literal{Variable: name, Negated: !first.Assignment[name]}
+		if !b.known[lit] && !assumed[lit] {
+			candidates = append(candidates, lit)
+		}
+	}
+
+	size := backboneChunk
+	for len(candidates) > 0 {
+		chunk := candidates[:min(size, len(candidates))]
+		test := b.solve(chunk, result)
+		if test.Error != nil {
+			return nil, test.Error
+		}
+		if test.Satisfiable {
+			// Every chunk literal is false in this model, so filtering
+			// always makes progress
+			candidates = filterCandidates(candidates, test.Assignment)
+			size = min(2*size, backboneChunk)
+			continue
+		}
+
+		var proven []Literal
+		ownCore := true
+		for _, lit := range test.FailedAssumptions {
+			if negated := lit.Negate(); literalIndex(chunk, negated) >= 0 {
+				proven = append(proven, negated)
+			} else {
+				ownCore = false
+			}
+		}
+		switch len(proven) {
+		case 0:
+			return nil, core.NewLogicError("sat", "BackboneExtractor.Compute",
+				"assumptions became inconsistent during the computation")
+		case 1:
+			b.prove(proven[0], ownCore)
+			candidates = removeLiteral(candidates, proven[0])
+		default:
+			// Several candidates conflict together; test them one at a time
+			size = 1
+		}
+	}
+
+	result.Backbone = make([]Literal, 0, len(b.known)+len(assumed))
+	for lit := range b.known {
+		result.Backbone = append(result.Backbone, lit)
+	}
+	for lit := range assumed {
+		if !b.known[lit] {
+			result.Backbone = append(result.Backbone, lit)
+		}
+	}
+	sort.Slice(result.Backbone, func(i, j int) bool {
+		x, y := result.Backbone[i], result.Backbone[j]
+		if x.Variable != y.Variable {
+			return x.Variable < y.Variable
+		}
+		return !x.Negated && y.Negated
+	})
+	return result, nil
+}
+
+// solve checks the formula under the assumptions with every literal of
+// chunk negated
+func (b *BackboneExtractor) solve(chunk []Literal, result *BackboneResult) *SolverResult {
+	assumptions := make([]Literal, 0, len(b.assumptions)+len(chunk))
+	assumptions = append(assumptions, b.assumptions...)
+	for _, lit := range chunk {
+		assumptions = append(assumptions, lit.Negate())
+	}
+	result.SolverCalls++
+	return b.solver.SolveAssuming(assumptions)
+}
+
+// probe adds the literals failed literal probing shows forced under the
+// assumptions. The probing formula is a copy, since the prober adds the
+// units it finds.
+func (b *BackboneExtractor) probe(result *BackboneResult, assumed map[Literal]bool) {
+	cnf := NewCNF()
+	for _, lits := range b.clauses {
+		cnf.AddClause(NewClause(lits...))
+	}
+	for lit := range b.forced {
+		cnf.AddClause(NewClause(lit))
+	}
+	assignment := make(Assignment, len(b.assumptions))
+	for _, a := range b.assumptions {
+		assignment[a.Variable] = !a.Negated
+	}
+	for _, lit := range NewFailedLiteralProber().ProbeFailedLiterals(cnf, assignment) {
+		if !b.known[lit] && !assumed[lit] {
+			b.prove(lit, len(b.assumptions) == 0)
+			result.Probed++
+		}
+	}
+}
+
+// prove records lit as a backbone literal. A literal the formula forces
+// without any assumption survives Retract and becomes a unit clause.
+func (b *BackboneExtractor) prove(lit Literal, formula bool) {
+	b.known[lit] = true
+	if formula && !b.forced[lit] {
+		b.forced[lit] = true
+		b.solver.AddClause(NewClause(lit))
+	}
+}
+
+// filterCandidates keeps the candidates true in model
+func filterCandidates(candidates []Literal, model Assignment) []Literal {
+	kept := candidates[:0]
+	for _, lit := range candidates {
+		if model[lit.Variable] != lit.Negated {
+			kept = append(kept, lit)
+		}
+	}
+	return kept
+}
+
+// literalIndex returns the position of lit in lits, or -1
+func literalIndex(lits []Literal, lit Literal) int {
+	for i, l := range lits {
+		if l == lit {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeLiteral removes lit from lits, keeping the order
+func removeLiteral(lits []Literal, lit Literal) []Literal {
+	if i := literalIndex(lits, lit); i >= 0 {
+		return append(lits[:i], lits[i+1:]...)
+	}
+	return lits
+}
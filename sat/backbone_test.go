@@ -0,0 +1,158 @@
+package sat
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBackboneExtractor_Compute(t *testing.T) {
+	A, B, C, D := L("A", false), L("B", false), L("C", false), L("D", false)
+	testCases := []struct {
+		description      string
+		clauses          [][]Literal
+		assumptions      []Literal
+		expectedSat      bool
+		expectedBackbone []Literal
+	}{
+		{"units are forced",
+			[][]Literal{{A}, {A.Negate(), B}, {C, D}}, nil, true, []Literal{A, B}},
+		{"forced along a chain",
+			[][]Literal{{A}, {A.Negate(), B}, {B.Negate(), C}, {C.Negate(), D.Negate()}}, nil, true, []Literal{A, B, C, D.Negate()}},
+		{"failed literal",
+			[][]Literal{{A.Negate(), B}, {A.Negate(), B.Negate()}, {A, C, D}}, nil, true, []Literal{A.Negate()}},
+		{"forced by both cases",
+			[][]Literal{{A, B}, {A, B.Negate()}, {C, D}}, nil, true, []Literal{A}},
+		{"equivalent variables stay free",
+			[][]Literal{{A.Negate(), B}, {B.Negate(), A}}, nil, true, nil},
+		{"assumption extends the backbone",
+			[][]Literal{{A.Negate(), B}, {B.Negate(), C}}, []Literal{A}, true, []Literal{A, B, C}},
+		{"negative assumption",
+			[][]Literal{{A, B}, {A, C}, {C, D}}, []Literal{A.Negate()}, true, []Literal{A.Negate(), B, C}},
+		{"assumption contradicts a unit",
+			[][]Literal{{A}, {B, C}}, []Literal{A.Negate()}, false, nil},
+		{"unsatisfiable formula",
+			[][]Literal{{A, B}, {A.Negate()}, {B.Negate()}}, nil, false, nil},
+	}
+
+	for _, tc := range testCases {
+		for _, probing := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s with probing %v", tc.description, probing), func(t *testing.T) {
+				b := NewBackboneExtractor(buildCNF(tc.clauses))
+				b.SetProbing(probing)
+				b.Assume(tc.assumptions...)
+				result, err := b.Compute()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Satisfiable != tc.expectedSat {
+					t.Fatalf("Expected satisfiable=%v, got %v", tc.expectedSat, result.Satisfiable)
+				}
+				if fmt.Sprint(result.Backbone) != fmt.Sprint(tc.expectedBackbone) {
+					t.Errorf("Expected %v, got %v", tc.expectedBackbone, result.Backbone)
+				}
+			})
+		}
+	}
+}
+
+func TestBackboneExtractor_Configurator(t *testing.T) {
+	// Exactly one engine; electric needs an automatic gearbox, diesel
+	// rules out the convertible, and the convertible needs a hard top or
+	// a soft top
+	cnf := NewCNF()
+	for _, clause := range [][]Literal{
+		{L("petrol", false), L("diesel", false), L("electric", false)},
+		{L("petrol", true), L("diesel", true)},
+		{L("petrol", true), L("electric", true)},
+		{L("diesel", true), L("electric", true)},
+		{L("electric", true), L("automatic", false)},
+		{L("diesel", true), L("convertible", true)},
+		{L("convertible", true), L("hardtop", false), L("softtop", false)},
+	} {
+		cnf.AddClause(NewClause(clause...))
+	}
+
+	// Without probing every literal is settled by a solver call
+	b := NewBackboneExtractor(cnf)
+	b.SetProbing(false)
+	result, err := b.Compute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Satisfiable || len(result.Backbone) != 0 {
+		t.Fatalf("Expected nothing forced, got %v", result.Backbone)
+	}
+
+	b.Assume(L("convertible", false))
+	result, err = b.Compute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Literal{L("convertible", false), L("diesel", true)}
+	if fmt.Sprint(result.Backbone) != fmt.Sprint(expected) {
+		t.Fatalf("Expected %v, got %v", expected, result.Backbone)
+	}
+
+	b.Assume(L("petrol", true))
+	result, err = b.Compute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []Literal{L("automatic", false), L("convertible", false), L("diesel", true), L("electric", false), L("petrol", true)}
+	if fmt.Sprint(result.Backbone) != fmt.Sprint(expected) {
+		t.Fatalf("Expected %v, got %v", expected, result.Backbone)
+	}
+	if result.Reused != 1 {
+		t.Errorf("Expected ¬diesel to be reused, got %d literals", result.Reused)
+	}
+
+	b.Assume(L("electric", true))
+	if result, err = b.Compute(); err != nil || result.Satisfiable || result.Backbone != nil {
+		t.Fatalf("Expected UNSAT, got %+v (%v)", result, err)
+	}
+
+	b.Retract(L("electric", true), L("petrol", true))
+	if result, err = b.Compute(); err != nil || len(result.Backbone) != 2 {
+		t.Fatalf("Expected the backbone of the convertible alone, got %+v (%v)", result, err)
+	}
+	// ¬diesel was proven under an assumption and must not outlive it
+	b.Retract(L("convertible", false))
+	if result, err = b.Compute(); err != nil || len(result.Backbone) != 0 {
+		t.Fatalf("Expected nothing forced, got %+v (%v)", result, err)
+	}
+}
+
+func TestComputeBackbone_Probing(t *testing.T) {
+	// A implies both B and ¬B, so ¬A is a failed literal; C is free
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", true), L("B", false)))
+	cnf.AddClause(NewClause(L("A", true), L("B", true)))
+	cnf.AddClause(NewClause(L("A", false), L("C", false), L("D", false)))
+	result, err := ComputeBackbone(cnf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(result.Backbone) != fmt.Sprint([]Literal{L("A", true)}) {
+		t.Fatalf("Expected ¬A, got %v", result.Backbone)
+	}
+	if result.Probed != 1 {
+		t.Errorf("Expected probing to find ¬A, got %d", result.Probed)
+	}
+}
+
+func TestComputeBackbone_Tautology(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(&Clause{Literals: []Literal{L("A", false), L("A", true), L("B", false)}})
+	cnf.AddClause(NewClause(L("B", false), L("C", false)))
+	for _, probing := range []bool{true, false} {
+		b := NewBackboneExtractor(cnf)
+		b.SetProbing(probing)
+		result, err := b.Compute()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Satisfiable || len(result.Backbone) != 0 {
+			t.Fatalf("Probing %v: expected an empty backbone, got %v", probing, result.Backbone)
+		}
+	}
+}
@@ -0,0 +1,877 @@
+package sat
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+// bvKind is the operator of a bit-vector term
+type bvKind int
+
+const (
+	bvConst bvKind = iota
+	bvVar
+	bvNot
+	bvNeg
+	bvAnd
+	bvOr
+	bvXor
+	bvAdd
+	bvSub
+	bvMul
+	bvShl
+	bvLshr
+	bvAshr
+	bvExtract
+	bvConcat
+	bvIte
+)
+
+// bvOperators are the SMT-LIB names of the operators
+var bvOperators = map[bvKind]string{
+	bvNot: "bvnot", bvNeg: "bvneg", bvAnd: "bvand", bvOr: "bvor", bvXor: "bvxor",
+	bvAdd: "bvadd", bvSub: "bvsub", bvMul: "bvmul", bvShl: "bvshl", bvLshr: "bvlshr",
+	bvAshr: "bvashr", bvExtract: "extract", bvConcat: "concat", bvIte: "ite",
+}
+
+// BVTerm is a fixed-width bit-vector term of the QF_BV fragment: a
+// constant, a variable, or an operator applied to terms. Widths range from
+// 1 to 64 bits and arithmetic wraps around modulo 2^width, as in SMT-LIB.
+// Terms are immutable and may be shared; ill-formed terms, such as an
+// addition of different widths, are reported when they are solved or
+// evaluated.
+type BVTerm struct {
+	kind   bvKind
+	width  int
+	value  uint64     // bvConst
+	name   string     // bvVar
+	args   []*BVTerm  // Operands
+	cond   *BVFormula // bvIte
+	hi, lo int        // bvExtract
+}
+
+// NewBVVar creates a bit-vector variable
+func NewBVVar(name string, width int) *BVTerm {
+	return &BVTerm{kind: bvVar, name: name, width: width}
+}
+
+// NewBVConst creates a bit-vector constant. Bits above width are dropped.
+func NewBVConst(value uint64, width int) *BVTerm {
+	return &BVTerm{kind: bvConst, value: value & bvMask(width), width: width}
+}
+
+// BVIte is cond ? then : otherwise
+func BVIte(cond *BVFormula, then, otherwise *BVTerm) *BVTerm {
+	return &BVTerm{kind: bvIte, cond: cond, args: []*BVTerm{then, otherwise}, width: then.Width()}
+}
+
+// Width returns the number of bits of the term
+func (t *BVTerm) Width() int {
+	if t == nil {
+		return 0
+	}
+	return t.width
+}
+
+func (t *BVTerm) unary(kind bvKind) *BVTerm {
+	return &BVTerm{kind: kind, args: []*BVTerm{t}, width: t.Width()}
+}
+
+func (t *BVTerm) binary(kind bvKind, other *BVTerm) *BVTerm {
+	return &BVTerm{kind: kind, args: []*BVTerm{t, other}, width: t.Width()}
+}
+
+// Not is the bitwise complement
+func (t *BVTerm) Not() *BVTerm { return t.unary(bvNot) }
+
+// Neg is the two's complement negation
+func (t *BVTerm) Neg() *BVTerm { return t.unary(bvNeg) }
+
+// And is the bitwise conjunction
+func (t *BVTerm) And(other *BVTerm) *BVTerm { return t.binary(bvAnd, other) }
+
+// Or is the bitwise disjunction
+func (t *BVTerm) Or(other *BVTerm) *BVTerm { return t.binary(bvOr, other) }
+
+// Xor is the bitwise exclusive or
+func (t *BVTerm) Xor(other *BVTerm) *BVTerm { return t.binary(bvXor, other) }
+
+// Add is the wrapping sum
+func (t *BVTerm) Add(other *BVTerm) *BVTerm { return t.binary(bvAdd, other) }
+
+// Sub is the wrapping difference
+func (t *BVTerm) Sub(other *BVTerm) *BVTerm { return t.binary(bvSub, other) }
+
+// Mul is the wrapping product
+func (t *BVTerm) Mul(other *BVTerm) *BVTerm { return t.binary(bvMul, other) }
+
+// Shl shifts left by amount, a term of the same width. Shifting by the
+// width or more gives zero.
+func (t *BVTerm) Shl(amount *BVTerm) *BVTerm { return t.binary(bvShl, amount) }
+
+// Lshr shifts right by amount, filling with zeros
+func (t *BVTerm) Lshr(amount *BVTerm) *BVTerm { return t.binary(bvLshr, amount) }
+
+// Ashr shifts right by amount, filling with the sign bit
+func (t *BVTerm) Ashr(amount *BVTerm) *BVTerm { return t.binary(bvAshr, amount) }
+
+// Extract returns bits hi down to lo, inclusive
+func (t *BVTerm) Extract(hi, lo int) *BVTerm {
+	return &BVTerm{kind: bvExtract, args: []*BVTerm{t}, hi: hi, lo: lo, width: hi - lo + 1}
+}
+
+// Concat returns t followed by low, with t in the high bits
+func (t *BVTerm) Concat(low *BVTerm) *BVTerm {
+	return &BVTerm{kind: bvConcat, args: []*BVTerm{t, low}, width: t.Width() + low.Width()}
+}
+
+// String returns the term in SMT-LIB syntax
+func (t *BVTerm) String() string {
+	switch {
+	case t == nil:
+		return "<nil>"
+	case t.kind == bvConst:
+		return fmt.Sprintf("(_ bv%d %d)", t.value, t.width)
+	case t.kind == bvVar:
+		return t.name
+	case t.kind == bvExtract:
+		return fmt.Sprintf("((_ extract %d %d) %s)", t.hi, t.lo, t.args[0])
+	}
+	parts := []string{bvOperators[t.kind]}
+	if t.kind == bvIte {
+		parts = append(parts, t.cond.String())
+	}
+	for _, arg := range t.args {
+		parts = append(parts, arg.String())
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// check validates the term's own widths; its operands are checked on
+// their own
+func (t *BVTerm) check() error {
+	fail := func(msg string) error {
+		return core.NewLogicError("sat", "BVTerm.check", fmt.Sprintf("%s: %s", t, msg))
+	}
+	if t == nil {
+		return core.NewLogicError("sat", "BVTerm.check", "nil term")
+	}
+	for _, arg := range t.args {
+		if arg == nil {
+			return fail("nil operand")
+		}
+	}
+	if t.width < 1 || t.width > 64 {
+		return fail(fmt.Sprintf("width %d is outside 1 to 64", t.width))
+	}
+	switch t.kind {
+	case bvVar:
+		if t.name == "" {
+			return fail("variable without a name")
+		}
+	case bvExtract:
+		if t.lo < 0 || t.hi >= t.args[0].width {
+			return fail(fmt.Sprintf("bits %d to %d of a %d-bit term", t.hi, t.lo, t.args[0].width))
+		}
+	case bvIte:
+		if t.cond == nil {
+			return fail("nil condition")
+		}
+		fallthrough
+	case bvAnd, bvOr, bvXor, bvAdd, bvSub, bvMul, bvShl, bvLshr, bvAshr:
+		if t.args[0].width != t.args[1].width {
+			return fail(fmt.Sprintf("widths %d and %d differ", t.args[0].width, t.args[1].width))
+		}
+	}
+	return nil
+}
+
+// Eval computes the term's value, taking variables from values. Missing
+// variables are zero.
+func (t *BVTerm) Eval(values map[string]uint64) (uint64, error) {
+	if err := t.check(); err != nil {
+		return 0, err
+	}
+	var args [2]uint64
+	for i, arg := range t.args {
+		v, err := arg.Eval(values)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+	a, b, w := args[0], args[1], uint64(t.width)
+	var v uint64
+	switch t.kind {
+	case bvConst:
+		v = t.value
+	case bvVar:
+		v = values[t.name]
+	case bvNot:
+		v = ^a
+	case bvNeg:
+		v = -a
+	case bvAnd:
+		v = a & b
+	case bvOr:
+		v = a | b
+	case bvXor:
+		v = a ^ b
+	case bvAdd:
+		v = a + b
+	case bvSub:
+		v = a - b
+	case bvMul:
+		v = a * b
+	case bvShl:
+		if b < w {
+			v = a << b
+		}
+	case bvLshr:
+		if b < w {
+			v = a >> b
+		}
+	case bvAshr:
+		if b >= w {
+			b = w - 1
+		}
+		v = uint64(bvSigned(a, t.width) >> b)
+	case bvExtract:
+		v = a >> uint(t.lo)
+	case bvConcat:
+		v = a<<uint(t.args[1].width) | b
+	case bvIte:
+		holds, err := t.cond.Eval(values)
+		if err != nil {
+			return 0, err
+		}
+		if v = b; holds {
+			v = a
+		}
+	}
+	return v & bvMask(t.width), nil
+}
+
+// bvMask has the low width bits set
+func bvMask(width int) uint64 {
+	if width >= 64 {
+		return ^uint64(0)
+	}
+	if width <= 0 {
+		return 0
+	}
+	return 1<<uint(width) - 1
+}
+
+// bvSigned reads the low width bits of v as a two's complement number
+func bvSigned(v uint64, width int) int64 {
+	shift := uint(64 - width)
+	return int64(v<<shift) >> shift
+}
+
+// bvRelation is the connective or predicate of a BVFormula
+type bvRelation int
+
+const (
+	bvEq bvRelation = iota
+	bvUlt
+	bvUle
+	bvSlt
+	bvSle
+	bvFormulaNot
+	bvFormulaAnd
+	bvFormulaOr
+)
+
+// bvRelations are the SMT-LIB names of the relations
+var bvRelations = map[bvRelation]string{
+	bvEq: "=", bvUlt: "bvult", bvUle: "bvule", bvSlt: "bvslt", bvSle: "bvsle",
+	bvFormulaNot: "not", bvFormulaAnd: "and", bvFormulaOr: "or",
+}
+
+// BVFormula is a Boolean combination of comparisons between bit-vector
+// terms
+type BVFormula struct {
+	relation bvRelation
+	terms    [2]*BVTerm   // Compared terms
+	args     []*BVFormula // Operands of not, and, or
+}
+
+func (t *BVTerm) compare(relation bvRelation, other *BVTerm) *BVFormula {
+	return &BVFormula{relation: relation, terms: [2]*BVTerm{t, other}}
+}
+
+// Eq holds if the terms are equal
+func (t *BVTerm) Eq(other *BVTerm) *BVFormula { return t.compare(bvEq, other) }
+
+// Ne holds if the terms differ
+func (t *BVTerm) Ne(other *BVTerm) *BVFormula { return t.Eq(other).Not() }
+
+// Ult is unsigned less than
+func (t *BVTerm) Ult(other *BVTerm) *BVFormula { return t.compare(bvUlt, other) }
+
+// Ule is unsigned less than or equal
+func (t *BVTerm) Ule(other *BVTerm) *BVFormula { return t.compare(bvUle, other) }
+
+// Ugt is unsigned greater than
+func (t *BVTerm) Ugt(other *BVTerm) *BVFormula { return other.Ult(t) }
+
+// Uge is unsigned greater than or equal
+func (t *BVTerm) Uge(other *BVTerm) *BVFormula { return other.Ule(t) }
+
+// Slt is signed less than
+func (t *BVTerm) Slt(other *BVTerm) *BVFormula { return t.compare(bvSlt, other) }
+
+// Sle is signed less than or equal
+func (t *BVTerm) Sle(other *BVTerm) *BVFormula { return t.compare(bvSle, other) }
+
+// Sgt is signed greater than
+func (t *BVTerm) Sgt(other *BVTerm) *BVFormula { return other.Slt(t) }
+
+// Sge is signed greater than or equal
+func (t *BVTerm) Sge(other *BVTerm) *BVFormula { return other.Sle(t) }
+
+// Not is the negation of f
+func (f *BVFormula) Not() *BVFormula {
+	return &BVFormula{relation: bvFormulaNot, args: []*BVFormula{f}}
+}
+
+// And is the conjunction of f and others
+func (f *BVFormula) And(others ...*BVFormula) *BVFormula {
+	return &BVFormula{relation: bvFormulaAnd, args: append([]*BVFormula{f}, others...)}
+}
+
+// Or is the disjunction of f and others
+func (f *BVFormula) Or(others ...*BVFormula) *BVFormula {
+	return &BVFormula{relation: bvFormulaOr, args: append([]*BVFormula{f}, others...)}
+}
+
+// Implies is ¬f ∨ other
+func (f *BVFormula) Implies(other *BVFormula) *BVFormula {
+	return f.Not().Or(other)
+}
+
+// String returns the formula in SMT-LIB syntax
+func (f *BVFormula) String() string {
+	if f == nil {
+		return "<nil>"
+	}
+	parts := []string{bvRelations[f.relation]}
+	if f.relation < bvFormulaNot {
+		parts = append(parts, f.terms[0].String(), f.terms[1].String())
+	}
+	for _, arg := range f.args {
+		parts = append(parts, arg.String())
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// check validates the formula's own operands
+func (f *BVFormula) check() error {
+	if f == nil {
+		return core.NewLogicError("sat", "BVFormula.check", "nil formula")
+	}
+	if f.relation >= bvFormulaNot {
+		return nil
+	}
+	for _, t := range f.terms {
+		if err := t.check(); err != nil {
+			return err
+		}
+	}
+	if f.terms[0].width != f.terms[1].width {
+		return core.NewLogicError("sat", "BVFormula.check",
+			fmt.Sprintf("%s: widths %d and %d differ", f, f.terms[0].width, f.terms[1].width))
+	}
+	return nil
+}
+
+// Eval reports whether the formula holds, taking variables from values.
+// Missing variables are zero.
+func (f *BVFormula) Eval(values map[string]uint64) (bool, error) {
+	if err := f.check(); err != nil {
+		return false, err
+	}
+	switch f.relation {
+	case bvFormulaNot:
+		holds, err := f.args[0].Eval(values)
+		return !holds, err
+	case bvFormulaAnd, bvFormulaOr:
+		for _, arg := range f.args {
+			holds, err := arg.Eval(values)
+			if err != nil {
+				return false, err
+			}
+			if holds == (f.relation == bvFormulaOr) {
+				return holds, nil
+			}
+		}
+		return f.relation == bvFormulaAnd, nil
+	}
+	a, err := f.terms[0].Eval(values)
+	if err != nil {
+		return false, err
+	}
+	b, err := f.terms[1].Eval(values)
+	if err != nil {
+		return false, err
+	}
+	w := f.terms[0].width
+	switch f.relation {
+	case bvEq:
+		return a == b, nil
+	case bvUlt:
+		return a < b, nil
+	case bvUle:
+		return a <= b, nil
+	case bvSlt:
+		return bvSigned(a, w) < bvSigned(b, w), nil
+	default:
+		return bvSigned(a, w) <= bvSigned(b, w), nil
+	}
+}
+
+// BVResult represents the result of bit-vector solving
+type BVResult struct {
+	Satisfiable bool
+	Values      map[string]uint64 // Value of every variable in a model
+	Statistics  SolverStatistics
+	Error       error
+	Status      SolverStatus
+	Reason      UnknownReason
+}
+
+// BVSolver decides conjunctions of bit-vector formulas by bit-blasting
+// them to CNF with the Tseitin transformation, as CNFConverter does for
+// Boolean formulas, and solving the CNF with a CDCLSolver. Bit i of
+// variable x becomes the Boolean variable "x[i]", bit 0 being the least
+// significant.
+type BVSolver struct {
+	assertions []*BVFormula
+	solver     *CDCLSolver
+}
+
+// NewBVSolver creates a bit-vector solver without assertions
+func NewBVSolver() *BVSolver {
+	return &BVSolver{solver: NewCDCLSolver()}
+}
+
+// Assert adds formulas that every model must satisfy
+func (s *BVSolver) Assert(formulas ...*BVFormula) {
+	s.assertions = append(s.assertions, formulas...)
+}
+
+// Reset drops every assertion
+func (s *BVSolver) Reset() {
+	s.assertions = nil
+}
+
+// ToCNF bit-blasts the assertions
+func (s *BVSolver) ToCNF() (*CNF, error) {
+	b, err := s.blast()
+	if err != nil {
+		return nil, err
+	}
+	return b.cnf, nil
+}
+
+// Solve searches for values satisfying every assertion
+func (s *BVSolver) Solve() *BVResult {
+	return s.SolveWithTimeout(0)
+}
+
+// SolveWithTimeout solves with timeout
+func (s *BVSolver) SolveWithTimeout(timeout time.Duration) *BVResult {
+	b, err := s.blast()
+	if err != nil {
+		return &BVResult{Error: err, Status: StatusUnknown, Reason: ReasonError}
+	}
+	result := s.solver.SolveWithTimeout(b.cnf, timeout)
+	out := &BVResult{
+		Satisfiable: result.Satisfiable,
+		Statistics:  result.Statistics,
+		Error:       result.Error,
+		Status:      result.Status,
+		Reason:      result.Reason,
+	}
+	if result.Satisfiable {
+		out.Values = b.values(result.Assignment)
+	}
+	return out
+}
+
+func (s *BVSolver) blast() (*bitBlaster, error) {
+	b := newBitBlaster()
+	for _, f := range s.assertions {
+		lit, err := b.formula(f)
+		if err != nil {
+			return nil, err
+		}
+		b.add(lit)
+	}
+	return b, nil
+}
+
+// bitBlaster translates terms to vectors of literals, least significant
+// bit first, and formulas to single literals, adding the defining clauses
+// of every gate to cnf. Gates on constants or repeated inputs are folded.
+type bitBlaster struct {
+	cnf   *CNF
+	next  int
+	truth Literal // Forced true by a unit clause
+	terms map[*BVTerm][]Literal
+	vars  map[string][]Literal
+}
+
+func newBitBlaster() *bitBlaster {
+	b := &bitBlaster{
+		cnf:   NewCNF(),
+		truth: Literal{Variable: "__bv_true"},
+		terms: make(map[*BVTerm][]Literal),
+		vars:  make(map[string][]Literal),
+	}
+	b.cnf.AddClause(NewClause(b.truth))
+	return b
+}
+
+// values reads every variable's value from model
+func (b *bitBlaster) values(model Assignment) map[string]uint64 {
+	values := make(map[string]uint64, len(b.vars))
+	for name, bits := range b.vars {
+		var v uint64
+		for i, bit := range bits {
+			if model[bit.Variable] {
+				v |= 1 << uint(i)
+			}
+		}
+		values[name] = v
+	}
+	return values
+}
+
+func (b *bitBlaster) constant(value bool) Literal {
+	if value {
+		return b.truth
+	}
+	return b.truth.Negate()
+}
+
+// isConstant reports the value of a constant literal
+func (b *bitBlaster) isConstant(l Literal) (value, ok bool) {
+	if l.Variable != b.truth.Variable {
+		return false, false
+	}
+	return !l.Negated, true
+}
+
+func (b *bitBlaster) fresh() Literal {
+	b.next++
+	return Literal{Variable: fmt.Sprintf("__bv_%d", b.next)}
+}
+
+// add adds the clause of lits unless it is a tautology
+func (b *bitBlaster) add(lits ...Literal) {
+	if !isTautology(lits) {
+		b.cnf.AddClause(NewClause(lits...))
+	}
+}
+
+// and2 returns o ↔ x ∧ y: (¬o ∨ x) ∧ (¬o ∨ y) ∧ (o ∨ ¬x ∨ ¬y)
+func (b *bitBlaster) and2(x, y Literal) Literal {
+	if v, ok := b.isConstant(x); ok {
+		if !v {
+			return x
+		}
+		return y
+	}
+	if v, ok := b.isConstant(y); ok {
+		if !v {
+			return y
+		}
+		return x
+	}
+	if x == y {
+		return x
+	}
+	if x == y.Negate() {
+		return b.constant(false)
+	}
+	o := b.fresh()
+	b.add(o.Negate(), x)
+	b.add(o.Negate(), y)
+	b.add(o, x.Negate(), y.Negate())
+	return o
+}
+
+// or2 returns x ∨ y = ¬(¬x ∧ ¬y)
+func (b *bitBlaster) or2(x, y Literal) Literal {
+	return b.and2(x.Negate(), y.Negate()).Negate()
+}
+
+// xor2 returns o ↔ x ⊕ y
+func (b *bitBlaster) xor2(x, y Literal) Literal {
+	if v, ok := b.isConstant(x); ok {
+		if v {
+			return y.Negate()
+		}
+		return y
+	}
+	if v, ok := b.isConstant(y); ok {
+		if v {
+			return x.Negate()
+		}
+		return x
+	}
+	if x == y {
+		return b.constant(false)
+	}
+	if x == y.Negate() {
+		return b.constant(true)
+	}
+	o := b.fresh()
+	b.add(o.Negate(), x, y)
+	b.add(o.Negate(), x.Negate(), y.Negate())
+	b.add(o, x.Negate(), y)
+	b.add(o, x, y.Negate())
+	return o
+}
+
+// mux returns o ↔ (s ? x : y)
+func (b *bitBlaster) mux(s, x, y Literal) Literal {
+	if v, ok := b.isConstant(s); ok {
+		if v {
+			return x
+		}
+		return y
+	}
+	if x == y {
+		return x
+	}
+	o := b.fresh()
+	b.add(s.Negate(), x.Negate(), o)
+	b.add(s.Negate(), x, o.Negate())
+	b.add(s, y.Negate(), o)
+	b.add(s, y, o.Negate())
+	return o
+}
+
+// adder returns x + y + carry, dropping the carry out, and the carry out
+func (b *bitBlaster) adder(x, y []Literal, carry Literal) ([]Literal, Literal) {
+	sum := make([]Literal, len(x))
+	for i := range x {
+		half := b.xor2(x[i], y[i])
+		sum[i] = b.xor2(half, carry)
+		carry = b.or2(b.and2(x[i], y[i]), b.and2(half, carry))
+	}
+	return sum, carry
+}
+
+// negate returns the complement of every bit
+func negateBits(x []Literal) []Literal {
+	out := make([]Literal, len(x))
+	for i, lit := range x {
+		out[i] = lit.Negate()
+	}
+	return out
+}
+
+// multiply adds x shifted left by i for every set bit i of y
+func (b *bitBlaster) multiply(x, y []Literal) []Literal {
+	product := make([]Literal, len(x))
+	for i := range product {
+		product[i] = b.constant(false)
+	}
+	for i := range y {
+		partial := make([]Literal, len(x))
+		for j := range partial {
+			if j < i {
+				partial[j] = b.constant(false)
+			} else {
+				partial[j] = b.and2(x[j-i], y[i])
+			}
+		}
+		product, _ = b.adder(product, partial, b.constant(false))
+	}
+	return product
+}
+
+// shift is a barrel shifter: stage k shifts by 2^k if bit k of amount is
+// set. Amounts of the width or more give fill in every bit.
+func (b *bitBlaster) shift(kind bvKind, x, amount []Literal) []Literal {
+	w := len(x)
+	fill := b.constant(false)
+	if kind == bvAshr {
+		fill = x[w-1]
+	}
+	current := x
+	overflow := b.constant(false)
+	for k, bit := range amount {
+		if k >= 31 || 1<<uint(k) >= w {
+			overflow = b.or2(overflow, bit)
+			continue
+		}
+		step := 1 << uint(k)
+		next := make([]Literal, w)
+		for i := range next {
+			shifted := fill
+			if kind == bvShl {
+				shifted = b.constant(false)
+				if i >= step {
+					shifted = current[i-step]
+				}
+			} else if i+step < w {
+				shifted = current[i+step]
+			}
+			next[i] = b.mux(bit, shifted, current[i])
+		}
+		current = next
+	}
+	out := make([]Literal, w)
+	for i := range out {
+		out[i] = b.mux(overflow, fill, current[i])
+	}
+	return out
+}
+
+// lessThan returns x < y, unsigned: the carry out of x + ¬y + 1 is clear
+func (b *bitBlaster) lessThan(x, y []Literal) Literal {
+	_, carry := b.adder(x, negateBits(y), b.constant(true))
+	return carry.Negate()
+}
+
+// signedLessThan compares with the sign bits flipped
+func (b *bitBlaster) signedLessThan(x, y []Literal) Literal {
+	w := len(x)
+	xs := append(append([]Literal(nil), x[:w-1]...), x[w-1].Negate())
+	ys := append(append([]Literal(nil), y[:w-1]...), y[w-1].Negate())
+	return b.lessThan(xs, ys)
+}
+
+// term returns the bits of t
+func (b *bitBlaster) term(t *BVTerm) ([]Literal, error) {
+	if bits, ok := b.terms[t]; ok {
+		return bits, nil
+	}
+	if err := t.check(); err != nil {
+		return nil, err
+	}
+	args := make([][]Literal, len(t.args))
+	for i, arg := range t.args {
+		bits, err := b.term(arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = bits
+	}
+
+	bits := make([]Literal, t.width)
+	switch t.kind {
+	case bvConst:
+		for i := range bits {
+			bits[i] = b.constant(t.value&(1<<uint(i)) != 0)
+		}
+	case bvVar:
+		if known, ok := b.vars[t.name]; ok {
+			if len(known) != t.width {
+				return nil, core.NewLogicError("sat", "BVSolver.Solve",
+					fmt.Sprintf("variable %s used with widths %d and %d", t.name, len(known), t.width))
+			}
+			bits = known
+			break
+		}
+		for i := range bits {
+			bits[i] = Literal{Variable: fmt.Sprintf("%s[%d]", t.name, i)}
+		}
+		b.vars[t.name] = bits
+	case bvNot:
+		bits = negateBits(args[0])
+	case bvNeg:
+		zero := make([]Literal, t.width)
+		for i := range zero {
+			zero[i] = b.constant(false)
+		}
+		bits, _ = b.adder(zero, negateBits(args[0]), b.constant(true))
+	case bvAnd, bvOr, bvXor:
+		gate := map[bvKind]func(x, y Literal) Literal{bvAnd: b.and2, bvOr: b.or2, bvXor: b.xor2}[t.kind]
+		for i := range bits {
+			bits[i] = gate(args[0][i], args[1][i])
+		}
+	case bvAdd:
+		bits, _ = b.adder(args[0], args[1], b.constant(false))
+	case bvSub:
+		bits, _ = b.adder(args[0], negateBits(args[1]), b.constant(true))
+	case bvMul:
+		bits = b.multiply(args[0], args[1])
+	case bvShl, bvLshr, bvAshr:
+		bits = b.shift(t.kind, args[0], args[1])
+	case bvExtract:
+		copy(bits, args[0][t.lo:t.hi+1])
+	case bvConcat:
+		bits = append(append(bits[:0], args[1]...), args[0]...)
+	case bvIte:
+		cond, err := b.formula(t.cond)
+		if err != nil {
+			return nil, err
+		}
+		for i := range bits {
+			bits[i] = b.mux(cond, args[0][i], args[1][i])
+		}
+	}
+	b.terms[t] = bits
+	return bits, nil
+}
+
+// formula returns a literal equivalent to f
+func (b *bitBlaster) formula(f *BVFormula) (Literal, error) {
+	if err := f.check(); err != nil {
+		return Literal{}, err
+	}
+	switch f.relation {
+	case bvFormulaNot:
+		lit, err := b.formula(f.args[0])
+		return lit.Negate(), err
+	case bvFormulaAnd, bvFormulaOr:
+		or := f.relation == bvFormulaOr
+		result := b.constant(!or)
+		for _, arg := range f.args {
+			lit, err := b.formula(arg)
+			if err != nil {
+				return Literal{}, err
+			}
+			if or {
+				result = b.or2(result, lit)
+			} else {
+				result = b.and2(result, lit)
+			}
+		}
+		return result, nil
+	}
+
+	x, err := b.term(f.terms[0])
+	if err != nil {
+		return Literal{}, err
+	}
+	y, err := b.term(f.terms[1])
+	if err != nil {
+		return Literal{}, err
+	}
+	switch f.relation {
+	case bvEq:
+		result := b.constant(true)
+		for i := range x {
+			result = b.and2(result, b.xor2(x[i], y[i]).Negate())
+		}
+		return result, nil
+	case bvUlt:
+		return b.lessThan(x, y), nil
+	case bvUle:
+		return b.lessThan(y, x).Negate(), nil
+	case bvSlt:
+		return b.signedLessThan(x, y), nil
+	default:
+		return b.signedLessThan(y, x).Negate(), nil
+	}
+}
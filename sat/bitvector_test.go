@@ -0,0 +1,147 @@
+package sat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBVSolver_Example(t *testing.T) {
+	x, y := NewBVVar("x", 8), NewBVVar("y", 8)
+	s := NewBVSolver()
+	s.Assert(x.Add(y).Eq(NewBVConst(0xFF, 8)), x.And(y).Eq(NewBVConst(0, 8)), x.Ugt(NewBVConst(0x10, 8)))
+	result := s.Solve()
+	if result.Error != nil || !result.Satisfiable {
+		t.Fatalf("Expected SAT, got %v (%v)", result.Status, result.Error)
+	}
+	vx, vy := result.Values["x"], result.Values["y"]
+	if vx+vy != 0xFF || vx&vy != 0 || vx <= 0x10 {
+		t.Errorf("Model x=%#x y=%#x violates the assertions", vx, vy)
+	}
+
+	// x + y == 0xFF with x & y == 0 forces y == ^x, so y == x is impossible
+	s.Assert(x.Eq(y))
+	if result := s.Solve(); result.Satisfiable || result.Status != StatusUNSAT {
+		t.Errorf("Expected UNSAT, got %v", result.Status)
+	}
+	s.Reset()
+	if result := s.Solve(); !result.Satisfiable {
+		t.Errorf("Expected SAT without assertions, got %v", result.Status)
+	}
+}
+
+func TestBVSolver_Operators(t *testing.T) {
+	a, b := NewBVVar("a", 4), NewBVVar("b", 4)
+	c := func(value uint64) *BVTerm { return NewBVConst(value, 4) }
+	x, y := NewBVVar("x", 1), NewBVVar("y", 1)
+	testCases := []struct {
+		description string
+		formula     *BVFormula
+		expectedSat bool
+	}{
+		{"addition wraps", a.Add(b).Eq(c(0)).And(a.Ne(c(0))), true},
+		{"subtraction adds the negation", a.Sub(b).Ne(a.Add(b.Neg())), false},
+		{"doubling is a left shift", a.Mul(c(2)).Ne(a.Shl(c(1))), false},
+		{"an even factor gives an even product", a.Mul(b).Eq(c(1)).And(a.Eq(c(2))), false},
+		{"xor is or without and", a.Xor(b).Ne(a.Or(b).Sub(a.And(b))), false},
+		{"not is the bitwise complement", a.Not().Add(a).Ne(c(15)), false},
+		{"logical shift right", a.Lshr(c(2)).Eq(c(3)), true},
+		{"arithmetic shift keeps the sign", a.Slt(c(0)).And(a.Ashr(c(3)).Ne(c(15))), false},
+		{"shifting by the width clears", a.Shl(c(4)).Ne(c(0)), false},
+		{"shift by a variable amount", a.Shl(b).Eq(c(8)).And(b.Ugt(c(2))), true},
+		{"extract and concat", a.Extract(1, 0).Concat(a.Extract(3, 2)).Eq(b).And(b.Eq(c(6))), true},
+		{"unsigned and signed order differ", a.Ult(b).And(a.Sgt(b)), true},
+		{"strict order is irreflexive", a.Slt(b).And(b.Sle(a)), false},
+		{"antisymmetry", a.Uge(b).And(b.Uge(a), a.Ne(b)), false},
+		{"ite picks the signed minimum", BVIte(a.Slt(b), a, b).Sgt(a), false},
+		{"disjunction", a.Ult(c(2)).Or(a.Ugt(c(13))).And(a.Uge(c(2)), a.Ule(c(13))), false},
+		{"implication", a.Eq(c(0)).Implies(b.Eq(c(0))).And(a.Eq(c(0)), b.Ne(c(0))), false},
+		{"one-bit addition is xor", x.Add(y).Ne(x.Xor(y)), false},
+		{"one-bit signed order", x.Slt(y), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			s := NewBVSolver()
+			s.Assert(tc.formula)
+			result := s.Solve()
+			if result.Error != nil {
+				t.Fatalf("Solver error: %v", result.Error)
+			}
+			if result.Satisfiable != tc.expectedSat {
+				t.Fatalf("Expected satisfiable=%v, got %v for %v", tc.expectedSat, result.Status, tc.formula)
+			}
+			if !result.Satisfiable {
+				return
+			}
+			if holds, err := tc.formula.Eval(result.Values); err != nil || !holds {
+				t.Errorf("Model %v violates %v", result.Values, tc.formula)
+			}
+		})
+	}
+}
+
+func TestBVSolver_Arithmetic(t *testing.T) {
+	// Factor 143 as a product of two 8-bit numbers greater than 1,
+	// computed in 16 bits so the product cannot wrap
+	p, q := NewBVVar("p", 8), NewBVVar("q", 8)
+	zero := NewBVConst(0, 8)
+	wide := zero.Concat(p).Mul(zero.Concat(q))
+	s := NewBVSolver()
+	s.Assert(wide.Eq(NewBVConst(143, 16)), p.Ugt(NewBVConst(1, 8)), q.Ugt(p))
+	result := s.Solve()
+	if !result.Satisfiable || result.Values["p"] != 11 || result.Values["q"] != 13 {
+		t.Errorf("Expected 11 × 13, got %v (%v)", result.Values, result.Status)
+	}
+
+	// Signed and unsigned order differ on the sign bit
+	v := NewBVVar("v", 64)
+	s = NewBVSolver()
+	s.Assert(v.Slt(NewBVConst(0, 64)), v.Ashr(NewBVConst(62, 64)).Eq(NewBVConst(^uint64(1), 64)))
+	result = s.Solve()
+	if !result.Satisfiable || result.Values["v"]>>62 != 2 {
+		t.Errorf("Expected top bits 10, got %#x (%v)", result.Values["v"], result.Status)
+	}
+}
+
+func TestBVSolver_Errors(t *testing.T) {
+	a8, a16 := NewBVVar("a", 8), NewBVVar("a", 16)
+	b8 := NewBVVar("b", 8)
+	for _, f := range []*BVFormula{
+		a8.Add(NewBVVar("c", 16)).Eq(b8),
+		a8.Eq(NewBVVar("c", 16)),
+		a8.Extract(8, 0).Eq(NewBVVar("c", 9)),
+		a8.Concat(NewBVVar("c", 64)).Eq(a8),
+		NewBVVar("c", 0).Eq(NewBVVar("d", 0)),
+		a8.Eq(b8).And(a16.Eq(NewBVConst(0, 16))),
+		a8.Eq(b8).And(nil),
+	} {
+		s := NewBVSolver()
+		s.Assert(f)
+		result := s.Solve()
+		if result.Error == nil || result.Status != StatusUnknown {
+			t.Errorf("Expected an error for %v, got %v", f, result.Status)
+		}
+	}
+
+	if _, err := a8.Add(NewBVVar("c", 4)).Eval(nil); err == nil {
+		t.Error("Expected a width error from Eval")
+	}
+	got := a8.Extract(3, 0).Concat(NewBVConst(5, 4)).Ult(b8).String()
+	if want := "(bvult (concat ((_ extract 3 0) a) (_ bv5 4)) b)"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	s := NewBVSolver()
+	s.Assert(a8.Ule(b8))
+	cnf, err := s.ToCNF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := strings.Join(cnf.Variables, " ")
+	for i := 0; i < 8; i++ {
+		if !strings.Contains(names, fmt.Sprintf("a[%d]", i)) {
+			t.Errorf("Expected bit variable a[%d] in %v", i, cnf.Variables)
+		}
+	}
+}
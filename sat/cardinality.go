@@ -0,0 +1,189 @@
+package sat
+
+import (
+	"sort"
+)
+
+// constraintQueue holds the constraints to visit in the next propagation
+// round, each at most once
+type constraintQueue struct {
+	dirty  []int32
+	queued []bool
+}
+
+// touch queues constraint i for a visit
+func (q *constraintQueue) touch(i int32) {
+	if !q.queued[i] {
+		q.queued[i] = true
+		q.dirty = append(q.dirty, i)
+	}
+}
+
+// pop dequeues a constraint to visit
+func (q *constraintQueue) pop() int32 {
+	i := q.dirty[len(q.dirty)-1]
+	q.dirty = q.dirty[:len(q.dirty)-1]
+	q.queued[i] = false
+	return i
+}
+
+// cardinalityCounter keeps the number of true and false literals of each
+// cardinality constraint, counted from the trail as literals are assigned
+// and uncounted as they are backtracked, so that propagation only visits
+// the constraints whose counts changed.
+type cardinalityCounter struct {
+	constraintQueue // Constraints whose counts changed since their visit
+	clauses         []*CardinalityClause
+	occurs          [][]int32 // Literal -> constraints containing it
+	trueCount       []int
+	falseCount      []int
+	head            int // Trail literals counted
+}
+
+// newCardinalityCounter numbers the variables of clauses in trail and
+// indexes the constraints by literal. It returns nil without constraints.
+func newCardinalityCounter(trail *literalTrail, clauses []*CardinalityClause) *cardinalityCounter {
+	if len(clauses) == 0 {
+		return nil
+	}
+	for _, card := range clauses {
+		for _, lit := range card.Literals {
+			trail.varOf(lit.Variable)
+		}
+	}
+	k := &cardinalityCounter{
+		constraintQueue: constraintQueue{queued: make([]bool, len(clauses))},
+		clauses:         clauses,
+		occurs:          make([][]int32, len(trail.values)),
+		trueCount:       make([]int, len(clauses)),
+		falseCount:      make([]int, len(clauses)),
+	}
+	for i, card := range clauses {
+		for _, lit := range card.Literals {
+			p := trail.lit(lit)
+			k.occurs[p] = append(k.occurs[p], int32(i))
+		}
+		// Every constraint is visited once, as one with K = 0 forces its
+		// literals before any assignment
+		k.touch(int32(i))
+	}
+	return k
+}
+
+// add adds delta to the counts of the constraints that p makes true or
+// false
+func (k *cardinalityCounter) add(p Lit, delta int) {
+	// occurs holds both literals of every variable it numbers
+	if int(p) >= len(k.occurs) {
+		return
+	}
+	for _, i := range k.occurs[p] {
+		k.trueCount[i] += delta
+		k.touch(i)
+	}
+	for _, i := range k.occurs[p.Not()] {
+		k.falseCount[i] += delta
+		k.touch(i)
+	}
+}
+
+// count counts the trail literals assigned since the last call
+func (k *cardinalityCounter) count(trail *literalTrail) {
+	for ; k.head < len(trail.lits); k.head++ {
+		k.add(trail.lits[k.head], 1)
+	}
+}
+
+// undo uncounts the trail literals from position cut on. It runs before
+// the trail drops them.
+func (k *cardinalityCounter) undo(trail *literalTrail, cut int) {
+	for ; k.head > cut; k.head-- {
+		k.add(trail.lits[k.head-1], -1)
+	}
+}
+
+// propagateCardinality enforces the cardinality constraints of the
+// extended formula on the current assignment. A constraint with K true
+// literals forces its unassigned literals false; one with more than K is a
+// conflict. Both are explained by clauses over the true literals, so that
+// FirstUIPAnalyzer can resolve on them like on any other reason:
+//
+//	forced ¬l:  (¬l ∨ ¬t1 ∨ ... ∨ ¬tK)
+//	conflict:   (¬t1 ∨ ... ∨ ¬tK+1)
+//
+// Only the constraints whose counts changed are visited. Literals forced
+// in this round are counted in the next, which the caller runs whenever a
+// literal was forced. It returns the conflict clause, if any, and whether
+// a literal was forced.
+func (c *CDCLSolver) propagateCardinality() (*Clause, bool) {
+	counter := c.cardinality
+	if counter == nil {
+		return nil, false
+	}
+	counter.count(c.trail)
+	propagated := false
+	for len(counter.dirty) > 0 {
+		i := counter.pop()
+		card := counter.clauses[i]
+		satisfied := counter.trueCount[i]
+		unassigned := len(card.Literals) - satisfied - counter.falseCount[i]
+		if satisfied < card.K || (satisfied == card.K && unassigned == 0) {
+			continue
+		}
+
+		trueLits := make([]Literal, 0, satisfied)
+		for _, lit := range card.Literals {
+			if value, assigned := c.assignment[lit.Variable]; assigned && value != lit.Negated {
+				trueLits = append(trueLits, lit)
+			}
+		}
+
+		if len(trueLits) > card.K {
+			return c.cardinalityConflict(card, trueLits), propagated
+		}
+
+		// Exactly K true literals: the rest must be false
+		for _, lit := range card.Literals {
+			if c.assignment.IsAssigned(lit.Variable) {
+				continue
+			}
+			reason := c.cardinalityClause(trueLits, lit.Negate())
+			reason.ConflictType = "CARD_REASON"
+			c.assign(lit.Variable, lit.Negated, reason)
+			c.statistics.Propagations++
+			propagated = true
+		}
+	}
+	return nil, propagated
+}
+
+// cardinalityConflict explains a constraint with more than K true literals
+// by the K+1 of them assigned at the highest decision levels. One of those
+// is on the current level, as the constraint held before its last
+// propagation.
+func (c *CDCLSolver) cardinalityConflict(card *CardinalityClause, trueLits []Literal) *Clause {
+	sort.SliceStable(trueLits, func(i, j int) bool {
+		return c.trail.GetLevel(trueLits[i].Variable) > c.trail.GetLevel(trueLits[j].Variable)
+	})
+	need := card.K + 1
+	if need < 0 {
+		need = 0
+	}
+	clause := c.cardinalityClause(trueLits[:need])
+	clause.ConflictType = "CARD_CONFLICT"
+	return clause
+}
+
+// cardinalityClause builds the learned clause of extra and the negations
+// of trueLits
+func (c *CDCLSolver) cardinalityClause(trueLits []Literal, extra ...Literal) *Clause {
+	literals := make([]Literal, 0, len(trueLits)+len(extra))
+	literals = append(literals, extra...)
+	for _, lit := range trueLits {
+		literals = append(literals, lit.Negate())
+	}
+	clause := NewClauseIn(c.pool, literals...)
+	clause.Learned = true
+	c.setXORClauseLBD(clause)
+	return clause
+}
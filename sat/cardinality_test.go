@@ -0,0 +1,234 @@
+package sat
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCardinalityClause(t *testing.T) {
+	lits := []Literal{L("A", false), L("B", true), L("C", false)}
+	atMost := NewAtMostK(lits, 1)
+	if decided, _ := atMost.IsSatisfied(Assignment{"A": true}); decided {
+		t.Error("One true literal with two unassigned should be undecided")
+	}
+	if decided, holds := atMost.IsSatisfied(Assignment{"A": true, "B": false}); !decided || holds {
+		t.Error("Expected A and ¬B to violate the constraint")
+	}
+	if decided, holds := atMost.IsSatisfied(Assignment{"A": false, "B": true}); !decided || !holds {
+		t.Error("Expected the constraint to hold with one literal left")
+	}
+
+	// At least two of A, ¬B, C is at most one of ¬A, B, ¬C
+	atLeast := NewAtLeastK(lits, 2)
+	if atLeast.K != 1 || atLeast.Literals[0] != L("A", true) || atLeast.Literals[1] != L("B", false) {
+		t.Errorf("Unexpected constraint %v", atLeast)
+	}
+	if got := atLeast.String(); got != "(¬A + B + ¬C ≤ 1)" {
+		t.Errorf("Unexpected string %q", got)
+	}
+
+	ecnf := NewExtendedCNF()
+	ecnf.AddCardinalityClause(atMost)
+	ecnf.AddCardinalityClause(atLeast)
+	if !ecnf.HasCardinalityClauses() || atLeast.ID != 2 || len(ecnf.Variables) != 3 {
+		t.Errorf("Expected two constraints over three variables, got %v", ecnf.Variables)
+	}
+}
+
+func TestCDCLSolver_Cardinality(t *testing.T) {
+	A, B, C, D, E := L("A", false), L("B", false), L("C", false), L("D", false), L("E", false)
+	testCases := []struct {
+		description string
+		clauses     [][]Literal
+		cardinality []*CardinalityClause
+		expectedSat bool
+	}{
+		{"at most one with a free choice",
+			[][]Literal{{A, B}},
+			[]*CardinalityClause{NewAtMostK([]Literal{A, B, C}, 1)}, true},
+		{"at most one forces the rest false",
+			[][]Literal{{A}, {B, C}},
+			[]*CardinalityClause{NewAtMostK([]Literal{A, B, C}, 1)}, false},
+		{"at most zero forces every literal false",
+			[][]Literal{{A, C}},
+			[]*CardinalityClause{NewAtMostK([]Literal{A, B.Negate()}, 0)}, true},
+		{"at most zero against a unit",
+			[][]Literal{{B}},
+			[]*CardinalityClause{NewAtMostK([]Literal{A, B}, 0)}, false},
+		{"at least two of three",
+			[][]Literal{{A.Negate()}},
+			[]*CardinalityClause{NewAtLeastK([]Literal{A, B, C}, 2)}, true},
+		{"at least two with two false",
+			[][]Literal{{A.Negate()}, {B.Negate()}},
+			[]*CardinalityClause{NewAtLeastK([]Literal{A, B, C}, 2)}, false},
+		{"at least two negated literals",
+			[][]Literal{{A, B}},
+			[]*CardinalityClause{NewAtLeastK([]Literal{A.Negate(), B.Negate(), C.Negate()}, 2)}, true},
+		{"exactly two of four",
+			[][]Literal{{A}, {B, C}},
+			[]*CardinalityClause{NewAtMostK([]Literal{A, B, C, D}, 2), NewAtLeastK([]Literal{A, B, C, D}, 2)}, true},
+		{"overlapping constraints",
+			nil,
+			[]*CardinalityClause{
+				NewAtMostK([]Literal{A, B, C}, 1),
+				NewAtMostK([]Literal{C, D, E}, 1),
+				NewAtLeastK([]Literal{A, B, C, D, E}, 3),
+			}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ecnf := NewExtendedCNF()
+			for _, clause := range tc.clauses {
+				ecnf.AddClause(NewClause(clause...))
+			}
+			for _, card := range tc.cardinality {
+				ecnf.AddCardinalityClause(card)
+			}
+
+			result := NewCDCLSolver().SolveExtended(ecnf)
+			if result.Error != nil {
+				t.Fatalf("Solver error: %v", result.Error)
+			}
+			if result.Satisfiable != tc.expectedSat {
+				t.Fatalf("Expected satisfiable=%v, got %v", tc.expectedSat, result.Status)
+			}
+			if !result.Satisfiable {
+				return
+			}
+			for _, name := range ecnf.Variables {
+				if _, ok := result.Assignment[name]; !ok {
+					result.Assignment[name] = false
+				}
+			}
+			if !holdsExtended(result.Assignment, ecnf) {
+				t.Errorf("Model %v violates the formula", result.Assignment)
+			}
+		})
+	}
+}
+
+func TestCDCLSolver_CardinalityPigeonhole(t *testing.T) {
+	for _, tc := range []struct {
+		pigeons, holes int
+		satisfiable    bool
+	}{{5, 5, true}, {6, 5, false}, {7, 6, false}} {
+		ecnf := NewExtendedCNF()
+		encoded := NewCNF()
+		encoder := NewEncoder("_card")
+		holes := make([][]Literal, tc.holes)
+		for p := 0; p < tc.pigeons; p++ {
+			var clause []Literal
+			for h := 0; h < tc.holes; h++ {
+				lit := L(fmt.Sprintf("p%dh%d", p, h), false)
+				clause = append(clause, lit)
+				holes[h] = append(holes[h], lit)
+			}
+			ecnf.AddClause(NewClause(clause...))
+			encoded.AddClause(NewClause(clause...))
+		}
+		for _, lits := range holes {
+			ecnf.AddCardinalityClause(NewAtMostK(lits, 1))
+			encoder.AtMostK(lits, 1).AddTo(encoded)
+		}
+
+		result := NewCDCLSolver().SolveExtended(ecnf)
+		if result.Error != nil || result.Satisfiable != tc.satisfiable {
+			t.Fatalf("%d pigeons in %d holes: got %v (%v)", tc.pigeons, tc.holes, result.Status, result.Error)
+		}
+		if expected := NewCDCLSolver().Solve(encoded); expected.Satisfiable != result.Satisfiable {
+			t.Fatalf("%d pigeons in %d holes: the clausal encoding says %v", tc.pigeons, tc.holes, expected.Status)
+		}
+		if result.Satisfiable && !holdsExtended(result.Assignment, ecnf) {
+			t.Errorf("%d pigeons in %d holes: model violates the formula", tc.pigeons, tc.holes)
+		}
+	}
+}
+
+func TestCDCLSolver_CardinalityConcurrent(t *testing.T) {
+	// Seven pigeons in six holes keep the solver busy for a while
+	ecnf := NewExtendedCNF()
+	holes := make([][]Literal, 6)
+	for p := 0; p < 7; p++ {
+		var clause []Literal
+		for h := range holes {
+			lit := L(fmt.Sprintf("p%dh%d", p, h), false)
+			clause = append(clause, lit)
+			holes[h] = append(holes[h], lit)
+		}
+		ecnf.AddClause(NewClause(clause...))
+	}
+	for _, lits := range holes {
+		ecnf.AddCardinalityClause(NewAtMostK(lits, 1))
+	}
+
+	// A rejected concurrent call leaves the running solve's counters alone
+	solver := NewCDCLSolver()
+	started := make(chan struct{})
+	var once sync.Once
+	solver.SetProgress(func(SolverStatistics) { once.Do(func() { close(started) }) }, time.Microsecond)
+	done := make(chan *SolverResult, 1)
+	go func() { done <- solver.SolveExtended(ecnf) }()
+	<-started
+	if result := solver.SolveExtended(ecnf); result.Error == nil {
+		t.Fatal("Expected the concurrent call to be rejected")
+	}
+	if result := <-done; result.Error != nil || result.Status != StatusUNSAT {
+		t.Errorf("Expected UNSAT, got %v (%v)", result.Status, result.Error)
+	}
+}
+
+func TestCDCLSolver_CardinalityRoot(t *testing.T) {
+	// At least three of two literals fails before any decision
+	ecnf := NewExtendedCNF()
+	ecnf.AddClause(NewClause(L("A", false), L("B", false)))
+	ecnf.AddCardinalityClause(NewAtLeastK([]Literal{L("A", false), L("B", false)}, 3))
+	if result := NewCDCLSolver().SolveExtended(ecnf); result.Status != StatusUNSAT {
+		t.Errorf("Expected UNSAT, got %v (%v)", result.Status, result.Error)
+	}
+
+	// At most zero forces every literal false at the root
+	ecnf = NewExtendedCNF()
+	ecnf.AddClause(NewClause(L("A", false), L("B", false), L("C", false)))
+	ecnf.AddCardinalityClause(NewAtMostK([]Literal{L("A", false), L("B", false)}, 0))
+	result := NewCDCLSolver().SolveExtended(ecnf)
+	if !result.Satisfiable || result.Assignment["A"] || result.Assignment["B"] || !result.Assignment["C"] {
+		t.Errorf("Expected only C true, got %v (%v)", result.Assignment, result.Status)
+	}
+}
+
+func TestCardinalityCounter(t *testing.T) {
+	trail := newLiteralTrail()
+	atMost := NewAtMostK([]Literal{L("A", false), L("B", true), L("C", false)}, 1)
+	other := NewAtMostK([]Literal{L("D", false), L("E", false)}, 1)
+	counter := newCardinalityCounter(trail, []*CardinalityClause{atMost, other})
+	for len(counter.dirty) > 0 {
+		counter.pop()
+	}
+
+	trail.Assign("A", true, 1, nil)
+	trail.Assign("B", true, 2, nil)
+	trail.Assign("D", false, 2, nil)
+	counter.count(trail)
+	if counter.trueCount[0] != 1 || counter.falseCount[0] != 1 || counter.falseCount[1] != 1 {
+		t.Fatalf("Unexpected counts %v and %v", counter.trueCount, counter.falseCount)
+	}
+	if len(counter.dirty) != 2 {
+		t.Errorf("Expected both constraints queued, got %v", counter.dirty)
+	}
+	for len(counter.dirty) > 0 {
+		counter.pop()
+	}
+
+	// Backtracking to level 1 uncounts B and D and queues their constraints
+	counter.undo(trail, trail.levelStart(1))
+	trail.Backtrack(1)
+	if counter.trueCount[0] != 1 || counter.falseCount[0] != 0 || counter.falseCount[1] != 0 || counter.head != 1 {
+		t.Errorf("Unexpected counts %v and %v after backtracking", counter.trueCount, counter.falseCount)
+	}
+	if len(counter.dirty) != 2 {
+		t.Errorf("Expected both constraints queued, got %v", counter.dirty)
+	}
+}
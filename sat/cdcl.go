@@ -1,6 +1,10 @@
 package sat
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
 	"sync/atomic"
 	"time"
 
@@ -16,7 +20,7 @@ type CDCLSolver struct {
 	statistics SolverStatistics
 	assignment Assignment
 	cnf        *CNF
-	trail      DecisionTrail
+	trail      *literalTrail // Values, levels and reasons over core literals
 	startTime  time.Time
 
 	// Advanced CDCL components
@@ -25,9 +29,14 @@ type CDCLSolver struct {
 	deletionPolicy  ClauseDeletionPolicy
 	analyzer        ConflictAnalyzer
 
-	// Two-watched literals optimization (variable-keyed watch lists)
-	watchLists map[string][]*WatchedClause // Variable -> clauses watching it
-	watchPool  *memory.Pool                // Off-heap pool for watch lists
+	// Two watched literals over core literals. Like DenseSolver's, the
+	// arrays live on the Go heap and are reused when the lists are rebuilt.
+	watches   [][]watcher  // Literal -> clauses watching it
+	watched   []coreClause // Watched clauses, indexed by watcher.cref
+	watchLits []Lit        // Literals of the watched clauses
+	vars      []int32      // Core variables of the formula's variables
+	qhead     int          // Next trail literal to propagate
+	pool      *memory.Pool // Solver arrays and learned clauses; satPool by default
 
 	// Learned clause management (tiered)
 	clauseDatabase *ClauseDatabase
@@ -39,7 +48,7 @@ type CDCLSolver struct {
 	decisionLevel int
 
 	// Variable activity (VSIDS with optimizations)
-	variableActivity map[string]float64
+	activity         []float64 // Core variable -> activity
 	varActivityInc   float64
 	varActivityDecay float64
 
@@ -51,9 +60,7 @@ type CDCLSolver struct {
 	preprocessor *SATPreprocessor
 
 	// Performance optimizations
-	conflictLimit    int64
-	propagationQueue []Literal
-	queueHead        int
+	conflictLimit int64
 
 	// LBD tracking
 	lbdSum          int64 // Sum of all LBDs for average tracking
@@ -63,9 +70,6 @@ type CDCLSolver struct {
 	unassignedCache []string
 	cacheValid      bool
 
-	// Additional performance optimizations
-	propagationCache map[string]bool // Cache for propagation state
-
 	// Inprocessor
 	inprocessor            Inprocessor
 	inprocessConfig        InprocessConfig
@@ -84,6 +88,8 @@ type CDCLSolver struct {
 	extendedCNF        *ExtendedCNF        // Extended CNF with XOR clauses
 	gaussianEliminator *GaussianEliminator // Gaussian eliminator
 	xorEnabled         bool                // Enable XOR support
+	cardinality        *cardinalityCounter // Counts of the cardinality constraints
+	pbSlack            *pbSlack            // Slack of the PB constraints
 
 	// XOR-specific statistics
 	xorPropagations int64
@@ -95,6 +101,34 @@ type CDCLSolver struct {
 
 	// WalkSAT pre-solver
 	walkSolver *WalkSolver
+
+	// Clausal proof output (DRAT/LRAT)
+	proofOutput io.Writer
+	proofFormat ProofFormat
+	proof       *ProofWriter
+	proofUnits  map[string]int // root-level variable -> ID of its unit lemma
+
+	// Model reconstruction after variable elimination
+	reconstruction *ReconstructionStack
+	variables      []string    // Variables of the formula passed to Solve
+	modelCheck     bool        // Verify every model against the input formula
+	original       [][]Literal // Input clauses, kept while modelCheck is on
+
+	// Cancellation and progress reporting (SolveContext)
+	ctx      context.Context // Context of the running SolveContext call
+	progress progressReporter
+
+	// Portfolio clause sharing (PortfolioSolver)
+	exchange       *clauseExchange
+	exchangeID     int    // This worker's index in the portfolio
+	exchangeCursor uint64 // Next exchange slot to import
+
+	// Incremental solving (SolveAssuming)
+	assumptions    []Literal // Assumptions of the running SolveAssuming call
+	incremental    bool      // Search state is kept between calls
+	pendingClauses bool      // Clauses were added since the last search
+	refuted        bool      // The clauses alone are unsatisfiable
+	eliminated     bool      // Variable elimination removed clauses from the formula
 }
 
 // IncrementalLazyBacktrack manages lazy backtracking optimization
@@ -120,9 +154,13 @@ type ChronologicalStats struct {
 }
 
 func NewIncrementalLazyBacktrack() *IncrementalLazyBacktrack {
+	return newIncrementalLazyBacktrack(nil)
+}
+
+func newIncrementalLazyBacktrack(pool *memory.Pool) *IncrementalLazyBacktrack {
 	return &IncrementalLazyBacktrack{
 		enabled:                true,
-		reimplicationQueue:     memory.MustPoolSlice[Literal](satPool, 100),
+		reimplicationQueue:     memory.MustPoolSlice[Literal](poolOr(pool), 100),
 		chronologicalEnabled:   true,
 		reimplicationCache:     make(map[string]bool),
 		levelImplicationCount:  make(map[int]int),
@@ -131,10 +169,14 @@ func NewIncrementalLazyBacktrack() *IncrementalLazyBacktrack {
 }
 
 func NewChronologicalStats() *ChronologicalStats {
+	return newChronologicalStats(nil)
+}
+
+func newChronologicalStats(pool *memory.Pool) *ChronologicalStats {
 	return &ChronologicalStats{
 		adaptiveThreshold: 2,
-		successWindow:     memory.MustPoolSlice[bool](satPool, 20)[:20], // 20-conflict sliding window
-		recentSuccessRate: 0.5,              // Start optimistic
+		successWindow:     memory.MustPoolSlice[bool](poolOr(pool), 20)[:20], // 20-conflict sliding window
+		recentSuccessRate: 0.5,                                               // Start optimistic
 	}
 }
 
@@ -148,31 +190,36 @@ func mustCreatePool() *memory.Pool {
 
 // NewCDCLSolver creates a new unified CDCL solver with advanced configuration by default
 func NewCDCLSolver() *CDCLSolver {
+	return NewCDCLSolverWithPool(nil)
+}
+
+// NewCDCLSolverWithPool creates a CDCL solver whose arrays, learned
+// clauses and inprocessing clauses come from pool rather than the package
+// pools, so that independent solvers can be reclaimed one at a time by
+// resetting or freeing their own pools once their results are consumed.
+// A nil pool means the package pools.
+func NewCDCLSolverWithPool(pool *memory.Pool) *CDCLSolver {
+	pool = poolOr(pool)
 	solver := &CDCLSolver{
+		pool: pool,
 		statistics: SolverStatistics{
 			LBDDistribution: make(map[int]int64),
 		},
 		assignment:       make(Assignment),
-		trail:            NewAdvancedDecisionTrail(), // Use advanced trail by default
-		watchLists:       make(map[string][]*WatchedClause),
-		watchPool:        mustCreatePool(),
+		trail:            newLiteralTrail(),
 		maxLearnedSize:   2000,
 		clauseActivity:   make(map[int]float64),
 		clauseDecay:      0.999,
 		decisionLevel:    0,
-		variableActivity: make(map[string]float64),
 		varActivityInc:   1.0,
 		varActivityDecay: 0.95,
 		conflicts:        0,
 		restartThreshold: 100,
 		conflictLimit:    10000000,
-		propagationQueue: memory.MustPoolSlice[Literal](satPool, 0)[:0],
-		queueHead:        0,
 		lbdSum:           0,
 		glueClauseCount:  0,
-		unassignedCache:  memory.MustPoolSlice[string](satPool, 0)[:0],
+		unassignedCache:  memory.MustPoolSlice[string](pool, 0)[:0],
 		cacheValid:       false,
-		propagationCache: make(map[string]bool),
 		// Inprocessing initialization
 		inprocessConfig:        DefaultInprocessConfig(),
 		lastInprocess:          0,
@@ -180,10 +227,10 @@ func NewCDCLSolver() *CDCLSolver {
 		lastInprocessReduction: 0,
 		lastInprocessCostNs:    0,
 		// ILB initialization
-		ilb:                NewIncrementalLazyBacktrack(),
-		chronologicalStats: NewChronologicalStats(),
+		ilb:                newIncrementalLazyBacktrack(pool),
+		chronologicalStats: newChronologicalStats(pool),
 		// XOR support initialization
-		gaussianEliminator: NewGaussianEliminator(),
+		gaussianEliminator: newGaussianEliminator(pool),
 		xorEnabled:         true,
 		xorPropagations:    0,
 		xorConflicts:       0,
@@ -191,18 +238,19 @@ func NewCDCLSolver() *CDCLSolver {
 	}
 
 	// Initialize enhanced components by default (now the base versions include all enhancements)
-	solver.heuristic = NewVSIDSHeuristic()             // Now includes LRB, polarity, anti-aging
-	solver.restartStrategy = NewLubyRestartStrategy()  // Now hybrid Luby+Glucose
-	solver.deletionPolicy = NewActivityBasedDeletion() // Now LBD-aware
-	solver.analyzer = NewFirstUIPAnalyzer()
+	solver.heuristic = newVSIDSHeuristic(pool)             // Now includes LRB, polarity, anti-aging
+	solver.restartStrategy = newLubyRestartStrategy(pool)  // Now hybrid Luby+Glucose
+	solver.deletionPolicy = newActivityBasedDeletion(pool) // Now LBD-aware
+	solver.analyzer = newFirstUIPAnalyzer(pool)
 	solver.preprocessor = NewSATPreprocessor()
-	solver.inprocessor = NewModernInprocessor()
-	solver.modeSwitcher = NewModeSwitcher()
-	solver.walkSolver = NewWalkSolver()
+	solver.inprocessor = newModernInprocessor(pool)
+	solver.modeSwitcher = newModeSwitcher(pool)
+	solver.walkSolver = newWalkSolver(pool)
+	solver.reconstruction = NewReconstructionStack()
 
 	// Initialize the tiered clause database:
 	// recentProtectionAge ~ 1000 conflicts is common; tune as needed or make it configurable.
-	solver.clauseDatabase = NewClauseDatabase(solver.maxLearnedSize, 1000)
+	solver.clauseDatabase = newClauseDatabase(pool, solver.maxLearnedSize, 1000)
 
 	return solver
 }
@@ -218,44 +266,105 @@ func (c *CDCLSolver) Name() string {
 	return "CDCL"
 }
 
+// SetConflictLimit bounds the conflicts of each later solve call. A solve
+// that reaches the limit ends with StatusUnknown and ReasonConflictLimit.
+func (c *CDCLSolver) SetConflictLimit(limit int64) {
+	c.conflictLimit = limit
+}
+
+// SetInprocessConfig replaces the inprocessing configuration for later
+// solve calls. Once SolveAssuming has been called, the techniques that
+// eliminate variables or clauses stay off until Reset.
+func (c *CDCLSolver) SetInprocessConfig(config InprocessConfig) {
+	c.inprocessConfig = config
+	c.inprocessGap = config.InprocessGap
+	if c.inprocessor == nil {
+		return
+	}
+	if c.incremental {
+		config = config.withoutElimination()
+	}
+	c.inprocessor.Configure(config)
+}
+
 // Solve solves the SAT problem using CDCL
 func (c *CDCLSolver) Solve(cnf *CNF) *SolverResult {
 	return c.SolveWithTimeout(cnf, 0)
 }
 
-// SolveExtended adds XOR-aware solving method
+// SolveExtended solves a formula with XOR, cardinality and PB constraints
 func (c *CDCLSolver) SolveExtended(ecnf *ExtendedCNF) *SolverResult {
-	c.extendedCNF = ecnf
-	defer func() { c.extendedCNF = nil }()
-	
-	// If no XOR clauses, fall back to regular solving
-	if !ecnf.HasXORClauses() {
-		return c.Solve(ecnf.CNF)
+	return c.SolveExtendedWithTimeout(ecnf, 0)
+}
+
+// SolveExtendedWithTimeout solves an extended formula with a timeout
+func (c *CDCLSolver) SolveExtendedWithTimeout(ecnf *ExtendedCNF, timeout time.Duration) *SolverResult {
+	// If no XOR, cardinality or PB constraints, fall back to regular solving
+	if !ecnf.hasConstraints() {
+		return c.SolveWithTimeout(ecnf.CNF, timeout)
 	}
 
-	return c.SolveWithTimeout(ecnf.CNF, 0)
+	return c.solve(nil, ecnf.CNF, ecnf, timeout)
 }
 
 // SolveWithTimeout solves with timeout using advanced CDCL algorithm with inprocessing
 func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverResult {
+	return c.solve(nil, cnf, nil, timeout)
+}
+
+// solve runs SolveWithTimeout, checking ctx for cancellation if it is not
+// nil and propagating the constraints of ecnf if it is not nil. Both are
+// installed only once the solver is held, so that a rejected concurrent
+// call cannot replace or clear them.
+func (c *CDCLSolver) solve(ctx context.Context, cnf *CNF, ecnf *ExtendedCNF, timeout time.Duration) (result *SolverResult) {
+	defer func() { result.setStatus() }()
 	if !c.isSolving.CompareAndSwap(false, true) {
 		return &SolverResult{
 			Error: core.NewLogicError("sat", "CDCLSolver.SolveWithTimeout", "concurrent Solve calls on the same solver instance are not allowed"),
 		}
 	}
 	defer c.isSolving.Store(false)
-	defer c.watchPool.Reset()
+	if ctx != nil {
+		c.setContext(ctx)
+		defer c.setContext(nil)
+	}
+	if ecnf != nil {
+		c.extendedCNF = ecnf
+		c.cardinality = newCardinalityCounter(c.trail, ecnf.CardinalityClauses)
+		c.pbSlack = newPBSlack(c.trail, ecnf.PBConstraints)
+		defer func() { c.extendedCNF, c.cardinality, c.pbSlack = nil, nil, nil }()
+	}
 	c.startTime = time.Now()
+	c.progress.start()
 	c.cnf = cnf
+	c.beginProof(cnf)
+	c.beginReconstruction(cnf, true)
+	defer func() { c.finishModel(result) }()
+	defer func() {
+		if r := recover(); r != nil {
+			result = recoverMemory("CDCLSolver.SolveWithTimeout", r)
+			result.Statistics = c.statistics
+		}
+		c.finishProof(result)
+	}()
 	c.assignment = make(Assignment)
+	c.trail.Clear()
+	c.clearPropagationState()
 	c.statistics = SolverStatistics{LBDDistribution: make(map[int]int64)}
 	c.decisionLevel = 0
 	c.conflicts = 0
 	c.lbdSum = 0
 	c.glueClauseCount = 0
-	c.unassignedCache = memory.MustPoolSlice[string](satPool, 0)[:0]
+	c.unassignedCache = memory.MustPoolSlice[string](c.pool, 0)[:0]
 	c.cacheValid = false
-	c.propagationCache = make(map[string]bool)
+	c.resetIncremental()
+
+	// Elimination and substitution only see the clauses, and would remove
+	// variables that XOR and cardinality constraints still mention
+	if c.extendedCNF.hasConstraints() && c.inprocessor != nil {
+		c.inprocessor.Configure(c.inprocessConfig.withoutElimination())
+		defer c.inprocessor.Configure(c.inprocessConfig)
+	}
 
 	// Initialize components
 	c.initializeWatchLists()
@@ -263,6 +372,16 @@ func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 
 	// Enqueue initial unit clauses
 	for _, clause := range c.cnf.Clauses {
+		if clause != nil && !clause.Deleted && len(clause.Literals) == 0 {
+			// The empty clause; WalkSAT would never stop on it
+			c.proofRefute(clause)
+			c.refuted = true
+			c.statistics.TimeElapsed = time.Since(c.startTime).Nanoseconds()
+			return &SolverResult{
+				Satisfiable: false,
+				Statistics:  c.statistics,
+			}
+		}
 		if clause != nil && !clause.Deleted && len(clause.Literals) == 1 {
 			lit := clause.Literals[0]
 			if !c.assignment.IsAssigned(lit.Variable) {
@@ -270,6 +389,8 @@ func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 			} else {
 				val := c.assignment[lit.Variable]
 				if (val && lit.Negated) || (!val && !lit.Negated) {
+					c.proofRefute(clause)
+					c.refuted = true
 					c.statistics.TimeElapsed = time.Since(c.startTime).Nanoseconds()
 					return &SolverResult{
 						Satisfiable: false,
@@ -285,8 +406,9 @@ func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 		c.performInprocessing()
 	}
 
-	// WalkSAT pre-solving on irredundant clauses
-	if c.walkSolver != nil {
+	// WalkSAT pre-solving on irredundant clauses, which is only complete
+	// without extended constraints
+	if c.walkSolver != nil && !c.extendedCNF.hasConstraints() {
 		irredundant := c.filterIrredundant()
 		if c.walkSolver.Solve(irredundant) {
 			c.statistics.TimeElapsed = time.Since(c.startTime).Nanoseconds()
@@ -301,6 +423,12 @@ func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 		}
 	}
 
+	return c.search(timeout)
+}
+
+// search runs the CDCL loop from the current state. Pending assumptions
+// are decided before any other variable.
+func (c *CDCLSolver) search(timeout time.Duration) *SolverResult {
 	// Setup timeout
 	var timeoutChan <-chan time.Time
 	if timeout > 0 {
@@ -308,19 +436,31 @@ func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 	}
 
 	// Main CDCL loop with inprocessing integration
-	for c.conflicts < c.conflictLimit {
+	conflictLimit := c.conflicts + c.conflictLimit
+	for c.conflicts < conflictLimit {
 		select {
 		case <-timeoutChan:
 			return &SolverResult{
 				Error:      core.NewLogicError("sat", "CDCLSolver.SolveWithTimeout", "timeout exceeded"),
+				Reason:     ReasonTimeout,
 				Statistics: c.statistics,
 			}
 		default:
 		}
+		if err := c.cancelled(); err != nil {
+			return &SolverResult{Error: err, Statistics: c.statistics}
+		}
 
 		// **GAUSSIAN ELIMINATION INTEGRATION**
 		if c.xorEnabled && c.extendedCNF != nil && c.gaussianEliminator.ShouldRunGaussian(c.conflicts, len(c.extendedCNF.XORClauses)) {
-			c.performGaussianElimination()
+			if !c.performGaussianElimination() {
+				c.refuted = true
+				c.statistics.TimeElapsed = time.Since(c.startTime).Nanoseconds()
+				return &SolverResult{
+					Satisfiable: false,
+					Statistics:  c.statistics,
+				}
+			}
 		}
 
 		// **INPROCESSING INTEGRATION POINT 1**:
@@ -332,17 +472,21 @@ func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 		// Use advanced two-watched literals propagation
 		conflictClause := c.propagate()
 
-		// **XOR PROPAGATION**
-		if conflictClause == nil && c.xorEnabled && c.extendedCNF != nil {
-			xorConflict := c.propagateXOR()
-			if xorConflict != nil {
-				conflictClause = c.convertXORConflictToClause(xorConflict)
+		// **XOR, CARDINALITY AND PB PROPAGATION**
+		// Clause propagation runs again after every round that forces literals
+		for conflictClause == nil && c.hasPropagatedConstraints() {
+			var propagated bool
+			if conflictClause, propagated = c.propagateConstraints(); conflictClause != nil || !propagated {
+				break
 			}
+			conflictClause = c.propagate()
 		}
 		if conflictClause != nil {
 			c.statistics.Conflicts++
 			c.conflicts++
 			if c.decisionLevel == 0 {
+				c.proofRefute(conflictClause)
+				c.refuted = true
 				c.statistics.TimeElapsed = time.Since(c.startTime).Nanoseconds()
 				return &SolverResult{
 					Satisfiable: false,
@@ -354,7 +498,11 @@ func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 			learnedClause, backtrackLevel := c.analyzer.Analyze(conflictClause, c.trail)
 			if learnedClause != nil {
 				c.learnClause(learnedClause)
+				c.proofLearn(learnedClause, conflictClause)
 				c.statistics.LearnedClauses++
+				if c.exchange != nil {
+					c.exchange.publish(c.exchangeID, learnedClause)
+				}
 			}
 
 			// Use enhanced lazy backtracking instead of regular backtracking
@@ -362,13 +510,16 @@ func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 
 			// Assert the learned clause
 			if learnedClause != nil && len(learnedClause.Literals) > 0 {
-				assertingLit := learnedClause.Literals[0]
-				c.assign(assertingLit.Variable, !assertingLit.Negated, learnedClause)
+				c.assertLearnedClause(learnedClause)
 			}
 
 			// Update heuristic
 			c.heuristic.Update(conflictClause)
 
+			if c.conflicts&63 == 0 {
+				c.progress.poll(c.statistics, c.startTime)
+			}
+
 			// **INPROCESSING INTEGRATION POINT 2**:
 			// After backtracking to level 0, consider inprocessing
 			if c.decisionLevel == 0 && c.shouldRunInprocessingAfterBacktrack() {
@@ -389,6 +540,14 @@ func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 			if c.restartStrategy.ShouldRestart(c.statistics) {
 				c.restart()
 				c.statistics.Restarts++
+				if c.exchange != nil && !c.importShared() {
+					c.refuted = true
+					c.statistics.TimeElapsed = time.Since(c.startTime).Nanoseconds()
+					return &SolverResult{
+						Satisfiable: false,
+						Statistics:  c.statistics,
+					}
+				}
 				// Mode switching: check at restart boundaries
 				if c.modeSwitcher.ShouldSwitch(c.conflicts, c.statistics.Decisions) {
 					c.modeSwitcher.Switch(c.conflicts, c.statistics.Decisions)
@@ -402,6 +561,22 @@ func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 			continue
 		}
 
+		// Assumptions are decided first, each on a level of its own
+		if lit, ok := c.pendingAssumption(); ok {
+			if c.assignment.IsAssigned(lit.Variable) {
+				c.statistics.TimeElapsed = time.Since(c.startTime).Nanoseconds()
+				return &SolverResult{
+					Satisfiable:       false,
+					FailedAssumptions: c.analyzeFinal(lit),
+					Statistics:        c.statistics,
+				}
+			}
+			c.decisionLevel++
+			c.statistics.Decisions++
+			c.assign(lit.Variable, !lit.Negated, nil)
+			continue
+		}
+
 		if c.allVariablesAssigned() {
 			c.statistics.TimeElapsed = time.Since(c.startTime).Nanoseconds()
 			return &SolverResult{
@@ -414,7 +589,13 @@ func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 		// Make decision using advanced heuristics
 		decisionVar := c.chooseDecisionVariable()
 		if decisionVar == "" {
-			break
+			// Some variable is unassigned, so an empty choice is a bug
+			// rather than a proof of unsatisfiability
+			return &SolverResult{
+				Error:      core.NewLogicError("sat", "CDCLSolver.search", "no decision variable with variables unassigned"),
+				Reason:     ReasonError,
+				Statistics: c.statistics,
+			}
 		}
 
 		c.decisionLevel++
@@ -424,21 +605,29 @@ func (c *CDCLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 		c.assign(decisionVar, polarity, nil)
 	}
 
+	if c.conflicts >= conflictLimit {
+		return &SolverResult{
+			Error:      core.NewLogicError("sat", "CDCLSolver.search", "conflict limit reached"),
+			Reason:     ReasonConflictLimit,
+			Statistics: c.statistics,
+		}
+	}
 	return &SolverResult{
 		Satisfiable: false,
 		Statistics:  c.statistics,
 	}
 }
 
-// performGaussianElimination integrates Gaussian elimination
-func (c *CDCLSolver) performGaussianElimination() {
+// performGaussianElimination integrates Gaussian elimination. It returns
+// false if the XOR constraints contradict the root assignment.
+func (c *CDCLSolver) performGaussianElimination() bool {
 	if c.decisionLevel != 0 {
-		return // Only run at root level
+		return true // Only run at root level
 	}
 
 	result, err := c.gaussianEliminator.PerformGaussianElimination(c.extendedCNF, c.assignment, c.conflicts)
 	if err != nil {
-		return
+		return true
 	}
 
 	c.gaussianRuns++
@@ -446,8 +635,7 @@ func (c *CDCLSolver) performGaussianElimination() {
 	// Handle contradiction
 	if result.ConflictFound {
 		c.xorConflicts++
-		// This will be handled in the main loop
-		return
+		return false
 	}
 
 	// Apply unit propagations
@@ -457,13 +645,66 @@ func (c *CDCLSolver) performGaussianElimination() {
 		c.xorPropagations++
 	}
 
-	// Add learned XOR clauses
+	// Add learned XOR clauses. Later runs derive the same rows again, so
+	// only new ones are kept.
+	known := make(map[string]bool, len(c.extendedCNF.XORClauses))
+	for _, xorClause := range c.extendedCNF.XORClauses {
+		known[xorKey(xorClause)] = true
+	}
 	for _, xorClause := range result.XORClausesLearned {
-		c.extendedCNF.AddXORClause(xorClause)
+		if key := xorKey(xorClause); !known[key] {
+			known[key] = true
+			c.extendedCNF.AddXORClause(xorClause)
+		}
 	}
+	return true
+}
+
+// xorKey identifies an XOR constraint independently of its variable order
+func xorKey(xorClause *XORClause) string {
+	variables := append([]string(nil), xorClause.Variables...)
+	sort.Strings(variables)
+	return fmt.Sprint(variables, xorClause.Parity)
+}
+
+// hasPropagatedConstraints reports whether the extended formula has
+// constraints beyond clauses for the solver to propagate
+func (c *CDCLSolver) hasPropagatedConstraints() bool {
+	if c.extendedCNF == nil {
+		return false
+	}
+	return (c.xorEnabled && len(c.extendedCNF.XORClauses) > 0) ||
+		c.extendedCNF.HasCardinalityClauses() || c.extendedCNF.HasPBConstraints()
 }
 
-// propagateXOR performs XOR constraint propagation
+// propagateConstraints runs one round of XOR, cardinality and PB
+// propagation. It returns the conflict clause, if any, and whether a
+// literal was forced. Like propagate, it drops the queued literals on a
+// conflict, as backtracking unassigns them.
+func (c *CDCLSolver) propagateConstraints() (*Clause, bool) {
+	propagated := false
+	if c.xorEnabled && len(c.extendedCNF.XORClauses) > 0 {
+		before := c.xorPropagations
+		if xorConflict := c.propagateXOR(); xorConflict != nil {
+			c.clearPropagationState()
+			return c.convertXORConflictToClause(xorConflict), true
+		}
+		propagated = c.xorPropagations > before
+	}
+	conflict, counted := c.propagateCounting()
+	if conflict != nil {
+		c.clearPropagationState()
+	}
+	return conflict, propagated || counted
+}
+
+// propagateXOR performs XOR constraint propagation. A forced variable is
+// explained by the clause of the XOR's CNF encoding that became unit:
+//
+//	forced v:  (v' ∨ ¬a1 ∨ ... ∨ ¬am)
+//
+// where v' is v's forced literal and the aᵢ are the current literals of
+// the XOR's other variables.
 func (c *CDCLSolver) propagateXOR() *XORClause {
 	changed := true
 	for changed {
@@ -476,24 +717,31 @@ func (c *CDCLSolver) propagateXOR() *XORClause {
 			}
 
 			// Check for unit XOR propagation
-			unassignedVars := memory.MustPoolSlice[string](satPool, 0)[:0]
+			unassigned, unassignedCount := "", 0
 			xorSum := false
 			for _, variable := range xorClause.Variables {
 				if value, assigned := c.assignment[variable]; assigned {
 					if value {
 						xorSum = !xorSum
 					}
-				} else {
-					unassignedVars = append(unassignedVars, variable)
+				} else if unassignedCount++; unassignedCount == 1 {
+					unassigned = variable
 				}
 			}
 
 			// Unit XOR propagation
-			if len(unassignedVars) == 1 {
-				variable := unassignedVars[0]
+			if unassignedCount == 1 {
 				// The unassigned variable must make xorSum == xorClause.Parity
 				requiredValue := xorSum != xorClause.Parity
-				c.assign(variable, requiredValue, nil) // XOR reason handling would need extension
+				assigned := make([]string, 0, len(xorClause.Variables)-1)
+				for _, variable := range xorClause.Variables {
+					if variable != unassigned {
+						assigned = append(assigned, variable)
+					}
+				}
+				reason := c.createUnitXORConflictClause(xorClause, assigned, unassigned, xorSum)
+				reason.ConflictType = "XOR_REASON"
+				c.assign(unassigned, requiredValue, reason)
 				c.xorPropagations++
 				changed = true
 			}
@@ -510,8 +758,8 @@ func (c *CDCLSolver) convertXORConflictToClause(xorClause *XORClause) *Clause {
 	// 3. Handle both assigned and unassigned variables correctly
 	// 4. Ensure compatibility with CDCL conflict analysis
 
-	assignedVars := memory.MustPoolSlice[string](satPool, len(xorClause.Variables))
-	unassignedVars := memory.MustPoolSlice[string](satPool, len(xorClause.Variables))
+	assignedVars := memory.MustPoolSlice[string](c.pool, len(xorClause.Variables))
+	unassignedVars := memory.MustPoolSlice[string](c.pool, len(xorClause.Variables))
 	currentXorSum := false
 
 	// Analyze current state of XOR variables
@@ -543,7 +791,7 @@ func (c *CDCLSolver) convertXORConflictToClause(xorClause *XORClause) *Clause {
 func (c *CDCLSolver) createFullXORConflictClause(xorClause *XORClause, assignedVars []string, currentXorSum bool) *Clause {
 	// When all variables are assigned and XOR is violated, we create a clause
 	// that forces at least one variable to flip its current assignment
-	literals := memory.MustPoolSlice[Literal](satPool, len(assignedVars))
+	literals := memory.MustPoolSlice[Literal](c.pool, len(assignedVars))
 
 	// Add negation of each current assignment to force a change
 	for _, variable := range assignedVars {
@@ -556,7 +804,7 @@ func (c *CDCLSolver) createFullXORConflictClause(xorClause *XORClause, assignedV
 	}
 
 	// Create the conflict clause
-	clause := NewClause(literals...)
+	clause := NewClauseIn(c.pool, literals...)
 	clause.Learned = true
 	clause.ConflictType = "XOR_FULL"
 
@@ -572,7 +820,7 @@ func (c *CDCLSolver) createUnitXORConflictClause(xorClause *XORClause, assignedV
 	// If this leads to a conflict with other constraints, we create a clause that
 	// prevents the current assignment pattern of assigned variables
 	requiredValue := currentXorSum != xorClause.Parity
-	literals := memory.MustPoolSlice[Literal](satPool, len(assignedVars)+1)
+	literals := memory.MustPoolSlice[Literal](c.pool, len(assignedVars)+1)
 
 	// Add the required assignment for the unassigned variable
 	literals = append(literals, Literal{
@@ -589,7 +837,7 @@ func (c *CDCLSolver) createUnitXORConflictClause(xorClause *XORClause, assignedV
 		})
 	}
 
-	clause := NewClause(literals...)
+	clause := NewClauseIn(c.pool, literals...)
 	clause.Learned = true
 	clause.ConflictType = "XOR_UNIT"
 	c.setXORClauseLBD(clause)
@@ -601,7 +849,7 @@ func (c *CDCLSolver) createUnitXORConflictClause(xorClause *XORClause, assignedV
 func (c *CDCLSolver) createPartialXORConflictClause(xorClause *XORClause, assignedVars []string, unassignedVars []string, currentXorSum bool) *Clause {
 	// With multiple unassigned variables, we create a clause that captures
 	// the constraint violation based on current partial assignment
-	literals := memory.MustPoolSlice[Literal](satPool, len(assignedVars)+len(unassignedVars))
+	literals := memory.MustPoolSlice[Literal](c.pool, len(assignedVars)+len(unassignedVars))
 
 	// Strategy: Create a clause that prevents the current partial assignment
 	// while allowing flexibility for unassigned variables
@@ -627,7 +875,7 @@ func (c *CDCLSolver) createPartialXORConflictClause(xorClause *XORClause, assign
 		c.addUnassignedXORConstraints(&literals, unassignedVars, remainingParity)
 	}
 
-	clause := NewClause(literals...)
+	clause := NewClauseIn(c.pool, literals...)
 	clause.Learned = true
 	clause.ConflictType = "XOR_PARTIAL"
 	c.setXORClauseLBD(clause)
@@ -685,7 +933,7 @@ func (c *CDCLSolver) sortVariablesByActivity(variables []string) {
 	// Sort in descending order of activity
 	for i := 0; i < len(variables)-1; i++ {
 		for j := i + 1; j < len(variables); j++ {
-			if c.variableActivity[variables[i]] < c.variableActivity[variables[j]] {
+			if c.activityOf(variables[i]) < c.activityOf(variables[j]) {
 				variables[i], variables[j] = variables[j], variables[i]
 			}
 		}
@@ -793,21 +1041,8 @@ func (c *CDCLSolver) performReimplication(targetLevel int) bool {
 		}
 	}
 
-	// Perform selective unassignment (only above target level)
-	unassignedVars := memory.MustPoolSlice[string](satPool, 0)[:0]
-	for variable := range c.assignment {
-		if c.trail.GetLevel(variable) > targetLevel {
-			unassignedVars = append(unassignedVars, variable)
-		}
-	}
-
-	// Remove assignments above target level
-	for _, variable := range unassignedVars {
-		delete(c.assignment, variable)
-	}
-
-	// Update trail to target level
-	c.trail.Backtrack(targetLevel)
+	// Remove the assignments above the target level
+	c.backtrack(targetLevel)
 
 	// Attempt to restore implications through propagation
 	return c.performRestorativePropagation()
@@ -894,14 +1129,14 @@ func (c *CDCLSolver) performChronologicalBacktrack(targetLevel int) {
 func (c *CDCLSolver) isChronologicalStateProductive() bool {
 	// Simple heuristic: check if we have reasonable propagation potential
 	unassignedCount := 0
-	for _, variable := range c.cnf.Variables {
-		if !c.assignment.IsAssigned(variable) {
+	for _, v := range c.vars {
+		if c.trail.value(MkLit(v, false)) == 0 {
 			unassignedCount++
 		}
 	}
 
 	// State is productive if we haven't eliminated too many options
-	return unassignedCount > len(c.cnf.Variables)/4
+	return unassignedCount > len(c.vars)/4
 }
 
 // updateChronologicalSuccess updates the success rate tracking
@@ -1040,6 +1275,9 @@ func (c *CDCLSolver) performInprocessing() {
 	}
 
 	c.lastInprocess = c.conflicts
+	if result.VariablesEliminated > 0 || result.VariablesSubstituted > 0 || result.BlockedClauses > 0 {
+		c.eliminated = true
+	}
 
 	// Update statistics
 	c.statistics.InprocessRuns++
@@ -1047,7 +1285,7 @@ func (c *CDCLSolver) performInprocessing() {
 
 	// NEW: capture reduction and cost to drive adaptive scheduling
 	totalReductions := result.ClausesRemoved + result.ClausesStrengthened +
-		result.VariablesEliminated + result.UnitsLearned
+		result.VariablesEliminated + result.VariablesSubstituted + result.UnitsLearned
 	c.lastInprocessReduction = totalReductions
 	c.lastInprocessCostNs = inprocessTime
 
@@ -1061,10 +1299,14 @@ func (c *CDCLSolver) performInprocessing() {
 		c.statistics.VariablesEliminated += int64(originalVars - newVars)
 
 		// **CRITICAL**: Rebuild watch lists if clauses were modified
-		if result.ClausesRemoved > 0 || result.ClausesStrengthened > 0 {
+		if result.ClausesRemoved > 0 || result.ClausesStrengthened > 0 ||
+			result.VariablesEliminated > 0 || result.VariablesSubstituted > 0 ||
+			result.VariablesAdded > 0 || result.UnitsLearned > 0 {
 			c.rebuildWatchLists()
+			c.requeueRootAssignments()
 		}
 	}
+	c.syncVariables(true)
 
 	// Invalidate caches
 	c.cacheValid = false
@@ -1078,11 +1320,32 @@ func (c *CDCLSolver) performInprocessing() {
 
 // rebuildWatchLists reconstructs watch lists after formula modification
 func (c *CDCLSolver) rebuildWatchLists() {
-	// Clear existing watch lists
-	c.watchLists = make(map[string][]*WatchedClause)
-
-	// Rebuild from current clauses
+	// Rebuild from current clauses; learned clauses live only in the database
 	c.initializeWatchLists()
+	if c.clauseDatabase != nil {
+		for _, clause := range c.clauseDatabase.GetAllClauses() {
+			if clause != nil && !clause.Deleted {
+				c.watchClause(clause)
+			}
+		}
+	}
+}
+
+// requeueRootAssignments re-establishes the watch invariant after the watch
+// lists were rebuilt at level 0: new unit clauses are assigned and every
+// assigned literal is propagated again, so clauses that are already unit
+// or falsified are found by the next propagate call.
+func (c *CDCLSolver) requeueRootAssignments() {
+	for _, clause := range c.cnf.Clauses {
+		if clause == nil || clause.Deleted || len(clause.Literals) != 1 {
+			continue
+		}
+		lit := clause.Literals[0]
+		if !c.assignment.IsAssigned(lit.Variable) {
+			c.assign(lit.Variable, !lit.Negated, clause)
+		}
+	}
+	c.qhead = 0
 }
 
 // updateHeuristicsAfterInprocessing updates heuristics based on inprocessing results
@@ -1122,197 +1385,171 @@ func (c *CDCLSolver) adaptInprocessingFrequency(result *InprocessResult, duratio
 	}
 }
 
-// propagate implements optimized BCP with two-watched literals
+// propagate implements BCP with two watched literals over the core. Each
+// trail literal is propagated once; a clause whose blocker is true is
+// skipped without being visited.
 func (c *CDCLSolver) propagate() *Clause {
-	c.queueHead = 0
-
-	for c.queueHead < len(c.propagationQueue) {
-		// Get next literal that was just falsified
-		falseLit := c.propagationQueue[c.queueHead]
-		c.queueHead++
-
-		// Check if we've already processed this variable in this propagation round
-		if c.propagationCache[falseLit.Variable] {
+	t := c.trail
+	for c.qhead < len(t.lits) {
+		falseLit := t.lits[c.qhead].Not()
+		c.qhead++
+		if int(falseLit) >= len(c.watches) {
 			continue
 		}
-		c.propagationCache[falseLit.Variable] = true
-
-		// Check all clauses watching this literal's variable
-		watchedClauses := c.watchLists[falseLit.Variable]
-		i := 0
 
-		for j := 0; j < len(watchedClauses); j++ {
-			wc := watchedClauses[j]
-
-			if wc == nil || wc.Clause == nil || wc.Clause.Deleted {
+		ws := c.watches[falseLit]
+		i, j := 0, 0
+		for i < len(ws) {
+			w := ws[i]
+			i++
+			if t.value(w.blocker) == 1 {
+				ws[j] = w
+				j++
 				continue
 			}
-
-			conflict, keep := c.processWatchedClause(wc, falseLit)
-			
-			if keep {
-				watchedClauses[i] = wc
-				i++
+			cc := &c.watched[w.cref]
+			if cc.clause.Deleted {
+				continue // Drop the watch lazily
+			}
+			lits := c.watchLits[cc.start : cc.start+cc.size]
+			if len(lits) == 1 {
+				// A unit clause whose literal became false
+				ws[j] = w
+				j++
+				return c.propagationConflict(falseLit, ws, i, j, cc.clause)
 			}
-			
-			if conflict != nil {
-				// We found a conflict!
-				// MUST update the watch list for elements that were moved before the conflict occurred!
-				// Elements from j+1 to the end were not processed yet, so we keep them.
-				copy(watchedClauses[i:], watchedClauses[j+1:])
-				
-				newLen := i + len(watchedClauses) - (j + 1)
-				// Nil out remaining to prevent memory leaks
-				for k := newLen; k < len(watchedClauses); k++ {
-					watchedClauses[k] = nil
+			if lits[0] == falseLit {
+				lits[0], lits[1] = lits[1], lits[0]
+			}
+			first := lits[0]
+			kept := watcher{cref: w.cref, blocker: first}
+			if first != w.blocker && t.value(first) == 1 {
+				ws[j] = kept
+				j++
+				continue
+			}
+
+			moved := false
+			for k := 2; k < len(lits); k++ {
+				if t.value(lits[k]) != -1 {
+					lits[1], lits[k] = lits[k], falseLit
+					c.watch(lits[1], kept)
+					moved = true
+					break
 				}
-				c.watchLists[falseLit.Variable] = watchedClauses[:newLen]
-				
-				c.clearPropagationState()
-				return conflict
 			}
-		}
+			if moved {
+				continue
+			}
 
-		// Update watch list (remove clauses that moved to other lists)
-		for k := i; k < len(watchedClauses); k++ {
-			watchedClauses[k] = nil
+			ws[j] = kept
+			j++
+			if t.value(first) == -1 {
+				return c.propagationConflict(falseLit, ws, i, j, cc.clause)
+			}
+			c.assignLit(first, cc.clause)
+			c.statistics.Propagations++
 		}
-		c.watchLists[falseLit.Variable] = watchedClauses[:i]
+		c.watches[falseLit] = ws[:j]
 	}
-
-	c.clearPropagationState()
 	return nil
 }
 
-// processWatchedClause processes a single watched clause during propagation.
-// Returns (conflictClause, keepInCurrentWatchList)
-func (c *CDCLSolver) processWatchedClause(wc *WatchedClause, falseLit Literal) (*Clause, bool) {
-	// Determine which watch this affects
-	watchIdx := -1
-	if wc.Watch1 >= 0 && wc.Watch1 < len(wc.Clause.Literals) {
-		lit1 := wc.Clause.Literals[wc.Watch1]
-		if lit1.Variable == falseLit.Variable && lit1.Negated == falseLit.Negated {
-			watchIdx = wc.Watch1
-		}
-	}
+// propagationConflict keeps the watchers of falseLit not yet visited,
+// drops the literals waiting for propagation, as backtracking unassigns
+// them, and returns the conflict clause
+func (c *CDCLSolver) propagationConflict(falseLit Lit, ws []watcher, i, j int, conflict *Clause) *Clause {
+	j += copy(ws[j:], ws[i:])
+	c.watches[falseLit] = ws[:j]
+	c.clearPropagationState()
+	return conflict
+}
 
-	if watchIdx == -1 && wc.Watch2 >= 0 && wc.Watch2 < len(wc.Clause.Literals) {
-		lit2 := wc.Clause.Literals[wc.Watch2]
-		if lit2.Variable == falseLit.Variable && lit2.Negated == falseLit.Negated {
-			watchIdx = wc.Watch2
-		}
-	}
+// clearPropagationState drops the literals waiting for propagation
+func (c *CDCLSolver) clearPropagationState() {
+	c.qhead = len(c.trail.lits)
+}
 
-	if watchIdx == -1 {
-		// This clause is not affected by this literal
-		return nil, true
+// watch adds w to the watch list of l
+func (c *CDCLSolver) watch(l Lit, w watcher) {
+	for int(l) >= len(c.watches) {
+		c.watches = append(c.watches, nil)
 	}
+	c.watches[l] = append(c.watches[l], w)
+}
 
-	// Try to find a new literal to watch
-	newWatch := c.findNewWatch(wc, watchIdx)
-	if newWatch != -1 {
-		// Found new watch - update and move to new watch list
-		if watchIdx == wc.Watch1 {
-			wc.Watch1 = newWatch
-		} else {
-			wc.Watch2 = newWatch
-		}
-
-		// Add to new variable's watch list
-		newVar := wc.Clause.Literals[newWatch].Variable
-		c.appendWatch(newVar, wc)
-		return nil, false // Don't add back to current list
+// watchClauseAt copies clause to the core and watches its literals at
+// watch1 and watch2, or its only literal
+func (c *CDCLSolver) watchClauseAt(clause *Clause, watch1, watch2 int) {
+	if len(clause.Literals) == 0 {
+		return
 	}
-
-	// Could not find new watch - check other watched literal
-	otherIdx := wc.Watch1
-	if watchIdx == wc.Watch1 {
-		otherIdx = wc.Watch2
+	cref := int32(len(c.watched))
+	start := int32(len(c.watchLits))
+	for _, lit := range clause.Literals {
+		c.watchLits = append(c.watchLits, c.trail.lit(lit))
 	}
-
-	if otherIdx == -1 || otherIdx >= len(wc.Clause.Literals) {
-		// Unit clause became empty - conflict
-		return wc.Clause, true
+	c.watched = append(c.watched, coreClause{clause: clause, start: start, size: int32(len(clause.Literals))})
+	lits := c.watchLits[start:]
+	if len(lits) == 1 {
+		c.watch(lits[0], watcher{cref: cref, blocker: lits[0]})
+		return
 	}
 
-	otherLit := wc.Clause.Literals[otherIdx]
-	if c.assignment.IsAssigned(otherLit.Variable) {
-		val := c.assignment[otherLit.Variable]
-		satisfied := (val && !otherLit.Negated) || (!val && otherLit.Negated)
-
-		if !satisfied {
-			// Conflict - both watches are false
-			return wc.Clause, true
-		}
-
-		// Clause is satisfied - keep in watch list
-		return nil, true
+	lits[0], lits[watch1] = lits[watch1], lits[0]
+	if watch2 == 0 {
+		watch2 = watch1
 	}
-
-	// Unit propagation - assign the other literal
-	value := !otherLit.Negated
-	c.assign(otherLit.Variable, value, wc.Clause)
-	c.statistics.Propagations++
-
-	// Keep in watch list
-	return nil, true
+	lits[1], lits[watch2] = lits[watch2], lits[1]
+	c.watch(lits[0], watcher{cref: cref, blocker: lits[1]})
+	c.watch(lits[1], watcher{cref: cref, blocker: lits[0]})
 }
 
-// clearPropagationState resets the state variables for the next propagation
-func (c *CDCLSolver) clearPropagationState() {
-	c.propagationQueue = c.propagationQueue[:0]
-	c.queueHead = 0
-	c.propagationCache = make(map[string]bool)
-}
-
-// Helper methods for advanced CDCL
-func (c *CDCLSolver) findNewWatch(wc *WatchedClause, excludeIdx int) int {
-	for i, lit := range wc.Clause.Literals {
-		if i == wc.Watch1 || i == wc.Watch2 || i == excludeIdx {
-			continue
-		}
-
-		if !c.assignment.IsAssigned(lit.Variable) {
-			return i // Unassigned literal - good watch
-		}
-
-		val := c.assignment[lit.Variable]
-		if (val && !lit.Negated) || (!val && lit.Negated) {
-			return i // Satisfied literal - excellent watch
-		}
+// clearWatches empties the watch lists and the core's clauses, keeping
+// their arrays for the next rebuild
+func (c *CDCLSolver) clearWatches() {
+	for l := range c.watches {
+		c.watches[l] = c.watches[l][:0]
 	}
-	return -1
+	clear(c.watched)
+	c.watched = c.watched[:0]
+	c.watchLits = c.watchLits[:0]
 }
 
-// appendWatch adds a WatchedClause to a variable's watch list, using the memory pool for backing arrays
-func (c *CDCLSolver) appendWatch(variable string, wc *WatchedClause) {
-	list := c.watchLists[variable]
-	if len(list) == cap(list) {
-		newCap := cap(list) * 2
-		if newCap == 0 {
-			newCap = 8
-		}
-		// Allocate new backing array off-heap
-		newList := memory.MustPoolSlice[*WatchedClause](c.watchPool, newCap)
-		list = append(newList, list...)
+// syncVariables numbers the variables of the formula. Unless all is set,
+// only the variables added since the last call are numbered.
+func (c *CDCLSolver) syncVariables(all bool) {
+	if c.cnf == nil {
+		return
+	}
+	if all || len(c.vars) > len(c.cnf.Variables) {
+		c.vars = c.vars[:0]
+	}
+	for _, name := range c.cnf.Variables[len(c.vars):] {
+		c.vars = append(c.vars, c.trail.varOf(name))
 	}
-	list = append(list, wc)
-	c.watchLists[variable] = list
 }
 
+// assign makes variable take value at the current decision level
 func (c *CDCLSolver) assign(variable string, value bool, reason *Clause) {
+	c.assignLit(MkLit(c.trail.varOf(variable), !value), reason)
+}
+
+// assignLit makes p true at the current decision level. The assignment map
+// mirrors the trail for the components that read names.
+func (c *CDCLSolver) assignLit(p Lit, reason *Clause) {
+	c.trail.enqueue(p, c.decisionLevel, reason)
+	variable, value := c.trail.names[p.Var()], !p.Negated()
 	c.assignment[variable] = value
-	c.trail.Assign(variable, value, c.decisionLevel, reason)
 	c.cacheValid = false // Invalidate unassigned cache
+	if c.decisionLevel == 0 {
+		c.proofUnit(variable, value, reason)
+	}
 
 	// Track implications for ILB
 	if reason != nil && c.ilb != nil {
 		c.ilb.levelImplicationCount[c.decisionLevel]++
 	}
-
-	// Add to propagation queue
-	falseLit := Literal{Variable: variable, Negated: value}
-	c.propagationQueue = append(c.propagationQueue, falseLit)
 }
 
 func (c *CDCLSolver) learnClause(clause *Clause) {
@@ -1341,31 +1578,14 @@ func (c *CDCLSolver) learnClause(clause *Clause) {
 		c.clauseDatabase.AddClause(clause, c.conflicts)
 	}
 
-	// Add to watch lists with LBD-aware blocking literal selection
+	// Add to watch lists. NewClause sorts literals by name, so the watches
+	// are placed on the two highest-level literals: the asserting literal
+	// and the one that becomes false last after backtracking.
 	if len(clause.Literals) >= 2 {
-		wc := &WatchedClause{
-			Clause: clause,
-			Watch1: 0,
-			Watch2: 1,
-			// Set blocker to highest level literal for better performance
-			Blocker: c.selectBestBlocker(clause),
-		}
-
-		var1 := clause.Literals[0].Variable
-		var2 := clause.Literals[1].Variable
-		c.appendWatch(var1, wc)
-		c.appendWatch(var2, wc)
-	} else if len(clause.Literals) == 1 {
-		// Unit clause
-		wc := &WatchedClause{
-			Clause:  clause,
-			Watch1:  0,
-			Watch2:  -1,
-			Blocker: clause.Literals[0],
-		}
-
-		variable := clause.Literals[0].Variable
-		c.appendWatch(variable, wc)
+		watch1, watch2 := c.learnedWatches(clause)
+		c.watchClauseAt(clause, watch1, watch2)
+	} else {
+		c.watchClause(clause)
 	}
 
 	// Update variable activities
@@ -1374,25 +1594,60 @@ func (c *CDCLSolver) learnClause(clause *Clause) {
 	}
 }
 
-// selectBestBlocker selects the best blocking literal based on decision level
-func (c *CDCLSolver) selectBestBlocker(clause *Clause) Literal {
-	if len(clause.Literals) == 0 {
-		return Literal{}
-	}
-
-	// Select literal from highest decision level as blocker
-	bestLit := clause.Literals[0]
-	bestLevel := c.trail.GetLevel(bestLit.Variable)
+// learnedWatches returns the indices of the two literals of a learned clause
+// with the highest decision levels
+func (c *CDCLSolver) learnedWatches(clause *Clause) (int, int) {
+	watch1, watch2 := 0, 1
+	level1 := c.trail.GetLevel(clause.Literals[0].Variable)
+	level2 := c.trail.GetLevel(clause.Literals[1].Variable)
+	if level2 > level1 {
+		watch1, watch2 = watch2, watch1
+		level1, level2 = level2, level1
+	}
+	for i := 2; i < len(clause.Literals); i++ {
+		level := c.trail.GetLevel(clause.Literals[i].Variable)
+		if level > level1 {
+			watch2, level2 = watch1, level1
+			watch1, level1 = i, level
+		} else if level > level2 {
+			watch2, level2 = i, level
+		}
+	}
+	return watch1, watch2
+}
 
-	for _, lit := range clause.Literals[1:] {
-		level := c.trail.GetLevel(lit.Variable)
-		if level > bestLevel {
-			bestLevel = level
-			bestLit = lit
+// assertLearnedClause assigns the asserting literal of a learned clause once
+// backtracking has left it as the only unassigned literal. A clause that is
+// not unit at this point is left to propagation.
+func (c *CDCLSolver) assertLearnedClause(clause *Clause) {
+	asserting := -1
+	for i, lit := range clause.Literals {
+		value, assigned := c.assignment[lit.Variable]
+		if !assigned {
+			if asserting != -1 {
+				return
+			}
+			asserting = i
+			continue
+		}
+		if value != lit.Negated {
+			return // already satisfied
 		}
 	}
+	if asserting != -1 {
+		lit := clause.Literals[asserting]
+		c.assign(lit.Variable, !lit.Negated, clause)
+	}
+}
 
-	return bestLit
+// isReason reports whether clause is the reason of a current assignment
+func (c *CDCLSolver) isReason(clause *Clause) bool {
+	for _, lit := range clause.Literals {
+		if c.trail.GetReason(lit.Variable) == clause {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *CDCLSolver) allVariablesAssigned() bool {
@@ -1400,8 +1655,9 @@ func (c *CDCLSolver) allVariablesAssigned() bool {
 		return true
 	}
 
-	for _, variable := range c.cnf.Variables {
-		if !c.assignment.IsAssigned(variable) {
+	c.syncVariables(false)
+	for _, v := range c.vars {
+		if c.trail.value(MkLit(v, false)) == 0 {
 			return false
 		}
 	}
@@ -1416,9 +1672,10 @@ func (c *CDCLSolver) chooseDecisionVariable() string {
 	// Use cached unassigned variables if available and valid
 	if !c.cacheValid {
 		c.unassignedCache = c.unassignedCache[:0]
-		for _, variable := range c.cnf.Variables {
-			if !c.assignment.IsAssigned(variable) {
-				c.unassignedCache = append(c.unassignedCache, variable)
+		c.syncVariables(false)
+		for _, v := range c.vars {
+			if c.trail.value(MkLit(v, false)) == 0 {
+				c.unassignedCache = append(c.unassignedCache, c.trail.names[v])
 			}
 		}
 		c.cacheValid = true
@@ -1460,6 +1717,13 @@ func (c *CDCLSolver) choosePolarity(variable string) bool {
 }
 
 func (c *CDCLSolver) backtrack(level int) {
+	if c.cardinality != nil {
+		c.cardinality.undo(c.trail, c.trail.levelStart(level))
+	}
+	if c.pbSlack != nil {
+		c.pbSlack.undo(c.trail, c.trail.levelStart(level))
+	}
+
 	// Use the trail to backtrack
 	unassignedVars := c.trail.Backtrack(level)
 
@@ -1471,15 +1735,14 @@ func (c *CDCLSolver) backtrack(level int) {
 	// Re-insert unassigned variables into the decision heap
 	c.heuristic.OnBacktrack(unassignedVars)
 
+	c.qhead = min(c.qhead, len(c.trail.lits))
 	c.cacheValid = false // Invalidate unassigned cache
 	c.decisionLevel = level
 }
 
 func (c *CDCLSolver) restart() {
-	c.assignment = make(Assignment)
-	c.trail.Clear()
-	c.decisionLevel = 0
-	c.cacheValid = false // Invalidate unassigned cache
+	// Root-level assignments are consequences of the formula and survive
+	c.backtrack(0)
 	c.restartStrategy.OnRestart()
 }
 
@@ -1535,18 +1798,19 @@ func (c *CDCLSolver) deleteClauses() {
 	// Remove candidates from watch lists and the database
 	deleted := 0
 	for _, cl := range candidates {
-		if cl == nil {
+		if cl == nil || c.isReason(cl) {
 			continue
 		}
 		c.removeFromWatchLists(cl)
 		if c.clauseDatabase.RemoveClause(cl) {
 			cl.Deleted = true
+			c.proof.DeleteClause(cl.ID, cl.Literals)
 			deleted++
 		}
 	}
 
 	// Clean up cnf.Clauses and free deleted clauses
-	validClauses := memory.MustPoolSlice[*Clause](satPool, len(c.cnf.Clauses))
+	validClauses := memory.MustPoolSlice[*Clause](c.pool, len(c.cnf.Clauses))
 	for _, clause := range c.cnf.Clauses {
 		if clause != nil && !clause.Deleted {
 			validClauses = append(validClauses, clause)
@@ -1560,78 +1824,78 @@ func (c *CDCLSolver) deleteClauses() {
 }
 
 func (c *CDCLSolver) initializeWatchLists() {
-	if c.watchPool != nil {
-		c.watchPool.Reset()
-	}
-	c.watchLists = make(map[string][]*WatchedClause)
+	c.clearWatches()
+	c.syncVariables(true)
 
 	// Set up watch lists for all clauses
 	for _, clause := range c.cnf.Clauses {
 		if clause == nil || clause.Deleted {
 			continue
 		}
-
-		if len(clause.Literals) >= 2 {
-			// Watch first two literals
-			wc := &WatchedClause{
-				Clause: clause,
-				Watch1: 0,
-				Watch2: 1,
-			}
-
-			var1 := clause.Literals[0].Variable
-			var2 := clause.Literals[1].Variable
-			c.appendWatch(var1, wc)
-			c.appendWatch(var2, wc)
-		} else if len(clause.Literals) == 1 {
-			// Unit clause - create watched clause with single watch
-			wc := &WatchedClause{
-				Clause: clause,
-				Watch1: 0,
-				Watch2: -1, // No second watch
-			}
-
-			variable := clause.Literals[0].Variable
-			c.appendWatch(variable, wc)
-		}
+		c.watchClause(clause)
 	}
 }
 
+// watchClause watches the first two literals of clause, or its only one
+func (c *CDCLSolver) watchClause(clause *Clause) {
+	c.watchClauseAt(clause, 0, 1)
+}
+
 func (c *CDCLSolver) initializeHeuristics() {
 	// Initialize variable activities
-	for _, variable := range c.cnf.Variables {
-		c.variableActivity[variable] = 0.0
+	for _, v := range c.vars {
+		c.activityRef(v)
+		c.activity[v] = 0.0
 	}
 }
 
+// removeFromWatchLists drops the watchers of clause, which was deleted
 func (c *CDCLSolver) removeFromWatchLists(clause *Clause) {
 	for _, lit := range clause.Literals {
-		if watchedClauses, exists := c.watchLists[lit.Variable]; exists {
-			for i, watchedClause := range watchedClauses {
-				if watchedClause.Clause.ID == clause.ID {
-					copy(watchedClauses[i:], watchedClauses[i+1:])
-					watchedClauses[len(watchedClauses)-1] = nil
-					c.watchLists[lit.Variable] = watchedClauses[:len(watchedClauses)-1]
-					break
-				}
+		l := c.trail.lit(lit)
+		if int(l) >= len(c.watches) {
+			continue
+		}
+		ws := c.watches[l]
+		j := 0
+		for _, w := range ws {
+			if c.watched[w.cref].clause != clause {
+				ws[j] = w
+				j++
 			}
 		}
+		c.watches[l] = ws[:j]
 	}
 }
 
+// activityRef returns the activity of core variable v, growing the
+// activity array if needed
+func (c *CDCLSolver) activityRef(v int32) *float64 {
+	for int(v) >= len(c.activity) {
+		c.activity = append(c.activity, 0)
+	}
+	return &c.activity[v]
+}
+
+// activityOf returns the activity of variable
+func (c *CDCLSolver) activityOf(variable string) float64 {
+	return *c.activityRef(c.trail.varOf(variable))
+}
+
 // Variable activity management (VSIDS)
 func (c *CDCLSolver) bumpVariableActivity(variable string) {
-	c.variableActivity[variable] += c.varActivityInc
+	activity := c.activityRef(c.trail.varOf(variable))
+	*activity += c.varActivityInc
 
 	// Rescale if activities get too large
-	if c.variableActivity[variable] > 1e100 {
+	if *activity > 1e100 {
 		c.rescaleVariableActivities()
 	}
 }
 
 func (c *CDCLSolver) rescaleVariableActivities() {
-	for variable := range c.variableActivity {
-		c.variableActivity[variable] *= 1e-100
+	for v := range c.activity {
+		c.activity[v] *= 1e-100
 	}
 	c.varActivityInc *= 1e-100
 }
@@ -1641,34 +1905,24 @@ func (c *CDCLSolver) decayVariableActivities() {
 }
 
 // Interface implementations
+
+// AddClause adds a clause to the solver's formula, creating an empty
+// formula first if the solver has none. Added clauses are kept by later
+// SolveAssuming calls.
 func (c *CDCLSolver) AddClause(clause *Clause) error {
+	if c.cnf == nil {
+		c.cnf = NewCNFWithPool(c.pool)
+	}
 	c.cnf.AddClause(clause)
+	c.syncVariables(false)
 	c.cacheValid = false // Invalidate unassigned cache
-
-	// Update watch lists for new clause (complete implementation)
-	if len(clause.Literals) >= 2 {
-		wc := &WatchedClause{
-			Clause: clause,
-			Watch1: 0,
-			Watch2: 1,
-		}
-
-		var1 := clause.Literals[0].Variable
-		var2 := clause.Literals[1].Variable
-		c.appendWatch(var1, wc)
-		c.appendWatch(var2, wc)
-	} else if len(clause.Literals) == 1 {
-		// Unit clause
-		wc := &WatchedClause{
-			Clause: clause,
-			Watch1: 0,
-			Watch2: -1,
-		}
-
-		variable := clause.Literals[0].Variable
-		c.appendWatch(variable, wc)
+	c.pendingClauses = true
+	if len(clause.Literals) == 0 {
+		c.refuted = true
 	}
 
+	// Update watch lists for new clause
+	c.watchClause(clause)
 	return nil
 }
 
@@ -1679,21 +1933,20 @@ func (c *CDCLSolver) GetStatistics() SolverStatistics {
 func (c *CDCLSolver) Reset() {
 	c.statistics = SolverStatistics{LBDDistribution: make(map[int]int64)}
 	c.assignment = make(Assignment)
-	c.trail.Clear()
-	if c.watchPool != nil {
-		c.watchPool.Reset()
-	}
-	c.watchLists = make(map[string][]*WatchedClause)
+	c.cnf = nil
+	c.resetIncremental()
+	c.reconstruction.Reset()
+	c.variables, c.original = nil, nil
+	// Variables are numbered afresh, so the core's arrays are dropped
+	c.trail = newLiteralTrail()
+	c.watches, c.watched, c.watchLits, c.vars, c.activity = nil, nil, nil, nil, nil
+	c.qhead = 0
 	c.decisionLevel = 0
-	c.variableActivity = make(map[string]float64)
 	c.conflicts = 0
-	c.propagationQueue = memory.MustPoolSlice[Literal](satPool, 0)[:0]
-	c.queueHead = 0
 	c.lbdSum = 0
 	c.glueClauseCount = 0
-	c.unassignedCache = memory.MustPoolSlice[string](satPool, 0)[:0]
+	c.unassignedCache = memory.MustPoolSlice[string](c.pool, 0)[:0]
 	c.cacheValid = false
-	c.propagationCache = make(map[string]bool)
 
 	// Reset inprocessing tracking
 	c.lastInprocess = 0
@@ -1757,7 +2010,7 @@ func (c *CDCLSolver) filterIrredundant() []*Clause {
 	if c.cnf == nil {
 		return nil
 	}
-	out := memory.MustPoolSlice[*Clause](satPool, len(c.cnf.Clauses))[:0]
+	out := memory.MustPoolSlice[*Clause](c.pool, len(c.cnf.Clauses))[:0]
 	for _, clause := range c.cnf.Clauses {
 		if clause != nil && !clause.Deleted && !clause.Learned {
 			out = append(out, clause)
@@ -0,0 +1,170 @@
+package sat
+
+// literalTrail is the assignment state of CDCLSolver over the int32
+// literals of the dense core. Variables are numbered when first seen, and
+// values, levels and reasons are arrays indexed by literal or variable, so
+// propagation never hashes a variable name. The DecisionTrail methods map
+// names at the edge for the conflict analyzer and the constraint
+// propagators.
+type literalTrail struct {
+	// Name layer
+	ids   map[string]int32
+	names []string // Variable -> name
+
+	values []int8    // Literal -> 1 true, -1 false, 0 unassigned
+	level  []int32   // Variable -> decision level
+	reason []*Clause // Variable -> implying clause, nil for decisions
+	lits   []Lit     // Assigned literals in order
+	lim    []int     // Length of lits at the start of each decision level
+
+	unassigned []string // Scratch for Backtrack
+}
+
+// coreClause is a clause watched by the CDCL core. Its literals are copied
+// to the core's literal arena with the two watched literals first, so the
+// clause itself is never reordered.
+type coreClause struct {
+	clause      *Clause
+	start, size int32
+}
+
+// newLiteralTrail creates an empty trail with no variables
+func newLiteralTrail() *literalTrail {
+	return &literalTrail{ids: make(map[string]int32)}
+}
+
+// varOf returns the variable numbered for name, numbering it if needed
+func (t *literalTrail) varOf(name string) int32 {
+	if v, ok := t.ids[name]; ok {
+		return v
+	}
+	v := int32(len(t.names))
+	t.ids[name] = v
+	t.names = append(t.names, name)
+	t.values = append(t.values, 0, 0)
+	t.level = append(t.level, 0)
+	t.reason = append(t.reason, nil)
+	return v
+}
+
+// lit returns the core literal of lit
+func (t *literalTrail) lit(lit Literal) Lit {
+	return MkLit(t.varOf(lit.Variable), lit.Negated)
+}
+
+// literal returns the named literal of l
+func (t *literalTrail) literal(l Lit) Literal {
+	return Literal{Variable: t.names[l.Var()], Negated: l.Negated()}
+}
+
+// value returns 1 if l is true, -1 if it is false and 0 if it is unassigned
+func (t *literalTrail) value(l Lit) int8 {
+	return t.values[l]
+}
+
+// enqueue makes p true at level
+func (t *literalTrail) enqueue(p Lit, level int, reason *Clause) {
+	for len(t.lim) < level {
+		t.lim = append(t.lim, len(t.lits))
+	}
+	v := p.Var()
+	t.values[p], t.values[p.Not()] = 1, -1
+	t.level[v] = int32(level)
+	t.reason[v] = reason
+	t.lits = append(t.lits, p)
+}
+
+// Assign adds a variable assignment
+func (t *literalTrail) Assign(variable string, value bool, level int, reason *Clause) {
+	t.enqueue(MkLit(t.varOf(variable), !value), level, reason)
+}
+
+// levelStart returns the trail position of the first assignment above
+// level
+func (t *literalTrail) levelStart(level int) int {
+	if level >= len(t.lim) {
+		return len(t.lits)
+	}
+	return t.lim[level]
+}
+
+// Backtrack undoes the assignments above level and returns their
+// variables. The slice is reused by the next call.
+func (t *literalTrail) Backtrack(level int) []string {
+	t.unassigned = t.unassigned[:0]
+	if level >= len(t.lim) {
+		return t.unassigned
+	}
+	cut := t.levelStart(level)
+	for _, p := range t.lits[cut:] {
+		v := p.Var()
+		t.values[p], t.values[p.Not()] = 0, 0
+		t.reason[v] = nil
+		t.unassigned = append(t.unassigned, t.names[v])
+	}
+	t.lits = t.lits[:cut]
+	t.lim = t.lim[:level]
+	return t.unassigned
+}
+
+// GetLevel returns the decision level of variable, or -1 if it is
+// unassigned
+func (t *literalTrail) GetLevel(variable string) int {
+	v, ok := t.ids[variable]
+	if !ok || t.values[MkLit(v, false)] == 0 {
+		return -1
+	}
+	return int(t.level[v])
+}
+
+// GetReason returns the clause that implied variable, or nil
+func (t *literalTrail) GetReason(variable string) *Clause {
+	if v, ok := t.ids[variable]; ok {
+		return t.reason[v]
+	}
+	return nil
+}
+
+// GetAssignment returns the current assignment
+func (t *literalTrail) GetAssignment() Assignment {
+	assignment := make(Assignment, len(t.lits))
+	for _, p := range t.lits {
+		assignment[t.names[p.Var()]] = !p.Negated()
+	}
+	return assignment
+}
+
+// GetCurrentLevel returns the current decision level
+func (t *literalTrail) GetCurrentLevel() int {
+	return len(t.lim)
+}
+
+// GetTrailAtLevel returns the assignments of level in trail order
+func (t *literalTrail) GetTrailAtLevel(level int) []TrailEntry {
+	if level > len(t.lim) {
+		return nil
+	}
+	start, end := 0, len(t.lits)
+	if level > 0 {
+		start = t.lim[level-1]
+	}
+	if level < len(t.lim) {
+		end = t.lim[level]
+	}
+	entries := make([]TrailEntry, 0, end-start)
+	for _, p := range t.lits[start:end] {
+		v := p.Var()
+		entries = append(entries, TrailEntry{Variable: t.names[v], Value: !p.Negated(), Level: level, Reason: t.reason[v]})
+	}
+	return entries
+}
+
+// Clear unassigns every variable, keeping their numbers
+func (t *literalTrail) Clear() {
+	t.Backtrack(0)
+	for _, p := range t.lits {
+		t.values[p], t.values[p.Not()] = 0, 0
+		t.reason[p.Var()] = nil
+	}
+	t.lits = t.lits[:0]
+}
@@ -0,0 +1,262 @@
+package sat
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestFirstUIPAnalyzer_AssertingClause(t *testing.T) {
+	trail := NewDecisionTrail()
+	trail.Assign("A", true, 1, nil)
+	trail.Assign("B", true, 2, nil)
+	trail.Assign("C", true, 3, nil)
+	trail.Assign("D", true, 4, nil)
+
+	conflict := NewClause(L("A", true), L("B", true), L("C", true), L("D", true))
+	learned, level := NewFirstUIPAnalyzer().Analyze(conflict, trail)
+	if learned == nil {
+		t.Fatal("expected a learned clause")
+	}
+	for _, lit := range learned.Literals {
+		if !lit.Negated {
+			t.Errorf("learned literal %v is true under the trail", lit)
+		}
+	}
+	if level != 3 {
+		t.Errorf("backtrack level = %d, want 3", level)
+	}
+}
+
+func TestCDCLSolver_RestartKeepsRootAssignments(t *testing.T) {
+	solver := NewCDCLSolver()
+	solver.cnf = NewCNF()
+	solver.heuristic = NewVSIDSHeuristic()
+	solver.restartStrategy = NewLubyRestartStrategy()
+	solver.assign("A", true, nil)
+	solver.decisionLevel = 1
+	solver.assign("B", true, nil)
+
+	solver.restart()
+
+	if value, ok := solver.assignment["A"]; !ok || !value {
+		t.Error("root-level assignment of A was lost on restart")
+	}
+	if _, ok := solver.assignment["B"]; ok {
+		t.Error("level-1 assignment of B survived the restart")
+	}
+	if solver.decisionLevel != 0 {
+		t.Errorf("decision level = %d, want 0", solver.decisionLevel)
+	}
+}
+
+// deleteAll is a deletion policy that marks every clause for deletion
+type deleteAll struct{}
+
+func (deleteAll) ShouldDelete(*Clause, SolverStatistics) bool { return true }
+func (deleteAll) Update([]*Clause)                            {}
+func (deleteAll) Reset()                                      {}
+func (deleteAll) Name() string                                { return "DeleteAll" }
+
+func TestCDCLSolver_DeleteClausesKeepsReasons(t *testing.T) {
+	solver := NewCDCLSolver()
+	solver.deletionPolicy = deleteAll{}
+	solver.heuristic = NewVSIDSHeuristic()
+	solver.cnf = NewCNF()
+	db := NewClauseDatabase(10, 1)
+	var reason *Clause
+	for i := 0; i < 8; i++ {
+		c := NewClause(L(varName(i), false), L(varName(i+10), false))
+		c.Learned = true
+		c.ID = i
+		c.Tier = 2
+		db.AddClause(c, 0)
+		solver.cnf.Clauses = append(solver.cnf.Clauses, c)
+		if i == 0 {
+			reason = c
+		}
+	}
+	solver.clauseDatabase = db
+	solver.maxLearnedSize = 0
+	solver.conflicts = 100
+	solver.decisionLevel = 1
+	solver.assign(varName(10), false, nil)
+	solver.assign(varName(0), true, reason)
+
+	solver.deleteClauses()
+
+	if reason.Deleted {
+		t.Error("reason clause of an assigned variable was deleted")
+	}
+	if solver.statistics.DeletedClauses == 0 {
+		t.Error("expected the other learned clauses to be deleted")
+	}
+}
+
+func TestExtendedCNF_XORClausesSurviveGC(t *testing.T) {
+	ecnf := NewExtendedCNF()
+	for i := 0; i < 16; i++ {
+		ecnf.AddXORClause(NewXORClause([]string{varName(i), varName(i + 1)}, i%2 == 0))
+	}
+	// The XOR clauses are only reachable through ecnf.XORClauses; churn the
+	// heap so that memory freed behind its back is reused
+	for round := 0; round < 3; round++ {
+		runtime.GC()
+		garbage := make([]*XORClause, 0, 4096)
+		for i := 0; i < cap(garbage); i++ {
+			garbage = append(garbage, NewXORClause([]string{"junk"}, i%2 == 1))
+		}
+		runtime.KeepAlive(garbage)
+	}
+	for i, xor := range ecnf.XORClauses {
+		if len(xor.Variables) != 2 || xor.Variables[0] != varName(i) || xor.Parity != (i%2 == 0) {
+			t.Fatalf("XOR %d was overwritten: %v", i, xor)
+		}
+	}
+}
+
+func TestCDCLSolver_XORPropagationReason(t *testing.T) {
+	solver := NewCDCLSolver()
+	solver.extendedCNF = NewExtendedCNF()
+	solver.extendedCNF.AddXORClause(NewXORClause([]string{"A", "B", "C"}, true))
+	solver.decisionLevel = 1
+	solver.assign("A", true, nil)
+	solver.decisionLevel = 2
+	solver.assign("B", false, nil)
+
+	if conflict := solver.propagateXOR(); conflict != nil {
+		t.Fatalf("unexpected XOR conflict %v", conflict)
+	}
+	if value, ok := solver.assignment["C"]; !ok || value {
+		t.Fatalf("A ⊕ B ⊕ C with A=1, B=0 should force C=0, got %v", solver.assignment)
+	}
+	reason := solver.trail.GetReason("C")
+	if reason == nil {
+		t.Fatal("forced XOR literal has no reason clause")
+	}
+	for _, lit := range reason.Literals {
+		value := solver.assignment[lit.Variable]
+		if (lit.Variable == "C") != (value != lit.Negated) {
+			t.Errorf("reason %v does not explain C under %v", reason.Literals, solver.assignment)
+		}
+	}
+}
+
+func TestCDCLSolver_XORPropagation(t *testing.T) {
+	a, b, c, d := L("A", false), L("B", false), L("C", false), L("D", false)
+	na, nb, nc, nd := L("A", true), L("B", true), L("C", true), L("D", true)
+	testCases := []struct {
+		description string
+		clauses     [][]Literal
+		xors        []xorEncoding
+		expectedSat bool
+	}{
+		{
+			description: "XOR-forced literal propagates through clauses",
+			clauses:     [][]Literal{{a}, {b, c}, {nc, d}},
+			xors:        []xorEncoding{{[]string{"A", "B"}, true}},
+			expectedSat: true,
+		},
+		{
+			description: "XOR-forced literal leads to a clause conflict",
+			clauses:     [][]Literal{{a}, {b, c}, {nc, d}, {nd}},
+			xors:        []xorEncoding{{[]string{"A", "B"}, true}},
+			expectedSat: false,
+		},
+		{
+			description: "clause-forced literals complete an XOR",
+			clauses:     [][]Literal{{na, b}, {nb, c}},
+			xors:        []xorEncoding{{[]string{"A", "B", "C", "D"}, false}, {[]string{"A", "D"}, true}},
+			expectedSat: true,
+		},
+		{
+			description: "exactly one of three against even parity",
+			clauses:     [][]Literal{{a, b, c}, {na, nb}, {na, nc}, {nb, nc}},
+			xors:        []xorEncoding{{[]string{"A", "B", "C"}, false}},
+			expectedSat: false,
+		},
+		{
+			description: "XOR conflicts during search",
+			clauses:     [][]Literal{{a, b, c, d}, {na, nb, nc}, {nb, nd}},
+			xors: []xorEncoding{
+				{[]string{"A", "B"}, false}, {[]string{"C", "D"}, false}, {[]string{"A", "C", "E"}, true},
+			},
+			expectedSat: true,
+		},
+		{
+			description: "long XOR forced by unit clauses",
+			clauses: [][]Literal{
+				{L("X0", true)}, {L("X1", true)}, {L("X2", true)}, {L("X3", true)}, {L("X4", true)},
+				{L("X5", true), L("X6", false)},
+			},
+			xors:        []xorEncoding{{[]string{"X0", "X1", "X2", "X3", "X4", "X5"}, true}},
+			expectedSat: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			checkSolveExtended(t, buildExtendedCNF(tc.clauses, tc.xors), tc.expectedSat)
+		})
+	}
+}
+
+func TestCDCLSolver_GaussianContradictionRefutes(t *testing.T) {
+	ecnf := NewExtendedCNF()
+	ecnf.AddXORClause(NewXORClause([]string{"A", "B", "C"}, true))
+	ecnf.AddXORClause(NewXORClause([]string{"A", "B", "D"}, false))
+	ecnf.AddXORClause(NewXORClause([]string{"C", "D", "E"}, true))
+	ecnf.AddXORClause(NewXORClause([]string{"E", "F", "G"}, true))
+	ecnf.AddXORClause(NewXORClause([]string{"E", "F", "G"}, false))
+
+	solver := NewCDCLSolver()
+	solver.gaussianEliminator = NewGaussianEliminatorWithConfig(300, 200, 3, 20, 0)
+	result := solver.SolveExtended(ecnf)
+	if result.Satisfiable {
+		t.Fatal("contradictory XORs reported SAT")
+	}
+	if result.Statistics.Decisions != 0 {
+		t.Errorf("Gaussian contradiction took %d decisions to refute", result.Statistics.Decisions)
+	}
+}
+
+func TestCDCLSolver_GaussianDoesNotDuplicateXORs(t *testing.T) {
+	solver := NewCDCLSolver()
+	solver.extendedCNF = NewExtendedCNF()
+	solver.extendedCNF.AddXORClause(NewXORClause([]string{"A", "B", "C"}, true))
+	solver.extendedCNF.AddXORClause(NewXORClause([]string{"B", "C", "D"}, false))
+	solver.extendedCNF.AddXORClause(NewXORClause([]string{"C", "D", "E"}, true))
+	solver.extendedCNF.AddXORClause(NewXORClause([]string{"D", "E", "F"}, false))
+	solver.extendedCNF.AddXORClause(NewXORClause([]string{"E", "F", "G"}, true))
+	solver.gaussianEliminator = NewGaussianEliminatorWithConfig(300, 200, 3, 20, 0)
+
+	for run := 0; run < 3; run++ {
+		if !solver.performGaussianElimination() {
+			t.Fatal("consistent XORs reported as a contradiction")
+		}
+	}
+	seen := make(map[string]bool)
+	for _, xor := range solver.extendedCNF.XORClauses {
+		key := xorKey(xor)
+		if seen[key] {
+			t.Errorf("XOR %v added more than once", xor)
+		}
+		seen[key] = true
+	}
+}
+
+func TestCDCLSolver_NoDecisionVariableIsUnknown(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	solver := NewCDCLSolver()
+	solver.Solve(cnf)
+
+	// A stale, empty cache of unassigned variables leaves nothing to decide
+	solver.backtrack(0)
+	solver.unassignedCache = solver.unassignedCache[:0]
+	solver.cacheValid = true
+	result := solver.search(0)
+	result.setStatus()
+	if result.Status != StatusUnknown || result.Reason != ReasonError || result.Error == nil {
+		t.Fatalf("got %v (%v, %v), want UNKNOWN with an error", result.Status, result.Reason, result.Error)
+	}
+}
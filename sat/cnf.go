@@ -0,0 +1,43 @@
+package sat
+
+import "github.com/xDarkicex/memory"
+
+// copyLiveClauses calls add with a copy, allocated from pool (nil for the
+// package pools), of each clause of clauses that constrains a model, and
+// with its index. Deleted and learned clauses are left out, the latter
+// following from the rest. Tautologies are left out as well: they hold in
+// every model, and NewClause would cut one short at its complementary
+// pair, leaving a clause that does constrain. rewrite, if not nil, gives
+// the literals copied for clause i in place of its own.
+func copyLiveClauses(pool *memory.Pool, clauses []*Clause, rewrite func(i int, lits []Literal) []Literal, add func(i int, clause *Clause)) {
+	for i, clause := range clauses {
+		if clause == nil || clause.Deleted || clause.Learned || isTautology(clause.Literals) {
+			continue
+		}
+		lits := clause.Literals
+		if rewrite != nil {
+			lits = rewrite(i, lits)
+		}
+		add(i, NewClauseIn(pool, lits...))
+	}
+}
+
+// guardedBy returns lits with the negation of selector appended, so the
+// clause holds whenever selector is false
+func guardedBy(lits []Literal, selector string) []Literal {
+	guarded := make([]Literal, len(lits), len(lits)+1)
+	copy(guarded, lits)
+	return append(guarded, Literal{Variable: selector, Negated: true})
+}
+
+// isTautology reports whether lits contains a literal and its negation
+func isTautology(lits []Literal) bool {
+	seen := make(map[Literal]bool, len(lits))
+	for _, lit := range lits {
+		if seen[lit.Negate()] {
+			return true
+		}
+		seen[lit] = true
+	}
+	return false
+}
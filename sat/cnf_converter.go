@@ -12,13 +12,21 @@ import (
 type CNFConverter struct {
 	nextAuxVar int // Counter for auxiliary variables
 	cnf        *CNF
+	pool       *memory.Pool // Owner of the formulas built; nil for the package pools
 }
 
 // NewCNFConverter creates a new CNF converter
 func NewCNFConverter() *CNFConverter {
+	return NewCNFConverterWithPool(nil)
+}
+
+// NewCNFConverterWithPool creates a CNF converter whose formulas, clauses
+// and scratch arrays come from pool. A nil pool means the package pools.
+func NewCNFConverterWithPool(pool *memory.Pool) *CNFConverter {
 	return &CNFConverter{
 		nextAuxVar: 1,
-		cnf:        NewCNF(),
+		cnf:        NewCNFWithPool(pool),
+		pool:       pool,
 	}
 }
 
@@ -31,7 +39,7 @@ func (c *CNFConverter) ConvertExpression(expr string) (*CNF, error) {
 			fmt.Sprintf("failed to parse expression: %v", err))
 	}
 
-	c.cnf = NewCNF()
+	c.cnf = NewCNFWithPool(c.pool)
 	c.nextAuxVar = 1
 
 	// Convert AST to CNF using Tseitin transformation
@@ -42,13 +50,13 @@ func (c *CNFConverter) ConvertExpression(expr string) (*CNF, error) {
 
 	// Add unit clause to ensure root is true
 	rootLiteral := Literal{Variable: rootVar, Negated: false}
-	c.cnf.AddClause(NewClause(rootLiteral))
+	c.cnf.AddClause(c.cnf.NewClause(rootLiteral))
 	return c.cnf, nil
 }
 
 // ConvertAST converts AST node directly to CNF
 func (c *CNFConverter) ConvertAST(node *classical.ASTNode) (*CNF, error) {
-	c.cnf = NewCNF()
+	c.cnf = NewCNFWithPool(c.pool)
 	c.nextAuxVar = 1
 
 	rootVar, err := c.tseitinTransform(node)
@@ -58,7 +66,7 @@ func (c *CNFConverter) ConvertAST(node *classical.ASTNode) (*CNF, error) {
 
 	// Add unit clause to ensure root is true
 	rootLiteral := Literal{Variable: rootVar, Negated: false}
-	c.cnf.AddClause(NewClause(rootLiteral))
+	c.cnf.AddClause(c.cnf.NewClause(rootLiteral))
 	return c.cnf, nil
 }
 
@@ -74,10 +82,10 @@ func (c *CNFConverter) tseitinTransform(node *classical.ASTNode) (string, error)
 		auxVar := c.getNextAuxVar()
 		if node.Value == "true" || node.Value == "1" || node.Value == "T" {
 			// Add unit clause: auxVar
-			c.cnf.AddClause(NewClause(Literal{Variable: auxVar, Negated: false}))
+			c.cnf.AddClause(c.cnf.NewClause(Literal{Variable: auxVar, Negated: false}))
 		} else {
 			// Add unit clause: ¬auxVar
-			c.cnf.AddClause(NewClause(Literal{Variable: auxVar, Negated: true}))
+			c.cnf.AddClause(c.cnf.NewClause(Literal{Variable: auxVar, Negated: true}))
 		}
 		return auxVar, nil
 
@@ -95,11 +103,11 @@ func (c *CNFConverter) tseitinTransform(node *classical.ASTNode) (string, error)
 		auxVar := c.getNextAuxVar()
 		// auxVar ↔ ¬childVar
 		// (auxVar ∨ childVar) ∧ (¬auxVar ∨ ¬childVar)
-		c.cnf.AddClause(NewClause(
+		c.cnf.AddClause(c.cnf.NewClause(
 			Literal{Variable: auxVar, Negated: false},
 			Literal{Variable: childVar, Negated: false},
 		))
-		c.cnf.AddClause(NewClause(
+		c.cnf.AddClause(c.cnf.NewClause(
 			Literal{Variable: auxVar, Negated: true},
 			Literal{Variable: childVar, Negated: true},
 		))
@@ -141,7 +149,7 @@ func (c *CNFConverter) transformAnd(node *classical.ASTNode) (string, error) {
 	}
 
 	// Transform children
-	childVars := memory.MustPoolSlice[string](satPool, len(node.Children))[:len(node.Children)]
+	childVars := memory.MustPoolSlice[string](poolOr(c.pool), len(node.Children))[:len(node.Children)]
 	for i, child := range node.Children {
 		var err error
 		childVars[i], err = c.tseitinTransform(child)
@@ -154,19 +162,19 @@ func (c *CNFConverter) transformAnd(node *classical.ASTNode) (string, error) {
 
 	// (¬auxVar ∨ childi) for each child
 	for _, childVar := range childVars {
-		c.cnf.AddClause(NewClause(
+		c.cnf.AddClause(c.cnf.NewClause(
 			Literal{Variable: auxVar, Negated: true},
 			Literal{Variable: childVar, Negated: false},
 		))
 	}
 
 	// (auxVar ∨ ¬child1 ∨ ... ∨ ¬childN)
-	literals := memory.MustPoolSlice[Literal](satPool, len(childVars)+1)
+	literals := memory.MustPoolSlice[Literal](poolOr(c.pool), len(childVars)+1)
 	literals = append(literals, Literal{Variable: auxVar, Negated: false})
 	for _, childVar := range childVars {
 		literals = append(literals, Literal{Variable: childVar, Negated: true})
 	}
-	c.cnf.AddClause(NewClause(literals...))
+	c.cnf.AddClause(c.cnf.NewClause(literals...))
 
 	return auxVar, nil
 }
@@ -180,7 +188,7 @@ func (c *CNFConverter) transformOr(node *classical.ASTNode) (string, error) {
 	}
 
 	// Transform children
-	childVars := memory.MustPoolSlice[string](satPool, len(node.Children))[:len(node.Children)]
+	childVars := memory.MustPoolSlice[string](poolOr(c.pool), len(node.Children))[:len(node.Children)]
 	for i, child := range node.Children {
 		var err error
 		childVars[i], err = c.tseitinTransform(child)
@@ -192,16 +200,16 @@ func (c *CNFConverter) transformOr(node *classical.ASTNode) (string, error) {
 	auxVar := c.getNextAuxVar()
 
 	// First clause: (¬auxVar ∨ child1 ∨ ... ∨ childN)
-	lits := memory.MustPoolSlice[Literal](satPool, len(childVars)+1)
+	lits := memory.MustPoolSlice[Literal](poolOr(c.pool), len(childVars)+1)
 	lits = append(lits, Literal{Variable: auxVar, Negated: true})
 	for _, childVar := range childVars {
 		lits = append(lits, Literal{Variable: childVar, Negated: false})
 	}
-	c.cnf.AddClause(NewClause(lits...))
+	c.cnf.AddClause(c.cnf.NewClause(lits...))
 
 	// Per-child clauses: (auxVar ∨ ¬childi)
 	for _, childVar := range childVars {
-		c.cnf.AddClause(NewClause(
+		c.cnf.AddClause(c.cnf.NewClause(
 			Literal{Variable: auxVar, Negated: false},
 			Literal{Variable: childVar, Negated: true},
 		))
@@ -231,22 +239,22 @@ func (c *CNFConverter) transformXor(node *classical.ASTNode) (string, error) {
 
 	auxVar := c.getNextAuxVar()
 
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: true},
 		Literal{Variable: child1Var, Negated: true},
 		Literal{Variable: child2Var, Negated: true},
 	))
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: true},
 		Literal{Variable: child1Var, Negated: false},
 		Literal{Variable: child2Var, Negated: false},
 	))
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: false},
 		Literal{Variable: child1Var, Negated: true},
 		Literal{Variable: child2Var, Negated: false},
 	))
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: false},
 		Literal{Variable: child1Var, Negated: false},
 		Literal{Variable: child2Var, Negated: true},
@@ -276,16 +284,16 @@ func (c *CNFConverter) transformImplies(node *classical.ASTNode) (string, error)
 
 	auxVar := c.getNextAuxVar()
 
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: true},
 		Literal{Variable: child1Var, Negated: true},
 		Literal{Variable: child2Var, Negated: false},
 	))
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: false},
 		Literal{Variable: child1Var, Negated: false},
 	))
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: false},
 		Literal{Variable: child2Var, Negated: true},
 	))
@@ -314,22 +322,22 @@ func (c *CNFConverter) transformIff(node *classical.ASTNode) (string, error) {
 
 	auxVar := c.getNextAuxVar()
 
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: false},
 		Literal{Variable: child1Var, Negated: true},
 		Literal{Variable: child2Var, Negated: true},
 	))
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: false},
 		Literal{Variable: child1Var, Negated: false},
 		Literal{Variable: child2Var, Negated: false},
 	))
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: true},
 		Literal{Variable: child1Var, Negated: true},
 		Literal{Variable: child2Var, Negated: false},
 	))
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: true},
 		Literal{Variable: child1Var, Negated: false},
 		Literal{Variable: child2Var, Negated: true},
@@ -347,11 +355,11 @@ func (c *CNFConverter) transformNand(node *classical.ASTNode) (string, error) {
 
 	auxVar := c.getNextAuxVar()
 	// auxVar ↔ ¬andVar
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: false},
 		Literal{Variable: andVar, Negated: false},
 	))
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: true},
 		Literal{Variable: andVar, Negated: true},
 	))
@@ -367,11 +375,11 @@ func (c *CNFConverter) transformNor(node *classical.ASTNode) (string, error) {
 
 	auxVar := c.getNextAuxVar()
 	// auxVar ↔ ¬orVar
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: false},
 		Literal{Variable: orVar, Negated: false},
 	))
-	c.cnf.AddClause(NewClause(
+	c.cnf.AddClause(c.cnf.NewClause(
 		Literal{Variable: auxVar, Negated: true},
 		Literal{Variable: orVar, Negated: true},
 	))
@@ -385,7 +393,9 @@ func (c *CNFConverter) getNextAuxVar() string {
 	return auxVar
 }
 
-// Add to CNFConverter to handle XOR more efficiently
+// Add to CNFConverter to handle XOR more efficiently. Only ⊕ nodes of
+// the expression become XOR clauses; RecoverXORs finds those that arrive
+// as clauses.
 func (c *CNFConverter) ConvertExpressionExtended(expr string) (*ExtendedCNF, error) {
 	ast, err := classical.ParseExpression(expr)
 	if err != nil {
@@ -394,6 +404,7 @@ func (c *CNFConverter) ConvertExpressionExtended(expr string) (*ExtendedCNF, err
 	}
 
 	ecnf := NewExtendedCNF()
+	ecnf.CNF = NewCNFWithPool(c.pool)
 	c.cnf = ecnf.CNF
 	c.nextAuxVar = 1
 
@@ -405,7 +416,7 @@ func (c *CNFConverter) ConvertExpressionExtended(expr string) (*ExtendedCNF, err
 
 	// Add unit clause to ensure root is true
 	rootLiteral := Literal{Variable: rootVar, Negated: false}
-	ecnf.AddClause(NewClause(rootLiteral))
+	ecnf.AddClause(ecnf.NewClause(rootLiteral))
 
 	return ecnf, nil
 }
@@ -435,7 +446,7 @@ func (c *CNFConverter) transformXorDirect(node *classical.ASTNode, ecnf *Extende
 	}
 
 	// Transform children
-	childVars := memory.MustPoolSlice[string](satPool, len(node.Children))[:len(node.Children)]
+	childVars := memory.MustPoolSlice[string](poolOr(c.pool), len(node.Children))[:len(node.Children)]
 	for i, child := range node.Children {
 		var err error
 		childVars[i], err = c.tseitinTransformExtended(child, ecnf)
@@ -447,7 +458,7 @@ func (c *CNFConverter) transformXorDirect(node *classical.ASTNode, ecnf *Extende
 	auxVar := c.getNextAuxVar()
 
 	// Create XOR clause: auxVar ⊕ child1 ⊕ child2 ⊕ ... = 1 (odd parity)
-	xorVars := memory.MustPoolSlice[string](satPool, len(childVars)+1)
+	xorVars := memory.MustPoolSlice[string](poolOr(c.pool), len(childVars)+1)
 	xorVars = append(xorVars, auxVar)
 	xorVars = append(xorVars, childVars...)
 	xorClause := NewXORClause(xorVars, true) // odd parity
@@ -23,6 +23,8 @@ type FirstUIPAnalyzer struct {
 	trivialClauses  int64
 	unitClauses     int64
 	glueClauseCount int64
+
+	pool *memory.Pool // Backs scratch arrays and learned clauses
 }
 
 // ResolutionStep tracks each step in the resolution process for debugging
@@ -34,11 +36,17 @@ type ResolutionStep struct {
 }
 
 func NewFirstUIPAnalyzer() *FirstUIPAnalyzer {
+	return newFirstUIPAnalyzer(nil)
+}
+
+func newFirstUIPAnalyzer(pool *memory.Pool) *FirstUIPAnalyzer {
+	pool = poolOr(pool)
 	return &FirstUIPAnalyzer{
 		seen:            make(map[string]bool),
-		conflictSide:    memory.MustPoolSlice[string](satPool, 32),
-		resolutionStack: memory.MustPoolSlice[ResolutionStep](satPool, 32),
+		conflictSide:    memory.MustPoolSlice[string](pool, 32),
+		resolutionStack: memory.MustPoolSlice[ResolutionStep](pool, 32),
 		levelsSeen:      make(map[int]bool),
+		pool:            pool,
 	}
 }
 
@@ -61,11 +69,13 @@ func (f *FirstUIPAnalyzer) Analyze(conflictClause *Clause, trail DecisionTrail)
 	f.reset()
 
 	// Initialize with conflict clause
-	learntClause := memory.MustPoolSlice[Literal](satPool, len(conflictClause.Literals))
+	learntClause := memory.MustPoolSlice[Literal](f.pool, len(conflictClause.Literals))
 
-	// Add all literals from conflict clause and track levels
+	// Add all literals from conflict clause and track levels. Every literal
+	// of the conflict clause is false under the trail, which is exactly the
+	// polarity the learned clause needs.
 	for _, lit := range conflictClause.Literals {
-		learntClause = append(learntClause, lit.Negate())
+		learntClause = append(learntClause, lit)
 		f.seen[lit.Variable] = true
 
 		// Track decision levels for LBD computation
@@ -112,7 +122,7 @@ func (f *FirstUIPAnalyzer) Analyze(conflictClause *Clause, trail DecisionTrail)
 
 		// Perform resolution step with LBD tracking
 		f.resolutions++
-		oldClause := memory.MustPoolSlice[Literal](satPool, len(learntClause))[:len(learntClause)]
+		oldClause := memory.MustPoolSlice[Literal](f.pool, len(learntClause))[:len(learntClause)]
 		copy(oldClause, learntClause)
 
 		learntClause = f.resolveWithLBDTracking(learntClause, reason, resolveVar, trail)
@@ -152,7 +162,7 @@ func (f *FirstUIPAnalyzer) Analyze(conflictClause *Clause, trail DecisionTrail)
 
 // resolveWithLBDTracking performs resolution between current learnt clause and reason clause with LBD tracking
 func (f *FirstUIPAnalyzer) resolveWithLBDTracking(learntClause []Literal, reasonClause *Clause, resolveVar string, trail DecisionTrail) []Literal {
-	newClause := memory.MustPoolSlice[Literal](satPool, len(learntClause)+len(reasonClause.Literals))
+	newClause := memory.MustPoolSlice[Literal](f.pool, len(learntClause)+len(reasonClause.Literals))
 
 	// Add literals from learnt clause (except resolved variable)
 	for _, lit := range learntClause {
@@ -209,14 +219,15 @@ func (f *FirstUIPAnalyzer) findPositionInTrail(variable string, levelTrail []Tra
 
 // getTrailEntriesAtLevel extracts trail entries for specific level
 func (f *FirstUIPAnalyzer) getTrailEntriesAtLevel(trail DecisionTrail, level int) []TrailEntry {
-	// This would ideally use a method on the trail, but we'll implement it here
-	if simpleTrail, ok := trail.(*SimpleDecisionTrail); ok {
-		return simpleTrail.GetTrailAtLevel(level)
+	// Trails that keep their order, like DecisionTrailImpl and the CDCL
+	// core's, list the level directly
+	if ordered, ok := trail.(interface{ GetTrailAtLevel(int) []TrailEntry }); ok {
+		return ordered.GetTrailAtLevel(level)
 	}
 
 	// Fallback implementation
 	assignment := trail.GetAssignment()
-	entries := memory.MustPoolSlice[TrailEntry](satPool, 32)
+	entries := memory.MustPoolSlice[TrailEntry](f.pool, 32)
 
 	for variable := range assignment {
 		if trail.GetLevel(variable) == level {
@@ -248,7 +259,7 @@ func (f *FirstUIPAnalyzer) countCurrentLevelVars(clause []Literal, trail Decisio
 func (f *FirstUIPAnalyzer) buildLearnedClauseWithLBD(literals []Literal, trail DecisionTrail) *Clause {
 	// Remove duplicates and optimize
 	seen := make(map[string]bool)
-	uniqueLiterals := memory.MustPoolSlice[Literal](satPool, len(literals))
+	uniqueLiterals := memory.MustPoolSlice[Literal](f.pool, len(literals))
 	levelSet := make(map[int]bool)
 
 	for _, lit := range literals {
@@ -273,7 +284,7 @@ func (f *FirstUIPAnalyzer) buildLearnedClauseWithLBD(literals []Literal, trail D
 	})
 
 	// Create clause with LBD information
-	clause := NewClause(uniqueLiterals...)
+	clause := NewClauseIn(f.pool, uniqueLiterals...)
 	clause.Learned = true
 	clause.Activity = 1.0
 
@@ -284,45 +295,30 @@ func (f *FirstUIPAnalyzer) buildLearnedClauseWithLBD(literals []Literal, trail D
 	return clause
 }
 
-// computeBacktrackLevel determines the correct backtrack level
+// computeBacktrackLevel determines the correct backtrack level: the highest
+// level below the conflict level among the learned clause's literals. After
+// backtracking there, every literal except the UIP is still false, so the
+// clause is asserting.
 func (f *FirstUIPAnalyzer) computeBacktrackLevel(literals []Literal, trail DecisionTrail, currentLevel int) int {
-	if len(literals) <= 1 {
-		return 0
-	}
-
-	// Find second highest decision level
-	levels := memory.MustPoolSlice[int](satPool, len(literals))
+	backtrackLevel := 0
 	for _, lit := range literals {
 		level := trail.GetLevel(lit.Variable)
-		if level >= 0 && level < currentLevel {
-			levels = append(levels, level)
+		if level < currentLevel && level > backtrackLevel {
+			backtrackLevel = level
 		}
 	}
+	return backtrackLevel
+}
 
-	if len(levels) == 0 {
-		return 0
-	}
-
-	// Sort and return second highest or highest if only one
-	sort.Ints(levels)
-	if len(levels) == 1 {
-		return levels[0]
-	}
-
-	// Remove duplicates and return second highest
-	uniqueLevels := memory.MustPoolSlice[int](satPool, len(levels))
-	prev := -1
-	for _, level := range levels {
-		if level != prev {
-			uniqueLevels = append(uniqueLevels, level)
-			prev = level
-		}
-	}
-
-	if len(uniqueLevels) == 1 {
-		return uniqueLevels[0]
+// Antecedents returns the reason clauses resolved during the last analysis,
+// in resolution order (most recently assigned first). Together with the
+// conflict clause they form the resolution chain of the learned clause.
+func (f *FirstUIPAnalyzer) Antecedents() []*Clause {
+	reasons := memory.MustPoolSlice[*Clause](f.pool, len(f.resolutionStack))
+	for _, step := range f.resolutionStack {
+		reasons = append(reasons, step.ReasonClause)
 	}
-	return uniqueLevels[len(uniqueLevels)-2] // Second highest
+	return reasons
 }
 
 // Helper methods
@@ -0,0 +1,119 @@
+package sat
+
+import (
+	"context"
+	"time"
+)
+
+// ProgressFunc receives a snapshot of the solver's statistics while a
+// solve is running. It is called on the solving goroutine, so it should
+// return quickly.
+type ProgressFunc func(stats SolverStatistics)
+
+// defaultProgressInterval is used when SetProgress is given no interval
+const defaultProgressInterval = time.Second
+
+// progressReporter calls a ProgressFunc at most once per interval
+type progressReporter struct {
+	fn       ProgressFunc
+	interval time.Duration
+	next     time.Time
+}
+
+// set installs fn, or removes the callback if fn is nil
+func (p *progressReporter) set(fn ProgressFunc, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+	p.fn, p.interval = fn, interval
+}
+
+// start begins a new solve; the first report comes one interval later
+func (p *progressReporter) start() {
+	p.next = time.Now().Add(p.interval)
+}
+
+// poll reports stats if the interval has passed. Solvers call it every
+// few conflicts or decisions.
+func (p *progressReporter) poll(stats SolverStatistics, started time.Time) {
+	if p.fn == nil {
+		return
+	}
+	now := time.Now()
+	if now.Before(p.next) {
+		return
+	}
+	p.next = now.Add(p.interval)
+	stats.TimeElapsed = now.Sub(started).Nanoseconds()
+	p.fn(stats)
+}
+
+// SolveContext solves cnf like Solve, but stops promptly once ctx is
+// done. A stopped solve has Status StatusUnknown and ctx.Err() as its
+// Error.
+func (c *CDCLSolver) SolveContext(ctx context.Context, cnf *CNF) *SolverResult {
+	if err := ctx.Err(); err != nil {
+		return &SolverResult{Error: err, Status: StatusUnknown, Reason: ReasonCancelled}
+	}
+	return c.solve(ctx, cnf, nil, 0)
+}
+
+// setContext makes the solver and its long-running components check ctx,
+// or stop checking if ctx is nil
+func (c *CDCLSolver) setContext(ctx context.Context) {
+	c.ctx = ctx
+	c.walkSolver.setContext(ctx)
+	if stopper, ok := c.inprocessor.(contextual); ok {
+		stopper.setContext(ctx)
+	}
+}
+
+// contextual is implemented by solver components that run long enough to
+// check for cancellation themselves.
+type contextual interface {
+	setContext(ctx context.Context)
+}
+
+// contextDone reports whether ctx, which may be nil, is done
+func contextDone(ctx context.Context) bool {
+	return ctx != nil && ctx.Err() != nil
+}
+
+// SetProgress makes every later solve call fn with the solver's
+// statistics about once per interval, a second if interval is zero. A nil
+// fn removes the callback.
+func (c *CDCLSolver) SetProgress(fn ProgressFunc, interval time.Duration) {
+	c.progress.set(fn, interval)
+}
+
+// cancelled returns ctx.Err() of a SolveContext call, or nil
+func (c *CDCLSolver) cancelled() error {
+	if c.ctx == nil {
+		return nil
+	}
+	select {
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	default:
+		return nil
+	}
+}
+
+// SolveContext solves cnf like Solve, but stops promptly once ctx is
+// done. A stopped solve has Status StatusUnknown and ctx.Err() as its
+// Error.
+func (d *DPLLSolver) SolveContext(ctx context.Context, cnf *CNF) *SolverResult {
+	if err := ctx.Err(); err != nil {
+		return &SolverResult{Error: err, Status: StatusUnknown, Reason: ReasonCancelled}
+	}
+	d.ctx = ctx
+	defer func() { d.ctx = nil }()
+	return d.SolveWithTimeout(cnf, 0)
+}
+
+// SetProgress makes every later solve call fn with the solver's
+// statistics about once per interval, a second if interval is zero. A nil
+// fn removes the callback.
+func (d *DPLLSolver) SetProgress(fn ProgressFunc, interval time.Duration) {
+	d.progress.set(fn, interval)
+}
@@ -0,0 +1,111 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func contextSolvers() []ContextSolver {
+	return []ContextSolver{NewCDCLSolver(), NewDPLLSolver(), NewDenseSolver()}
+}
+
+func TestSolveContext_Status(t *testing.T) {
+	for _, s := range contextSolvers() {
+		sat := s.SolveContext(context.Background(), createSimpleSATInstanceAdvanced())
+		if sat.Error != nil || sat.Status != StatusSAT {
+			t.Errorf("%s: expected SAT, got %v (%v)", s.Name(), sat.Status, sat.Error)
+		}
+		unsat := s.SolveContext(context.Background(), pigeonhole(3, 2))
+		if unsat.Error != nil || unsat.Status != StatusUNSAT {
+			t.Errorf("%s: expected UNSAT, got %v (%v)", s.Name(), unsat.Status, unsat.Error)
+		}
+	}
+}
+
+func TestSolveContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, s := range contextSolvers() {
+		result := s.SolveContext(ctx, createSimpleSATInstanceAdvanced())
+		if result.Status != StatusUnknown || !errors.Is(result.Error, context.Canceled) {
+			t.Errorf("%s: expected UNKNOWN with context.Canceled, got %v (%v)", s.Name(), result.Status, result.Error)
+		}
+		if result.Satisfiable {
+			t.Errorf("%s: cancelled solve reported satisfiable", s.Name())
+		}
+	}
+}
+
+func TestSolveContext_Deadline(t *testing.T) {
+	for _, s := range contextSolvers() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		start := time.Now()
+		result := s.SolveContext(ctx, pigeonhole(10, 9))
+		cancel()
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("%s: took %v to stop", s.Name(), elapsed)
+		}
+		if result.Status != StatusUnknown || !errors.Is(result.Error, context.DeadlineExceeded) {
+			t.Errorf("%s: expected UNKNOWN with context.DeadlineExceeded, got %v (%v)", s.Name(), result.Status, result.Error)
+		}
+	}
+}
+
+func TestCDCLSolver_SolveContextRejected(t *testing.T) {
+	// A rejected concurrent call leaves the running solve cancellable
+	solver := NewCDCLSolver()
+	started := make(chan struct{})
+	var once sync.Once
+	solver.SetProgress(func(SolverStatistics) { once.Do(func() { close(started) }) }, time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan *SolverResult, 1)
+	go func() { done <- solver.SolveContext(ctx, pigeonhole(10, 9)) }()
+	<-started
+	if result := solver.SolveContext(context.Background(), pigeonhole(3, 2)); result.Error == nil {
+		t.Fatal("Expected the concurrent call to be rejected")
+	}
+	cancel()
+	select {
+	case result := <-done:
+		if !errors.Is(result.Error, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v (%v)", result.Status, result.Error)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The running solve ignored its cancelled context")
+	}
+}
+
+func TestSolveContext_Progress(t *testing.T) {
+	for _, s := range contextSolvers() {
+		var reports []SolverStatistics
+		s.SetProgress(func(stats SolverStatistics) { reports = append(reports, stats) }, time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		s.SolveContext(ctx, pigeonhole(10, 9))
+		cancel()
+		if len(reports) == 0 {
+			t.Errorf("%s: progress callback was never called", s.Name())
+			continue
+		}
+		last := reports[len(reports)-1]
+		if last.TimeElapsed <= 0 || last.Decisions == 0 {
+			t.Errorf("%s: unexpected progress report %+v", s.Name(), last)
+		}
+	}
+}
+
+func TestTheorySolver_SolveContext(t *testing.T) {
+	ts := NewTheorySolver(2, testPool(t))
+	ts.AddClause([]int32{0, 2})
+	if _, status := ts.SolveContext(context.Background()); status != StatusSAT {
+		t.Errorf("Expected SAT, got %v", status)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if assign, status := ts.SolveContext(ctx); status != StatusUnknown || assign != nil {
+		t.Errorf("Expected UNKNOWN with no assignment, got %v %v", status, assign)
+	}
+}
@@ -0,0 +1,342 @@
+package sat
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+// CubeGenerator splits a formula into cubes: conjunctions of literals that
+// together cover every model. Each split branches on the variable whose two
+// polarities imply the most literals, as measured by failed literal
+// probing. A polarity that fails is fixed rather than branched on, and a
+// branch where both fail is dropped.
+type CubeGenerator struct {
+	Depth      int     // Maximum decisions per cube, so at most 2^Depth cubes
+	Cutoff     float64 // Stop splitting once this fraction of the variables is assigned; 0 disables
+	Candidates int     // Literals ranked by the prober and looked ahead on per split
+
+	prober *FailedLiteralProber
+	cnf    *CNF
+	cubes  [][]Literal
+}
+
+// NewCubeGenerator creates a generator that splits up to 6 levels deep and
+// stops at half the variables assigned
+func NewCubeGenerator() *CubeGenerator {
+	return &CubeGenerator{Depth: 6, Cutoff: 0.5, Candidates: 20}
+}
+
+// Cubes splits cnf, which is not modified. Branches refuted by lookahead
+// are dropped, so no cubes means cnf is unsatisfiable, and a single empty
+// cube means it was not split.
+func (g *CubeGenerator) Cubes(cnf *CNF) ([][]Literal, error) {
+	if cnf == nil {
+		return nil, core.NewLogicError("sat", "CubeGenerator.Cubes", "formula is nil")
+	}
+	if g.Depth < 0 || g.Cutoff < 0 || g.Cutoff > 1 {
+		return nil, core.NewLogicError("sat", "CubeGenerator.Cubes", "depth must be non-negative and cutoff between 0 and 1")
+	}
+
+	// The prober's cache is only filled by ProbeFailedLiterals, which is
+	// never called here, so a fresh prober probes every literal afresh. Its
+	// buffers live in a pool freed with the call.
+	pool := mustCreatePool()
+	defer pool.Free()
+	g.prober = newFailedLiteralProber(pool)
+	if g.Candidates > 0 {
+		g.prober.maxCandidates = g.Candidates
+	}
+	g.cnf, g.cubes = cnf, nil
+	defer func() { g.prober, g.cnf = nil, nil }()
+
+	root := make(Assignment)
+	if _, conflict := g.prober.performProbingWithUnitPropagation(cnf, root, Literal{}); conflict == nil {
+		g.split(root, nil)
+	}
+	return g.cubes, nil
+}
+
+// split emits the cubes below cube, whose literals and their implications
+// are in assignment
+func (g *CubeGenerator) split(assignment Assignment, cube []Literal) {
+	if len(cube) >= g.Depth || g.cutoff(assignment) {
+		g.cubes = append(g.cubes, cube)
+		return
+	}
+	branch, found, refuted := g.lookahead(assignment)
+	if refuted {
+		return
+	}
+	if !found {
+		g.cubes = append(g.cubes, cube)
+		return
+	}
+	for _, lit := range []Literal{branch, branch.Negate()} {
+		result := g.prober.probeLiteral(lit, g.cnf, assignment)
+		if result.Failed {
+			continue
+		}
+		extended := assignment.Clone()
+		g.fix(extended, lit, result)
+		g.split(extended, append(cube[:len(cube):len(cube)], lit))
+	}
+}
+
+// cutoff reports whether enough variables are assigned to stop splitting
+func (g *CubeGenerator) cutoff(assignment Assignment) bool {
+	return g.Cutoff > 0 && len(g.cnf.Variables) > 0 &&
+		float64(len(assignment)) >= g.Cutoff*float64(len(g.cnf.Variables))
+}
+
+// lookahead probes both polarities of the prober's candidates and returns
+// the positive literal of the variable with the highest product of implied
+// counts. Failed literals found on the way are fixed in assignment; it is
+// refuted if both polarities of a variable fail.
+func (g *CubeGenerator) lookahead(assignment Assignment) (best Literal, found, refuted bool) {
+	for {
+		fixed := false
+		bestScore := -1
+		probed := make(map[string]bool)
+		for _, candidate := range g.prober.generateProbingCandidates(g.cnf, assignment) {
+			variable := candidate.Literal.Variable
+			if probed[variable] || assignment.IsAssigned(variable) {
+				continue
+			}
+			probed[variable] = true
+			positive := Literal{Variable: variable}
+			pos := g.prober.probeLiteral(positive, g.cnf, assignment)
+			neg := g.prober.probeLiteral(positive.Negate(), g.cnf, assignment)
+			switch {
+			case pos.Failed && neg.Failed:
+				return Literal{}, false, true
+			case pos.Failed:
+				g.fix(assignment, positive.Negate(), neg)
+				fixed = true
+			case neg.Failed:
+				g.fix(assignment, positive, pos)
+				fixed = true
+			default:
+				if score := (len(pos.Implied) + 1) * (len(neg.Implied) + 1); score > bestScore {
+					best, bestScore = positive, score
+				}
+			}
+		}
+		// Scores taken before a literal was fixed are stale
+		if !fixed {
+			return best, bestScore >= 0, false
+		}
+	}
+}
+
+// fix assigns lit and the literals probing it implied
+func (g *CubeGenerator) fix(assignment Assignment, lit Literal, result ProbingResult) {
+	assignment[lit.Variable] = !lit.Negated
+	for _, implied := range result.Implied {
+		assignment[implied.Variable] = !implied.Negated
+	}
+}
+
+// CubeSolver solves a formula by cube-and-conquer. Its Generator splits the
+// formula into cubes, which CDCLSolver workers then solve as assumptions,
+// each worker keeping its learned clauses from one cube to the next. The
+// formula is SAT as soon as one cube is, and UNSAT once every cube is.
+//
+// CubeSolver is not incremental, and like CDCLSolver is not safe for
+// concurrent Solve calls.
+type CubeSolver struct {
+	Generator *CubeGenerator
+
+	isSolving atomic.Bool // atomic guard for concurrent Solve calls
+	workers   int
+	progress  ProgressFunc
+	interval  time.Duration
+
+	statistics SolverStatistics
+	cubes      int
+	refuted    int
+}
+
+// NewCubeSolver creates a cube-and-conquer solver with n workers, one per
+// CPU if n is zero or less. A single worker solves the cubes in order.
+func NewCubeSolver(n int) *CubeSolver {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	return &CubeSolver{Generator: NewCubeGenerator(), workers: n}
+}
+
+// Name returns solver name
+func (s *CubeSolver) Name() string {
+	return "CubeAndConquer"
+}
+
+// Solve splits cnf into cubes and solves them
+func (s *CubeSolver) Solve(cnf *CNF) *SolverResult {
+	return s.solve(context.Background(), cnf, 0)
+}
+
+// SolveWithTimeout splits cnf into cubes and solves them under a time limit
+func (s *CubeSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverResult {
+	return s.solve(context.Background(), cnf, timeout)
+}
+
+// SolveContext splits cnf into cubes and solves them, stopping every
+// worker once ctx is done
+func (s *CubeSolver) SolveContext(ctx context.Context, cnf *CNF) *SolverResult {
+	return s.solve(ctx, cnf, 0)
+}
+
+// SetProgress reports each worker's statistics for its current cube to fn
+// about once per interval. Calls from different workers are serialised.
+func (s *CubeSolver) SetProgress(fn ProgressFunc, interval time.Duration) {
+	s.progress, s.interval = fn, interval
+}
+
+// solve generates the cubes, runs the workers and waits for all of them to
+// stop
+func (s *CubeSolver) solve(parent context.Context, cnf *CNF, timeout time.Duration) (result *SolverResult) {
+	defer func() { result.setStatus() }()
+	if !s.isSolving.CompareAndSwap(false, true) {
+		return &SolverResult{
+			Error: core.NewLogicError("sat", "CubeSolver.Solve", "concurrent Solve calls on the same solver instance are not allowed"),
+		}
+	}
+	defer s.isSolving.Store(false)
+	s.statistics, s.cubes, s.refuted = SolverStatistics{}, 0, 0
+	start := time.Now()
+	if err := parent.Err(); err != nil {
+		return &SolverResult{Error: err, Reason: ReasonCancelled}
+	}
+
+	cubes, err := s.Generator.Cubes(cnf)
+	if err != nil {
+		return &SolverResult{Error: err, Reason: ReasonError}
+	}
+	s.cubes = len(cubes)
+	if len(cubes) == 0 {
+		return &SolverResult{Satisfiable: false, Statistics: s.statistics}
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	var timedOut atomic.Bool
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			timedOut.Store(true)
+			cancel()
+		})
+		defer timer.Stop()
+	}
+
+	queue := make(chan []Literal, len(cubes))
+	for _, cube := range cubes {
+		queue <- cube
+	}
+	close(queue)
+
+	var progressMu sync.Mutex
+	report := func(stats SolverStatistics) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		s.progress(stats)
+	}
+	outcomes := make(chan *SolverResult, len(cubes))
+	var wg sync.WaitGroup
+	for range min(s.workers, len(cubes)) {
+		// A worker's solver and formula live in a pool freed with the
+		// solve
+		pool := mustCreatePool()
+		defer pool.Free()
+		worker := NewCDCLSolverWithPool(pool)
+		if s.progress != nil {
+			worker.SetProgress(report, s.interval)
+		}
+		formula := newClauseExchange(cnf.Variables, 0).copyCNF(pool, cnf)
+		for _, clause := range formula.Clauses {
+			worker.AddClause(clause)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cube := range queue {
+				if ctx.Err() != nil {
+					return
+				}
+				outcomes <- worker.solveAssuming(ctx, cube, 0)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	var answer, stopped *SolverResult
+	for r := range outcomes {
+		s.statistics.Decisions += r.Statistics.Decisions
+		s.statistics.Propagations += r.Statistics.Propagations
+		s.statistics.Conflicts += r.Statistics.Conflicts
+		s.statistics.Restarts += r.Statistics.Restarts
+		s.statistics.LearnedClauses += r.Statistics.LearnedClauses
+		switch {
+		case r.Status == StatusSAT && answer == nil:
+			answer = r
+			cancel()
+		case r.Status == StatusUNSAT:
+			s.refuted++
+			// Refuted without assumptions: every cube is UNSAT
+			if len(r.FailedAssumptions) == 0 && answer == nil {
+				answer = r
+				cancel()
+			}
+		case r.Status == StatusUnknown && stopped == nil:
+			stopped = r
+		}
+	}
+
+	switch {
+	case answer != nil:
+		result = answer
+	case s.refuted == len(cubes):
+		result = &SolverResult{Satisfiable: false}
+	case parent.Err() != nil:
+		result = &SolverResult{Error: parent.Err(), Reason: ReasonCancelled}
+	case timedOut.Load():
+		result = &SolverResult{
+			Error:  core.NewLogicError("sat", "CubeSolver.SolveWithTimeout", "timeout exceeded"),
+			Reason: ReasonTimeout,
+		}
+	default:
+		result = stopped
+	}
+	s.statistics.TimeElapsed = time.Since(start).Nanoseconds()
+	result.Statistics = s.statistics
+	return result
+}
+
+// AddClause is not supported by cube-and-conquer
+func (s *CubeSolver) AddClause(clause *Clause) error {
+	return core.NewLogicError("sat", "CubeSolver.AddClause",
+		"incremental solving not supported by cube-and-conquer")
+}
+
+// GetStatistics returns the summed statistics of every solved cube
+func (s *CubeSolver) GetStatistics() SolverStatistics {
+	return s.statistics
+}
+
+// Cubes returns the number of cubes generated by the last solve and how
+// many of them were refuted
+func (s *CubeSolver) Cubes() (generated, refuted int) {
+	return s.cubes, s.refuted
+}
+
+// Reset clears solver state
+func (s *CubeSolver) Reset() {
+	s.statistics, s.cubes, s.refuted = SolverStatistics{}, 0, 0
+}
@@ -0,0 +1,225 @@
+package sat
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCubeGenerator_Partition(t *testing.T) {
+	testCases := []struct {
+		description string
+		cnf         *CNF
+	}{
+		{"independent pairs", buildCNF([][]Literal{
+			{L("A", false), L("B", false)},
+			{L("C", false), L("D", true)},
+			{L("E", true), L("F", true)},
+		})},
+		{"implication chain", buildCNF([][]Literal{
+			{L("A", true), L("B", false)},
+			{L("B", true), L("C", false)},
+			{L("C", true), L("D", false)},
+			{L("D", false), L("E", false), L("F", false)},
+		})},
+		{"exactly one of four", buildCNF([][]Literal{
+			{L("A", false), L("B", false), L("C", false), L("D", false)},
+			{L("A", true), L("B", true)},
+			{L("A", true), L("C", true)},
+			{L("A", true), L("D", true)},
+			{L("B", true), L("C", true)},
+			{L("B", true), L("D", true)},
+			{L("C", true), L("D", true)},
+		})},
+		{"three pigeons in three holes", pigeonhole(3, 3)},
+		{"four pigeons in three holes", pigeonhole(4, 3)},
+	}
+
+	g := NewCubeGenerator()
+	g.Depth, g.Cutoff = 3, 0
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cubes, err := g.Cubes(tc.cnf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(cubes) > 8 {
+				t.Fatalf("%d cubes from depth 3", len(cubes))
+			}
+
+			// Every model satisfies exactly one cube
+			count := 0
+			allModels(variablesOf(tc.cnf.Clauses), func(model Assignment) {
+				if !satisfiesAll(model, tc.cnf.Clauses) {
+					return
+				}
+				count++
+				covering := 0
+				for _, cube := range cubes {
+					holds := true
+					for _, lit := range cube {
+						holds = holds && model[lit.Variable] != lit.Negated
+					}
+					if holds {
+						covering++
+					}
+				}
+				if covering != 1 {
+					t.Fatalf("Model %v is in %d cubes %v", model, covering, cubes)
+				}
+			})
+			// Lookahead need not refute an UNSAT formula, but must not drop
+			// a satisfiable one
+			if count > 0 && len(cubes) == 0 {
+				t.Fatal("Satisfiable formula refuted")
+			}
+		})
+	}
+}
+
+func TestCubeGenerator_Limits(t *testing.T) {
+	g := NewCubeGenerator()
+	g.Depth = 0
+	cubes, err := g.Cubes(pigeonhole(4, 3))
+	if err != nil || len(cubes) != 1 || len(cubes[0]) != 0 {
+		t.Errorf("Expected one empty cube at depth 0, got %v (%v)", cubes, err)
+	}
+
+	g.Depth = 8
+	g.Cutoff = 0.1
+	cubes, _ = g.Cubes(pigeonhole(6, 5))
+	for _, cube := range cubes {
+		if len(cube) > 3 {
+			t.Errorf("Cube %v goes past the 10%% cutoff of 30 variables", cube)
+		}
+	}
+
+	// Units refute the formula at the root
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false)))
+	cnf.AddClause(NewClause(L("A", true), L("B", false)))
+	cnf.AddClause(NewClause(L("B", true)))
+	if cubes, _ := NewCubeGenerator().Cubes(cnf); len(cubes) != 0 {
+		t.Errorf("Expected no cubes for a refuted formula, got %v", cubes)
+	}
+
+	if _, err := NewCubeGenerator().Cubes(nil); err == nil {
+		t.Error("Expected an error for a nil formula")
+	}
+	g.Cutoff = 2
+	if _, err := g.Cubes(cnf); err == nil {
+		t.Error("Expected an error for a cutoff above 1")
+	}
+}
+
+func TestCubeSolver_Solve(t *testing.T) {
+	testCases := []struct {
+		description string
+		cnf         *CNF
+		expectedSat bool
+	}{
+		{"implication chain", buildCNF([][]Literal{
+			{L("A", false)},
+			{L("A", true), L("B", false)},
+			{L("B", true), L("C", false)},
+		}), true},
+		{"every sign pattern of two variables", buildCNF([][]Literal{
+			{L("A", false), L("B", false)},
+			{L("A", false), L("B", true)},
+			{L("A", true), L("B", false)},
+			{L("A", true), L("B", true)},
+		}), false},
+		{"four pigeons in four holes", pigeonhole(4, 4), true},
+		{"five pigeons in four holes", pigeonhole(5, 4), false},
+	}
+
+	for _, workers := range []int{1, 4} {
+		s := NewCubeSolver(workers)
+		s.Generator.Depth = 4
+		for _, tc := range testCases {
+			t.Run(fmt.Sprintf("%s with %d workers", tc.description, workers), func(t *testing.T) {
+				result := s.Solve(tc.cnf)
+				if result.Error != nil {
+					t.Fatalf("Solver error: %v", result.Error)
+				}
+				if result.Satisfiable != tc.expectedSat {
+					t.Fatalf("Expected satisfiable=%v, got %v", tc.expectedSat, result.Status)
+				}
+				if result.Satisfiable && !satisfiesAll(result.Assignment, tc.cnf.Clauses) {
+					t.Errorf("Model %v does not satisfy the formula", result.Assignment)
+				}
+			})
+		}
+	}
+}
+
+func TestCubeSolver_Unsat(t *testing.T) {
+	s := NewCubeSolver(4)
+	result := s.Solve(pigeonhole(7, 6))
+	if result.Status != StatusUNSAT {
+		t.Fatalf("Expected UNSAT, got %v (%v)", result.Status, result.Error)
+	}
+	if generated, refuted := s.Cubes(); generated < 2 || refuted > generated {
+		t.Errorf("Expected several cubes, got %d (%d refuted)", generated, refuted)
+	}
+	if s.GetStatistics().Conflicts == 0 {
+		t.Error("Expected the workers' conflicts to be counted")
+	}
+}
+
+func TestCubeSolver_PackagePoolUntouched(t *testing.T) {
+	// The workers' formulas are freed with the solve
+	cnf := pigeonhole(7, 6)
+	initAllocators()
+	before, lits := satPool.Stats().Allocated, litPool.Stats().Allocated
+	s := NewCubeSolver(4)
+	s.Generator.Depth = 2
+	if result := s.Solve(cnf); result.Status != StatusUNSAT {
+		t.Fatalf("Expected UNSAT, got %v (%v)", result.Status, result.Error)
+	}
+	if after := satPool.Stats().Allocated; after != before {
+		t.Errorf("The solve allocated %d bytes from the package pool", after-before)
+	}
+	if after := litPool.Stats().Allocated; after != lits {
+		t.Errorf("The solve allocated %d bytes from the literal pool", after-lits)
+	}
+}
+
+func TestCubeSolver_Cancel(t *testing.T) {
+	s := NewCubeSolver(2)
+	s.Generator.Depth = 2
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result := s.SolveContext(ctx, pigeonhole(10, 9))
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Took %v to stop", elapsed)
+	}
+	if result.Status != StatusUnknown || result.Reason != ReasonCancelled {
+		t.Errorf("Expected UNKNOWN (cancelled), got %v (%v)", result.Status, result.Reason)
+	}
+
+	if result := s.SolveWithTimeout(pigeonhole(10, 9), 20*time.Millisecond); result.Reason != ReasonTimeout {
+		t.Errorf("Expected UNKNOWN (timeout), got %v (%v)", result.Status, result.Reason)
+	}
+	if err := s.AddClause(NewClause(L("A", false))); err == nil {
+		t.Error("Expected AddClause to fail")
+	}
+}
+
+func TestFailedLiteralProber_Propagates(t *testing.T) {
+	flp := NewFailedLiteralProber()
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", true), L("B", false)))
+	cnf.AddClause(NewClause(L("B", true), L("C", false)))
+	cnf.AddClause(NewClause(L("C", true), L("A", true)))
+
+	// A implies B, then C, which falsifies the last clause
+	if result := flp.probeLiteral(L("A", false), cnf, make(Assignment)); !result.Failed {
+		t.Errorf("Expected A to fail, got implications %v", result.Implied)
+	}
+	if result := flp.probeLiteral(L("B", false), cnf, make(Assignment)); result.Failed || len(result.Implied) != 2 {
+		t.Errorf("Expected B to imply C and ¬A, got %+v", result)
+	}
+}
@@ -0,0 +1,906 @@
+package sat
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/xDarkicex/logic/core"
+	"github.com/xDarkicex/memory"
+)
+
+// Lit is a literal of the dense solver core. Variable v, numbered from 0,
+// is encoded as 2v and its negation as 2v+1, the encoding TheoryPlugin
+// uses, so a literal indexes per-literal arrays directly.
+type Lit int32
+
+// litUndef marks the absence of a literal
+const litUndef Lit = -1
+
+// MkLit returns the literal of variable v, negated if negated is set
+func MkLit(v int32, negated bool) Lit {
+	if negated {
+		return Lit(v<<1 | 1)
+	}
+	return Lit(v << 1)
+}
+
+// Var returns the literal's variable
+func (l Lit) Var() int32 { return int32(l >> 1) }
+
+// Negated reports whether the literal is the negation of its variable
+func (l Lit) Negated() bool { return l&1 == 1 }
+
+// Not returns the complementary literal
+func (l Lit) Not() Lit { return l ^ 1 }
+
+// crefUndef is the reason of decisions and level-0 facts
+const crefUndef = -1
+
+// denseClause is a clause stored in the arena. The first two literals are
+// watched; a clause that is the reason of an assignment has the implied
+// literal first.
+type denseClause struct {
+	start, size int32
+	lbd         int32
+	activity    float32
+	learnt      bool
+	deleted     bool
+}
+
+// watcher is an entry of a watch list. If the blocker, some other literal
+// of the clause, is true the clause need not be visited.
+type watcher struct {
+	cref    int32
+	blocker Lit
+}
+
+// DenseSolver is a CDCL solver over int32 literals: clauses live in one
+// flat literal arena, watch lists are indexed by literal and carry blocker
+// literals, and values, levels and reasons are plain arrays, so
+// propagation never hashes a variable name. It learns first-UIP clauses
+// with local minimisation, branches by VSIDS with phase saving, restarts
+// on the Luby sequence and keeps the learned clauses of low LBD.
+//
+// A thin name-mapping layer implements IncrementalSolver, so callers
+// using Literal{Variable, Negated} work unchanged; the integer API (NewVar,
+// AddClauseLits, SolveLits) skips it. The arrays hold no pointers, so they
+// live on the Go heap without adding to garbage collection work; only the
+// decision heap is pool-backed, like the other VarHeap users.
+type DenseSolver struct {
+	isSolving atomic.Bool
+
+	// Name layer
+	ids   map[string]int32
+	names []string // Variable -> name, "" for variables created by NewVar
+
+	// Clause storage
+	arena   []Lit
+	clauses []denseClause
+	learnts []int32 // Learned clause references
+	wasted  int     // Arena literals of deleted clauses
+	watches [][]watcher
+
+	// Assignment state
+	values   []int8  // Literal -> 1 true, -1 false, 0 unassigned
+	level    []int32 // Variable -> decision level
+	reason   []int32 // Variable -> implying clause, or crefUndef
+	phase    []bool  // Variable -> last value, reused for decisions
+	seen     []bool
+	trail    []Lit
+	trailLim []int // Trail length at the start of each decision level
+	qhead    int
+
+	// Branching
+	heap      *VarHeap
+	pool      *memory.Pool
+	activity  []float64
+	varInc    float64
+	clauseInc float32
+
+	// Conflict analysis scratch space
+	learnt     []Lit
+	toClear    []Lit
+	levelStamp []int64
+	stamp      int64
+
+	ok          bool   // False once the clauses are unsatisfiable on their own
+	model       []int8 // Variable -> value of the last model
+	failed      []Lit  // Failed assumptions of the last unsatisfiable call
+	assumptions []Lit
+
+	learntLimit float64
+	deadline    time.Time
+	ctx         context.Context // Context of the running call
+	progress    progressReporter
+	started     time.Time     // Start of the running call
+	ticks       uint32        // Search iterations; interrupts are polled every 256
+	stopped     UnknownReason // Why the last call stopped early
+	statistics  SolverStatistics
+	lbdSum      int64
+}
+
+// NewDenseSolver creates a dense solver with no variables
+func NewDenseSolver() *DenseSolver {
+	return NewDenseSolverWithPool(nil)
+}
+
+// NewDenseSolverWithPool creates a dense solver whose decision heap comes
+// from pool, so that it is reclaimed with the pool. A nil pool means the
+// package pools.
+func NewDenseSolverWithPool(pool *memory.Pool) *DenseSolver {
+	pool = poolOr(pool)
+	return &DenseSolver{
+		ids:        make(map[string]int32),
+		heap:       NewVarHeap(16, pool),
+		pool:       pool,
+		varInc:     1,
+		clauseInc:  1,
+		levelStamp: make([]int64, 1),
+		ok:         true,
+		statistics: SolverStatistics{LBDDistribution: make(map[int]int64)},
+	}
+}
+
+// Name returns solver name
+func (s *DenseSolver) Name() string {
+	return "Dense CDCL"
+}
+
+// NewVar adds an unnamed variable and returns its index
+func (s *DenseSolver) NewVar() int32 {
+	v := int32(len(s.level))
+	s.values = append(s.values, 0, 0)
+	s.watches = append(s.watches, nil, nil)
+	s.level = append(s.level, 0)
+	s.reason = append(s.reason, crefUndef)
+	s.phase = append(s.phase, false)
+	s.seen = append(s.seen, false)
+	s.activity = append(s.activity, 0)
+	s.names = append(s.names, "")
+	s.heap.Update(int(v), 0)
+	return v
+}
+
+// NumVars returns the number of variables
+func (s *DenseSolver) NumVars() int {
+	return len(s.level)
+}
+
+// Lit returns the dense literal of lit, creating its variable if needed
+func (s *DenseSolver) Lit(lit Literal) Lit {
+	v, ok := s.ids[lit.Variable]
+	if !ok {
+		v = s.NewVar()
+		s.ids[lit.Variable] = v
+		s.names[v] = lit.Variable
+	}
+	return MkLit(v, lit.Negated)
+}
+
+// Literal returns the named literal of l. Variables created by NewVar are
+// given their canonical DIMACS name.
+func (s *DenseSolver) Literal(l Lit) Literal {
+	v := l.Var()
+	if s.names[v] == "" {
+		name := DIMACSVariableName(int(v) + 1)
+		if _, taken := s.ids[name]; taken {
+			name = fmt.Sprintf("%s_%d", name, v+1)
+		}
+		s.ids[name] = v
+		s.names[v] = name
+	}
+	return Literal{Variable: s.names[v], Negated: l.Negated()}
+}
+
+func (s *DenseSolver) value(l Lit) int8 {
+	return s.values[l]
+}
+
+func (s *DenseSolver) decisionLevel() int {
+	return len(s.trailLim)
+}
+
+func (s *DenseSolver) clauseLits(cref int32) []Lit {
+	c := &s.clauses[cref]
+	return s.arena[c.start : c.start+c.size]
+}
+
+// AddClause adds a clause between solves
+func (s *DenseSolver) AddClause(clause *Clause) error {
+	lits := make([]Lit, len(clause.Literals))
+	for i, lit := range clause.Literals {
+		lits[i] = s.Lit(lit)
+	}
+	s.AddClauseLits(lits)
+	return nil
+}
+
+// AddClauseLits adds a clause of dense literals between solves, creating
+// variables up to the largest one mentioned
+func (s *DenseSolver) AddClauseLits(lits []Lit) {
+	for _, l := range lits {
+		for int(l.Var()) >= s.NumVars() {
+			s.NewVar()
+		}
+	}
+	if !s.ok {
+		return
+	}
+	s.cancelUntil(0)
+
+	// Drop duplicate and false literals; skip satisfied clauses and
+	// tautologies
+	sorted := make([]Lit, len(lits))
+	copy(sorted, lits)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	kept := sorted[:0]
+	prev := litUndef
+	for _, l := range sorted {
+		if s.value(l) == 1 || l == prev.Not() {
+			return
+		}
+		if l != prev && s.value(l) != -1 {
+			kept = append(kept, l)
+			prev = l
+		}
+	}
+
+	switch len(kept) {
+	case 0:
+		s.ok = false
+	case 1:
+		s.enqueue(kept[0], crefUndef)
+		s.ok = s.propagate() == crefUndef
+	default:
+		s.attach(s.allocate(kept, false, 0))
+	}
+}
+
+// allocate stores a clause in the arena and returns its reference
+func (s *DenseSolver) allocate(lits []Lit, learnt bool, lbd int) int32 {
+	cref := int32(len(s.clauses))
+	s.clauses = append(s.clauses, denseClause{
+		start:  int32(len(s.arena)),
+		size:   int32(len(lits)),
+		lbd:    int32(lbd),
+		learnt: learnt,
+	})
+	s.arena = append(s.arena, lits...)
+	if learnt {
+		s.learnts = append(s.learnts, cref)
+	}
+	return cref
+}
+
+// attach watches the first two literals of a clause
+func (s *DenseSolver) attach(cref int32) {
+	lits := s.clauseLits(cref)
+	s.watches[lits[0]] = append(s.watches[lits[0]], watcher{cref: cref, blocker: lits[1]})
+	s.watches[lits[1]] = append(s.watches[lits[1]], watcher{cref: cref, blocker: lits[0]})
+}
+
+func (s *DenseSolver) enqueue(l Lit, from int32) {
+	v := l.Var()
+	s.values[l], s.values[l.Not()] = 1, -1
+	s.level[v] = int32(s.decisionLevel())
+	s.reason[v] = from
+	s.trail = append(s.trail, l)
+}
+
+// propagate assigns the literals implied by unit clauses and returns a
+// conflicting clause, or crefUndef
+func (s *DenseSolver) propagate() int32 {
+	conflict := int32(crefUndef)
+	for s.qhead < len(s.trail) {
+		falseLit := s.trail[s.qhead].Not()
+		s.qhead++
+		s.statistics.Propagations++
+
+		ws := s.watches[falseLit]
+		i, j := 0, 0
+		for i < len(ws) {
+			w := ws[i]
+			i++
+			if s.value(w.blocker) == 1 {
+				ws[j] = w
+				j++
+				continue
+			}
+			c := &s.clauses[w.cref]
+			if c.deleted {
+				continue // Drop the watch lazily
+			}
+			lits := s.arena[c.start : c.start+c.size]
+			if lits[0] == falseLit {
+				lits[0], lits[1] = lits[1], lits[0]
+			}
+			first := lits[0]
+			kept := watcher{cref: w.cref, blocker: first}
+			if first != w.blocker && s.value(first) == 1 {
+				ws[j] = kept
+				j++
+				continue
+			}
+
+			moved := false
+			for k := 2; k < len(lits); k++ {
+				if s.value(lits[k]) != -1 {
+					lits[1], lits[k] = lits[k], falseLit
+					s.watches[lits[1]] = append(s.watches[lits[1]], kept)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+
+			ws[j] = kept
+			j++
+			if s.value(first) == -1 {
+				conflict = w.cref
+				s.qhead = len(s.trail)
+				j += copy(ws[j:], ws[i:])
+				break
+			}
+			s.enqueue(first, w.cref)
+		}
+		s.watches[falseLit] = ws[:j]
+		if conflict != crefUndef {
+			break
+		}
+	}
+	return conflict
+}
+
+// analyze derives the first-UIP clause of a conflict. It returns the
+// clause, asserting literal first, with its backtrack level and LBD.
+func (s *DenseSolver) analyze(conflict int32) ([]Lit, int, int) {
+	s.learnt = append(s.learnt[:0], litUndef)
+	pathCount := 0
+	p := litUndef
+	index := len(s.trail) - 1
+
+	for {
+		if s.clauses[conflict].learnt {
+			s.bumpClause(conflict)
+		}
+		lits := s.clauseLits(conflict)
+		start := 0
+		if p != litUndef {
+			start = 1 // Skip the implied literal
+		}
+		for _, q := range lits[start:] {
+			v := q.Var()
+			if s.seen[v] || s.level[v] == 0 {
+				continue
+			}
+			s.bumpVar(v)
+			s.seen[v] = true
+			if int(s.level[v]) >= s.decisionLevel() {
+				pathCount++
+			} else {
+				s.learnt = append(s.learnt, q)
+			}
+		}
+		for !s.seen[s.trail[index].Var()] {
+			index--
+		}
+		p = s.trail[index]
+		index--
+		conflict = s.reason[p.Var()]
+		s.seen[p.Var()] = false
+		if pathCount--; pathCount == 0 {
+			break
+		}
+	}
+	s.learnt[0] = p.Not()
+
+	// Local minimisation: drop literals implied by the rest of the clause
+	s.toClear = append(s.toClear[:0], s.learnt...)
+	kept := 1
+	for _, q := range s.learnt[1:] {
+		if s.redundant(q) {
+			continue
+		}
+		s.learnt[kept] = q
+		kept++
+	}
+	s.learnt = s.learnt[:kept]
+	for _, q := range s.toClear {
+		s.seen[q.Var()] = false
+	}
+
+	backtrack := 0
+	if len(s.learnt) > 1 {
+		top := 1
+		for i := 2; i < len(s.learnt); i++ {
+			if s.level[s.learnt[i].Var()] > s.level[s.learnt[top].Var()] {
+				top = i
+			}
+		}
+		s.learnt[1], s.learnt[top] = s.learnt[top], s.learnt[1]
+		backtrack = int(s.level[s.learnt[1].Var()])
+	}
+	return s.learnt, backtrack, s.computeLBD(s.learnt)
+}
+
+// redundant reports whether a learned literal's reason consists of other
+// literals of the clause and level-0 facts
+func (s *DenseSolver) redundant(q Lit) bool {
+	r := s.reason[q.Var()]
+	if r == crefUndef {
+		return false
+	}
+	for _, l := range s.clauseLits(r)[1:] {
+		if !s.seen[l.Var()] && s.level[l.Var()] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// computeLBD returns the number of distinct decision levels in lits
+func (s *DenseSolver) computeLBD(lits []Lit) int {
+	s.stamp++
+	lbd := 0
+	for _, l := range lits {
+		level := s.level[l.Var()]
+		if s.levelStamp[level] != s.stamp {
+			s.levelStamp[level] = s.stamp
+			lbd++
+		}
+	}
+	return lbd
+}
+
+// analyzeFinal collects the assumptions that imply ¬p, where p is an
+// assumption found false
+func (s *DenseSolver) analyzeFinal(p Lit) {
+	s.failed = append(s.failed[:0], p)
+	if s.decisionLevel() == 0 {
+		return
+	}
+	s.seen[p.Var()] = true
+	for i := len(s.trail) - 1; i >= s.trailLim[0]; i-- {
+		v := s.trail[i].Var()
+		if !s.seen[v] {
+			continue
+		}
+		if r := s.reason[v]; r == crefUndef {
+			s.failed = append(s.failed, s.trail[i])
+		} else {
+			for _, l := range s.clauseLits(r)[1:] {
+				if s.level[l.Var()] > 0 {
+					s.seen[l.Var()] = true
+				}
+			}
+		}
+		s.seen[v] = false
+	}
+	s.seen[p.Var()] = false
+}
+
+func (s *DenseSolver) bumpVar(v int32) {
+	if s.activity[v] += s.varInc; s.activity[v] > 1e100 {
+		for i := range s.activity {
+			s.activity[i] *= 1e-100
+		}
+		s.heap.Rescale(1e-100)
+		s.varInc *= 1e-100
+	}
+	if s.heap.Contains(int(v)) {
+		s.heap.Update(int(v), s.activity[v])
+	}
+}
+
+func (s *DenseSolver) bumpClause(cref int32) {
+	c := &s.clauses[cref]
+	if c.activity += s.clauseInc; c.activity > 1e20 {
+		for _, r := range s.learnts {
+			s.clauses[r].activity *= 1e-20
+		}
+		s.clauseInc *= 1e-20
+	}
+}
+
+func (s *DenseSolver) newDecisionLevel() {
+	s.trailLim = append(s.trailLim, len(s.trail))
+	if len(s.levelStamp) <= s.decisionLevel() {
+		s.levelStamp = append(s.levelStamp, 0)
+	}
+}
+
+// cancelUntil backtracks to the given decision level, saving phases
+func (s *DenseSolver) cancelUntil(level int) {
+	if s.decisionLevel() <= level {
+		return
+	}
+	for i := len(s.trail) - 1; i >= s.trailLim[level]; i-- {
+		l := s.trail[i]
+		v := l.Var()
+		s.values[l], s.values[l.Not()] = 0, 0
+		s.reason[v] = crefUndef
+		s.phase[v] = !l.Negated()
+		if !s.heap.Contains(int(v)) {
+			s.heap.Update(int(v), s.activity[v])
+		}
+	}
+	s.trail = s.trail[:s.trailLim[level]]
+	s.trailLim = s.trailLim[:level]
+	s.qhead = len(s.trail)
+}
+
+// pickBranchLit returns the unassigned variable of highest activity with
+// its saved phase, or litUndef if every variable is assigned
+func (s *DenseSolver) pickBranchLit() Lit {
+	for !s.heap.IsEmpty() {
+		v := int32(s.heap.PopMax())
+		if s.values[MkLit(v, false)] == 0 {
+			return MkLit(v, !s.phase[v])
+		}
+	}
+	return litUndef
+}
+
+// locked reports whether a clause is the reason of an assignment
+func (s *DenseSolver) locked(cref int32) bool {
+	first := s.clauseLits(cref)[0]
+	return s.reason[first.Var()] == cref && s.value(first) == 1
+}
+
+// reduceDB deletes the less useful half of the learned clauses, keeping
+// glue clauses (LBD ≤ 2) and reasons
+func (s *DenseSolver) reduceDB() {
+	sort.Slice(s.learnts, func(i, j int) bool {
+		a, b := &s.clauses[s.learnts[i]], &s.clauses[s.learnts[j]]
+		if a.lbd != b.lbd {
+			return a.lbd > b.lbd
+		}
+		return a.activity < b.activity
+	})
+	half := len(s.learnts) / 2
+	kept := s.learnts[:0]
+	for i, cref := range s.learnts {
+		c := &s.clauses[cref]
+		if i < half && c.lbd > 2 && !s.locked(cref) {
+			c.deleted = true
+			s.wasted += int(c.size)
+			s.statistics.DeletedClauses++
+			continue
+		}
+		kept = append(kept, cref)
+	}
+	s.learnts = kept
+	if s.wasted > len(s.arena)/2 {
+		s.collectGarbage()
+	}
+}
+
+// collectGarbage compacts the arena and clause table, dropping deleted
+// clauses, and rebuilds the watch lists
+func (s *DenseSolver) collectGarbage() {
+	moved := make([]int32, len(s.clauses))
+	arena := make([]Lit, 0, len(s.arena)-s.wasted)
+	clauses := make([]denseClause, 0, len(s.clauses))
+	for cref, c := range s.clauses {
+		if c.deleted {
+			moved[cref] = crefUndef
+			continue
+		}
+		moved[cref] = int32(len(clauses))
+		lits := s.arena[c.start : c.start+c.size]
+		c.start = int32(len(arena))
+		arena = append(arena, lits...)
+		clauses = append(clauses, c)
+	}
+	s.arena, s.clauses, s.wasted = arena, clauses, 0
+
+	for i, cref := range s.learnts {
+		s.learnts[i] = moved[cref]
+	}
+	for _, l := range s.trail {
+		if r := s.reason[l.Var()]; r != crefUndef {
+			s.reason[l.Var()] = moved[r]
+		}
+	}
+	for i := range s.watches {
+		s.watches[i] = s.watches[i][:0]
+	}
+	for cref := range s.clauses {
+		s.attach(int32(cref))
+	}
+}
+
+// search runs CDCL until a model is found, the clauses are refuted under
+// the assumptions, or conflictBudget conflicts have passed. It returns
+// 1, -1 or 0 respectively.
+func (s *DenseSolver) search(conflictBudget int) (int8, error) {
+	conflicts := 0
+	for {
+		if s.ticks++; s.ticks&255 == 0 {
+			if err := s.interrupted(); err != nil {
+				s.cancelUntil(0)
+				return 0, err
+			}
+			s.progress.poll(s.statistics, s.started)
+		}
+		conflict := s.propagate()
+		if conflict != crefUndef {
+			s.statistics.Conflicts++
+			conflicts++
+			if s.decisionLevel() == 0 {
+				s.ok = false
+				return -1, nil
+			}
+			learnt, backtrack, lbd := s.analyze(conflict)
+			s.cancelUntil(backtrack)
+			s.recordLBD(lbd)
+			if len(learnt) == 1 {
+				s.enqueue(learnt[0], crefUndef)
+			} else {
+				cref := s.allocate(learnt, true, lbd)
+				s.attach(cref)
+				s.bumpClause(cref)
+				s.enqueue(learnt[0], cref)
+			}
+			s.statistics.LearnedClauses++
+			s.varInc /= 0.95
+			s.clauseInc /= 0.999
+			continue
+		}
+
+		if conflicts >= conflictBudget {
+			s.cancelUntil(0)
+			return 0, nil
+		}
+		if float64(len(s.learnts)-len(s.trail)) >= s.learntLimit {
+			s.reduceDB()
+			s.learntLimit *= 1.1
+		}
+
+		next := litUndef
+		for s.decisionLevel() < len(s.assumptions) {
+			p := s.assumptions[s.decisionLevel()]
+			if s.value(p) == 1 {
+				s.newDecisionLevel() // Already true; keep levels aligned
+				continue
+			}
+			if s.value(p) == -1 {
+				s.analyzeFinal(p)
+				return -1, nil
+			}
+			next = p
+			break
+		}
+		if next == litUndef {
+			s.statistics.Decisions++
+			if next = s.pickBranchLit(); next == litUndef {
+				return 1, nil
+			}
+		}
+		s.newDecisionLevel()
+		s.enqueue(next, crefUndef)
+	}
+}
+
+// interrupted reports a passed deadline or a done context
+func (s *DenseSolver) interrupted() error {
+	if !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		s.stopped = ReasonTimeout
+		return core.NewLogicError("sat", "DenseSolver.SolveWithTimeout", "timeout exceeded")
+	}
+	select {
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	default:
+		return nil
+	}
+}
+
+func (s *DenseSolver) recordLBD(lbd int) {
+	s.lbdSum += int64(lbd)
+	s.statistics.LBDDistribution[lbd]++
+	if lbd <= 2 {
+		s.statistics.GlueClauses++
+	}
+	s.statistics.AvgLBD = float64(s.lbdSum) / float64(s.statistics.Conflicts)
+}
+
+// SolveLits solves the clauses added so far with the given literals
+// assumed true. After a satisfiable call Value reports the model; after an
+// unsatisfiable one FailedLits reports the assumptions responsible.
+func (s *DenseSolver) SolveLits(assumptions []Lit) (bool, error) {
+	return s.SolveLitsContext(context.Background(), assumptions)
+}
+
+// SolveLitsContext is SolveLits stopping promptly, with ctx.Err(), once
+// ctx is done
+func (s *DenseSolver) SolveLitsContext(ctx context.Context, assumptions []Lit) (sat bool, err error) {
+	if !s.isSolving.CompareAndSwap(false, true) {
+		return false, core.NewLogicError("sat", "DenseSolver.SolveLits", "concurrent Solve calls on the same solver instance are not allowed")
+	}
+	defer s.isSolving.Store(false)
+	s.stopped = ReasonNone
+	defer func() {
+		if r := recover(); r != nil {
+			failed := recoverMemory("DenseSolver.SolveLits", r)
+			sat, err, s.stopped = false, failed.Error, failed.Reason
+		}
+	}()
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	s.ctx = ctx
+	defer func() { s.ctx = nil }()
+	s.started = time.Now()
+	s.progress.start()
+	defer func() { s.statistics.TimeElapsed += time.Since(s.started).Nanoseconds() }()
+
+	s.model, s.failed = s.model[:0], s.failed[:0]
+	for _, l := range assumptions {
+		for int(l.Var()) >= s.NumVars() {
+			s.NewVar()
+		}
+	}
+	if !s.ok {
+		return false, nil
+	}
+	s.assumptions = assumptions
+	defer func() { s.assumptions = nil }()
+	if s.learntLimit == 0 {
+		s.learntLimit = max(float64(len(s.clauses))/3, 2000)
+	}
+
+	for restarts := 0; ; restarts++ {
+		status, err := s.search(100 * luby(restarts))
+		if err != nil {
+			return false, err
+		}
+		switch status {
+		case 1:
+			s.model = s.model[:0]
+			for v := range s.level {
+				s.model = append(s.model, s.values[MkLit(int32(v), false)])
+			}
+			s.cancelUntil(0)
+			return true, nil
+		case -1:
+			s.cancelUntil(0)
+			return false, nil
+		}
+		s.statistics.Restarts++
+	}
+}
+
+// luby returns element i of the Luby sequence 1, 1, 2, 1, 1, 2, 4, ...
+func luby(i int) int {
+	size, seq := 1, 0
+	for size < i+1 {
+		seq++
+		size = 2*size + 1
+	}
+	for size-1 != i {
+		size = (size - 1) >> 1
+		seq--
+		i %= size
+	}
+	return 1 << seq
+}
+
+// Value returns the value of v in the last model
+func (s *DenseSolver) Value(v int32) bool {
+	return int(v) < len(s.model) && s.model[v] == 1
+}
+
+// FailedLits returns the failed assumptions of the last unsatisfiable
+// call, empty if the clauses are unsatisfiable on their own
+func (s *DenseSolver) FailedLits() []Lit {
+	return s.failed
+}
+
+// SolveAssuming solves the clauses added so far with the given literals
+// assumed true
+func (s *DenseSolver) SolveAssuming(assumptions []Literal) *SolverResult {
+	return s.solveAssuming(context.Background(), assumptions)
+}
+
+func (s *DenseSolver) solveAssuming(ctx context.Context, assumptions []Literal) *SolverResult {
+	lits := make([]Lit, len(assumptions))
+	for i, lit := range assumptions {
+		lits[i] = s.Lit(lit)
+	}
+	sat, err := s.SolveLitsContext(ctx, lits)
+	result := &SolverResult{Satisfiable: sat, Error: err, Statistics: s.GetStatistics()}
+	if err != nil {
+		result.Reason = s.stopped
+	}
+	defer result.setStatus()
+	switch {
+	case err != nil:
+		result.Satisfiable = false
+	case sat:
+		result.Assignment = make(Assignment, len(s.ids))
+		for name, v := range s.ids {
+			result.Assignment[name] = s.Value(v)
+		}
+	default:
+		for _, l := range s.failed {
+			result.FailedAssumptions = append(result.FailedAssumptions, s.Literal(l))
+		}
+	}
+	return result
+}
+
+// Solve solves cnf on its own, discarding previously added clauses
+func (s *DenseSolver) Solve(cnf *CNF) *SolverResult {
+	return s.SolveWithTimeout(cnf, 0)
+}
+
+// SolveWithTimeout solves cnf on its own with a time limit
+func (s *DenseSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverResult {
+	s.load(cnf)
+	if timeout > 0 {
+		s.deadline = time.Now().Add(timeout)
+		defer func() { s.deadline = time.Time{} }()
+	}
+	return s.SolveAssuming(nil)
+}
+
+// SolveContext solves cnf on its own, stopping promptly once ctx is done.
+// A stopped solve has Status StatusUnknown and ctx.Err() as its Error.
+func (s *DenseSolver) SolveContext(ctx context.Context, cnf *CNF) *SolverResult {
+	s.load(cnf)
+	return s.solveAssuming(ctx, nil)
+}
+
+// SetProgress makes every later solve call fn with the solver's
+// statistics about once per interval, a second if interval is zero. A nil
+// fn removes the callback.
+func (s *DenseSolver) SetProgress(fn ProgressFunc, interval time.Duration) {
+	s.progress.set(fn, interval)
+}
+
+// load replaces the solver's clauses with those of cnf
+func (s *DenseSolver) load(cnf *CNF) {
+	s.Reset()
+	for _, name := range cnf.Variables {
+		s.Lit(Literal{Variable: name})
+	}
+	for _, clause := range cnf.Clauses {
+		if clause != nil && !clause.Deleted {
+			s.AddClause(clause)
+		}
+	}
+}
+
+// GetStatistics returns solver performance metrics
+func (s *DenseSolver) GetStatistics() SolverStatistics {
+	stats := s.statistics
+	stats.LBDDistribution = make(map[int]int64, len(s.statistics.LBDDistribution))
+	for lbd, n := range s.statistics.LBDDistribution {
+		stats.LBDDistribution[lbd] = n
+	}
+	return stats
+}
+
+// Reset removes every variable and clause
+func (s *DenseSolver) Reset() {
+	s.ids = make(map[string]int32)
+	s.names = s.names[:0]
+	s.arena, s.clauses, s.learnts, s.wasted = s.arena[:0], s.clauses[:0], s.learnts[:0], 0
+	s.watches = s.watches[:0]
+	s.values, s.level, s.reason, s.phase, s.seen = s.values[:0], s.level[:0], s.reason[:0], s.phase[:0], s.seen[:0]
+	s.trail, s.trailLim, s.qhead = s.trail[:0], s.trailLim[:0], 0
+	s.activity = s.activity[:0]
+	s.heap.Reset()
+	s.varInc, s.clauseInc = 1, 1
+	s.ok = true
+	s.model, s.failed = s.model[:0], s.failed[:0]
+	s.learntLimit = 0
+	s.statistics = SolverStatistics{LBDDistribution: make(map[int]int64)}
+	s.lbdSum = 0
+}
@@ -0,0 +1,102 @@
+package sat
+
+import "testing"
+
+func TestLit(t *testing.T) {
+	l := MkLit(7, true)
+	if l != 15 || l.Var() != 7 || !l.Negated() || l.Not() != MkLit(7, false) {
+		t.Errorf("Unexpected encoding of ¬x7: %d", l)
+	}
+}
+
+func TestDenseSolver_Names(t *testing.T) {
+	s := NewDenseSolver()
+	a := s.Lit(L("A", true))
+	if got := s.Literal(a); got != L("A", true) {
+		t.Errorf("Expected ¬A back, got %v", got)
+	}
+	v := s.NewVar()
+	if got := s.Literal(MkLit(v, false)); got.Variable != DIMACSVariableName(int(v)+1) {
+		t.Errorf("Expected a canonical name for an unnamed variable, got %v", got)
+	}
+
+	s.AddClause(NewClause(L("A", false), L("B", false)))
+	s.AddClauseLits([]Lit{a})
+	result := s.SolveAssuming(nil)
+	if !result.Satisfiable || result.Assignment["A"] || !result.Assignment["B"] {
+		t.Errorf("Expected ¬A and B, got %v (%v)", result.Assignment, result.Error)
+	}
+}
+
+func TestDenseSolver_Solve(t *testing.T) {
+	a, b, c, d := L("A", false), L("B", false), L("C", false), L("D", false)
+	na, nb, nc, nd := L("A", true), L("B", true), L("C", true), L("D", true)
+	testCases := []struct {
+		description string
+		cnf         *CNF
+		expectedSat bool
+	}{
+		{"empty formula", NewCNF(), true},
+		{"implication chain", buildCNF([][]Literal{{a}, {na, b}, {nb, c}, {nc, d}}), true},
+		{"refuted chain", buildCNF([][]Literal{{a}, {na, b}, {nb, c}, {nc, d}, {nd}}), false},
+		{"exactly one of four", buildCNF([][]Literal{
+			{a, b, c, d}, {na, nb}, {na, nc}, {na, nd}, {nb, nc}, {nb, nd}, {nc, nd},
+		}), true},
+		{"all sign patterns", buildCNF([][]Literal{{a, b}, {a, nb}, {na, b}, {na, nb}}), false},
+		{"tautology and duplicate literals", buildCNF([][]Literal{{a, na, b}, {c, c}, {nc, d, d}}), true},
+		{"odd parity of three", buildCNF([][]Literal{{a, b, c}, {a, nb, nc}, {na, b, nc}, {na, nb, c}}), true},
+		{"odd and even parity of three", buildCNF([][]Literal{
+			{a, b, c}, {a, nb, nc}, {na, b, nc}, {na, nb, c},
+			{na, nb, nc}, {na, b, c}, {a, nb, c}, {a, b, nc},
+		}), false},
+		{"pigeonhole with as many holes", createPigeonHolePrincipleAdvanced(5, 5), true},
+		{"pigeonhole", createPigeonHolePrincipleAdvanced(6, 5), false},
+		{"structured 3-SAT", createRandom3SATAdvanced(150, 600), true},
+	}
+
+	solver := NewDenseSolver()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result := solver.Solve(tc.cnf)
+			if result.Error != nil {
+				t.Fatalf("Solve failed: %v", result.Error)
+			}
+			if result.Satisfiable != tc.expectedSat {
+				t.Fatalf("Expected satisfiable=%v, got %v", tc.expectedSat, result.Satisfiable)
+			}
+			if !result.Satisfiable {
+				return
+			}
+			for _, clause := range tc.cnf.Clauses {
+				if !result.Assignment.Satisfies(clause) {
+					t.Errorf("Model violates %v", clause)
+				}
+			}
+			for _, name := range tc.cnf.Variables {
+				if _, ok := result.Assignment[name]; !ok {
+					t.Errorf("Model misses %s", name)
+				}
+			}
+		})
+	}
+}
+
+func TestDenseSolver_Assumptions(t *testing.T) {
+	checkAssumptionSequence(t, NewDenseSolver())
+}
+
+func TestDenseSolver_ClauseDeletion(t *testing.T) {
+	solver := NewDenseSolver()
+	cnf := createPigeonHolePrincipleAdvanced(7, 6)
+	for _, clause := range cnf.Clauses {
+		solver.AddClause(clause)
+	}
+	solver.learntLimit = 20 // Force frequent reductions and compaction
+	result := solver.SolveAssuming(nil)
+	if result.Error != nil || result.Satisfiable {
+		t.Fatalf("Expected pigeonhole(7,6) to be unsatisfiable, got %v (%v)", result.Satisfiable, result.Error)
+	}
+	if result.Statistics.DeletedClauses == 0 {
+		t.Errorf("Expected learned clauses to be deleted, got %+v", result.Statistics)
+	}
+}
@@ -0,0 +1,136 @@
+package sat
+
+import (
+	"fmt"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+// DifferenceAtom is the constraint x - y ≤ c over integer variables
+type DifferenceAtom struct {
+	X, Y int
+	C    int64
+}
+
+// String returns "x3 - x1 ≤ 5"
+func (a DifferenceAtom) String() string {
+	return fmt.Sprintf("x%d - x%d ≤ %d", a.X, a.Y, a.C)
+}
+
+// differenceEdge is y → x with weight c, so dist(x) ≤ dist(y) + c
+type differenceEdge struct {
+	from, to int
+	weight   int64
+	lit      int32 // Negation of the literal that asserts the edge
+}
+
+// DifferenceLogic is a TheoryPlugin for integer difference logic. Boolean
+// variables registered with AddAtom stand for constraints x - y ≤ c; a
+// false atom asserts its negation y - x ≤ -c-1. The asserted constraints
+// are consistent exactly when their constraint graph, with an edge y → x
+// of weight c for x - y ≤ c, has no negative cycle. Check finds one with
+// Bellman-Ford and returns the lemma that at least one of its atoms takes
+// the other value; a simple cycle is a minimal infeasible subset, as any
+// proper subset of its edges forms paths only.
+type DifferenceLogic struct {
+	numVars int
+	atoms   map[int32]DifferenceAtom
+	order   []int32 // Atom variables in registration order
+	values  []int64
+}
+
+// NewDifferenceLogic creates a plugin over integer variables 0..n-1
+func NewDifferenceLogic(n int) *DifferenceLogic {
+	return &DifferenceLogic{
+		numVars: n,
+		atoms:   make(map[int32]DifferenceAtom),
+	}
+}
+
+// AddAtom makes Boolean variable v stand for x - y ≤ c
+func (d *DifferenceLogic) AddAtom(v int32, x, y int, c int64) error {
+	if v < 0 {
+		return core.NewLogicError("sat", "DifferenceLogic.AddAtom", fmt.Sprintf("invalid Boolean variable %d", v))
+	}
+	if x < 0 || x >= d.numVars || y < 0 || y >= d.numVars {
+		return core.NewLogicError("sat", "DifferenceLogic.AddAtom",
+			fmt.Sprintf("variables %d and %d must be below %d", x, y, d.numVars))
+	}
+	if _, ok := d.atoms[v]; ok {
+		return core.NewLogicError("sat", "DifferenceLogic.AddAtom", fmt.Sprintf("Boolean variable %d is already an atom", v))
+	}
+	d.atoms[v] = DifferenceAtom{X: x, Y: y, C: c}
+	d.order = append(d.order, v)
+	return nil
+}
+
+// Name returns the plugin identifier
+func (d *DifferenceLogic) Name() string { return "difference-logic" }
+
+// Values returns integer values satisfying the atoms as assigned in the
+// last consistent Check. Adding a constant to every value gives another
+// solution.
+func (d *DifferenceLogic) Values() []int64 {
+	return d.values
+}
+
+// Check asserts every atom as assign has it and looks for a negative cycle
+func (d *DifferenceLogic) Check(assign []int8) (bool, []int32) {
+	edges := make([]differenceEdge, 0, len(d.order))
+	for _, v := range d.order {
+		if int(v) >= len(assign) {
+			continue
+		}
+		atom := d.atoms[v]
+		if assign[v] != 0 {
+			edges = append(edges, differenceEdge{from: atom.Y, to: atom.X, weight: atom.C, lit: v*2 + 1})
+		} else {
+			edges = append(edges, differenceEdge{from: atom.X, to: atom.Y, weight: -atom.C - 1, lit: v * 2})
+		}
+	}
+
+	// Every variable starts at distance 0, as from a virtual source with
+	// an edge of weight 0 to each, so shortest paths need at most
+	// numVars-1 further rounds.
+	dist := make([]int64, d.numVars)
+	pred := make([]int, d.numVars)
+	for i := range pred {
+		pred[i] = -1
+	}
+	for round := 0; round < d.numVars; round++ {
+		relaxed := -1
+		for i, e := range edges {
+			if dist[e.from]+e.weight < dist[e.to] {
+				dist[e.to] = dist[e.from] + e.weight
+				pred[e.to] = i
+				relaxed = e.to
+			}
+		}
+		if relaxed < 0 {
+			d.values = dist
+			return true, nil
+		}
+		if round == d.numVars-1 {
+			return false, d.negativeCycle(edges, pred, relaxed)
+		}
+	}
+	d.values = dist
+	return true, nil
+}
+
+// negativeCycle follows predecessors from a vertex relaxed in the last
+// round, which leads into a cycle of the predecessor graph, and returns
+// the negation of the literals of the cycle's edges
+func (d *DifferenceLogic) negativeCycle(edges []differenceEdge, pred []int, v int) []int32 {
+	for i := 0; i < d.numVars; i++ {
+		v = edges[pred[v]].from
+	}
+	var lemma []int32
+	for u := v; ; {
+		e := edges[pred[u]]
+		lemma = append(lemma, e.lit)
+		if u = e.from; u == v {
+			return lemma
+		}
+	}
+}
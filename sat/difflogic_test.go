@@ -0,0 +1,146 @@
+package sat
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// dlHolds reports whether values satisfy the atoms as assign has them
+func dlHolds(atoms map[int32]DifferenceAtom, assign []int8, values []int64) bool {
+	for v, a := range atoms {
+		if holds := values[a.X]-values[a.Y] <= a.C; holds != (assign[v] != 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDifferenceLogic_Check(t *testing.T) {
+	testCases := []struct {
+		description   string
+		atoms         []DifferenceAtom
+		assign        []int8
+		expectedLemma []int32 // nil if the assignment is consistent
+	}{
+		{"chain within bounds",
+			[]DifferenceAtom{{0, 1, 2}, {1, 2, 1}}, []int8{1, 1}, nil},
+		{"negative cycle",
+			[]DifferenceAtom{{0, 1, -1}, {1, 0, 0}}, []int8{1, 1}, []int32{1, 3}},
+		{"cycle of weight zero",
+			[]DifferenceAtom{{0, 1, 1}, {1, 2, -2}, {2, 0, 1}}, []int8{1, 1, 1}, nil},
+		{"negated atom closes a cycle",
+			[]DifferenceAtom{{0, 1, 1}, {1, 2, -2}, {0, 2, -1}}, []int8{1, 1, 0}, []int32{1, 3, 4}},
+		{"same atoms with the last one true",
+			[]DifferenceAtom{{0, 1, 1}, {1, 2, -2}, {0, 2, -1}}, []int8{1, 1, 1}, nil},
+		{"every atom negated",
+			[]DifferenceAtom{{0, 1, 0}, {1, 2, 0}, {2, 0, 0}}, []int8{0, 0, 0}, []int32{0, 2, 4}},
+		{"unrelated atoms stay out of the lemma",
+			[]DifferenceAtom{{0, 1, 0}, {1, 0, -1}, {2, 1, 3}, {1, 2, 3}}, []int8{1, 1, 1, 0}, []int32{1, 3}},
+		{"negative self loop",
+			[]DifferenceAtom{{0, 0, -1}, {1, 2, 0}}, []int8{1, 1}, []int32{1}},
+		{"negated self loop",
+			[]DifferenceAtom{{0, 0, -1}, {1, 2, 0}}, []int8{0, 1}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			dl := NewDifferenceLogic(3)
+			atoms := make(map[int32]DifferenceAtom)
+			for v, a := range tc.atoms {
+				if err := dl.AddAtom(int32(v), a.X, a.Y, a.C); err != nil {
+					t.Fatal(err)
+				}
+				atoms[int32(v)] = a
+			}
+
+			ok, lemma := dl.Check(tc.assign)
+			if ok != (tc.expectedLemma == nil) {
+				t.Fatalf("Expected consistent=%v, got %v with lemma %v", tc.expectedLemma == nil, ok, lemma)
+			}
+			if ok {
+				if !dlHolds(atoms, tc.assign, dl.Values()) {
+					t.Errorf("Values %v violate %v", dl.Values(), tc.assign)
+				}
+				return
+			}
+			sort.Slice(lemma, func(i, j int) bool { return lemma[i] < lemma[j] })
+			if !reflect.DeepEqual(lemma, tc.expectedLemma) {
+				t.Errorf("Expected lemma %v, got %v", tc.expectedLemma, lemma)
+			}
+		})
+	}
+
+	dl := NewDifferenceLogic(2)
+	if err := dl.AddAtom(0, 0, 2, 1); err == nil {
+		t.Error("Expected an error for an unknown variable")
+	}
+	dl.AddAtom(0, 0, 1, 1)
+	if err := dl.AddAtom(0, 1, 0, 1); err == nil {
+		t.Error("Expected an error for a repeated atom")
+	}
+	if got := (DifferenceAtom{X: 3, Y: 1, C: 5}).String(); got != "x3 - x1 ≤ 5" {
+		t.Errorf("Unexpected string %q", got)
+	}
+}
+
+func TestTheorySolver_DifferenceLogic(t *testing.T) {
+	// Jobs 0, 1 and 2 with durations 3, 2 and 4 share one machine, start
+	// at s0, s1 and s2 no earlier than an origin s3 and end by a deadline;
+	// job 0 precedes job 2
+	durations := []int64{3, 2, 4}
+	build := func(deadline int64) (*TheorySolver, *DifferenceLogic) {
+		ts := newTheorySolver(t, 13)
+		dl := NewDifferenceLogic(4)
+		next := int32(0)
+		// atom adds s_x - s_y ≤ c as the next Boolean variable
+		atom := func(x, y int, c int64) int32 {
+			dl.AddAtom(next, x, y, c)
+			next++
+			return next - 1
+		}
+		for _, p := range [][2]int{{0, 1}, {0, 2}, {1, 2}} {
+			// Job i ends before job j starts or the other way round
+			before := atom(p[0], p[1], -durations[p[0]])
+			after := atom(p[1], p[0], -durations[p[1]])
+			ts.AddClause([]int32{before * 2, after * 2})
+		}
+		// Job 0 precedes job 2
+		ts.AddClause([]int32{atom(0, 2, -durations[0]) * 2})
+		for i, d := range durations {
+			// s3 - s_i ≤ 0 and s_i - s3 ≤ deadline - d_i
+			ts.AddClause([]int32{atom(3, i, 0) * 2})
+			ts.AddClause([]int32{atom(i, 3, deadline-d) * 2})
+		}
+		ts.RegisterPlugin(dl)
+		return ts, dl
+	}
+
+	// The jobs take 9 in total
+	ts, dl := build(9)
+	assign, status := ts.Solve()
+	if status != StatusSAT {
+		t.Fatalf("Expected SAT with deadline 9, got %v", status)
+	}
+	s := dl.Values()
+	for i, d := range durations {
+		for j, e := range durations {
+			if i < j && s[i]+d > s[j] && s[j]+e > s[i] {
+				t.Errorf("Jobs %d and %d overlap in %v (%v)", i, j, s, assign)
+			}
+		}
+	}
+	if s[0]+durations[0] > s[2] {
+		t.Errorf("Schedule %v breaks the precedence", s)
+	}
+	for i, d := range durations {
+		if s[i] < s[3] || s[i]+d-s[3] > 9 {
+			t.Errorf("Schedule %v misses the window of job %d", s, i)
+		}
+	}
+
+	ts, _ = build(8)
+	if _, status := ts.Solve(); status != StatusUNSAT {
+		t.Errorf("Expected UNSAT with deadline 8, got %v", status)
+	}
+}
@@ -0,0 +1,630 @@
+package sat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xDarkicex/logic/core"
+	"github.com/xDarkicex/memory"
+)
+
+// DIMACSVariableName returns the canonical name of DIMACS variable n.
+// Variables read from a DIMACS stream are named "v1", "v2", ...
+func DIMACSVariableName(n int) string {
+	return "v" + strconv.Itoa(n)
+}
+
+// parseDIMACSVariableName reports the index of a canonical DIMACS name.
+func parseDIMACSVariableName(name string) (int, bool) {
+	if len(name) < 2 || name[0] != 'v' || name[1] == '0' {
+		return 0, false
+	}
+	n, err := strconv.Atoi(name[1:])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// VariableMap is a bidirectional mapping between variable names and the
+// 1-based integer indices used by DIMACS-style formats.
+type VariableMap struct {
+	ids   map[string]int
+	names []string // names[i] is the name of variable i+1
+}
+
+// NewVariableMap creates an empty variable mapping
+func NewVariableMap() *VariableMap {
+	return &VariableMap{
+		ids:   make(map[string]int),
+		names: memory.MustPoolSlice[string](satPool, 64),
+	}
+}
+
+// NewVariableMapForCNF creates a mapping covering every variable of cnf.
+// Canonical names ("v7") keep their index so that read/write round trips
+// are stable; all other names are numbered after them in order of
+// appearance.
+func NewVariableMapForCNF(cnf *CNF) *VariableMap {
+	return newVariableMapForCNFs(cnf)
+}
+
+// newVariableMapForCNFs numbers the variables of several formulas as
+// NewVariableMapForCNF numbers those of one
+func newVariableMapForCNFs(cnfs ...*CNF) *VariableMap {
+	m := NewVariableMap()
+	for _, cnf := range cnfs {
+		for _, v := range cnf.Variables {
+			if n, ok := parseDIMACSVariableName(v); ok {
+				m.Name(n)
+			}
+		}
+	}
+	for _, cnf := range cnfs {
+		for _, v := range cnf.Variables {
+			m.Index(v)
+		}
+	}
+	return m
+}
+
+// Index returns the index of name, assigning the next free index if the
+// name has not been seen before.
+func (m *VariableMap) Index(name string) int {
+	if id, ok := m.ids[name]; ok {
+		return id
+	}
+	m.names = append(m.names, name)
+	id := len(m.names)
+	m.ids[name] = id
+	return id
+}
+
+// Lookup returns the index of name without assigning one
+func (m *VariableMap) Lookup(name string) (int, bool) {
+	id, ok := m.ids[name]
+	return id, ok
+}
+
+// Name returns the name of variable index, creating canonical names for
+// every index up to it that has not been named yet.
+func (m *VariableMap) Name(index int) string {
+	for len(m.names) < index {
+		name := DIMACSVariableName(len(m.names) + 1)
+		if _, taken := m.ids[name]; taken {
+			// A non-canonical caller already owns this name; keep the
+			// slot but make the generated name unique.
+			name = fmt.Sprintf("%s_%d", name, len(m.names)+1)
+		}
+		m.names = append(m.names, name)
+		m.ids[name] = len(m.names)
+	}
+	return m.names[index-1]
+}
+
+// Len returns the number of mapped variables
+func (m *VariableMap) Len() int {
+	return len(m.names)
+}
+
+// ToDIMACS converts a literal to its signed integer form
+func (m *VariableMap) ToDIMACS(lit Literal) int {
+	id := m.Index(lit.Variable)
+	if lit.Negated {
+		return -id
+	}
+	return id
+}
+
+// FromDIMACS converts a non-zero signed integer to a literal
+func (m *VariableMap) FromDIMACS(lit int) Literal {
+	if lit < 0 {
+		return Literal{Variable: m.Name(-lit), Negated: true}
+	}
+	return Literal{Variable: m.Name(lit), Negated: false}
+}
+
+// DIMACSReader is a streaming parser for the DIMACS "p cnf" format.
+// Clauses may span lines and several clauses may share a line. Lines
+// starting with "x" are parsed as CryptoMiniSat-style XOR constraints:
+// "x1 -2 3 0" encodes v1 ⊕ ¬v2 ⊕ v3 = 1.
+type DIMACSReader struct {
+	dimacsTokenizer
+
+	numVars    int
+	numClauses int
+	header     bool
+	done       bool
+}
+
+// NewDIMACSReader creates a streaming DIMACS reader over r
+func NewDIMACSReader(r io.Reader) *DIMACSReader {
+	return &DIMACSReader{dimacsTokenizer: newDIMACSTokenizer(r)}
+}
+
+// dimacsTokenizer holds the line scanning and clause building shared by
+// the DIMACS-style readers
+type dimacsTokenizer struct {
+	scanner *bufio.Scanner
+	vars    *VariableMap
+	line    int
+
+	fields  []string
+	pos     int
+	pending []int
+	seen    map[int]bool
+}
+
+// newDIMACSTokenizer creates a tokenizer over r
+func newDIMACSTokenizer(r io.Reader) dimacsTokenizer {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	return dimacsTokenizer{
+		scanner: scanner,
+		vars:    NewVariableMap(),
+		pending: memory.MustPoolSlice[int](satPool, 16),
+		seen:    make(map[int]bool),
+	}
+}
+
+// scanLine advances to the next line that is neither blank nor a comment
+// and returns it trimmed. It reports false at the end of the stream; op
+// names the caller in scanner errors.
+func (t *dimacsTokenizer) scanLine(op string) (string, bool, error) {
+	t.fields, t.pos = t.fields[:0], 0
+	for t.scanner.Scan() {
+		t.line++
+		text := strings.TrimSpace(t.scanner.Text())
+		if text != "" && text[0] != 'c' {
+			return text, true, nil
+		}
+	}
+	if err := t.scanner.Err(); err != nil {
+		e := core.NewLogicError("sat", op, err.Error())
+		e.Position = t.line
+		return "", false, e
+	}
+	return "", false, nil
+}
+
+// parseLiteral parses a signed literal. A numVars of zero or more is the
+// declared maximum variable; a negative numVars skips the check.
+func (t *dimacsTokenizer) parseLiteral(op, tok string, numVars int) (int, error) {
+	lit, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, t.errorf(op, fmt.Sprintf("invalid literal %q", tok))
+	}
+	v := lit
+	if v < 0 {
+		v = -v
+	}
+	if numVars >= 0 && v > numVars {
+		return 0, t.errorf(op, fmt.Sprintf("variable %d exceeds declared maximum %d", v, numVars))
+	}
+	return lit, nil
+}
+
+// buildClause converts the pending literals to a Clause and clears them.
+// The second result reports whether the clause is a tautology.
+func (t *dimacsTokenizer) buildClause() (*Clause, bool) {
+	defer func() { t.pending = t.pending[:0] }()
+	for k := range t.seen {
+		delete(t.seen, k)
+	}
+	lits := memory.MustPoolSlice[Literal](satPool, len(t.pending))
+	for _, l := range t.pending {
+		if t.seen[-l] {
+			return nil, true
+		}
+		t.seen[l] = true
+		lits = append(lits, t.vars.FromDIMACS(l))
+	}
+	return NewClause(lits...), false
+}
+
+// parseCounts parses the variable and clause counts of the problem line
+// text split into fields. Variables are named only as literals use them,
+// so the declared count allocates nothing.
+func (t *dimacsTokenizer) parseCounts(op, text string, fields []string) (vars, clauses int, err error) {
+	vars, err1 := strconv.Atoi(fields[2])
+	clauses, err2 := strconv.Atoi(fields[3])
+	if err1 != nil || err2 != nil || vars < 0 || clauses < 0 {
+		return 0, 0, t.errorf(op, fmt.Sprintf("malformed problem line %q", text))
+	}
+	return vars, clauses, nil
+}
+
+// errorf builds a LogicError carrying the current line number
+func (t *dimacsTokenizer) errorf(op, msg string) error {
+	e := core.NewLogicError("sat", op, fmt.Sprintf("line %d: %s", t.line, msg))
+	e.Position = t.line
+	return e
+}
+
+// Variables returns the mapping between DIMACS indices and variable names
+func (d *DIMACSReader) Variables() *VariableMap {
+	return d.vars
+}
+
+// Header reads up to and including the problem line and returns the
+// declared variable and clause counts.
+func (d *DIMACSReader) Header() (numVars, numClauses int, err error) {
+	for !d.header {
+		kind, err := d.nextLine()
+		if err != nil {
+			return 0, 0, err
+		}
+		if kind == dimacsEOF {
+			return 0, 0, d.errorf("DIMACSReader.Header", "missing problem line")
+		}
+	}
+	return d.numVars, d.numClauses, nil
+}
+
+// Next returns the next clause or XOR constraint in the stream. Exactly
+// one of the returned pointers is non-nil unless err is set. Tautological
+// clauses are skipped. io.EOF is returned once the stream is exhausted.
+func (d *DIMACSReader) Next() (*Clause, *XORClause, error) {
+	if _, _, err := d.Header(); err != nil {
+		return nil, nil, err
+	}
+	for {
+		if d.pos >= len(d.fields) {
+			kind, err := d.nextLine()
+			if err != nil {
+				return nil, nil, err
+			}
+			switch kind {
+			case dimacsEOF:
+				if len(d.pending) > 0 {
+					return nil, nil, d.errorf("DIMACSReader.Next", "unterminated clause at end of input")
+				}
+				return nil, nil, io.EOF
+			case dimacsXOR:
+				xor, err := d.parseXORLine()
+				if err != nil {
+					return nil, nil, err
+				}
+				return nil, xor, nil
+			}
+			continue
+		}
+
+		tok := d.fields[d.pos]
+		d.pos++
+		lit, err := d.parseLiteral("DIMACSReader.Next", tok, d.numVars)
+		if err != nil {
+			return nil, nil, err
+		}
+		if lit != 0 {
+			d.pending = append(d.pending, lit)
+			continue
+		}
+
+		clause, tautology := d.buildClause()
+		if tautology {
+			continue
+		}
+		return clause, nil, nil
+	}
+}
+
+// Line kinds returned by nextLine
+const (
+	dimacsEOF = iota
+	dimacsData
+	dimacsXOR
+)
+
+// nextLine advances to the next line carrying data and reports its kind.
+// Data lines are split into fields; XOR lines are left in the scanner for
+// parseXORLine.
+func (d *DIMACSReader) nextLine() (int, error) {
+	for !d.done {
+		text, ok, err := d.scanLine("DIMACSReader.Next")
+		if err != nil || !ok {
+			return dimacsEOF, err
+		}
+		switch text[0] {
+		case '%':
+			// SATLIB benchmarks terminate with "%" followed by junk
+			d.done = true
+			return dimacsEOF, nil
+		case 'p':
+			if err := d.parseHeader(text); err != nil {
+				return dimacsEOF, err
+			}
+			return dimacsData, nil
+		case 'x':
+			if !d.header {
+				return dimacsEOF, d.errorf("DIMACSReader.Header", "XOR constraint before problem line")
+			}
+			if len(d.pending) > 0 {
+				return dimacsEOF, d.errorf("DIMACSReader.Next", "XOR constraint inside unterminated clause")
+			}
+			return dimacsXOR, nil
+		}
+		if !d.header {
+			return dimacsEOF, d.errorf("DIMACSReader.Header", "clause data before problem line")
+		}
+		d.fields = strings.Fields(text)
+		return dimacsData, nil
+	}
+	return dimacsEOF, nil
+}
+
+// parseXORLine parses the "x" line currently held by the scanner
+func (d *DIMACSReader) parseXORLine() (*XORClause, error) {
+	raw := strings.TrimSpace(d.scanner.Text())[1:]
+
+	counts := make(map[int]int)
+	order := memory.MustPoolSlice[int](satPool, 8)
+	parity := true
+	terminated := false
+	for _, tok := range strings.Fields(raw) {
+		if terminated {
+			return nil, d.errorf("DIMACSReader.Next", "data after terminating 0 in XOR constraint")
+		}
+		lit, err := d.parseLiteral("DIMACSReader.Next", tok, d.numVars)
+		if err != nil {
+			return nil, err
+		}
+		if lit == 0 {
+			terminated = true
+			continue
+		}
+		if lit < 0 {
+			parity = !parity
+			lit = -lit
+		}
+		if counts[lit] == 0 {
+			order = append(order, lit)
+		}
+		counts[lit]++
+	}
+	if !terminated {
+		return nil, d.errorf("DIMACSReader.Next", "XOR constraint not terminated by 0")
+	}
+
+	// x ⊕ x = 0, so variables occurring an even number of times cancel
+	variables := memory.MustPoolSlice[string](satPool, len(order))
+	for _, v := range order {
+		if counts[v]%2 == 1 {
+			variables = append(variables, d.vars.Name(v))
+		}
+	}
+	return NewXORClause(variables, parity), nil
+}
+
+// parseHeader parses a "p cnf <vars> <clauses>" problem line
+func (d *DIMACSReader) parseHeader(text string) error {
+	if d.header {
+		return d.errorf("DIMACSReader.Header", "duplicate problem line")
+	}
+	fields := strings.Fields(text)
+	if len(fields) != 4 || fields[0] != "p" || fields[1] != "cnf" {
+		return d.errorf("DIMACSReader.Header", fmt.Sprintf("malformed problem line %q", text))
+	}
+	vars, clauses, err := d.parseCounts("DIMACSReader.Header", text, fields)
+	if err != nil {
+		return err
+	}
+	d.numVars, d.numClauses = vars, clauses
+	d.header = true
+	return nil
+}
+
+// ReadCNF reads every remaining clause into a new CNF. XOR constraints
+// are rejected; use ReadExtendedCNF for streams containing them.
+func (d *DIMACSReader) ReadCNF() (*CNF, error) {
+	cnf := NewCNF()
+	for {
+		clause, xor, err := d.Next()
+		if err == io.EOF {
+			return cnf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if xor != nil {
+			return nil, d.errorf("DIMACSReader.ReadCNF", "XOR constraint in plain CNF input")
+		}
+		cnf.AddClause(clause)
+	}
+}
+
+// ReadExtendedCNF reads every remaining clause and XOR constraint
+func (d *DIMACSReader) ReadExtendedCNF() (*ExtendedCNF, error) {
+	ecnf := NewExtendedCNF()
+	for {
+		clause, xor, err := d.Next()
+		if err == io.EOF {
+			return ecnf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if xor != nil {
+			ecnf.AddXORClause(xor)
+		} else {
+			ecnf.AddClause(clause)
+		}
+	}
+}
+
+// ReadDIMACS parses a DIMACS CNF stream
+func ReadDIMACS(r io.Reader) (*CNF, error) {
+	return NewDIMACSReader(r).ReadCNF()
+}
+
+// ReadDIMACSExtended parses a DIMACS CNF stream with XOR extension lines
+func ReadDIMACSExtended(r io.Reader) (*ExtendedCNF, error) {
+	return NewDIMACSReader(r).ReadExtendedCNF()
+}
+
+// DIMACSWriter is a streaming DIMACS writer
+type DIMACSWriter struct {
+	w    *bufio.Writer
+	vars *VariableMap
+	buf  []byte
+}
+
+// NewDIMACSWriter creates a writer using vars to number variables. A nil
+// map starts empty and assigns indices on first use.
+func NewDIMACSWriter(w io.Writer, vars *VariableMap) *DIMACSWriter {
+	if vars == nil {
+		vars = NewVariableMap()
+	}
+	return &DIMACSWriter{
+		w:    bufio.NewWriter(w),
+		vars: vars,
+		buf:  memory.MustPoolSlice[byte](satPool, 64),
+	}
+}
+
+// Variables returns the mapping used by the writer
+func (dw *DIMACSWriter) Variables() *VariableMap {
+	return dw.vars
+}
+
+// WriteHeader writes the "p cnf" problem line
+func (dw *DIMACSWriter) WriteHeader(numVars, numClauses int) error {
+	_, err := fmt.Fprintf(dw.w, "p cnf %d %d\n", numVars, numClauses)
+	return err
+}
+
+// WriteComment writes a "c" comment line
+func (dw *DIMACSWriter) WriteComment(text string) error {
+	_, err := fmt.Fprintf(dw.w, "c %s\n", text)
+	return err
+}
+
+// WriteClause writes a zero-terminated clause line
+func (dw *DIMACSWriter) WriteClause(clause *Clause) error {
+	return dw.writePrefixedClause(nil, clause)
+}
+
+// writePrefixedClause writes a clause line starting with prefix, such as
+// the weight of a WCNF clause
+func (dw *DIMACSWriter) writePrefixedClause(prefix []byte, clause *Clause) error {
+	dw.buf = append(dw.buf[:0], prefix...)
+	for _, lit := range clause.Literals {
+		dw.buf = strconv.AppendInt(dw.buf, int64(dw.vars.ToDIMACS(lit)), 10)
+		dw.buf = append(dw.buf, ' ')
+	}
+	dw.buf = append(dw.buf, '0', '\n')
+	_, err := dw.w.Write(dw.buf)
+	return err
+}
+
+// WriteXORClause writes an "x" line. Even parity is expressed by negating
+// the first variable. An empty XOR with odd parity is written as the
+// empty clause; an empty XOR with even parity is trivially true and
+// nothing is written.
+func (dw *DIMACSWriter) WriteXORClause(xor *XORClause) error {
+	if len(xor.Variables) == 0 {
+		if xor.Parity {
+			_, err := dw.w.WriteString("0\n")
+			return err
+		}
+		return nil
+	}
+	dw.buf = append(dw.buf[:0], 'x')
+	for i, v := range xor.Variables {
+		id := dw.vars.Index(v)
+		if i == 0 && !xor.Parity {
+			id = -id
+		}
+		dw.buf = strconv.AppendInt(dw.buf, int64(id), 10)
+		dw.buf = append(dw.buf, ' ')
+	}
+	dw.buf = append(dw.buf, '0', '\n')
+	_, err := dw.w.Write(dw.buf)
+	return err
+}
+
+// Flush writes any buffered data to the underlying writer
+func (dw *DIMACSWriter) Flush() error {
+	return dw.w.Flush()
+}
+
+// writeNameComments records non-canonical variable names so dumps of
+// converted formulas stay readable.
+func (dw *DIMACSWriter) writeNameComments() error {
+	for i, name := range dw.vars.names {
+		if n, ok := parseDIMACSVariableName(name); ok && n == i+1 {
+			continue
+		}
+		if err := dw.WriteComment(fmt.Sprintf("var %d %s", i+1, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countLiveClauses returns the number of clauses not marked deleted
+func countLiveClauses(clauses []*Clause) int {
+	n := 0
+	for _, c := range clauses {
+		if !c.Deleted {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteDIMACS writes cnf in DIMACS format. Clauses marked Deleted are
+// skipped.
+func WriteDIMACS(w io.Writer, cnf *CNF) error {
+	dw := NewDIMACSWriter(w, NewVariableMapForCNF(cnf))
+	if err := dw.writeNameComments(); err != nil {
+		return err
+	}
+	if err := dw.WriteHeader(dw.vars.Len(), countLiveClauses(cnf.Clauses)); err != nil {
+		return err
+	}
+	for _, clause := range cnf.Clauses {
+		if clause.Deleted {
+			continue
+		}
+		if err := dw.WriteClause(clause); err != nil {
+			return err
+		}
+	}
+	return dw.Flush()
+}
+
+// WriteDIMACSExtended writes ecnf in DIMACS format with XOR constraints as
+// "x" lines. The header clause count includes the XOR lines.
+func WriteDIMACSExtended(w io.Writer, ecnf *ExtendedCNF) error {
+	dw := NewDIMACSWriter(w, NewVariableMapForCNF(ecnf.CNF))
+	if err := dw.writeNameComments(); err != nil {
+		return err
+	}
+	count := countLiveClauses(ecnf.Clauses)
+	for _, xor := range ecnf.XORClauses {
+		if len(xor.Variables) > 0 || xor.Parity {
+			count++
+		}
+	}
+	if err := dw.WriteHeader(dw.vars.Len(), count); err != nil {
+		return err
+	}
+	for _, clause := range ecnf.Clauses {
+		if clause.Deleted {
+			continue
+		}
+		if err := dw.WriteClause(clause); err != nil {
+			return err
+		}
+	}
+	for _, xor := range ecnf.XORClauses {
+		if err := dw.WriteXORClause(xor); err != nil {
+			return err
+		}
+	}
+	return dw.Flush()
+}
@@ -0,0 +1,228 @@
+package sat
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+func TestReadDIMACS_Basic(t *testing.T) {
+	input := `c example from the DIMACS spec
+p cnf 3 2
+1 -3 0
+2 3 -1 0
+`
+	cnf, err := ReadDIMACS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadDIMACS failed: %v", err)
+	}
+	if len(cnf.Clauses) != 2 {
+		t.Fatalf("Expected 2 clauses, got %d", len(cnf.Clauses))
+	}
+	if !cnf.Clauses[0].Contains(L("v1", false)) || !cnf.Clauses[0].Contains(L("v3", true)) {
+		t.Errorf("Unexpected first clause %s", cnf.Clauses[0])
+	}
+	if len(cnf.Clauses[1].Literals) != 3 {
+		t.Errorf("Expected 3 literals in second clause, got %d", len(cnf.Clauses[1].Literals))
+	}
+
+	result := NewCDCLSolver().Solve(cnf)
+	if !result.Satisfiable {
+		t.Error("Expected example formula to be satisfiable")
+	}
+}
+
+func TestReadDIMACS_MultiLineClauses(t *testing.T) {
+	input := "p cnf 4 3\n1 2\n-3 0 4 0\n\n-1\n-2 0\n%\n0\n"
+	cnf, err := ReadDIMACS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadDIMACS failed: %v", err)
+	}
+	if len(cnf.Clauses) != 3 {
+		t.Fatalf("Expected 3 clauses, got %d", len(cnf.Clauses))
+	}
+	if len(cnf.Clauses[0].Literals) != 3 || len(cnf.Clauses[1].Literals) != 1 {
+		t.Errorf("Clause boundaries not respected: %s", cnf)
+	}
+}
+
+func TestReadDIMACS_SkipsTautologies(t *testing.T) {
+	cnf, err := ReadDIMACS(strings.NewReader("p cnf 2 2\n1 -1 2 0\n2 0\n"))
+	if err != nil {
+		t.Fatalf("ReadDIMACS failed: %v", err)
+	}
+	if len(cnf.Clauses) != 1 || !cnf.Clauses[0].IsUnit() {
+		t.Errorf("Expected only the unit clause to remain, got %s", cnf)
+	}
+}
+
+func TestReadDIMACS_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int
+	}{
+		{"missing header", "1 2 0\n", 1},
+		{"bad header", "p dnf 2 1\n", 1},
+		{"duplicate header", "p cnf 2 1\np cnf 2 1\n", 2},
+		{"bad literal", "p cnf 2 1\nc ok\n1 a 0\n", 3},
+		{"variable out of range", "p cnf 2 1\n1 3 0\n", 2},
+		{"unterminated", "p cnf 2 1\n1 2\n", 2},
+		{"xor in plain cnf", "p cnf 2 1\nx1 2 0\n", 2},
+		{"no header at all", "c nothing\n", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadDIMACS(strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("Expected parse error")
+			}
+			var le *core.LogicError
+			if !errors.As(err, &le) {
+				t.Fatalf("Expected *core.LogicError, got %T", err)
+			}
+			if le.Position != tt.line {
+				t.Errorf("Expected error on line %d, got %d (%v)", tt.line, le.Position, err)
+			}
+		})
+	}
+}
+
+func TestReadDIMACSExtended_XOR(t *testing.T) {
+	input := "p cnf 3 3\n1 2 0\nx1 -2 3 0\nx 2 3 3 0\n"
+	ecnf, err := ReadDIMACSExtended(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadDIMACSExtended failed: %v", err)
+	}
+	if len(ecnf.Clauses) != 1 || len(ecnf.XORClauses) != 2 {
+		t.Fatalf("Expected 1 clause and 2 XORs, got %d and %d", len(ecnf.Clauses), len(ecnf.XORClauses))
+	}
+
+	first := ecnf.XORClauses[0]
+	if len(first.Variables) != 3 || first.Parity {
+		t.Errorf("Expected v1 ⊕ v2 ⊕ v3 = 0, got %s", first)
+	}
+	second := ecnf.XORClauses[1]
+	if len(second.Variables) != 1 || second.Variables[0] != "v2" || !second.Parity {
+		t.Errorf("Expected duplicate v3 to cancel leaving v2 = 1, got %s", second)
+	}
+}
+
+func TestWriteDIMACS_RoundTrip(t *testing.T) {
+	input := "p cnf 5 3\n1 -3 0\n2 3 -1 0\n-5 0\n"
+	cnf, err := ReadDIMACS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadDIMACS failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDIMACS(&buf, cnf); err != nil {
+		t.Fatalf("WriteDIMACS failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "p cnf 5 3\n") {
+		t.Errorf("Expected header to be preserved, got %q", buf.String())
+	}
+
+	again, err := ReadDIMACS(&buf)
+	if err != nil {
+		t.Fatalf("Re-reading written DIMACS failed: %v", err)
+	}
+	if again.String() != cnf.String() {
+		t.Errorf("Round trip mismatch:\n%s\n%s", cnf, again)
+	}
+}
+
+func TestWriteDIMACS_NamedVariables(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", true)))
+	cnf.AddClause(NewClause(L("B", false)))
+	deleted := NewClause(L("A", true))
+	deleted.Deleted = true
+	cnf.AddClause(deleted)
+
+	var buf bytes.Buffer
+	if err := WriteDIMACS(&buf, cnf); err != nil {
+		t.Fatalf("WriteDIMACS failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "c var 1 A\n") || !strings.Contains(out, "c var 2 B\n") {
+		t.Errorf("Expected name comments, got %q", out)
+	}
+	if !strings.Contains(out, "p cnf 2 2\n1 -2 0\n2 0\n") {
+		t.Errorf("Unexpected body %q", out)
+	}
+}
+
+func TestWriteDIMACSExtended_RoundTrip(t *testing.T) {
+	ecnf := NewExtendedCNF()
+	ecnf.AddClause(NewClause(L("v1", false), L("v2", false)))
+	ecnf.AddXORClause(NewXORClause([]string{"v1", "v2", "v3"}, true))
+	ecnf.AddXORClause(NewXORClause([]string{"v2", "v3"}, false))
+
+	var buf bytes.Buffer
+	if err := WriteDIMACSExtended(&buf, ecnf); err != nil {
+		t.Fatalf("WriteDIMACSExtended failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "x-2 3 0\n") {
+		t.Errorf("Expected even parity to negate the first variable, got %q", buf.String())
+	}
+
+	again, err := ReadDIMACSExtended(&buf)
+	if err != nil {
+		t.Fatalf("Re-reading written DIMACS failed: %v", err)
+	}
+	if len(again.XORClauses) != 2 {
+		t.Fatalf("Expected 2 XORs, got %d", len(again.XORClauses))
+	}
+	for i, xor := range again.XORClauses {
+		if xor.String() != ecnf.XORClauses[i].String() {
+			t.Errorf("XOR %d mismatch: %s vs %s", i, xor, ecnf.XORClauses[i])
+		}
+	}
+}
+
+func TestDIMACSReader_Streaming(t *testing.T) {
+	reader := NewDIMACSReader(strings.NewReader("c hdr\np cnf 3 2\n1 2 0 -3 0\n"))
+	vars, clauses, err := reader.Header()
+	if err != nil || vars != 3 || clauses != 2 {
+		t.Fatalf("Header() = %d, %d, %v", vars, clauses, err)
+	}
+
+	count := 0
+	for {
+		clause, xor, err := reader.Next()
+		if err != nil {
+			break
+		}
+		if clause == nil || xor != nil {
+			t.Fatal("Expected plain clauses only")
+		}
+		count++
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 streamed clauses, got %d", count)
+	}
+	if reader.Variables().Len() != 3 {
+		t.Errorf("Expected the used variables to be mapped, got %d", reader.Variables().Len())
+	}
+}
+
+func TestReadDIMACS_Empty(t *testing.T) {
+	cnf, err := ReadDIMACS(strings.NewReader("p cnf 0 0\n"))
+	if err != nil || len(cnf.Clauses) != 0 {
+		t.Fatalf("Expected an empty formula, got %v (%v)", cnf, err)
+	}
+
+	// A declared count names nothing until literals use it
+	reader := NewDIMACSReader(strings.NewReader("p cnf 2000000000 1\n1 0\n"))
+	if _, err := reader.ReadCNF(); err != nil {
+		t.Fatalf("ReadCNF failed: %v", err)
+	}
+	if reader.Variables().Len() != 1 {
+		t.Errorf("Expected 1 mapped variable, got %d", reader.Variables().Len())
+	}
+}
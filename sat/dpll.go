@@ -1,6 +1,7 @@
 package sat
 
 import (
+	"context"
 	"time"
 
 	"github.com/xDarkicex/logic/core"
@@ -12,6 +13,10 @@ type DPLLSolver struct {
 	assignment Assignment
 	cnf        *CNF
 	startTime  time.Time
+
+	ctx      context.Context // Context of the running SolveContext call
+	progress progressReporter
+	reason   UnknownReason // Why the running solve stopped early
 }
 
 // NewDPLLSolver creates a new DPLL solver
@@ -38,6 +43,7 @@ func (d *DPLLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 	d.cnf = cnf
 	d.assignment = make(Assignment)
 	d.statistics = SolverStatistics{}
+	d.progress.start()
 
 	// Set up timeout channel if needed
 	var timeoutChan <-chan time.Time
@@ -46,11 +52,17 @@ func (d *DPLLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 	}
 
 	result := &SolverResult{}
+	defer result.setStatus()
+	d.reason = ReasonNone
 
 	// Start DPLL algorithm
-	satisfiable, err := d.dpll(timeoutChan)
+	var done <-chan struct{}
+	if d.ctx != nil {
+		done = d.ctx.Done()
+	}
+	satisfiable, err := d.dpll(timeoutChan, done)
 	if err != nil {
-		result.Error = err
+		result.Error, result.Reason = err, d.reason
 		return result
 	}
 
@@ -66,11 +78,14 @@ func (d *DPLLSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverRe
 }
 
 // dpll implements the core DPLL algorithm
-func (d *DPLLSolver) dpll(timeoutChan <-chan time.Time) (bool, error) {
-	// Check for timeout
+func (d *DPLLSolver) dpll(timeoutChan <-chan time.Time, done <-chan struct{}) (bool, error) {
+	// Check for timeout and cancellation
 	select {
 	case <-timeoutChan:
+		d.reason = ReasonTimeout
 		return false, core.NewLogicError("sat", "DPLLSolver.dpll", "timeout exceeded")
+	case <-done:
+		return false, d.ctx.Err()
 	default:
 	}
 
@@ -102,6 +117,9 @@ func (d *DPLLSolver) dpll(timeoutChan <-chan time.Time) (bool, error) {
 	}
 
 	d.statistics.Decisions++
+	if d.statistics.Decisions&63 == 0 {
+		d.progress.poll(d.statistics, d.startTime)
+	}
 
 	// Step 5: Try both assignments (backtracking search)
 	for _, value := range []bool{true, false} {
@@ -112,7 +130,7 @@ func (d *DPLLSolver) dpll(timeoutChan <-chan time.Time) (bool, error) {
 		d.assignment[decisionVar] = value
 
 		// Recursive call
-		result, err := d.dpll(timeoutChan)
+		result, err := d.dpll(timeoutChan, done)
 		if err != nil {
 			return false, err
 		}
@@ -206,10 +224,18 @@ func (d *DPLLSolver) pureLiteralElimination() error {
 	return nil
 }
 
-// allClausesSatisfied checks if all clauses are satisfied
+// allClausesSatisfied checks if every clause has a true literal. Unlike
+// Assignment.Satisfies, unassigned literals do not count.
 func (d *DPLLSolver) allClausesSatisfied() bool {
 	for _, clause := range d.cnf.Clauses {
-		if !d.assignment.Satisfies(clause) {
+		satisfied := false
+		for _, lit := range clause.Literals {
+			if value, ok := d.assignment[lit.Variable]; ok && value != lit.Negated {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
 			return false
 		}
 	}
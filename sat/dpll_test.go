@@ -0,0 +1,46 @@
+package sat
+
+import "testing"
+
+func TestDPLLSolver_AllClausesSatisfiedIgnoresUnassigned(t *testing.T) {
+	dpll := NewDPLLSolver()
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	dpll.cnf = cnf
+	dpll.assignment = make(Assignment)
+
+	if dpll.allClausesSatisfied() {
+		t.Error("(A ∨ B) is not satisfied while A and B are unassigned")
+	}
+	dpll.assignment["A"] = false
+	if dpll.allClausesSatisfied() {
+		t.Error("(A ∨ B) is not satisfied while B is unassigned")
+	}
+	dpll.assignment["B"] = true
+	if !dpll.allClausesSatisfied() {
+		t.Error("B=true should satisfy (A ∨ B)")
+	}
+}
+
+func TestDPLLSolver_ModelIsComplete(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false), L("C", false)))
+	cnf.AddClause(NewClause(L("A", true), L("B", true)))
+	cnf.AddClause(NewClause(L("B", true), L("C", true)))
+
+	result := NewDPLLSolver().Solve(cnf)
+	if !result.Satisfiable {
+		t.Fatal("expected SAT")
+	}
+	for _, clause := range cnf.Clauses {
+		satisfied := false
+		for _, lit := range clause.Literals {
+			if value, ok := result.Assignment[lit.Variable]; ok && value != lit.Negated {
+				satisfied = true
+			}
+		}
+		if !satisfied {
+			t.Errorf("model %v does not satisfy %v", result.Assignment, clause.Literals)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package sat
 
 import (
-	"fmt"
+	"context"
+	"time"
+	"unsafe"
 
 	"github.com/xDarkicex/memory"
 )
@@ -13,6 +15,7 @@ import (
 //
 // This implements the DPLL(T) architecture: SAT provides Boolean skeletons,
 // theory plugins provide domain-specific consistency checks.
+// DifferenceLogic and LinearArithmetic are the arithmetic plugins.
 //
 // Literal encoding: var*2 = positive, var*2+1 = negative.
 // To decode: variable = lit/2; negated = lit%2 == 1.
@@ -26,39 +29,40 @@ type TheoryPlugin interface {
 	Name() string
 }
 
-// TheorySolver wraps a CDCL SAT solver with integer-indexed variables
-// and theory plugin support. All internal arrays use Pool backing.
-// Variable indices are dense [0..numVars), matching BDD variable indices
-// from the BDDCtx bridge (§B.1).
+// TheorySolver wraps a DenseSolver with integer-indexed variables and
+// theory plugin support. Variable indices are dense [0..numVars), matching
+// BDD variable indices from the BDDCtx bridge (§B.1), and the plugin
+// literal encoding is the solver's own, so no names are involved.
 type TheorySolver struct {
-	cdcl    *CDCLSolver
+	core    *DenseSolver
 	plugins []TheoryPlugin
 
-	varNames []string // varNames[i] = variable name string
-	numVars  int32
-	cnf      *CNF
-	pool     *memory.Pool
+	numVars int32
+	pool    *memory.Pool
+	err     error // Error that stopped the last solve
 }
 
 // NewTheorySolver creates a DPLL(T) solver with n integer-indexed variables.
 func NewTheorySolver(n int, pool *memory.Pool) *TheorySolver {
-	names := memory.MustPoolSlice[string](pool, n)
-	names = names[:n]
-	for i := range names {
-		names[i] = fmt.Sprintf("v%d", i)
+	ts := &TheorySolver{
+		core:    NewDenseSolverWithPool(pool),
+		numVars: int32(n),
+		pool:    pool,
 	}
-	return &TheorySolver{
-		cdcl:     NewCDCLSolver(),
-		varNames: names,
-		numVars:  int32(n),
-		cnf:      NewCNF(),
-		pool:     pool,
+	ts.addVars()
+	return ts
+}
+
+// addVars creates the solver's variables
+func (ts *TheorySolver) addVars() {
+	for ts.core.NumVars() < int(ts.numVars) {
+		ts.core.NewVar()
 	}
 }
 
-// AddClause adds a clause from integer literals (positive=var, negative=¬var).
+// AddClause adds a clause from integer literals (var*2 = var, var*2+1 = ¬var).
 func (ts *TheorySolver) AddClause(lits []int32) {
-	ts.cnf.AddClause(ts.litsToClause(lits))
+	ts.core.AddClauseLits(ts.toLits(lits))
 }
 
 // RegisterPlugin adds a theory plugin. Plugins are checked in order after
@@ -71,88 +75,86 @@ func (ts *TheorySolver) RegisterPlugin(p TheoryPlugin) {
 func (ts *TheorySolver) NumVars() int32 { return ts.numVars }
 
 // Solve attempts to find a satisfying assignment.
-// Returns the satisfying assignment (nil if UNSAT) and status. CC=7.
-// Each theory lemma causes a fresh SAT call since the CDCL solver
-// reinitializes on each Solve; the accumulated CNF captures all lemmas.
-func (ts *TheorySolver) Solve() ([]int8, bool) {
+// Returns the satisfying assignment (nil unless SAT) and status; Reason
+// explains a StatusUnknown. CC=1.
+// Each theory lemma is added to the solver, which then resumes
+// incrementally with its learned clauses and heuristic state intact.
+func (ts *TheorySolver) Solve() ([]int8, SolverStatus) {
+	return ts.SolveContext(context.Background())
+}
+
+// SolveContext is Solve stopping promptly once ctx is done, in which case
+// the status is StatusUnknown and the assignment nil. CC=5.
+func (ts *TheorySolver) SolveContext(ctx context.Context) ([]int8, SolverStatus) {
 	for {
-		result := ts.cdcl.Solve(ts.cnf)
-		if !result.Satisfiable {
-			return nil, false
+		sat, err := ts.core.SolveLitsContext(ctx, nil)
+		ts.err = err
+		switch {
+		case err != nil:
+			return nil, StatusUnknown
+		case !sat:
+			return nil, StatusUNSAT
 		}
-		assign := ts.assignmentToInts(result.Assignment)
+		assign := ts.modelToInts()
 		if ts.checkPlugins(assign) {
-			return assign, true
+			return assign, StatusSAT
 		}
 	}
 }
 
+// Reason returns why the last solve ended with StatusUnknown, or
+// ReasonNone if it decided the formula. CC=3.
+func (ts *TheorySolver) Reason() UnknownReason {
+	if ts.err == nil {
+		return ReasonNone
+	}
+	if ts.core.stopped != ReasonNone {
+		return ts.core.stopped
+	}
+	return reasonOf(ts.err)
+}
+
+// SetProgress reports the solver's statistics to fn about once per
+// interval during later solves. A nil fn removes the callback. CC=1.
+func (ts *TheorySolver) SetProgress(fn ProgressFunc, interval time.Duration) {
+	ts.core.SetProgress(fn, interval)
+}
+
 // checkPlugins runs all theory plugins against the assignment.
 // Returns true if all plugins are satisfied. CC=4.
 func (ts *TheorySolver) checkPlugins(assign []int8) bool {
 	for _, p := range ts.plugins {
 		ok, lemma := p.Check(assign)
 		if !ok {
-			cl := ts.litsToClause(lemma)
-			ts.cnf.AddClause(cl)
+			ts.core.AddClauseLits(ts.toLits(lemma))
 			return false
 		}
 	}
 	return true
 }
 
-// litsToClause converts integer literals to a Clause via ShardedFreeList.
-// Encoding: var*2 = positive, var*2+1 = negative. CC=3.
-func (ts *TheorySolver) litsToClause(lits []int32) *Clause {
-	literals := memory.MustPoolSlice[Literal](ts.pool, len(lits))
-	literals = literals[:len(lits)]
-	for i, enc := range lits {
-		v := enc / 2
-		neg := enc%2 == 1
-		literals[i] = Literal{Variable: ts.varNames[v], Negated: neg}
-	}
-	return NewClause(literals...)
+// toLits reinterprets plugin literals as solver literals; the encodings
+// coincide. CC=1.
+func (ts *TheorySolver) toLits(lits []int32) []Lit {
+	return unsafe.Slice((*Lit)(unsafe.SliceData(lits)), len(lits))
 }
 
-// assignmentToInts converts the string-based assignment to []int8. CC=4.
-func (ts *TheorySolver) assignmentToInts(assign Assignment) []int8 {
+// modelToInts copies the solver's model to a pool-backed []int8. CC=3.
+func (ts *TheorySolver) modelToInts() []int8 {
 	result := memory.MustPoolSlice[int8](ts.pool, int(ts.numVars))
 	result = result[:ts.numVars]
-	// Default unassigned to 0 (false) — SAT solver may leave don't-cares unset.
 	for i := range result {
 		result[i] = 0
-	}
-	for v, val := range assign {
-		idx := ts.varIndex(v)
-		if idx >= 0 && int(idx) < len(result) {
-			if val {
-				result[idx] = 1
-			} else {
-				result[idx] = 0
-			}
+		if ts.core.Value(int32(i)) {
+			result[i] = 1
 		}
 	}
 	return result
 }
 
-// varIndex returns the integer index for a variable name, or -1. CC=2.
-func (ts *TheorySolver) varIndex(name string) int32 {
-	if len(name) < 2 || name[0] != 'v' {
-		return -1
-	}
-	var idx int32
-	for i := 1; i < len(name); i++ {
-		idx = idx*10 + int32(name[i]-'0')
-	}
-	if idx < ts.numVars {
-		return idx
-	}
-	return -1
-}
-
-// Reset clears the solver for reuse. CC=2.
+// Reset clears the solver for reuse. CC=1.
 func (ts *TheorySolver) Reset() {
-	ts.cdcl.Reset()
-	ts.cnf = NewCNF()
+	ts.core.Reset()
+	ts.addVars()
 	ts.plugins = ts.plugins[:0]
 }
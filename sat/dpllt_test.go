@@ -24,8 +24,8 @@ func TestTheorySolverBasic(t *testing.T) {
 	ts.AddClause([]int32{0*2 + 1, 2 * 2})     // ¬x0 ∨ x2
 	ts.AddClause([]int32{1*2 + 1})             // ¬x1
 
-	assign, ok := ts.Solve()
-	if !ok {
+	assign, status := ts.Solve()
+	if status != StatusSAT {
 		t.Fatal("expected satisfiable")
 	}
 	// x1 must be false (unit clause ¬x1).
@@ -47,8 +47,8 @@ func TestTheorySolverUnsat(t *testing.T) {
 	ts.AddClause([]int32{0 * 2})
 	ts.AddClause([]int32{0*2 + 1})
 
-	_, ok := ts.Solve()
-	if ok {
+	_, status := ts.Solve()
+	if status != StatusUNSAT {
 		t.Error("x0 ∧ ¬x0 should be UNSAT")
 	}
 }
@@ -60,8 +60,8 @@ func TestTheorySolverLarge(t *testing.T) {
 	for i := int32(0); i < 9; i++ {
 		ts.AddClause([]int32{i*2 + 1, (i + 1) * 2})
 	}
-	assign, ok := ts.Solve()
-	if !ok {
+	assign, status := ts.Solve()
+	if status != StatusSAT {
 		t.Fatal("expected satisfiable")
 	}
 	for i := int32(0); i < 10; i++ {
@@ -90,8 +90,8 @@ func TestTheorySolverPlugin(t *testing.T) {
 	ts.AddClause([]int32{0*2 + 1, 1 * 2})
 	ts.AddClause([]int32{0 * 2, 2 * 2})
 
-	assign, ok := ts.Solve()
-	if !ok {
+	assign, status := ts.Solve()
+	if status != StatusSAT {
 		t.Fatal("expected satisfiable")
 	}
 	// Theory forces x0 = true.
@@ -107,8 +107,8 @@ func TestTheorySolverReset(t *testing.T) {
 	ts.Reset()
 	ts.AddClause([]int32{0 * 2})
 	ts.AddClause([]int32{0*2 + 1})
-	_, ok := ts.Solve()
-	if ok {
+	_, status := ts.Solve()
+	if status != StatusUNSAT {
 		t.Error("should be UNSAT after reset and new clauses")
 	}
 }
@@ -0,0 +1,635 @@
+package sat
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/xDarkicex/gobdd"
+	"github.com/xDarkicex/logic/core"
+)
+
+// CardinalityEncoding selects the CNF encoding of cardinality constraints
+type CardinalityEncoding int
+
+const (
+	// SequentialCounterEncoding is the sequential counter of Sinz (2005),
+	// O(n·k) clauses and auxiliary variables
+	SequentialCounterEncoding CardinalityEncoding = iota
+	// TotalizerEncoding is the totalizer of Bailleux & Boufkhad (2003),
+	// O(n²) clauses but few variables for each unit of the bound
+	TotalizerEncoding
+	// CardinalityNetworkEncoding is Batcher's odd-even merge sorting
+	// network restricted to the comparators the first k+1 outputs depend
+	// on, in the spirit of the cardinality networks of Asín et al. (2011)
+	CardinalityNetworkEncoding
+)
+
+// String returns the encoding name
+func (c CardinalityEncoding) String() string {
+	switch c {
+	case SequentialCounterEncoding:
+		return "sequential counter"
+	case TotalizerEncoding:
+		return "totalizer"
+	case CardinalityNetworkEncoding:
+		return "cardinality network"
+	default:
+		return "unknown"
+	}
+}
+
+// PBEncoding selects the CNF encoding of pseudo-Boolean constraints
+type PBEncoding int
+
+const (
+	// BDDEncoding translates the constraint's reduced ordered BDD, built
+	// with gobdd, one auxiliary variable per node (Eén & Sörensson, 2006).
+	// Unit propagation is complete, but the BDD can grow large when the
+	// coefficients are large and distinct.
+	BDDEncoding PBEncoding = iota
+	// AdderEncoding sums the terms with a network of binary full and half
+	// adders and compares the sum with the bound (Warners, 1998). It is
+	// linear in the total size of the coefficients' binary digits.
+	AdderEncoding
+)
+
+// String returns the encoding name
+func (p PBEncoding) String() string {
+	switch p {
+	case BDDEncoding:
+		return "BDD"
+	case AdderEncoding:
+		return "adder"
+	default:
+		return "unknown"
+	}
+}
+
+// PBComparison is the relation of a pseudo-Boolean constraint to its bound
+type PBComparison int
+
+const (
+	PBLessEqual    PBComparison = iota // Σ aᵢ·lᵢ ≤ bound
+	PBGreaterEqual                     // Σ aᵢ·lᵢ ≥ bound
+	PBEqual                            // Σ aᵢ·lᵢ = bound
+)
+
+// String returns the comparison operator
+func (c PBComparison) String() string {
+	switch c {
+	case PBLessEqual:
+		return "<="
+	case PBGreaterEqual:
+		return ">="
+	case PBEqual:
+		return "="
+	default:
+		return "?"
+	}
+}
+
+// PBTerm is a term aᵢ·lᵢ of a pseudo-Boolean constraint, where the literal
+// counts as 1 when true and 0 when false
+type PBTerm struct {
+	Coefficient int64
+	Literal     Literal
+}
+
+// Encoding is the CNF translation of a constraint: clauses over the
+// constraint's literals and fresh auxiliary variables
+type Encoding struct {
+	Clauses   []*Clause
+	Auxiliary []string // Auxiliary variables, in creation order
+}
+
+// AddTo adds the encoding's clauses to cnf
+func (enc *Encoding) AddTo(cnf *CNF) {
+	for _, clause := range enc.Clauses {
+		cnf.AddClause(clause)
+	}
+}
+
+// add appends the clause of lits unless it is a tautology
+func (enc *Encoding) add(lits ...Literal) {
+	for i := range lits {
+		for j := i + 1; j < len(lits); j++ {
+			if lits[i].Equals(lits[j].Negate()) {
+				return
+			}
+		}
+	}
+	enc.Clauses = append(enc.Clauses, NewClause(lits...))
+}
+
+// Encoder translates cardinality and pseudo-Boolean constraints to CNF.
+// Auxiliary variables are named from a prefix and a counter, so every
+// encoding from one Encoder can be added to the same formula.
+type Encoder struct {
+	Cardinality CardinalityEncoding
+	PB          PBEncoding
+
+	prefix string
+	next   int
+}
+
+// NewEncoder creates an encoder whose auxiliary variables are named
+// prefix0, prefix1, ...; an empty prefix means "__enc_". It uses the
+// sequential counter and the BDD encoding.
+func NewEncoder(prefix string) *Encoder {
+	if prefix == "" {
+		prefix = "__enc_"
+	}
+	return &Encoder{prefix: prefix}
+}
+
+// newVar returns a fresh auxiliary variable of enc
+func (e *Encoder) newVar(enc *Encoding) Literal {
+	name := fmt.Sprintf("%s%d", e.prefix, e.next)
+	e.next++
+	enc.Auxiliary = append(enc.Auxiliary, name)
+	return Literal{Variable: name}
+}
+
+// AtMostK encodes that at most k of lits are true
+func (e *Encoder) AtMostK(lits []Literal, k int) *Encoding {
+	enc := &Encoding{}
+	e.atMost(enc, lits, k)
+	return enc
+}
+
+// AtLeastK encodes that at least k of lits are true
+func (e *Encoder) AtLeastK(lits []Literal, k int) *Encoding {
+	enc := &Encoding{}
+	e.atLeast(enc, lits, k)
+	return enc
+}
+
+// ExactlyK encodes that exactly k of lits are true, as the conjunction of
+// the at-most and at-least encodings
+func (e *Encoder) ExactlyK(lits []Literal, k int) *Encoding {
+	enc := &Encoding{}
+	e.atMost(enc, lits, k)
+	e.atLeast(enc, lits, k)
+	return enc
+}
+
+// atLeast encodes "at least k of lits" as "at most n-k of their negations"
+func (e *Encoder) atLeast(enc *Encoding, lits []Literal, k int) {
+	negated := make([]Literal, len(lits))
+	for i, lit := range lits {
+		negated[i] = lit.Negate()
+	}
+	e.atMost(enc, negated, len(lits)-k)
+}
+
+func (e *Encoder) atMost(enc *Encoding, lits []Literal, k int) {
+	switch {
+	case k < 0:
+		enc.add() // Unsatisfiable
+	case k >= len(lits):
+		// Always satisfied
+	case k == 0:
+		for _, lit := range lits {
+			enc.add(lit.Negate())
+		}
+	case e.Cardinality == TotalizerEncoding:
+		totalizer := NewTotalizer(lits, func() string { return e.newVar(enc).Variable })
+		enc.Clauses = append(enc.Clauses, totalizer.Clauses...)
+		enc.add(totalizer.Outputs[k].Negate())
+	case e.Cardinality == CardinalityNetworkEncoding:
+		e.cardinalityNetwork(enc, lits, k)
+	default:
+		e.sequentialCounter(enc, lits, k)
+	}
+}
+
+// sequentialCounter encodes "at most k of lits" for 0 < k < n: register
+// s[i][j] is true whenever more than j of lits[0..i] are true
+func (e *Encoder) sequentialCounter(enc *Encoding, lits []Literal, k int) {
+	n := len(lits)
+	s := make([][]Literal, n-1)
+	for i := range s {
+		s[i] = make([]Literal, k)
+		for j := range s[i] {
+			s[i][j] = e.newVar(enc)
+		}
+	}
+
+	enc.add(lits[0].Negate(), s[0][0])
+	for j := 1; j < k; j++ {
+		enc.add(s[0][j].Negate())
+	}
+	for i := 1; i < n-1; i++ {
+		enc.add(lits[i].Negate(), s[i][0])
+		enc.add(s[i-1][0].Negate(), s[i][0])
+		for j := 1; j < k; j++ {
+			enc.add(lits[i].Negate(), s[i-1][j-1].Negate(), s[i][j])
+			enc.add(s[i-1][j].Negate(), s[i][j])
+		}
+		enc.add(lits[i].Negate(), s[i-1][k-1].Negate())
+	}
+	enc.add(lits[n-1].Negate(), s[n-2][k-1].Negate())
+}
+
+// wireFalse is the constant false wire of a sorting network
+const wireFalse = -1
+
+// comparator is a two-wire comparator of a sorting network: hi is the
+// disjunction and lo the conjunction of its inputs a and b
+type comparator struct {
+	a, b, hi, lo int
+}
+
+// sortingNetwork is Batcher's odd-even merge sort over wires. Wires below
+// the input count are the inputs; wireFalse pads them to a power of two.
+type sortingNetwork struct {
+	comparators []comparator
+	wires       int
+}
+
+// compare adds a comparator, folding constant false inputs
+func (s *sortingNetwork) compare(a, b int) (int, int) {
+	if a == wireFalse {
+		return b, wireFalse
+	}
+	if b == wireFalse {
+		return a, wireFalse
+	}
+	c := comparator{a: a, b: b, hi: s.wires, lo: s.wires + 1}
+	s.wires += 2
+	s.comparators = append(s.comparators, c)
+	return c.hi, c.lo
+}
+
+// sort returns the wires of in sorted, true first
+func (s *sortingNetwork) sort(in []int) []int {
+	if len(in) == 1 {
+		return in
+	}
+	half := len(in) / 2
+	return s.merge(s.sort(in[:half]), s.sort(in[half:]))
+}
+
+// merge merges two sorted sequences of the same power-of-two length
+func (s *sortingNetwork) merge(a, b []int) []int {
+	m := len(a)
+	if m == 1 {
+		hi, lo := s.compare(a[0], b[0])
+		return []int{hi, lo}
+	}
+	v := s.merge(strided(a, 0), strided(b, 0))
+	w := s.merge(strided(a, 1), strided(b, 1))
+
+	out := make([]int, 2*m)
+	out[0] = v[0]
+	for i := 0; i < m-1; i++ {
+		out[2*i+1], out[2*i+2] = s.compare(v[i+1], w[i])
+	}
+	out[2*m-1] = w[m-1]
+	return out
+}
+
+// strided returns the elements of wires at even (from 0) or odd (from 1)
+// positions
+func strided(wires []int, from int) []int {
+	out := make([]int, 0, (len(wires)+1)/2)
+	for i := from; i < len(wires); i += 2 {
+		out = append(out, wires[i])
+	}
+	return out
+}
+
+// cardinalityNetwork encodes "at most k of lits" for 0 < k < n by sorting
+// lits and forbidding output k. Only comparators that outputs 0..k depend
+// on are encoded, and only in the direction that forces outputs true.
+func (e *Encoder) cardinalityNetwork(enc *Encoding, lits []Literal, k int) {
+	size := 1
+	for size < len(lits) {
+		size *= 2
+	}
+	in := make([]int, size)
+	for i := range in {
+		if i < len(lits) {
+			in[i] = i
+		} else {
+			in[i] = wireFalse
+		}
+	}
+	network := &sortingNetwork{wires: len(lits)}
+	out := network.sort(in)
+
+	if out[k] == wireFalse {
+		return // Fewer than k+1 inputs can never be true
+	}
+	needed := make([]bool, network.wires)
+	needed[out[k]] = true
+	for i := len(network.comparators) - 1; i >= 0; i-- {
+		c := network.comparators[i]
+		if needed[c.hi] || needed[c.lo] {
+			needed[c.a], needed[c.b] = true, true
+		}
+	}
+
+	wire := make([]Literal, network.wires)
+	copy(wire, lits)
+	for _, c := range network.comparators {
+		if needed[c.hi] {
+			wire[c.hi] = e.newVar(enc)
+			enc.add(wire[c.a].Negate(), wire[c.hi])
+			enc.add(wire[c.b].Negate(), wire[c.hi])
+		}
+		if needed[c.lo] {
+			wire[c.lo] = e.newVar(enc)
+			enc.add(wire[c.a].Negate(), wire[c.b].Negate(), wire[c.lo])
+		}
+	}
+	enc.add(wire[out[k]].Negate())
+}
+
+// PseudoBoolean encodes the linear constraint Σ aᵢ·lᵢ ⋈ bound with the
+// encoder's PB encoding. Coefficients may be negative. If every
+// coefficient is equal after normalisation the constraint is encoded as a
+// cardinality constraint instead. An error is returned if the sum of the
+// coefficients' magnitudes overflows int64.
+func (e *Encoder) PseudoBoolean(terms []PBTerm, comparison PBComparison, bound int64) (*Encoding, error) {
+	enc := &Encoding{}
+	if comparison != PBGreaterEqual {
+		if err := e.pbLessEqual(enc, terms, bound, false); err != nil {
+			return nil, err
+		}
+	}
+	if comparison != PBLessEqual {
+		if err := e.pbLessEqual(enc, terms, bound, true); err != nil {
+			return nil, err
+		}
+	}
+	return enc, nil
+}
+
+// pbLessEqual encodes Σ aᵢ·lᵢ ≤ bound, or Σ aᵢ·lᵢ ≥ bound if flip is set,
+// after normalising to positive coefficients and a ≤ comparison
+func (e *Encoder) pbLessEqual(enc *Encoding, terms []PBTerm, bound int64, flip bool) error {
+	overflow := core.NewLogicError("sat", "Encoder.PseudoBoolean", "coefficients overflow int64")
+
+	// Σ aᵢ·lᵢ ≥ b  ⇔  Σ -aᵢ·lᵢ ≤ -b, and a·l = a + (-a)·¬l for a < 0
+	normal := make([]PBTerm, 0, len(terms))
+	var total int64
+	if flip {
+		if bound == math.MinInt64 {
+			return overflow
+		}
+		bound = -bound
+	}
+	for _, term := range terms {
+		a, lit := term.Coefficient, term.Literal
+		if a == math.MinInt64 {
+			return overflow
+		}
+		if flip {
+			a = -a
+		}
+		if a == 0 {
+			continue
+		}
+		if a < 0 {
+			if bound > 0 && bound > math.MaxInt64+a {
+				// The bound is beyond any reachable sum; it stays so
+				bound = math.MaxInt64
+			} else {
+				bound -= a
+			}
+			a, lit = -a, lit.Negate()
+		}
+		if total > math.MaxInt64-a {
+			return overflow
+		}
+		total += a
+		normal = append(normal, PBTerm{Coefficient: a, Literal: lit})
+	}
+
+	switch {
+	case bound < 0:
+		enc.add() // Unsatisfiable
+		return nil
+	case total <= bound:
+		return nil // Always satisfied
+	}
+	// A term heavier than the bound must be false
+	kept := normal[:0]
+	for _, term := range normal {
+		if term.Coefficient > bound {
+			enc.add(term.Literal.Negate())
+		} else {
+			kept = append(kept, term)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+
+	uniform := true
+	for _, term := range kept {
+		uniform = uniform && term.Coefficient == kept[0].Coefficient
+	}
+	if uniform {
+		lits := make([]Literal, len(kept))
+		for i, term := range kept {
+			lits[i] = term.Literal
+		}
+		e.atMost(enc, lits, int(bound/kept[0].Coefficient))
+		return nil
+	}
+
+	if e.PB == AdderEncoding {
+		e.adder(enc, kept, bound)
+	} else {
+		e.bdd(enc, kept, bound)
+	}
+	return nil
+}
+
+// bdd encodes Σ aᵢ·lᵢ ≤ bound for positive coefficients through its BDD.
+// Each internal node n testing lᵢ gets a variable with n ∧ lᵢ → hi and
+// n ∧ ¬lᵢ → lo; the root is asserted.
+func (e *Encoder) bdd(enc *Encoding, terms []PBTerm, bound int64) {
+	// Heavy terms first keep the BDD small
+	sort.SliceStable(terms, func(i, j int) bool {
+		return terms[i].Coefficient > terms[j].Coefficient
+	})
+	suffix := make([]int64, len(terms)+1)
+	for i := len(terms) - 1; i >= 0; i-- {
+		suffix[i] = suffix[i+1] + terms[i].Coefficient
+	}
+
+	pool := mustCreatePool()
+	defer pool.Free()
+	b := gobdd.New(len(terms), pool)
+
+	type key struct {
+		i    int
+		rest int64
+	}
+	memo := make(map[key]gobdd.NodeID)
+	var build func(i int, rest int64) gobdd.NodeID
+	build = func(i int, rest int64) gobdd.NodeID {
+		switch {
+		case rest < 0:
+			return gobdd.False
+		case suffix[i] <= rest:
+			return gobdd.True
+		}
+		k := key{i, rest}
+		if node, ok := memo[k]; ok {
+			return node
+		}
+		hi := build(i+1, rest-terms[i].Coefficient)
+		lo := build(i+1, rest)
+		node := b.ITE(b.Var(int32(i)), hi, lo)
+		memo[k] = node
+		return node
+	}
+	root := build(0, bound)
+
+	nodes := make(map[gobdd.NodeID]Literal)
+	var literal func(node gobdd.NodeID) Literal
+	literal = func(node gobdd.NodeID) Literal {
+		if lit, ok := nodes[node]; ok {
+			return lit
+		}
+		lit := e.newVar(enc)
+		nodes[node] = lit
+		term := terms[b.VarOf(node)].Literal
+		for _, branch := range []struct {
+			child gobdd.NodeID
+			test  Literal
+		}{{b.High(node), term}, {b.Low(node), term.Negate()}} {
+			switch branch.child {
+			case gobdd.True:
+			case gobdd.False:
+				enc.add(lit.Negate(), branch.test.Negate())
+			default:
+				enc.add(lit.Negate(), branch.test.Negate(), literal(branch.child))
+			}
+		}
+		return lit
+	}
+
+	switch root {
+	case gobdd.True:
+	case gobdd.False:
+		enc.add()
+	default:
+		enc.add(literal(root))
+	}
+}
+
+// adder encodes Σ aᵢ·lᵢ ≤ bound for positive coefficients by summing the
+// terms in binary: bucket j holds the literals worth 2ʲ, reduced by full
+// and half adders to a single sum bit with carries into bucket j+1
+func (e *Encoder) adder(enc *Encoding, terms []PBTerm, bound int64) {
+	var buckets [][]Literal
+	for _, term := range terms {
+		for j := 0; term.Coefficient>>j != 0; j++ {
+			if term.Coefficient>>j&1 == 1 {
+				for len(buckets) <= j {
+					buckets = append(buckets, nil)
+				}
+				buckets[j] = append(buckets[j], term.Literal)
+			}
+		}
+	}
+
+	// bits[j] is sum bit j; present[j] is false where the bit is constant 0
+	var bits []Literal
+	var present []bool
+	for j := 0; j < len(buckets); j++ {
+		bucket := buckets[j]
+		for len(bucket) >= 2 {
+			var sum, carry Literal
+			if len(bucket) >= 3 {
+				sum, carry = e.fullAdder(enc, bucket[0], bucket[1], bucket[2])
+				bucket = append(bucket[3:], sum)
+			} else {
+				sum, carry = e.halfAdder(enc, bucket[0], bucket[1])
+				bucket = []Literal{sum}
+			}
+			if j+1 == len(buckets) {
+				buckets = append(buckets, nil)
+			}
+			buckets[j+1] = append(buckets[j+1], carry)
+		}
+		if len(bucket) == 1 {
+			bits, present = append(bits, bucket[0]), append(present, true)
+		} else {
+			bits, present = append(bits, Literal{}), append(present, false)
+		}
+	}
+
+	// sum > bound iff at the highest bit where they differ the sum has a 1
+	// and the bound a 0; forbid each such j
+	for j := range bits {
+		if !present[j] || bound>>j&1 == 1 {
+			continue
+		}
+		lits := []Literal{bits[j].Negate()}
+		satisfied := false
+		for i := j + 1; i < 63; i++ {
+			if bound>>i&1 == 0 {
+				continue
+			}
+			if i >= len(bits) || !present[i] {
+				satisfied = true // Sum bit i is 0, below the bound's 1
+				break
+			}
+			lits = append(lits, bits[i].Negate())
+		}
+		if !satisfied {
+			enc.add(lits...)
+		}
+	}
+}
+
+// fullAdder returns the sum and carry bits of a + b + c
+func (e *Encoder) fullAdder(enc *Encoding, a, b, c Literal) (Literal, Literal) {
+	sum, carry := e.newVar(enc), e.newVar(enc)
+	// sum ↔ a ⊕ b ⊕ c
+	for mask := 0; mask < 8; mask++ {
+		// The clause applies when the inputs set in mask are true and the
+		// others false
+		in := []Literal{a, b, c}
+		odd := false
+		for i := range in {
+			if mask>>i&1 == 1 {
+				in[i] = in[i].Negate()
+				odd = !odd
+			}
+		}
+		if odd {
+			enc.add(append(in, sum)...)
+		} else {
+			enc.add(append(in, sum.Negate())...)
+		}
+	}
+	// carry ↔ at least two of a, b, c
+	for _, pair := range [][2]Literal{{a, b}, {a, c}, {b, c}} {
+		enc.add(pair[0].Negate(), pair[1].Negate(), carry)
+		enc.add(pair[0], pair[1], carry.Negate())
+	}
+	return sum, carry
+}
+
+// halfAdder returns the sum and carry bits of a + b
+func (e *Encoder) halfAdder(enc *Encoding, a, b Literal) (Literal, Literal) {
+	sum, carry := e.newVar(enc), e.newVar(enc)
+	// sum ↔ a ⊕ b
+	enc.add(a.Negate(), b.Negate(), sum.Negate())
+	enc.add(a, b, sum.Negate())
+	enc.add(a.Negate(), b, sum)
+	enc.add(a, b.Negate(), sum)
+	// carry ↔ a ∧ b
+	enc.add(a.Negate(), b.Negate(), carry)
+	enc.add(a, carry.Negate())
+	enc.add(b, carry.Negate())
+	return sum, carry
+}
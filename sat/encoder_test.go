@@ -0,0 +1,122 @@
+package sat
+
+import (
+	"fmt"
+	"testing"
+)
+
+// checkEncoding verifies that enc, under every assignment of the variables
+// in names, is satisfiable exactly when holds reports the constraint true
+func checkEncoding(t *testing.T, label string, enc *Encoding, names []string, holds func(Assignment) bool) {
+	t.Helper()
+	solver := NewCDCLSolver()
+	for _, clause := range enc.Clauses {
+		solver.AddClause(NewClause(clause.Literals...))
+	}
+	allModels(names, func(model Assignment) {
+		assumptions := make([]Literal, len(names))
+		for i, name := range names {
+			assumptions[i] = Literal{Variable: name, Negated: !model[name]}
+		}
+		result := solver.SolveAssuming(assumptions)
+		if result.Error != nil {
+			t.Fatalf("%s: solve failed: %v", label, result.Error)
+		}
+		if want := holds(model); result.Satisfiable != want {
+			t.Fatalf("%s: under %v expected satisfiable=%v", label, model, want)
+		}
+	})
+}
+
+func TestEncoder_Cardinality(t *testing.T) {
+	names := []string{"A", "B", "C", "D", "E", "F"}
+	lits := []Literal{L("A", false), L("B", true), L("C", false), L("D", false), L("E", true), L("F", false)}
+	trueCount := func(model Assignment) int {
+		count := 0
+		for _, lit := range lits {
+			if model[lit.Variable] != lit.Negated {
+				count++
+			}
+		}
+		return count
+	}
+
+	for _, method := range []CardinalityEncoding{SequentialCounterEncoding, TotalizerEncoding, CardinalityNetworkEncoding} {
+		encoder := NewEncoder("")
+		encoder.Cardinality = method
+		for k := -1; k <= len(lits)+1; k++ {
+			k := k
+			checkEncoding(t, fmt.Sprintf("%v AtMostK(%d)", method, k), encoder.AtMostK(lits, k), names,
+				func(m Assignment) bool { return trueCount(m) <= k })
+			checkEncoding(t, fmt.Sprintf("%v AtLeastK(%d)", method, k), encoder.AtLeastK(lits, k), names,
+				func(m Assignment) bool { return trueCount(m) >= k })
+			checkEncoding(t, fmt.Sprintf("%v ExactlyK(%d)", method, k), encoder.ExactlyK(lits, k), names,
+				func(m Assignment) bool { return trueCount(m) == k })
+		}
+	}
+}
+
+func TestEncoder_Auxiliary(t *testing.T) {
+	encoder := NewEncoder("__card_")
+	lits := []Literal{L("A", false), L("B", false), L("C", false), L("D", false)}
+	first := encoder.AtMostK(lits, 2)
+	second := encoder.AtMostK(lits, 1)
+	seen := make(map[string]bool)
+	for _, name := range append(first.Auxiliary, second.Auxiliary...) {
+		if seen[name] || name[:7] != "__card_" {
+			t.Fatalf("Expected fresh __card_ variables, got %v and %v", first.Auxiliary, second.Auxiliary)
+		}
+		seen[name] = true
+	}
+
+	cnf := NewCNF()
+	first.AddTo(cnf)
+	second.AddTo(cnf)
+	cnf.AddClause(NewClause(L("A", false)))
+	cnf.AddClause(NewClause(L("B", false)))
+	if result := NewCDCLSolver().Solve(cnf); result.Satisfiable {
+		t.Errorf("Expected A, B and at most one of A..D to be unsatisfiable")
+	}
+}
+
+func TestEncoder_PseudoBoolean(t *testing.T) {
+	names := []string{"A", "B", "C", "D", "E"}
+	A, B, C, D, E := L("A", false), L("B", false), L("C", false), L("D", false), L("E", false)
+	testCases := []struct {
+		description string
+		pbCase
+	}{
+		{"weighted at most", pbCase{[]PBTerm{{3, A}, {2, B}, {2, C}, {1, D}}, PBLessEqual, 4}},
+		{"weighted at least", pbCase{[]PBTerm{{3, A}, {2, B}, {2, C}, {1, D}}, PBGreaterEqual, 5}},
+		{"weighted equality", pbCase{[]PBTerm{{3, A}, {2, B}, {2, C}, {1, D}, {1, E}}, PBEqual, 5}},
+		{"negated literals", pbCase{[]PBTerm{{4, A.Negate()}, {3, B}, {2, C.Negate()}, {1, E}}, PBLessEqual, 5}},
+		{"negative coefficients", pbCase{[]PBTerm{{-3, A}, {2, B}, {-1, C}, {5, D}}, PBGreaterEqual, 1}},
+		{"negative bound", pbCase{[]PBTerm{{-4, A}, {-2, B}, {1, C}, {-1, D}}, PBLessEqual, -3}},
+		{"equality with mixed signs", pbCase{[]PBTerm{{2, A}, {-3, B}, {4, C.Negate()}, {-1, E}}, PBEqual, 1}},
+		{"repeated and opposite literals", pbCase{[]PBTerm{{2, A}, {3, A}, {4, A.Negate()}, {1, B}}, PBGreaterEqual, 6}},
+		{"zero coefficient", pbCase{[]PBTerm{{0, A}, {2, B}, {2, C}}, PBEqual, 2}},
+		{"always true", pbCase{[]PBTerm{{1, A}, {2, B}, {3, C}}, PBLessEqual, 6}},
+		{"always false", pbCase{[]PBTerm{{1, A}, {2, B}, {3, C}}, PBGreaterEqual, 7}},
+		{"unreachable equality", pbCase{[]PBTerm{{2, A}, {4, B}, {6, C}}, PBEqual, 5}},
+		{"no terms", pbCase{nil, PBGreaterEqual, 0}},
+	}
+
+	for _, method := range []PBEncoding{BDDEncoding, AdderEncoding} {
+		encoder := NewEncoder("")
+		encoder.PB = method
+		for _, tc := range testCases {
+			enc, err := encoder.PseudoBoolean(tc.terms, tc.comparison, tc.bound)
+			if err != nil {
+				t.Fatalf("%v %s: PseudoBoolean failed: %v", method, tc.description, err)
+			}
+			checkEncoding(t, fmt.Sprintf("%v %s", method, tc.description), enc, names, func(m Assignment) bool {
+				return holdsPB(m, tc.terms, tc.comparison, tc.bound)
+			})
+		}
+	}
+
+	huge := []PBTerm{{Coefficient: 1 << 62, Literal: L("A", false)}, {Coefficient: 1 << 62, Literal: L("B", false)}}
+	if _, err := NewEncoder("").PseudoBoolean(huge, PBLessEqual, 1); err == nil {
+		t.Errorf("Expected an overflow error")
+	}
+}
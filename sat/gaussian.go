@@ -33,6 +33,8 @@ type GaussianEliminator struct {
 
 	// Statistics
 	stats GaussianStats
+
+	pool *memory.Pool // Backs the matrix and results
 }
 
 // GaussianStats tracks Gaussian elimination performance
@@ -49,6 +51,10 @@ type GaussianStats struct {
 
 // NewGaussianEliminator creates a new Gaussian eliminator
 func NewGaussianEliminator() *GaussianEliminator {
+	return newGaussianEliminator(nil)
+}
+
+func newGaussianEliminator(pool *memory.Pool) *GaussianEliminator {
 	return &GaussianEliminator{
 		maxMatrixRows:     300,  // Reasonable size limit
 		maxMatrixCols:     200,  // Reasonable size limit
@@ -61,6 +67,7 @@ func NewGaussianEliminator() *GaussianEliminator {
 		lastGaussian:     0,
 		disabled:         false,
 		eliminationCount: 0,
+		pool:             poolOr(pool),
 	}
 }
 
@@ -103,9 +110,10 @@ func (ge *GaussianEliminator) PerformGaussianElimination(ecnf *ExtendedCNF, assi
 		ge.lastGaussian = conflicts
 	}()
 
+	// Learned XOR clauses stay on the heap, where the collector sees them
 	result := &GaussianResult{
-		UnitsLearned:      memory.MustPoolSlice[Literal](satPool, 0),
-		XORClausesLearned: memory.MustPoolSlice[*XORClause](satPool, 0),
+		UnitsLearned:      memory.MustPoolSlice[Literal](ge.pool, 0),
+		XORClausesLearned: make([]*XORClause, 0),
 		ConflictFound:     false,
 	}
 
@@ -137,7 +145,7 @@ func (ge *GaussianEliminator) buildMatrix(xorClauses []*XORClause, assignment As
 	}
 
 	// Collect suitable XOR clauses
-	suitableXORs := memory.MustPoolSlice[*XORClause](satPool, 0)
+	suitableXORs := memory.MustPoolSlice[*XORClause](ge.pool, 0)
 	variableSet := make(map[string]bool)
 
 	for _, xor := range xorClauses {
@@ -170,7 +178,7 @@ func (ge *GaussianEliminator) buildMatrix(xorClauses []*XORClause, assignment As
 	}
 
 	// Build variable mapping
-	ge.matrixToVar = memory.MustPoolSlice[string](satPool, len(variableSet))
+	ge.matrixToVar = memory.MustPoolSlice[string](ge.pool, len(variableSet))
 	ge.varToMatrix = make(map[string]int)
 
 	for variable := range variableSet {
@@ -182,9 +190,9 @@ func (ge *GaussianEliminator) buildMatrix(xorClauses []*XORClause, assignment As
 	ge.matrixCols = len(ge.matrixToVar) + 1 // +1 for augmented column (RHS)
 
 	// Initialize matrix
-	ge.matrix = memory.MustPoolSlice[[]bool](satPool, ge.matrixRows)[:ge.matrixRows]
+	ge.matrix = memory.MustPoolSlice[[]bool](ge.pool, ge.matrixRows)[:ge.matrixRows]
 	for i := range ge.matrix {
-		ge.matrix[i] = memory.MustPoolSlice[bool](satPool, ge.matrixCols)[:ge.matrixCols]
+		ge.matrix[i] = memory.MustPoolSlice[bool](ge.pool, ge.matrixCols)[:ge.matrixCols]
 	}
 
 	// Fill matrix
@@ -267,7 +275,7 @@ func (ge *GaussianEliminator) eliminateRow(row1, row2 int) {
 // extractResults extracts unit propagations and learned XOR clauses
 func (ge *GaussianEliminator) extractResults(result *GaussianResult, assignment Assignment) {
 	for row := 0; row < ge.matrixRows; row++ {
-		activeVars := memory.MustPoolSlice[string](satPool, 0)
+		activeVars := memory.MustPoolSlice[string](ge.pool, 0)
 
 		// Count active variables in this row
 		for col := 0; col < ge.matrixCols-1; col++ {
@@ -120,3 +120,101 @@ func TestGaussianEliminator_AutoDisable(t *testing.T) {
 		t.Error("Should disable if extremely ineffective")
 	}
 }
+
+// buildExtendedCNF builds a formula from clauses and XOR constraints
+func buildExtendedCNF(clauses [][]Literal, xors []xorEncoding) *ExtendedCNF {
+	ecnf := NewExtendedCNF()
+	for _, lits := range clauses {
+		ecnf.AddClause(NewClause(lits...))
+	}
+	for _, xor := range xors {
+		ecnf.AddXORClause(NewXORClause(xor.variables, xor.parity))
+	}
+	return ecnf
+}
+
+// checkSolveExtended solves ecnf and checks the answer and the model
+func checkSolveExtended(t *testing.T, ecnf *ExtendedCNF, expectedSat bool) {
+	t.Helper()
+	result := NewCDCLSolver().SolveExtended(ecnf)
+	if result.Error != nil {
+		t.Fatalf("SolveExtended failed: %v", result.Error)
+	}
+	if result.Satisfiable != expectedSat {
+		t.Fatalf("Expected satisfiable=%v, got %v", expectedSat, result.Satisfiable)
+	}
+	if result.Satisfiable && !holdsExtended(result.Assignment, ecnf) {
+		t.Errorf("Model %v violates the formula", result.Assignment)
+	}
+}
+
+func TestSolveExtended_XORSystems(t *testing.T) {
+	testCases := []struct {
+		description string
+		clauses     [][]Literal
+		xors        []xorEncoding
+		expectedSat bool
+	}{
+		{
+			description: "consistent chain",
+			xors: []xorEncoding{
+				{[]string{"A", "B", "C"}, true}, {[]string{"C", "D"}, false}, {[]string{"D", "E", "F"}, true},
+			},
+			expectedSat: true,
+		},
+		{
+			description: "odd cycle of inequalities",
+			xors: []xorEncoding{
+				{[]string{"A", "B"}, true}, {[]string{"B", "C"}, true}, {[]string{"A", "C"}, true},
+			},
+			expectedSat: false,
+		},
+		{
+			description: "same variables with opposite parities",
+			xors:        []xorEncoding{{[]string{"A", "B", "C"}, true}, {[]string{"C", "B", "A"}, false}},
+			expectedSat: false,
+		},
+		{
+			description: "contradiction only in the sum of all rows",
+			xors: []xorEncoding{
+				{[]string{"A", "B", "C"}, true}, {[]string{"C", "D", "E"}, false},
+				{[]string{"A", "D"}, true}, {[]string{"B", "E"}, true},
+			},
+			expectedSat: false,
+		},
+		{
+			description: "consistent rows over shared variables",
+			xors: []xorEncoding{
+				{[]string{"A", "B", "C"}, true}, {[]string{"C", "D", "E"}, false},
+				{[]string{"A", "D"}, true}, {[]string{"B", "E"}, false},
+			},
+			expectedSat: true,
+		},
+		{
+			description: "clauses pin the free variables",
+			clauses:     [][]Literal{{L("A", false)}, {L("B", true)}, {L("D", false), L("E", false)}},
+			xors: []xorEncoding{
+				{[]string{"A", "B", "C"}, true}, {[]string{"C", "D", "E"}, false},
+				{[]string{"A", "D"}, true}, {[]string{"B", "E"}, false},
+			},
+			expectedSat: false,
+		},
+		{
+			description: "wide rows",
+			clauses:     [][]Literal{{L("X0", false), L("X8", false)}, {L("X3", true), L("X5", true)}},
+			xors: []xorEncoding{
+				{[]string{"X0", "X1", "X2", "X3", "X4", "X5"}, true},
+				{[]string{"X3", "X4", "X5", "X6", "X7", "X8"}, false},
+				{[]string{"X0", "X2", "X4", "X6", "X8"}, true},
+				{[]string{"X1", "X3", "X5", "X7"}, false},
+			},
+			expectedSat: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			checkSolveExtended(t, buildExtendedCNF(tc.clauses, tc.xors), tc.expectedSat)
+		})
+	}
+}
@@ -8,7 +8,7 @@ import (
 )
 
 const (
-	initVarCap      = 256  // initial variable capacity
+	initVarCap      = 32   // initial variable capacity, doubled on demand
 	phaseUnset      = -1   // phaseCache sentinel: no cached phase
 	phaseFalse      = 0    // phaseCache: cached false
 	phaseTrue       = 1    // phaseCache: cached true
@@ -47,13 +47,20 @@ type VSIDSHeuristic struct {
 	varIndex map[string]int
 	varNames []string
 	nextVar  int
-	cap      int // current capacity of all arrays
+	cap      int          // current capacity of all arrays
+	pool     *memory.Pool // Backs the arrays and the heap
 }
 
 // NewVSIDSHeuristic creates a VSIDS heuristic with LRB, polarity, anti-aging,
 // and a binary max-heap. All backing arrays are off-heap via Pool.
 func NewVSIDSHeuristic() *VSIDSHeuristic {
+	return newVSIDSHeuristic(nil)
+}
+
+// newVSIDSHeuristic creates a VSIDS heuristic allocating from pool
+func newVSIDSHeuristic(pool *memory.Pool) *VSIDSHeuristic {
 	v := &VSIDSHeuristic{
+		pool:         poolOr(pool),
 		increment:    1.0,
 		decay:        0.95,
 		lrbDecay:     0.8,
@@ -64,18 +71,18 @@ func NewVSIDSHeuristic() *VSIDSHeuristic {
 		cap:          initVarCap,
 	}
 	v.allocArrays(initVarCap)
-	v.heap = NewVarHeap(initVarCap, satPool)
+	v.heap = NewVarHeap(initVarCap, v.pool)
 	return v
 }
 
 // allocArrays allocates or re-allocates all Pool-backed arrays to the given capacity.
 func (v *VSIDSHeuristic) allocArrays(cap int) {
-	v.activity = memory.MustPoolSlice[float64](satPool, cap)[:cap]
-	v.lrbScores = memory.MustPoolSlice[float64](satPool, cap)[:cap]
-	v.polarity = memory.MustPoolSlice[float64](satPool, cap)[:cap]
-	v.phases = memory.MustPoolSlice[int8](satPool, cap)[:cap]
-	v.participated = memory.MustPoolSlice[int64](satPool, cap)[:cap]
-	v.varNames = memory.MustPoolSlice[string](satPool, cap)[:cap]
+	v.activity = memory.MustPoolSlice[float64](v.pool, cap)[:cap]
+	v.lrbScores = memory.MustPoolSlice[float64](v.pool, cap)[:cap]
+	v.polarity = memory.MustPoolSlice[float64](v.pool, cap)[:cap]
+	v.phases = memory.MustPoolSlice[int8](v.pool, cap)[:cap]
+	v.participated = memory.MustPoolSlice[int64](v.pool, cap)[:cap]
+	v.varNames = memory.MustPoolSlice[string](v.pool, cap)[:cap]
 	for i := range v.phases {
 		v.phases[i] = phaseUnset
 	}
@@ -91,33 +98,33 @@ func (v *VSIDSHeuristic) grow(minCap int) {
 		newCap = minCap
 	}
 
-	newActivity := memory.MustPoolSlice[float64](satPool, newCap)[:newCap]
+	newActivity := memory.MustPoolSlice[float64](v.pool, newCap)[:newCap]
 	copy(newActivity, v.activity)
 	v.activity = newActivity
 
-	newLRB := memory.MustPoolSlice[float64](satPool, newCap)[:newCap]
+	newLRB := memory.MustPoolSlice[float64](v.pool, newCap)[:newCap]
 	copy(newLRB, v.lrbScores)
 	v.lrbScores = newLRB
 
-	newPolarity := memory.MustPoolSlice[float64](satPool, newCap)[:newCap]
+	newPolarity := memory.MustPoolSlice[float64](v.pool, newCap)[:newCap]
 	copy(newPolarity, v.polarity)
 	v.polarity = newPolarity
 
-	newPhases := memory.MustPoolSlice[int8](satPool, newCap)[:newCap]
+	newPhases := memory.MustPoolSlice[int8](v.pool, newCap)[:newCap]
 	copy(newPhases, v.phases)
 	for i := v.cap; i < newCap; i++ {
 		newPhases[i] = phaseUnset
 	}
 	v.phases = newPhases
 
-	newPart := memory.MustPoolSlice[int64](satPool, newCap)[:newCap]
+	newPart := memory.MustPoolSlice[int64](v.pool, newCap)[:newCap]
 	copy(newPart, v.participated)
 	for i := v.cap; i < newCap; i++ {
 		newPart[i] = participateUnset
 	}
 	v.participated = newPart
 
-	newNames := memory.MustPoolSlice[string](satPool, newCap)[:newCap]
+	newNames := memory.MustPoolSlice[string](v.pool, newCap)[:newCap]
 	copy(newNames, v.varNames)
 	v.varNames = newNames
 
@@ -348,11 +355,15 @@ type LubyRestartStrategy struct {
 
 // NewLubyRestartStrategy creates a hybrid Luby + adaptive Glucose restart strategy.
 func NewLubyRestartStrategy() *LubyRestartStrategy {
+	return newLubyRestartStrategy(nil)
+}
+
+func newLubyRestartStrategy(pool *memory.Pool) *LubyRestartStrategy {
 	return &LubyRestartStrategy{
 		sequence:      []int{1, 1, 2, 1, 1, 2, 4, 1, 1, 2, 1, 1, 2, 4, 8},
 		index:         0,
 		baseUnit:      100,
-		glucoseWindow: memory.MustPoolSlice[int64](satPool, 50)[:50],
+		glucoseWindow: memory.MustPoolSlice[int64](poolOr(pool), 50)[:50],
 		windowSize:    50,
 		threshold:     1.4,
 	}
@@ -453,11 +464,17 @@ type ActivityBasedDeletion struct {
 	midThreshold      float64
 	localThreshold    float64
 	recentProtection  int64
+	pool              *memory.Pool // Backs Update's scratch arrays
 }
 
 // NewActivityBasedDeletion creates a tier-aware clause deletion policy.
 func NewActivityBasedDeletion() *ActivityBasedDeletion {
+	return newActivityBasedDeletion(nil)
+}
+
+func newActivityBasedDeletion(pool *memory.Pool) *ActivityBasedDeletion {
 	return &ActivityBasedDeletion{
+		pool:              poolOr(pool),
 		activityThreshold: 0.1,
 		lbdThreshold:      4,
 		sizeThreshold:     30,
@@ -537,7 +554,7 @@ func (a *ActivityBasedDeletion) Update(clauses []*Clause) {
 	var lbdSum int
 	var clauseCount int
 	lbdCounts := make(map[int]int)
-	activities := memory.MustPoolSlice[float64](satPool, len(clauses))
+	activities := memory.MustPoolSlice[float64](a.pool, len(clauses))
 
 	for _, clause := range clauses {
 		if clause.Learned {
@@ -0,0 +1,151 @@
+package sat
+
+import (
+	"context"
+	"time"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+// SolveAssuming solves the solver's formula with the given literals assumed
+// true. The formula is the one passed to the last Solve call plus every
+// clause added with AddClause since; a fresh or Reset solver starts from
+// the empty formula.
+//
+// Learned clauses, root-level assignments, variable activities and saved
+// phases carry over from one call to the next, so a sequence of related
+// queries costs far less than solving each from scratch. On UNSAT the
+// result's FailedAssumptions lists the assumptions the refutation used;
+// it is empty if the clauses are unsatisfiable without any assumption.
+//
+// Variable elimination is turned off while solving incrementally, since a
+// later clause may mention an eliminated variable. If an earlier Solve
+// call eliminated variables, SolveAssuming fails and the formula has to be
+// rebuilt after Reset. SolveAssuming does not write proofs.
+func (c *CDCLSolver) SolveAssuming(assumptions []Literal) *SolverResult {
+	return c.solveAssuming(nil, assumptions, 0)
+}
+
+// solveAssuming runs SolveAssuming, checking ctx for cancellation if it is
+// not nil and giving up after timeout if it is positive. Like solve, it
+// installs the context only once the solver is held.
+func (c *CDCLSolver) solveAssuming(ctx context.Context, assumptions []Literal, timeout time.Duration) (result *SolverResult) {
+	defer func() { result.setStatus() }()
+	if !c.isSolving.CompareAndSwap(false, true) {
+		return &SolverResult{
+			Error: core.NewLogicError("sat", "CDCLSolver.SolveAssuming", "concurrent Solve calls on the same solver instance are not allowed"),
+		}
+	}
+	defer c.isSolving.Store(false)
+	if ctx != nil {
+		c.setContext(ctx)
+		defer c.setContext(nil)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			result = recoverMemory("CDCLSolver.SolveAssuming", r)
+			result.Statistics = c.statistics
+		}
+	}()
+	if c.eliminated {
+		return &SolverResult{
+			Error: core.NewLogicError("sat", "CDCLSolver.SolveAssuming", "variables were eliminated by an earlier Solve call; Reset the solver and add the clauses again"),
+		}
+	}
+
+	c.startTime = time.Now()
+	c.progress.start()
+	c.statistics = SolverStatistics{LBDDistribution: make(map[int]int64)}
+	c.lbdSum = 0
+	c.glueClauseCount = 0
+	if c.cnf == nil {
+		c.cnf = NewCNF()
+	}
+	c.beginReconstruction(c.cnf, false)
+	defer func() { c.finishModel(result) }()
+	if !c.incremental && c.inprocessor != nil {
+		c.inprocessor.Configure(c.inprocessConfig.withoutElimination())
+	}
+	c.incremental = true
+
+	// Return to the root level. New clauses may already be unit or
+	// falsified there, and a previous call may have stopped before its
+	// last propagation, so root assignments are propagated again.
+	requeue := c.pendingClauses || c.qhead < len(c.trail.lits)
+	c.backtrack(0)
+	c.clearPropagationState()
+	if requeue {
+		c.requeueRootAssignments()
+		c.pendingClauses = false
+	}
+	if c.refuted {
+		c.statistics.TimeElapsed = time.Since(c.startTime).Nanoseconds()
+		return &SolverResult{
+			Satisfiable: false,
+			Statistics:  c.statistics,
+		}
+	}
+
+	c.assumptions = assumptions
+	defer func() { c.assumptions = nil }()
+	return c.search(timeout)
+}
+
+// pendingAssumption returns the first assumption that is not yet true. The
+// caller decides it if it is unassigned; if it is false, the assumptions
+// have failed.
+func (c *CDCLSolver) pendingAssumption() (Literal, bool) {
+	for _, lit := range c.assumptions {
+		value, assigned := c.assignment[lit.Variable]
+		if !assigned || value == lit.Negated {
+			return lit, true
+		}
+	}
+	return Literal{}, false
+}
+
+// analyzeFinal returns the assumptions that imply the negation of the
+// falsified assumption lit, lit included. Every decision on the trail is an
+// assumption, since free decisions are only made once all assumptions hold,
+// so the decisions lit depends on are exactly the failed assumptions.
+func (c *CDCLSolver) analyzeFinal(lit Literal) []Literal {
+	failed := []Literal{lit}
+	if c.trail.GetLevel(lit.Variable) == 0 {
+		return failed
+	}
+
+	seen := map[string]bool{lit.Variable: true}
+	stack := []string{lit.Variable}
+	for len(stack) > 0 {
+		variable := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		reason := c.trail.GetReason(variable)
+		if reason == nil {
+			failed = append(failed, Literal{Variable: variable, Negated: !c.assignment[variable]})
+			continue
+		}
+		for _, l := range reason.Literals {
+			if !seen[l.Variable] && c.trail.GetLevel(l.Variable) > 0 {
+				seen[l.Variable] = true
+				stack = append(stack, l.Variable)
+			}
+		}
+	}
+	return failed
+}
+
+// resetIncremental drops the state kept between SolveAssuming calls
+func (c *CDCLSolver) resetIncremental() {
+	if c.incremental && c.inprocessor != nil {
+		c.inprocessor.Configure(c.inprocessConfig)
+	}
+	if c.clauseDatabase != nil {
+		c.clauseDatabase.Clear()
+	}
+	c.assumptions = nil
+	c.incremental = false
+	c.pendingClauses = false
+	c.refuted = false
+	c.eliminated = false
+}
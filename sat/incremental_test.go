@@ -0,0 +1,278 @@
+package sat
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+func containsLiteral(lits []Literal, lit Literal) bool {
+	for _, l := range lits {
+		if l.Equals(lit) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSolveAssuming_Basic(t *testing.T) {
+	solver := NewCDCLSolver()
+	solver.AddClause(NewClause(L("A", false), L("B", false)))
+	solver.AddClause(NewClause(L("A", true), L("C", false)))
+
+	result := solver.SolveAssuming([]Literal{L("B", true)})
+	if !result.Satisfiable {
+		t.Fatal("Expected SAT under ¬B")
+	}
+	if !result.Assignment["A"] || !result.Assignment["C"] || result.Assignment["B"] {
+		t.Errorf("Expected A, C true and B false, got %v", result.Assignment)
+	}
+
+	result = solver.SolveAssuming([]Literal{L("D", false), L("B", true), L("C", true), L("E", false)})
+	if result.Satisfiable {
+		t.Fatal("Expected UNSAT under ¬B, ¬C")
+	}
+	if len(result.FailedAssumptions) != 2 ||
+		!containsLiteral(result.FailedAssumptions, L("B", true)) ||
+		!containsLiteral(result.FailedAssumptions, L("C", true)) {
+		t.Errorf("Expected failed assumptions {¬B, ¬C}, got %v", result.FailedAssumptions)
+	}
+
+	// The same solver answers again without the failing assumptions
+	result = solver.SolveAssuming([]Literal{L("C", true)})
+	if !result.Satisfiable || result.Assignment["A"] || !result.Assignment["B"] {
+		t.Errorf("Expected SAT with A false and B true under ¬C, got %v", result.Assignment)
+	}
+}
+
+func TestSolveAssuming_FailedAssumptions(t *testing.T) {
+	solver := NewCDCLSolver()
+	solver.AddClause(NewClause(L("A", true)))
+	solver.AddClause(NewClause(L("B", false), L("C", false)))
+
+	// Falsified at the root level
+	result := solver.SolveAssuming([]Literal{L("B", false), L("A", false)})
+	if result.Satisfiable || len(result.FailedAssumptions) != 1 || !result.FailedAssumptions[0].Equals(L("A", false)) {
+		t.Errorf("Expected failed assumptions {A}, got %v", result.FailedAssumptions)
+	}
+
+	// Contradictory assumptions
+	result = solver.SolveAssuming([]Literal{L("D", false), L("C", false), L("D", true)})
+	if result.Satisfiable || len(result.FailedAssumptions) != 2 ||
+		!containsLiteral(result.FailedAssumptions, L("D", false)) ||
+		!containsLiteral(result.FailedAssumptions, L("D", true)) {
+		t.Errorf("Expected failed assumptions {D, ¬D}, got %v", result.FailedAssumptions)
+	}
+
+	// An assumption over a variable outside the formula appears in the model
+	result = solver.SolveAssuming([]Literal{L("E", true)})
+	if !result.Satisfiable {
+		t.Fatal("Expected SAT")
+	}
+	if value, ok := result.Assignment["E"]; !ok || value {
+		t.Errorf("Expected E false in the model, got %v", result.Assignment)
+	}
+}
+
+func TestSolveAssuming_AddClauseBetweenCalls(t *testing.T) {
+	solver := NewCDCLSolver()
+	solver.AddClause(NewClause(L("A", false)))
+	if result := solver.SolveAssuming(nil); !result.Satisfiable {
+		t.Fatal("Expected SAT")
+	}
+
+	// Already unit at the root level when added
+	solver.AddClause(NewClause(L("A", true), L("B", false)))
+	result := solver.SolveAssuming(nil)
+	if !result.Satisfiable || !result.Assignment["B"] {
+		t.Fatalf("Expected B to be propagated, got %v", result.Assignment)
+	}
+
+	solver.AddClause(NewClause(L("B", true)))
+	for i := 0; i < 2; i++ {
+		result = solver.SolveAssuming([]Literal{L("C", false)})
+		if result.Satisfiable {
+			t.Fatal("Expected UNSAT")
+		}
+		if len(result.FailedAssumptions) != 0 {
+			t.Errorf("Expected no failed assumptions for an unsatisfiable formula, got %v", result.FailedAssumptions)
+		}
+	}
+
+	// Reset starts a new, empty formula
+	solver.Reset()
+	solver.AddClause(NewClause(L("B", true)))
+	if result := solver.SolveAssuming(nil); !result.Satisfiable {
+		t.Error("Expected SAT after Reset")
+	}
+}
+
+func TestSolveAssuming_AfterSolve(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	cnf.AddClause(NewClause(L("A", true), L("B", false)))
+
+	solver := NewCDCLSolver()
+	if result := solver.Solve(cnf); !result.Satisfiable {
+		t.Fatal("Expected SAT")
+	}
+	result := solver.SolveAssuming([]Literal{L("B", true)})
+	if result.Satisfiable || len(result.FailedAssumptions) != 1 {
+		t.Errorf("Expected UNSAT with failed assumption ¬B, got %v", result.FailedAssumptions)
+	}
+
+	solver.eliminated = true
+	result = solver.SolveAssuming(nil)
+	var logicErr *core.LogicError
+	if !errors.As(result.Error, &logicErr) {
+		t.Errorf("Expected LogicError after variable elimination, got %v", result.Error)
+	}
+}
+
+func TestSolveAssuming_KeepsLearnedClauses(t *testing.T) {
+	// Pigeonhole(4,3) guarded by selector S
+	solver := NewCDCLSolver()
+	solver.walkSolver = nil
+	pigeon := func(p, h int) string { return varName(p*3 + h) }
+	selector := "S"
+	for p := 0; p < 4; p++ {
+		solver.AddClause(NewClause(L(selector, true), L(pigeon(p, 0), false), L(pigeon(p, 1), false), L(pigeon(p, 2), false)))
+	}
+	for h := 0; h < 3; h++ {
+		for p := 0; p < 4; p++ {
+			for q := p + 1; q < 4; q++ {
+				solver.AddClause(NewClause(L(selector, true), L(pigeon(p, h), true), L(pigeon(q, h), true)))
+			}
+		}
+	}
+
+	first := solver.SolveAssuming([]Literal{L(selector, false)})
+	if first.Satisfiable || len(first.FailedAssumptions) != 1 {
+		t.Fatalf("Expected UNSAT under S, got %v", first.FailedAssumptions)
+	}
+	learned := solver.clauseDatabase.Size()
+	if learned == 0 {
+		t.Fatal("Expected learned clauses")
+	}
+
+	second := solver.SolveAssuming([]Literal{L(selector, false)})
+	if second.Satisfiable {
+		t.Fatal("Expected UNSAT under S")
+	}
+	if second.Statistics.Conflicts > first.Statistics.Conflicts {
+		t.Errorf("Expected the second call to reuse learned clauses: %d conflicts after %d",
+			second.Statistics.Conflicts, first.Statistics.Conflicts)
+	}
+	if result := solver.SolveAssuming(nil); !result.Satisfiable || result.Assignment[selector] {
+		t.Error("Expected SAT with the selector off")
+	}
+}
+
+// assumptionSequence is a script of queries for one incremental solver.
+// Each step first adds its clauses, so later queries see everything added
+// before them.
+var assumptionSequence = []struct {
+	description    string
+	clauses        [][]Literal
+	assumptions    []Literal
+	expectedSat    bool
+	expectedFailed []Literal
+}{
+	{
+		description: "implication chain under its head",
+		clauses: [][]Literal{
+			{L("A", true), L("B", false)},
+			{L("B", true), L("C", false)},
+			{L("C", true), L("D", false)},
+		},
+		assumptions: []Literal{L("A", false)},
+		expectedSat: true,
+	},
+	{
+		description:    "chain head against its tail",
+		assumptions:    []Literal{L("A", false), L("D", true)},
+		expectedFailed: []Literal{L("A", false), L("D", true)},
+	},
+	{
+		description:    "unrelated assumption is not blamed",
+		assumptions:    []Literal{L("E", false), L("A", false), L("D", true)},
+		expectedFailed: []Literal{L("A", false), L("D", true)},
+	},
+	{
+		description:    "exactly one of E and F",
+		clauses:        [][]Literal{{L("E", true), L("F", true)}, {L("E", false), L("F", false)}},
+		assumptions:    []Literal{L("E", true), L("F", true)},
+		expectedFailed: []Literal{L("E", true), L("F", true)},
+	},
+	{
+		description: "exactly one of E and F forces F",
+		assumptions: []Literal{L("E", true), L("A", false)},
+		expectedSat: true,
+	},
+	{
+		description:    "unit clause refutes an earlier satisfiable query",
+		clauses:        [][]Literal{{L("A", true)}},
+		assumptions:    []Literal{L("C", false), L("A", false)},
+		expectedFailed: []Literal{L("A", false)},
+	},
+	{
+		description:    "middle of the chain",
+		assumptions:    []Literal{L("B", false), L("C", true)},
+		expectedFailed: []Literal{L("B", false), L("C", true)},
+	},
+	{
+		description: "no assumptions",
+		expectedSat: true,
+	},
+	{
+		description:    "formula becomes unsatisfiable",
+		clauses:        [][]Literal{{L("B", false)}, {L("B", true), L("D", true)}},
+		assumptions:    []Literal{L("E", false)},
+		expectedFailed: []Literal{},
+	},
+}
+
+// checkAssumptionSequence runs assumptionSequence against solver
+func checkAssumptionSequence(t *testing.T, solver IncrementalSolver) {
+	t.Helper()
+	var clauses [][]Literal
+	for _, tc := range assumptionSequence {
+		for _, clause := range tc.clauses {
+			clauses = append(clauses, clause)
+			solver.AddClause(NewClause(clause...))
+		}
+		result := solver.SolveAssuming(tc.assumptions)
+		if result.Error != nil {
+			t.Fatalf("%s: SolveAssuming failed: %v", tc.description, result.Error)
+		}
+		if result.Satisfiable != tc.expectedSat {
+			t.Fatalf("%s: expected satisfiable=%v, got %v", tc.description, tc.expectedSat, result.Satisfiable)
+		}
+		if result.Satisfiable {
+			if !holdsClauses(result.Assignment, clauses) {
+				t.Errorf("%s: model %v violates the formula", tc.description, result.Assignment)
+			}
+			for _, lit := range tc.assumptions {
+				if value, ok := result.Assignment[lit.Variable]; !ok || value == lit.Negated {
+					t.Errorf("%s: model %v violates assumption %v", tc.description, result.Assignment, lit)
+				}
+			}
+			continue
+		}
+		if len(result.FailedAssumptions) != len(tc.expectedFailed) {
+			t.Errorf("%s: expected failed assumptions %v, got %v", tc.description, tc.expectedFailed, result.FailedAssumptions)
+			continue
+		}
+		for _, lit := range tc.expectedFailed {
+			if !containsLiteral(result.FailedAssumptions, lit) {
+				t.Errorf("%s: expected failed assumptions %v, got %v", tc.description, tc.expectedFailed, result.FailedAssumptions)
+				break
+			}
+		}
+	}
+}
+
+func TestSolveAssuming_Sequence(t *testing.T) {
+	checkAssumptionSequence(t, NewCDCLSolver())
+}
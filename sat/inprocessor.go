@@ -1,6 +1,7 @@
 package sat
 
 import (
+	"context"
 	"time"
 
 	"github.com/xDarkicex/memory"
@@ -9,10 +10,13 @@ import (
 // ModernInprocessor implements state-of-the-art inprocessing techniques
 type ModernInprocessor struct {
 	// Core components (will be implemented in subsequent steps)
-	vivifier   *ClauseVivifier
-	subsumer   *InprocessSubsumption
-	eliminator *BoundedVariableElimination
-	prober     *FailedLiteralProber
+	vivifier    *ClauseVivifier
+	subsumer    *InprocessSubsumption
+	eliminator  *BoundedVariableElimination
+	prober      *FailedLiteralProber
+	substituter *EquivalentLiteralSubstitution
+	blocker     *BlockedClauseElimination
+	adder       *BoundedVariableAddition
 
 	// Configuration
 	config     InprocessConfig
@@ -24,16 +28,32 @@ type ModernInprocessor struct {
 
 	// Performance tracking
 	startTime time.Time
+
+	// Proof output for formula changes (nil when not tracing)
+	proof *ProofWriter
+
+	ctx context.Context // Once done, the remaining techniques are skipped
+
+	pool *memory.Pool // Backs scratch arrays and new clauses
 }
 
 // NewModernInprocessor creates a new modern inprocessor with default settings
 func NewModernInprocessor() *ModernInprocessor {
+	return newModernInprocessor(nil)
+}
+
+func newModernInprocessor(pool *memory.Pool) *ModernInprocessor {
+	pool = poolOr(pool)
 	return &ModernInprocessor{
+		pool: pool,
 		// Initialize real components
-		vivifier:   NewClauseVivifier(),
-		subsumer:   NewInprocessSubsumption(),
-		eliminator: NewBoundedVariableElimination(), // Now real!
-		prober:     NewFailedLiteralProber(),        // Now real!
+		vivifier:    newClauseVivifier(pool),
+		subsumer:    newInprocessSubsumption(pool),
+		eliminator:  newBoundedVariableElimination(pool), // Now real!
+		prober:      newFailedLiteralProber(pool),        // Now real!
+		substituter: newEquivalentLiteralSubstitution(pool),
+		blocker:     newBlockedClauseElimination(pool),
+		adder:       newBoundedVariableAddition(pool),
 
 		config:       DefaultInprocessConfig(),
 		statistics:   InprocessStatistics{},
@@ -68,7 +88,7 @@ func (m *ModernInprocessor) Inprocess(cnf *CNF, assignment Assignment, level int
 	m.statistics.InprocessRuns++
 
 	// Phase 1: Clause vivification (when implemented)
-	if m.config.EnableVivification && m.vivifier != nil {
+	if m.config.EnableVivification && m.vivifier != nil && !contextDone(m.ctx) {
 		startTime := time.Now()
 		vivified := m.VivifyClauses(cnf.Clauses, assignment)
 		m.statistics.TimeInVivification += time.Since(startTime).Nanoseconds()
@@ -76,8 +96,18 @@ func (m *ModernInprocessor) Inprocess(cnf *CNF, assignment Assignment, level int
 		result.ClausesStrengthened += vivified
 	}
 
-	// Phase 2: Subsumption and strengthening (when implemented)
-	if m.config.EnableSubsumption && m.subsumer != nil {
+	// Phase 2: Equivalent literal substitution. Rewritten clauses would
+	// need hints, so LRAT proofs go without it.
+	if m.config.EnableEquivalentLiterals && m.substituter != nil && !m.proof.Format().IsLRAT() && !contextDone(m.ctx) {
+		startTime := time.Now()
+		substituted := m.substituter.Substitute(cnf, assignment)
+		m.statistics.TimeInSubstitution += time.Since(startTime).Nanoseconds()
+		m.statistics.VariablesSubstituted += int64(substituted)
+		result.VariablesSubstituted = substituted
+	}
+
+	// Phase 3: Subsumption and strengthening (when implemented)
+	if m.config.EnableSubsumption && m.subsumer != nil && !contextDone(m.ctx) {
 		startTime := time.Now()
 		subsumed := m.SubsumeAndStrengthen(cnf)
 		m.statistics.TimeInSubsumption += time.Since(startTime).Nanoseconds()
@@ -85,19 +115,42 @@ func (m *ModernInprocessor) Inprocess(cnf *CNF, assignment Assignment, level int
 		result.ClausesRemoved += subsumed
 	}
 
-	// Phase 3: Variable elimination (when implemented)
-	if m.config.EnableVariableElim && m.eliminator != nil {
+	// Phase 4: Variable elimination (when implemented)
+	if m.config.EnableVariableElim && m.eliminator != nil && !contextDone(m.ctx) {
 		startTime := time.Now()
-		eliminated := m.EliminateVariables(cnf.Variables, cnf)
+		eliminated := m.eliminateVariables(cnf, assignment)
 		m.statistics.TimeInVariableElim += time.Since(startTime).Nanoseconds()
 		result.VariablesEliminated = eliminated
 	}
 
-	// Phase 4: Failed literal probing (when implemented)
-	if m.config.EnableFailedLitProbing && m.prober != nil {
+	// Phase 5: Blocked clause elimination
+	if m.config.EnableBlockedClauseElim && m.blocker != nil && !contextDone(m.ctx) {
+		startTime := time.Now()
+		blocked := m.blocker.Eliminate(cnf, assignment)
+		m.statistics.TimeInBlockedClauseElim += time.Since(startTime).Nanoseconds()
+		m.statistics.BlockedClausesEliminated += int64(blocked)
+		result.BlockedClauses = blocked
+		result.ClausesRemoved += blocked
+	}
+
+	// Phase 6: Bounded variable addition. Its clauses are RAT rather than
+	// RUP, which LRAT hints cannot express.
+	if m.config.EnableVariableAddition && m.adder != nil && !m.proof.Format().IsLRAT() && !contextDone(m.ctx) {
+		startTime := time.Now()
+		added, saved := m.adder.Add(cnf, assignment)
+		m.statistics.TimeInVariableAddition += time.Since(startTime).Nanoseconds()
+		m.statistics.VariablesAdded += int64(added)
+		m.statistics.ClausesSavedByAddition += int64(saved)
+		result.VariablesAdded = added
+		result.ClausesRemoved += saved
+	}
+
+	// Phase 7: Failed literal probing (when implemented). The prober does
+	// not track reasons, so its units cannot be justified in LRAT proofs.
+	if m.config.EnableFailedLitProbing && m.prober != nil && !m.proof.Format().IsLRAT() && !contextDone(m.ctx) {
 		startTime := time.Now()
 		// Create candidate literals from unassigned variables
-		candidates := memory.MustPoolSlice[Literal](satPool, len(cnf.Variables))
+		candidates := memory.MustPoolSlice[Literal](m.pool, len(cnf.Variables))
 		for _, variable := range cnf.Variables {
 			if !assignment.IsAssigned(variable) {
 				candidates = append(candidates, Literal{Variable: variable, Negated: false})
@@ -114,29 +167,32 @@ func (m *ModernInprocessor) Inprocess(cnf *CNF, assignment Assignment, level int
 	m.statistics.TotalInprocessTime += time.Since(m.startTime).Nanoseconds()
 
 	// Determine if formula was significantly reduced
-	totalChanges := result.ClausesRemoved + result.ClausesStrengthened + result.VariablesEliminated + result.UnitsLearned
+	totalChanges := result.ClausesRemoved + result.ClausesStrengthened + result.VariablesEliminated +
+		result.VariablesSubstituted + result.VariablesAdded + result.UnitsLearned
 	result.FormulaReduced = totalChanges > 0
 
 	return result, nil
 }
 
-// VivifyClauses applies clause vivification to strengthen clauses
+// VivifyClauses applies clause vivification to strengthen clauses. The
+// clauses themselves are the formula the vivifier propagates over.
 func (m *ModernInprocessor) VivifyClauses(clauses []*Clause, assignment Assignment) int {
 	if m.vivifier == nil {
 		return 0
 	}
 
 	vivifiedCount := 0
+	m.vivifier.buildOccurrenceLists(clauses)
 
 	// Only vivify learned clauses and long original clauses
 	for _, clause := range clauses {
-		if len(clause.Literals) <= 1 {
+		if clause == nil || clause.Deleted || len(clause.Literals) <= 1 {
 			continue // Skip unit and empty clauses
 		}
 
 		// Prioritize learned clauses or long original clauses
 		if clause.Learned || len(clause.Literals) > 5 {
-			if m.vivifier.VivifyClause(clause, assignment) {
+			if m.vivifier.vivifyInFormula(clause, assignment) {
 				vivifiedCount++
 				m.statistics.ClausesVivified++
 			}
@@ -148,13 +204,16 @@ func (m *ModernInprocessor) VivifyClauses(clauses []*Clause, assignment Assignme
 
 // EliminateVariables applies bounded variable elimination
 func (m *ModernInprocessor) EliminateVariables(variables []string, cnf *CNF) int {
+	return m.eliminateVariables(cnf, make(Assignment))
+}
+
+// eliminateVariables runs bounded variable elimination, leaving variables
+// fixed by assignment in place
+func (m *ModernInprocessor) eliminateVariables(cnf *CNF, assignment Assignment) int {
 	if m.eliminator == nil {
 		return 0
 	}
 
-	// Create a temporary assignment (empty for inprocessing)
-	assignment := make(Assignment)
-
 	eliminatedCount := m.eliminator.EliminateVariables(cnf, assignment)
 
 	// Update statistics
@@ -202,6 +261,60 @@ func (m *ModernInprocessor) GetStatistics() InprocessStatistics {
 	return m.statistics
 }
 
+// SetProofWriter makes the inprocessor log every clause it adds, removes
+// or strengthens to p. A nil writer turns logging off.
+func (m *ModernInprocessor) SetProofWriter(p *ProofWriter) {
+	m.proof = p
+	if m.vivifier != nil {
+		m.vivifier.proof = p
+	}
+	if m.subsumer != nil {
+		m.subsumer.proof = p
+	}
+	if m.eliminator != nil {
+		m.eliminator.proof = p
+	}
+	if m.prober != nil {
+		m.prober.proof = p
+	}
+	if m.substituter != nil {
+		m.substituter.proof = p
+	}
+	if m.blocker != nil {
+		m.blocker.proof = p
+	}
+	if m.adder != nil {
+		m.adder.proof = p
+	}
+}
+
+// setContext makes Inprocess skip the techniques it has not started once
+// ctx is done. Each technique leaves the formula consistent, so the
+// result of a cut-short run is still applied.
+func (m *ModernInprocessor) setContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetReconstructionStack makes variable elimination, equivalent literal
+// substitution and blocked clause elimination record the clauses they
+// remove on r, so models can be extended to the removed variables, and
+// keeps variable addition off the names r reserves. A nil stack turns
+// recording off.
+func (m *ModernInprocessor) SetReconstructionStack(r *ReconstructionStack) {
+	if m.eliminator != nil {
+		m.eliminator.SetReconstructionStack(r)
+	}
+	if m.substituter != nil {
+		m.substituter.SetReconstructionStack(r)
+	}
+	if m.blocker != nil {
+		m.blocker.SetReconstructionStack(r)
+	}
+	if m.adder != nil {
+		m.adder.SetReconstructionStack(r)
+	}
+}
+
 // Reset clears all inprocessor state
 func (m *ModernInprocessor) Reset() {
 	m.statistics = InprocessStatistics{}
@@ -239,11 +352,29 @@ type ClauseVivifier struct {
 	// Performance optimizations
 	literalCache   map[string]bool
 	candidateCache []Literal
+
+	// Formula-aware vivification state
+	occurrences      map[Literal][]*Clause
+	propagationLimit int
+	localAssignment  Assignment
+	localTrail       []Literal
+	localReasons     map[string]*Clause
+
+	// Proof output (nil when not tracing)
+	proof *ProofWriter
+
+	pool *memory.Pool // Backs scratch arrays and new clauses
 }
 
 // NewClauseVivifier creates a new clause vivifier with default settings
 func NewClauseVivifier() *ClauseVivifier {
+	return newClauseVivifier(nil)
+}
+
+func newClauseVivifier(pool *memory.Pool) *ClauseVivifier {
+	pool = poolOr(pool)
 	return &ClauseVivifier{
+		pool:          pool,
 		maxClauseSize: 20, // Only vivify clauses up to this size
 		maxTries:      3,  // Maximum vivification attempts per clause
 		useUnitProp:   true,
@@ -251,7 +382,13 @@ func NewClauseVivifier() *ClauseVivifier {
 
 		tempSolver:     NewDPLLSolver(), // Use DPLL for temp solving
 		literalCache:   make(map[string]bool),
-		candidateCache: memory.MustPoolSlice[Literal](satPool, 20),
+		candidateCache: memory.MustPoolSlice[Literal](pool, 20),
+
+		occurrences:      make(map[Literal][]*Clause),
+		propagationLimit: 2000, // Clause visits per vivified clause
+		localAssignment:  make(Assignment),
+		localTrail:       memory.MustPoolSlice[Literal](pool, 64),
+		localReasons:     make(map[string]*Clause),
 	}
 }
 
@@ -313,6 +450,170 @@ func (cv *ClauseVivifier) VivifyClause(clause *Clause, assignment Assignment) bo
 	return strengthened
 }
 
+// buildOccurrenceLists indexes the clauses of the formula by literal for
+// vivifyInFormula
+func (cv *ClauseVivifier) buildOccurrenceLists(clauses []*Clause) {
+	for k := range cv.occurrences {
+		delete(cv.occurrences, k)
+	}
+	for _, clause := range clauses {
+		if clause == nil || clause.Deleted || len(clause.Literals) < 2 {
+			continue
+		}
+		for _, lit := range clause.Literals {
+			cv.occurrences[lit] = append(cv.occurrences[lit], clause)
+		}
+	}
+}
+
+// vivifyInFormula strengthens clause against the rest of the formula. The
+// negations of its literals are assumed one at a time and propagated over
+// the occurrence lists:
+//   - a conflict means the literals assumed so far already form a clause;
+//   - a later literal that is implied true ends the clause after it;
+//   - a later literal that is implied false is dropped.
+//
+// Every step is a RUP inference, so the strengthened clause is logged to
+// the proof with the propagation reasons as its LRAT hints. Root-level
+// assignments only decide which clauses are worth trying; propagation
+// starts from scratch so that it never depends on unlogged units.
+func (cv *ClauseVivifier) vivifyInFormula(clause *Clause, assignment Assignment) bool {
+	if len(clause.Literals) <= 1 || len(clause.Literals) > cv.maxClauseSize {
+		return false
+	}
+	for _, lit := range clause.Literals {
+		if value, assigned := assignment[lit.Variable]; assigned && value != lit.Negated {
+			return false // Satisfied at the root, nothing to gain
+		}
+	}
+
+	cv.resetLocalAssignment()
+	budget := cv.propagationLimit
+
+	kept := memory.MustPoolSlice[Literal](cv.pool, len(clause.Literals))
+	var last *Clause // Clause that closes the RUP derivation
+	for _, lit := range clause.Literals {
+		if value, assigned := cv.localAssignment[lit.Variable]; assigned {
+			if value != lit.Negated {
+				// Implied true: the clause ends here
+				kept = append(kept, lit)
+				last = cv.localReasons[lit.Variable]
+				break
+			}
+			continue // Implied false: drop the literal
+		}
+
+		kept = append(kept, lit)
+		cv.assignLocal(lit.Negate(), nil)
+		conflict, ok := cv.propagateLocal(clause, &budget)
+		if !ok {
+			return false // Out of budget, leave the clause alone
+		}
+		if conflict != nil {
+			last = conflict
+			break
+		}
+	}
+
+	if len(kept) == len(clause.Literals) {
+		return false
+	}
+	if last == nil {
+		last = clause // Dropped literals are all implied false
+	}
+
+	old := clause.Literals
+	hints := cv.localHints(last)
+	lits := memory.MustPoolSlice[Literal](literalPool(cv.pool), len(kept))
+	clause.Literals = append(lits, kept...)
+	cv.proof.Strengthen(clause, old, hints)
+
+	cv.strengthened++
+	cv.vivified++
+	return true
+}
+
+// resetLocalAssignment clears the vivification trail
+func (cv *ClauseVivifier) resetLocalAssignment() {
+	for k := range cv.localAssignment {
+		delete(cv.localAssignment, k)
+	}
+	for k := range cv.localReasons {
+		delete(cv.localReasons, k)
+	}
+	cv.localTrail = cv.localTrail[:0]
+}
+
+// assignLocal makes lit true on the vivification trail
+func (cv *ClauseVivifier) assignLocal(lit Literal, reason *Clause) {
+	cv.localAssignment[lit.Variable] = !lit.Negated
+	cv.localTrail = append(cv.localTrail, lit)
+	if reason != nil {
+		cv.localReasons[lit.Variable] = reason
+	}
+}
+
+// propagateLocal runs unit propagation from the most recent decision,
+// ignoring the clause being vivified. It returns the conflicting clause,
+// if any, and false when the budget runs out.
+func (cv *ClauseVivifier) propagateLocal(skip *Clause, budget *int) (*Clause, bool) {
+	for head := len(cv.localTrail) - 1; head < len(cv.localTrail); head++ {
+		falseLit := cv.localTrail[head].Negate()
+		for _, other := range cv.occurrences[falseLit] {
+			if other == skip || other.Deleted {
+				continue
+			}
+			if *budget--; *budget < 0 {
+				return nil, false
+			}
+
+			var unit Literal
+			unassigned := 0
+			satisfied := false
+			for _, lit := range other.Literals {
+				value, assigned := cv.localAssignment[lit.Variable]
+				if !assigned {
+					unassigned++
+					unit = lit
+				} else if value != lit.Negated {
+					satisfied = true
+					break
+				}
+			}
+			if satisfied {
+				continue
+			}
+			if unassigned == 0 {
+				return other, true
+			}
+			if unassigned == 1 {
+				cv.assignLocal(unit, other)
+			}
+		}
+	}
+	return nil, true
+}
+
+// localHints returns the LRAT hints for a vivified clause: the reasons on
+// the vivification trail in propagation order, followed by last
+func (cv *ClauseVivifier) localHints(last *Clause) []int {
+	if !cv.proof.Format().IsLRAT() {
+		return nil
+	}
+	hints := memory.MustPoolSlice[int](cv.pool, len(cv.localTrail)+1)
+	for _, lit := range cv.localTrail {
+		reason := cv.localReasons[lit.Variable]
+		if reason == nil {
+			continue
+		}
+		hints = append(hints, reason.ID)
+		if reason == last {
+			return hints
+		}
+	}
+	return append(hints, last.ID)
+}
+
 // canRemoveLiteral checks if a literal can be removed from a clause
 // This is the core of vivification - we check if the clause remains "strong enough"
 // without this literal
@@ -322,7 +623,7 @@ func (cv *ClauseVivifier) canRemoveLiteral(clause *Clause, literalIndex int, ass
 	}
 
 	// Create test clause without this literal
-	testLiterals := memory.MustPoolSlice[Literal](satPool, len(clause.Literals)-1)
+	testLiterals := memory.MustPoolSlice[Literal](cv.pool, len(clause.Literals)-1)
 	for i, lit := range clause.Literals {
 		if i != literalIndex {
 			testLiterals = append(testLiterals, lit)
@@ -356,13 +657,13 @@ func (cv *ClauseVivifier) testWithUnitPropagation(literals []Literal, assignment
 	// Add unit clauses that negate each literal in the test clause
 	for _, lit := range literals {
 		negatedLit := lit.Negate()
-		testCNF.AddClause(NewClause(negatedLit))
+		testCNF.AddClause(NewClauseIn(cv.pool, negatedLit))
 	}
 
 	// Add any existing assignments as constraints
 	for variable, value := range assignment {
 		constraintLit := Literal{Variable: variable, Negated: !value}
-		testCNF.AddClause(NewClause(constraintLit))
+		testCNF.AddClause(NewClauseIn(cv.pool, constraintLit))
 	}
 
 	// Perform unit propagation to see if we get a contradiction
@@ -430,13 +731,13 @@ func (cv *ClauseVivifier) testWithSATSolver(literals []Literal, assignment Assig
 	// Add unit clauses that negate each literal
 	for _, lit := range literals {
 		negatedLit := lit.Negate()
-		testCNF.AddClause(NewClause(negatedLit))
+		testCNF.AddClause(NewClauseIn(cv.pool, negatedLit))
 	}
 
 	// Add assignment constraints
 	for variable, value := range assignment {
 		constraintLit := Literal{Variable: variable, Negated: !value}
-		testCNF.AddClause(NewClause(constraintLit))
+		testCNF.AddClause(NewClauseIn(cv.pool, constraintLit))
 	}
 
 	// Try to solve - if unsatisfiable, the original clause was necessary
@@ -496,6 +797,11 @@ type InprocessSubsumption struct {
 	literalOccurrence     map[string][]*Clause // Literal -> clauses containing it
 	subsumptionCandidates []SubsumptionPair
 	processed             map[int]bool // Clause ID -> processed
+
+	// Proof output (nil when not tracing)
+	proof *ProofWriter
+
+	pool *memory.Pool // Backs scratch arrays and new clauses
 }
 
 // SubsumptionPair represents a potential subsumption relationship
@@ -507,13 +813,19 @@ type SubsumptionPair struct {
 
 // NewInprocessSubsumption creates a new subsumption engine
 func NewInprocessSubsumption() *InprocessSubsumption {
+	return newInprocessSubsumption(nil)
+}
+
+func newInprocessSubsumption(pool *memory.Pool) *InprocessSubsumption {
+	pool = poolOr(pool)
 	return &InprocessSubsumption{
+		pool:                  pool,
 		maxClauseSize:         30,   // Only check clauses up to this size
 		maxSubsumptionTries:   1000, // Limit subsumption attempts
 		enableSelfSubsumption: true,
 
 		literalOccurrence:     make(map[string][]*Clause),
-		subsumptionCandidates: memory.MustPoolSlice[SubsumptionPair](satPool, 100),
+		subsumptionCandidates: memory.MustPoolSlice[SubsumptionPair](pool, 16),
 		processed:             make(map[int]bool),
 	}
 }
@@ -675,7 +987,7 @@ func (is *InprocessSubsumption) findCandidatesForClause(clause *Clause, remainin
 	}
 
 	// Convert map to slice
-	result := memory.MustPoolSlice[*Clause](satPool, len(candidates))
+	result := memory.MustPoolSlice[*Clause](is.pool, len(candidates))
 	for _, candidate := range candidates {
 		result = append(result, candidate)
 	}
@@ -772,7 +1084,7 @@ func (is *InprocessSubsumption) trySelfSubsumption(clause1, clause2 *Clause) boo
 	}
 
 	// Create resolvent (clause1 without resolveLit + clause2 without negated resolveLit)
-	resolvent := memory.MustPoolSlice[Literal](satPool, 0)[:0]
+	resolvent := memory.MustPoolSlice[Literal](is.pool, len(clause1.Literals)+len(clause2.Literals))
 
 	// Add literals from clause1 except the resolve literal
 	for _, lit := range clause1.Literals {
@@ -801,8 +1113,12 @@ func (is *InprocessSubsumption) trySelfSubsumption(clause1, clause2 *Clause) boo
 
 	// Check if resolvent subsumes clause1 (making clause1 weaker)
 	if len(resolvent) < len(clause1.Literals) {
-		// Replace clause1 literals with resolvent
+		// Replace clause1 literals with resolvent. Under its negation clause2
+		// is unit on the negated resolve literal, which falsifies clause1.
+		old := clause1.Literals
+		hints := []int{clause2.ID, clause1.ID}
 		clause1.Literals = resolvent
+		is.proof.Strengthen(clause1, old, hints)
 		is.strengthenedClauses++
 		return true
 	}
@@ -825,12 +1141,15 @@ func (is *InprocessSubsumption) sortCandidatesByStrength() {
 }
 
 func (is *InprocessSubsumption) markForRemoval(cnf *CNF, clause *Clause) {
+	if !clause.Deleted {
+		is.proof.DeleteClause(clause.ID, clause.Literals)
+	}
 	clause.Deleted = true
 }
 
 func (is *InprocessSubsumption) removeMarkedClauses(cnf *CNF) {
 	// Remove and free deleted clauses
-	validClauses := memory.MustPoolSlice[*Clause](satPool, len(cnf.Clauses))
+	validClauses := memory.MustPoolSlice[*Clause](is.pool, len(cnf.Clauses))
 	for _, clause := range cnf.Clauses {
 		if clause != nil && !clause.Deleted {
 			validClauses = append(validClauses, clause)
@@ -897,6 +1216,15 @@ type BoundedVariableElimination struct {
 	// Temporary storage for resolution
 	resolutionCache  []ResolventClause
 	processedClauses map[int]bool
+	oversized        bool // A resolvent exceeded maxResolventSize
+
+	// Proof output (nil when not tracing)
+	proof *ProofWriter
+
+	// Removed clauses for model reconstruction (nil when not recording)
+	reconstruction *ReconstructionStack
+
+	pool *memory.Pool // Backs scratch arrays and new clauses
 }
 
 // EliminationCandidate represents a variable candidate for elimination
@@ -918,7 +1246,13 @@ type ResolventClause struct {
 
 // NewBoundedVariableElimination creates a new variable elimination engine
 func NewBoundedVariableElimination() *BoundedVariableElimination {
+	return newBoundedVariableElimination(nil)
+}
+
+func newBoundedVariableElimination(pool *memory.Pool) *BoundedVariableElimination {
+	pool = poolOr(pool)
 	return &BoundedVariableElimination{
+		pool:                pool,
 		maxResolventSize:    16,  // Conservative limit to prevent explosion
 		maxResolvents:       100, // Maximum resolvents per variable
 		maxEliminationTries: 500, // Limit total elimination attempts
@@ -926,9 +1260,9 @@ func NewBoundedVariableElimination() *BoundedVariableElimination {
 
 		positiveOccurrence: make(map[string][]*Clause),
 		negativeOccurrence: make(map[string][]*Clause),
-		eliminationQueue:   memory.MustPoolSlice[EliminationCandidate](satPool, 100),
+		eliminationQueue:   memory.MustPoolSlice[EliminationCandidate](pool, 16),
 		substitutions:      make(map[string][]Literal),
-		resolutionCache:    memory.MustPoolSlice[ResolventClause](satPool, 1000),
+		resolutionCache:    memory.MustPoolSlice[ResolventClause](pool, 64),
 		processedClauses:   make(map[int]bool),
 	}
 }
@@ -1014,31 +1348,39 @@ func (bve *BoundedVariableElimination) eliminateVariable(variable string, cnf *C
 	}
 
 	// Generate all resolvents
+	bve.oversized = false
 	resolvents := bve.generateResolvents(validPos, validNeg, variable)
 
-	// Check if we exceed resolvent limits
-	if len(resolvents) > bve.maxResolvents {
+	// Check if we exceed resolvent limits. Every resolvent must be kept for
+	// the elimination to preserve satisfiability.
+	if len(resolvents) > bve.maxResolvents || bve.oversized {
 		return false // Skip this variable
 	}
+	for _, resolvent := range resolvents {
+		if len(resolvent.Literals) == 0 {
+			return false // Conflict at the root, leave it to the search
+		}
+	}
 
 	// Filter out redundant resolvents
 	filteredResolvents := bve.filterRedundantResolvents(resolvents)
 
-	// Remove original clauses containing the variable
-	bve.removeClausesContaining(variable, cnf)
-	bve.resolvedClauses += int64(len(validPos) + len(validNeg))
-
-	// Add new resolvent clauses
+	// Add new resolvent clauses before removing their antecedents, so that
+	// each one is a plain resolution step in the proof
 	var addedClauses []*Clause
 	for _, resolvent := range filteredResolvents {
-		if len(resolvent.Literals) > 0 && len(resolvent.Literals) <= bve.maxResolventSize {
-			newClause := NewClause(resolvent.Literals...)
-			cnf.AddClause(newClause)
-			addedClauses = append(addedClauses, newClause)
-			bve.addedResolvents++
-		}
+		newClause := NewClauseIn(bve.pool, resolvent.Literals...)
+		cnf.AddClause(newClause)
+		addedClauses = append(addedClauses, newClause)
+		bve.proof.AddClause(newClause.ID, newClause.Literals, []int{resolvent.SourcePos.ID, resolvent.SourceNeg.ID})
+		bve.addedResolvents++
 	}
 
+	// Remove original clauses containing the variable
+	bve.record(variable, validPos, validNeg)
+	bve.removeClausesContaining(variable, cnf)
+	bve.resolvedClauses += int64(len(validPos) + len(validNeg))
+
 	// Update occurrence lists
 	bve.updateOccurrenceListsAfterElimination(variable, addedClauses)
 
@@ -1064,7 +1406,7 @@ func (bve *BoundedVariableElimination) generateResolvents(posOccurrences, negOcc
 // resolveClausesPair performs resolution between two clauses on the given variable
 func (bve *BoundedVariableElimination) resolveClausesPair(posClause, negClause *Clause, variable string) *ResolventClause {
 	// Collect literals from both clauses, excluding the resolved variable
-	resolventLits := memory.MustPoolSlice[Literal](satPool, len(posClause.Literals)+len(negClause.Literals)-2)
+	resolventLits := memory.MustPoolSlice[Literal](bve.pool, len(posClause.Literals)+len(negClause.Literals)-2)
 
 	// Add literals from positive clause (except positive occurrence of variable)
 	for _, lit := range posClause.Literals {
@@ -1111,6 +1453,7 @@ func (bve *BoundedVariableElimination) resolveClausesPair(posClause, negClause *
 
 	// Skip if resolvent is too large
 	if len(resolventLits) > bve.maxResolventSize {
+		bve.oversized = true
 		return nil
 	}
 
@@ -1128,7 +1471,7 @@ func (bve *BoundedVariableElimination) filterRedundantResolvents(resolvents []Re
 		return resolvents
 	}
 
-	filtered := memory.MustPoolSlice[ResolventClause](satPool, len(resolvents))
+	filtered := memory.MustPoolSlice[ResolventClause](bve.pool, len(resolvents))
 
 	for i, resolvent := range resolvents {
 		if resolvent.Redundant {
@@ -1376,7 +1719,7 @@ func (bve *BoundedVariableElimination) isEliminable(variable string, cnf *CNF, a
 // eliminatePureVariable removes clauses containing a pure variable
 func (bve *BoundedVariableElimination) eliminatePureVariable(variable string, cnf *CNF) {
 	// Remove all clauses containing this variable (in any polarity)
-	clausesToRemove := memory.MustPoolSlice[*Clause](satPool, 0)[:0]
+	clausesToRemove := memory.MustPoolSlice[*Clause](bve.pool, 0)[:0]
 
 	// Collect clauses to remove
 	if posOccurrences := bve.positiveOccurrence[variable]; len(posOccurrences) > 0 {
@@ -1387,6 +1730,7 @@ func (bve *BoundedVariableElimination) eliminatePureVariable(variable string, cn
 	}
 
 	// Remove clauses from CNF
+	bve.record(variable, bve.positiveOccurrence[variable], bve.negativeOccurrence[variable])
 	for _, clauseToRemove := range clausesToRemove {
 		bve.removeClauseFromCNF(cnf, clauseToRemove)
 	}
@@ -1394,9 +1738,36 @@ func (bve *BoundedVariableElimination) eliminatePureVariable(variable string, cn
 	bve.resolvedClauses += int64(len(clausesToRemove))
 }
 
+// record pushes the irredundant clauses of an eliminated variable onto the
+// reconstruction stack, each witnessed by its literal of the variable
+func (bve *BoundedVariableElimination) record(variable string, pos, neg []*Clause) {
+	if bve.reconstruction == nil {
+		return
+	}
+	for _, occurrences := range [][]*Clause{pos, neg} {
+		for _, clause := range occurrences {
+			if clause == nil || clause.Deleted || clause.Learned {
+				continue
+			}
+			for _, lit := range clause.Literals {
+				if lit.Variable == variable {
+					bve.reconstruction.Push(lit, clause.Literals)
+					break
+				}
+			}
+		}
+	}
+}
+
+// SetReconstructionStack makes elimination record the clauses it removes
+// on r. A nil stack turns recording off.
+func (bve *BoundedVariableElimination) SetReconstructionStack(r *ReconstructionStack) {
+	bve.reconstruction = r
+}
+
 // removeClausesContaining removes all clauses containing the specified variable
 func (bve *BoundedVariableElimination) removeClausesContaining(variable string, cnf *CNF) {
-	clausesToRemove := memory.MustPoolSlice[*Clause](satPool, 0)[:0]
+	clausesToRemove := memory.MustPoolSlice[*Clause](bve.pool, 0)[:0]
 
 	// Collect all clauses containing this variable
 	if posOccurrences := bve.positiveOccurrence[variable]; len(posOccurrences) > 0 {
@@ -1420,6 +1791,9 @@ func (bve *BoundedVariableElimination) removeClausesContaining(variable string,
 
 // removeClauseFromCNF removes a specific clause from the CNF
 func (bve *BoundedVariableElimination) removeClauseFromCNF(cnf *CNF, clauseToRemove *Clause) {
+	if !clauseToRemove.Deleted {
+		bve.proof.DeleteClause(clauseToRemove.ID, clauseToRemove.Literals)
+	}
 	clauseToRemove.Deleted = true
 }
 
@@ -1444,7 +1818,7 @@ func (bve *BoundedVariableElimination) updateOccurrenceListsAfterElimination(eli
 // cleanupEliminatedVariables removes nil clauses and updates variable lists
 func (bve *BoundedVariableElimination) cleanupEliminatedVariables(cnf *CNF) {
 	// Remove and free deleted clauses
-	validClauses := memory.MustPoolSlice[*Clause](satPool, len(cnf.Clauses))
+	validClauses := memory.MustPoolSlice[*Clause](bve.pool, len(cnf.Clauses))
 	for _, clause := range cnf.Clauses {
 		if clause != nil && !clause.Deleted {
 			validClauses = append(validClauses, clause)
@@ -1463,7 +1837,7 @@ func (bve *BoundedVariableElimination) cleanupEliminatedVariables(cnf *CNF) {
 	}
 
 	// Rebuild variables slice
-	cnf.Variables = memory.MustPoolSlice[string](satPool, len(variableSet))
+	cnf.Variables = memory.MustPoolSlice[string](bve.pool, len(variableSet))
 	for variable := range variableSet {
 		cnf.Variables = append(cnf.Variables, variable)
 	}
@@ -1539,6 +1913,11 @@ type FailedLiteralProber struct {
 	equivalenceClasses  map[string]string    // Variable -> representative
 	probingOrder        []ProbingCandidate   // Optimized probing order
 	watchedImplications map[string][]*Clause // For efficient implication tracking
+
+	// Proof output (nil when not tracing)
+	proof *ProofWriter
+
+	pool *memory.Pool // Backs scratch arrays and new clauses
 }
 
 // ProbingCandidate represents a literal candidate for failed literal probing
@@ -1563,7 +1942,13 @@ type ProbingResult struct {
 
 // NewFailedLiteralProber creates a new failed literal prober with default settings
 func NewFailedLiteralProber() *FailedLiteralProber {
+	return newFailedLiteralProber(nil)
+}
+
+func newFailedLiteralProber(pool *memory.Pool) *FailedLiteralProber {
+	pool = poolOr(pool)
 	return &FailedLiteralProber{
+		pool:                pool,
 		maxProbingDepth:     10,   // Reasonable depth limit
 		maxCandidates:       200,  // Limit probing attempts
 		maxProbingTime:      5e9,  // 5 seconds maximum
@@ -1572,13 +1957,13 @@ func NewFailedLiteralProber() *FailedLiteralProber {
 		costThreshold:       50.0, // Cost threshold for candidate selection
 
 		probingSolver:       NewDPLLSolver(), // Use lightweight solver for probing
-		candidateQueue:      memory.MustPoolSlice[ProbingCandidate](satPool, 200),
+		candidateQueue:      memory.MustPoolSlice[ProbingCandidate](pool, 32),
 		probingCache:        make(map[string]ProbingResult),
 		literalImplications: make(map[string][]Literal),
 		binaryImplications:  make(map[string][]Literal),
-		impliedUnits:        memory.MustPoolSlice[Literal](satPool, 50),
+		impliedUnits:        memory.MustPoolSlice[Literal](pool, 16),
 		equivalenceClasses:  make(map[string]string),
-		probingOrder:        memory.MustPoolSlice[ProbingCandidate](satPool, 200),
+		probingOrder:        memory.MustPoolSlice[ProbingCandidate](pool, 32),
 		watchedImplications: make(map[string][]*Clause),
 	}
 }
@@ -1622,7 +2007,9 @@ func (flp *FailedLiteralProber) ProbeFailedLiterals(cnf *CNF, assignment Assignm
 			flp.unitsLearned++
 
 			// Add unit clause to CNF immediately
-			cnf.AddClause(NewClause(failedLiteral))
+			unit := NewClauseIn(flp.pool, failedLiteral)
+			cnf.AddClause(unit)
+			flp.proof.AddClause(unit.ID, unit.Literals, nil)
 		}
 
 		// Handle double probing if enabled
@@ -1635,7 +2022,9 @@ func (flp *FailedLiteralProber) ProbeFailedLiterals(cnf *CNF, assignment Assignm
 				flp.impliedUnits = append(flp.impliedUnits, candidate.Literal)
 				flp.failedLiteralsFound++
 				flp.unitsLearned++
-				cnf.AddClause(NewClause(candidate.Literal))
+				unit := NewClauseIn(flp.pool, candidate.Literal)
+				cnf.AddClause(unit)
+				flp.proof.AddClause(unit.ID, unit.Literals, nil)
 			}
 		}
 
@@ -1656,8 +2045,8 @@ func (flp *FailedLiteralProber) probeLiteral(literal Literal, cnf *CNF, assignme
 
 	result := ProbingResult{
 		Failed:      false,
-		Implied:     memory.MustPoolSlice[Literal](satPool, 0)[:0],
-		Equivalents: memory.MustPoolSlice[Literal](satPool, 0)[:0],
+		Implied:     memory.MustPoolSlice[Literal](flp.pool, 0)[:0],
+		Equivalents: memory.MustPoolSlice[Literal](flp.pool, 0)[:0],
 		Probed:      literal, // NEW
 	}
 
@@ -1680,7 +2069,7 @@ func (flp *FailedLiteralProber) probeLiteral(literal Literal, cnf *CNF, assignme
 
 // performProbingWithUnitPropagation performs unit propagation during probing
 func (flp *FailedLiteralProber) performProbingWithUnitPropagation(cnf *CNF, assignment Assignment, probedLiteral Literal) ([]Literal, *Clause) {
-	implications := memory.MustPoolSlice[Literal](satPool, 0)[:0]
+	implications := memory.MustPoolSlice[Literal](flp.pool, 0)[:0]
 	changed := true
 	depth := 0
 
@@ -1693,8 +2082,16 @@ func (flp *FailedLiteralProber) performProbingWithUnitPropagation(cnf *CNF, assi
 				continue
 			}
 
-			// Skip if clause is satisfied
-			if assignment.Satisfies(clause) {
+			// Skip if clause has a true literal. Assignment.Satisfies also
+			// counts unassigned literals, which would stop all propagation.
+			satisfied := false
+			for _, lit := range clause.Literals {
+				if value, ok := assignment[lit.Variable]; ok && value != lit.Negated {
+					satisfied = true
+					break
+				}
+			}
+			if satisfied {
 				continue
 			}
 
@@ -1770,9 +2167,8 @@ func (flp *FailedLiteralProber) performHyperBinaryResolution(result ProbingResul
 	}
 
 	for _, u := range firstHops {
-		// Second hop: (¬u -> v)
-		keyUNeg := flp.literalKey(u.Negate())
-		seconds, ok := flp.binaryImplications[keyUNeg]
+		// Second hop: (u -> v)
+		seconds, ok := flp.binaryImplications[flp.literalKey(u)]
 		if !ok {
 			continue
 		}
@@ -1785,8 +2181,9 @@ func (flp *FailedLiteralProber) performHyperBinaryResolution(result ProbingResul
 				continue // already present
 			}
 			// Create and register the new binary clause
-			clause := NewClause(probed, v)
+			clause := NewClauseIn(flp.pool, probed, v)
 			cnf.AddClause(clause)
+			flp.proof.AddClause(clause.ID, clause.Literals, nil)
 			flp.registerBinaryClause(probed, v)
 			flp.hyperbinariesFound++
 		}
@@ -0,0 +1,57 @@
+package sat
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFailedLiteralProber_PropagatesImplications(t *testing.T) {
+	flp := NewFailedLiteralProber()
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", true), L("B", false)))
+	cnf.AddClause(NewClause(L("B", true), L("C", false)))
+	assignment := Assignment{"A": true}
+
+	implications, conflict := flp.performProbingWithUnitPropagation(cnf, assignment, L("A", false))
+	if conflict != nil {
+		t.Fatalf("unexpected conflict %v", conflict.Literals)
+	}
+	if len(implications) != 2 || !assignment["B"] || !assignment["C"] {
+		t.Errorf("A should imply B and C, got %v", implications)
+	}
+}
+
+func TestFailedLiteralProber_FindsConflict(t *testing.T) {
+	flp := NewFailedLiteralProber()
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", true), L("B", false)))
+	cnf.AddClause(NewClause(L("A", true), L("B", true)))
+	assignment := Assignment{"A": true}
+
+	if _, conflict := flp.performProbingWithUnitPropagation(cnf, assignment, L("A", false)); conflict == nil {
+		t.Error("A=true should be a failed literal")
+	}
+}
+
+func TestModernInprocessor_SkipsTechniquesWhenCancelled(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	cnf.AddClause(NewClause(L("A", false), L("B", false), L("C", false)))
+	m := NewModernInprocessor()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.setContext(ctx)
+
+	result, err := m.Inprocess(cnf, make(Assignment), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FormulaReduced || len(cnf.Clauses) != 2 {
+		t.Errorf("cancelled inprocessing changed the formula: %+v", result)
+	}
+
+	m.setContext(nil)
+	if result, _ := m.Inprocess(cnf, make(Assignment), 0); !result.FormulaReduced {
+		t.Error("expected the subsumed clause to be removed")
+	}
+}
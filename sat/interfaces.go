@@ -1,6 +1,7 @@
 package sat
 
 import (
+	"context"
 	"time"
 
 	"github.com/xDarkicex/logic/core"
@@ -22,6 +23,28 @@ type Solver interface {
 	Name() string
 }
 
+// IncrementalSolver extends Solver with solving under assumptions. State
+// such as learned clauses is kept between calls, and clauses can be added
+// with AddClause in between.
+type IncrementalSolver interface {
+	Solver
+
+	// SolveAssuming solves the clauses added so far with the given literals
+	// assumed true
+	SolveAssuming(assumptions []Literal) *SolverResult
+}
+
+// ContextSolver extends Solver with cancellation and progress reporting
+type ContextSolver interface {
+	Solver
+
+	// SolveContext solves cnf, stopping promptly with StatusUnknown once
+	// ctx is done
+	SolveContext(ctx context.Context, cnf *CNF) *SolverResult
+	// SetProgress reports statistics to fn about once per interval
+	SetProgress(fn ProgressFunc, interval time.Duration)
+}
+
 // XORSolver extends Solver interface for XOR constraint support
 type XORSolver interface {
 	Solver
@@ -116,14 +139,44 @@ type MAXSATSolver interface {
 	SolveWeightedMAXSAT(cnf *CNF, weights []float64) *MAXSATResult
 }
 
+// PartialMAXSATSolver extends MAX-SAT solving with hard clauses
+type PartialMAXSATSolver interface {
+	MAXSATSolver
+	// SolvePartialMAXSAT solves weighted partial MAX-SAT with hard clauses
+	SolvePartialMAXSAT(wcnf *WeightedCNF) *MAXSATResult
+}
+
 // MAXSATResult represents MAX-SAT solving result
 type MAXSATResult struct {
 	Assignment         Assignment
 	SatisfiedCount     int
 	TotalWeight        float64
 	UnsatisfiedClauses []int // IDs of unsatisfied clauses
+	Cost               int64 // Total integer weight of the unsatisfied soft clauses
+	Optimal            bool  // True if no assignment has a lower Cost
 	Statistics         SolverStatistics
 	Error              error
+
+	// Status is StatusSAT once an optimum is found and StatusUNSAT if the
+	// hard clauses are unsatisfiable. StatusUnknown means the search or
+	// the input failed; Reason tells which.
+	Status SolverStatus
+	Reason UnknownReason
+}
+
+// PBResult represents the result of pseudo-Boolean optimisation
+type PBResult struct {
+	Assignment Assignment
+	Cost       int64 // Objective value of Assignment
+	Optimal    bool  // True if no model has a lower Cost
+	Statistics SolverStatistics
+	Error      error
+
+	// Status is StatusSAT once a model is found and StatusUNSAT if the
+	// constraints are unsatisfiable. StatusUnknown means the search or
+	// the input failed before any model was found; Reason tells which.
+	Status SolverStatus
+	Reason UnknownReason
 }
 
 // DecisionTrail tracks variable assignments and their reasons
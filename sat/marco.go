@@ -0,0 +1,225 @@
+package sat
+
+import (
+	"io"
+	"sort"
+	"time"
+)
+
+// SubsetKind tells the two kinds of subset a MARCOEnumerator yields apart
+type SubsetKind int
+
+const (
+	// MinimalUnsatisfiableSubset is a set of clauses that is unsatisfiable
+	// and becomes satisfiable when any one of them is removed
+	MinimalUnsatisfiableSubset SubsetKind = iota
+	// MinimalCorrectionSet is a set of clauses whose removal makes the
+	// formula satisfiable, none of which can be kept
+	MinimalCorrectionSet
+)
+
+// String returns the conventional abbreviation of the kind
+func (k SubsetKind) String() string {
+	if k == MinimalCorrectionSet {
+		return "MCS"
+	}
+	return "MUS"
+}
+
+// ClauseSubset is one MUS or MCS found by a MARCOEnumerator
+type ClauseSubset struct {
+	Kind      SubsetKind
+	ClauseIDs []int // Clause.ID of each clause in the subset, ascending
+}
+
+// MARCOConfig limits an enumeration. Zero values mean no limit.
+type MARCOConfig struct {
+	MaxResults int           // Subsets to yield in total
+	Timeout    time.Duration // Time from the first Next call
+}
+
+// MARCOEnumerator lists the MUSes and MCSes of a formula lazily, following
+// MARCO (Liffiton et al., 2016). A map solver over one selector per clause
+// tracks the subsets not yet explored. Each seed it proposes is checked:
+//
+//   - A satisfiable seed is grown to a maximal satisfiable subset, whose
+//     complement is an MCS; the map then blocks every subset of it.
+//   - An unsatisfiable seed is shrunk to an MUS with MUSExtractor; the map
+//     then blocks every superset of it.
+//
+// Every subset yielded is new, and the enumeration is complete, having
+// found every MUS and MCS, once the map becomes unsatisfiable. An
+// enumerator is not safe for concurrent use.
+type MARCOEnumerator struct {
+	config    MARCOConfig
+	mapSolver *CDCLSolver
+	subsets   *MUSExtractor
+	seed      []bool // Clause index -> in the current seed
+
+	started  bool
+	deadline time.Time
+	yielded  int
+	complete bool
+}
+
+// NewMARCOEnumerator creates an enumerator over the clauses of cnf
+func NewMARCOEnumerator(cnf *CNF, config MARCOConfig) *MARCOEnumerator {
+	groupOf := make([]int, len(cnf.Clauses))
+	for i := range groupOf {
+		groupOf[i] = i
+	}
+	subsets := NewMUSExtractor()
+	subsets.load(cnf, groupOf, len(cnf.Clauses))
+
+	return &MARCOEnumerator{
+		config:    config,
+		mapSolver: NewCDCLSolver(),
+		subsets:   subsets,
+		seed:      make([]bool, len(cnf.Clauses)),
+	}
+}
+
+// Next returns the next MUS or MCS. It returns io.EOF once the enumeration
+// is complete or a limit in the config is reached; Complete tells which.
+// A satisfiable formula has a single, empty MCS and no MUS.
+func (e *MARCOEnumerator) Next() (*ClauseSubset, error) {
+	if !e.started {
+		e.started = true
+		if e.config.Timeout > 0 {
+			e.deadline = time.Now().Add(e.config.Timeout)
+			e.subsets.deadline = e.deadline
+		}
+	}
+	if e.complete ||
+		(e.config.MaxResults > 0 && e.yielded >= e.config.MaxResults) ||
+		e.expired() {
+		return nil, io.EOF
+	}
+	subset, err := e.next()
+	if err != nil && e.expired() {
+		// A solve ran out of the remaining time
+		return nil, io.EOF
+	}
+	return subset, err
+}
+
+// expired reports whether the configured timeout has passed
+func (e *MARCOEnumerator) expired() bool {
+	return !e.deadline.IsZero() && !time.Now().Before(e.deadline)
+}
+
+// untilDeadline returns the time left before deadline as a solver timeout,
+// zero for no deadline. A spent budget still gives a positive timeout, as
+// zero means none.
+func untilDeadline(deadline time.Time) time.Duration {
+	if deadline.IsZero() {
+		return 0
+	}
+	return max(time.Until(deadline), time.Nanosecond)
+}
+
+// next finds the next subset once the limits have been checked
+func (e *MARCOEnumerator) next() (*ClauseSubset, error) {
+
+	// Unconstrained selectors are absent from the map's model and default
+	// to in the seed, which favours large seeds
+	result := e.mapSolver.solveAssuming(nil, nil, untilDeadline(e.deadline))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if !result.Satisfiable {
+		e.complete = true
+		return nil, io.EOF
+	}
+	m := e.subsets
+	for i := range e.seed {
+		value, assigned := result.Assignment[m.selectors[i]]
+		e.seed[i] = value || !assigned
+	}
+
+	for g := range m.status {
+		m.status[g] = musRemoved
+		if e.seed[g] {
+			m.status[g] = musCandidate
+		}
+	}
+	check := m.solve(-1)
+	if check.Error != nil {
+		return nil, check.Error
+	}
+
+	var subset *ClauseSubset
+	if check.Satisfiable {
+		mcs, err := e.grow(check.Assignment)
+		if err != nil {
+			return nil, err
+		}
+		subset = &ClauseSubset{Kind: MinimalCorrectionSet, ClauseIDs: make([]int, 0, len(mcs))}
+		block := make([]Literal, 0, len(mcs))
+		for _, i := range mcs {
+			subset.ClauseIDs = append(subset.ClauseIDs, m.clauses[i].ID)
+			block = append(block, Literal{Variable: m.selectors[i]})
+		}
+		e.mapSolver.AddClause(NewClause(block...))
+	} else {
+		if err := m.shrink(check.FailedAssumptions); err != nil {
+			return nil, err
+		}
+		subset = &ClauseSubset{Kind: MinimalUnsatisfiableSubset, ClauseIDs: m.necessaryIDs()}
+		block := make([]Literal, 0, len(subset.ClauseIDs))
+		for g, status := range m.status {
+			if status == musNecessary {
+				block = append(block, Literal{Variable: m.selectors[g], Negated: true})
+			}
+		}
+		e.mapSolver.AddClause(NewClause(block...))
+	}
+	sort.Ints(subset.ClauseIDs)
+	e.yielded++
+	return subset, nil
+}
+
+// Complete reports whether every MUS and MCS has been returned
+func (e *MARCOEnumerator) Complete() bool {
+	return e.complete
+}
+
+// grow extends the satisfiable seed, with model, to a maximal satisfiable
+// subset and returns the indices of the clauses left out: an MCS
+func (e *MARCOEnumerator) grow(model Assignment) ([]int, error) {
+	m := e.subsets
+	include := func(model Assignment) {
+		for i := range e.seed {
+			if !e.seed[i] && m.satisfied(model, i) {
+				e.seed[i] = true
+				m.status[i] = musCandidate
+			}
+		}
+	}
+	include(model)
+
+	for i := range e.seed {
+		if e.seed[i] {
+			continue
+		}
+		m.status[i] = musCandidate
+		result := m.solve(-1)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if !result.Satisfiable {
+			m.status[i] = musRemoved
+			continue
+		}
+		e.seed[i] = true
+		include(result.Assignment)
+	}
+
+	var mcs []int
+	for i, in := range e.seed {
+		if !in {
+			mcs = append(mcs, i)
+		}
+	}
+	return mcs, nil
+}
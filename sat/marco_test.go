@@ -0,0 +1,187 @@
+package sat
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"testing"
+	"time"
+)
+
+// enumerateAll collects every subset from e, failing t on an error
+func enumerateAll(t *testing.T, e *MARCOEnumerator) []*ClauseSubset {
+	t.Helper()
+	var subsets []*ClauseSubset
+	for {
+		subset, err := e.Next()
+		if err == io.EOF {
+			return subsets
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		subsets = append(subsets, subset)
+	}
+}
+
+// pigeonhole builds the pigeonhole formula with one string per variable.
+// Clauses live in pool memory the collector does not scan, so every name
+// must also be reachable from cnf.Variables for long solves.
+func pigeonhole(pigeons, holes int) *CNF {
+	names := make([][]string, pigeons)
+	for p := range names {
+		names[p] = make([]string, holes)
+		for h := range names[p] {
+			names[p][h] = fmt.Sprintf("p%dh%d", p, h)
+		}
+	}
+	cnf := NewCNF()
+	for p := range names {
+		clause := make([]Literal, holes)
+		for h := range clause {
+			clause[h] = L(names[p][h], false)
+		}
+		cnf.AddClause(NewClause(clause...))
+	}
+	for h := 0; h < holes; h++ {
+		for p1 := 0; p1 < pigeons; p1++ {
+			for p2 := p1 + 1; p2 < pigeons; p2++ {
+				cnf.AddClause(NewClause(L(names[p1][h], true), L(names[p2][h], true)))
+			}
+		}
+	}
+	return cnf
+}
+
+func TestMARCOEnumerator_Basic(t *testing.T) {
+	// MUSes {1,2} and {1,3,4}; MCSes {1}, {2,3} and {2,4}
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false)))
+	cnf.AddClause(NewClause(L("A", true)))
+	cnf.AddClause(NewClause(L("A", true), L("B", false)))
+	cnf.AddClause(NewClause(L("B", true)))
+
+	e := NewMARCOEnumerator(cnf, MARCOConfig{})
+	subsets := enumerateAll(t, e)
+	if !e.Complete() {
+		t.Error("Expected a complete enumeration")
+	}
+	var got []string
+	for _, subset := range subsets {
+		got = append(got, fmt.Sprint(subset.Kind, subset.ClauseIDs))
+	}
+	sort.Strings(got)
+	want := []string{"MCS [1]", "MCS [2 3]", "MCS [2 4]", "MUS [1 2]", "MUS [1 3 4]"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if _, err := e.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF after completion, got %v", err)
+	}
+}
+
+func TestMARCOEnumerator_Satisfiable(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	cnf.AddClause(NewClause(L("A", true)))
+
+	subsets := enumerateAll(t, NewMARCOEnumerator(cnf, MARCOConfig{}))
+	if len(subsets) != 1 || subsets[0].Kind != MinimalCorrectionSet || len(subsets[0].ClauseIDs) != 0 {
+		t.Errorf("Expected a single empty MCS, got %v", subsets)
+	}
+}
+
+func TestMARCOEnumerator_Limits(t *testing.T) {
+	cnf := NewCNF()
+	for i := 0; i < 6; i++ {
+		cnf.AddClause(NewClause(L(varName(i), false)))
+		cnf.AddClause(NewClause(L(varName(i), true)))
+	}
+
+	e := NewMARCOEnumerator(cnf, MARCOConfig{MaxResults: 3})
+	if subsets := enumerateAll(t, e); len(subsets) != 3 {
+		t.Errorf("Expected 3 subsets, got %d", len(subsets))
+	}
+	if e.Complete() {
+		t.Error("Expected an incomplete enumeration")
+	}
+
+	e = NewMARCOEnumerator(cnf, MARCOConfig{Timeout: time.Nanosecond})
+	time.Sleep(time.Millisecond)
+	e.Next()
+	if subset, err := e.Next(); err != io.EOF || e.Complete() {
+		t.Errorf("Expected the timeout to stop enumeration, got %v, %v", subset, err)
+	}
+
+	// The timeout also bounds the solves within a subset
+	e = NewMARCOEnumerator(pigeonhole(10, 9), MARCOConfig{Timeout: 50 * time.Millisecond})
+	start := time.Now()
+	if subset, err := e.Next(); err != io.EOF {
+		t.Errorf("Expected the timeout to stop the first subset, got %v, %v", subset, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected Next to stop near the timeout, took %v", elapsed)
+	}
+
+	// 6 MUSes and 2^6 MCSes in all
+	e = NewMARCOEnumerator(cnf, MARCOConfig{})
+	if subsets := enumerateAll(t, e); len(subsets) != 6+64 || !e.Complete() {
+		t.Errorf("Expected 70 subsets, got %d", len(subsets))
+	}
+}
+
+func TestMARCOEnumerator_Cases(t *testing.T) {
+	a, b, c := L("A", false), L("B", false), L("C", false)
+	na, nb := L("A", true), L("B", true)
+	testCases := []struct {
+		description string
+		clauses     [][]Literal
+		expected    []string
+	}{
+		{
+			description: "chain with a shortcut",
+			clauses:     [][]Literal{{a}, {na, b}, {nb}, {na}},
+			expected:    []string{"MCS [1]", "MCS [2 4]", "MCS [3 4]", "MUS [1 2 3]", "MUS [1 4]"},
+		},
+		{
+			description: "two disjoint conflicts",
+			clauses:     [][]Literal{{a}, {na}, {b}, {nb}},
+			expected:    []string{"MCS [1 3]", "MCS [1 4]", "MCS [2 3]", "MCS [2 4]", "MUS [1 2]", "MUS [3 4]"},
+		},
+		{
+			description: "all four sign patterns over two variables",
+			clauses:     [][]Literal{{a, b}, {a, nb}, {na, b}, {na, nb}},
+			expected:    []string{"MCS [1]", "MCS [2]", "MCS [3]", "MCS [4]", "MUS [1 2 3 4]"},
+		},
+		{
+			description: "clause outside every core",
+			clauses:     [][]Literal{{a}, {b, c}, {na}},
+			expected:    []string{"MCS [1]", "MCS [3]", "MUS [1 3]"},
+		},
+		{
+			description: "cores sharing a clause",
+			clauses:     [][]Literal{{a}, {na, b}, {nb}, {na, nb}, {b}},
+			expected: []string{
+				"MCS [1 3]", "MCS [1 5]", "MCS [2 5]", "MCS [3 4]",
+				"MUS [1 2 3]", "MUS [1 2 4]", "MUS [1 4 5]", "MUS [3 5]",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			e := NewMARCOEnumerator(buildCNF(tc.clauses), MARCOConfig{})
+			var got []string
+			for _, subset := range enumerateAll(t, e) {
+				got = append(got, fmt.Sprint(subset.Kind, subset.ClauseIDs))
+			}
+			if !e.Complete() {
+				t.Error("Expected a complete enumeration")
+			}
+			sort.Strings(got)
+			if fmt.Sprint(got) != fmt.Sprint(tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
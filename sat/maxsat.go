@@ -2,13 +2,23 @@ package sat
 
 import (
 	"fmt"
+	"math"
+	"sort"
 
+	"github.com/xDarkicex/logic/core"
 	"github.com/xDarkicex/memory"
 )
 
-// MAXSATSolverImpl implements MAX-SAT solving
+// MAXSATSolverImpl implements weighted partial MAX-SAT with the core-guided
+// OLL algorithm as in RC2 (Ignatiev, Morgado & Marques-Silva, 2019). Each
+// soft clause gets an assumption literal; every unsatisfiable core raises
+// the lower bound by its minimum weight and is relaxed with a totalizer
+// whose outputs become new, incrementally loosened assumptions. Weights are
+// stratified: heavy soft clauses are considered first and lighter ones
+// joined once the heavy ones are satisfiable. The first model that
+// satisfies all remaining assumptions is optimal.
 type MAXSATSolverImpl struct {
-	baseSolver Solver
+	baseSolver IncrementalSolver
 }
 
 // NewMAXSATSolver creates new MAX-SAT solver
@@ -30,83 +40,303 @@ func (m *MAXSATSolverImpl) SolveMAXSAT(cnf *CNF, weights []float64) *MAXSATResul
 	return m.SolveWeightedMAXSAT(cnf, weights)
 }
 
-// SolveWeightedMAXSAT solves weighted MAX-SAT using binary search
+// SolveWeightedMAXSAT finds an assignment maximising the total weight of
+// the satisfied clauses; all clauses are soft. The weights are scaled by
+// the smallest power of ten, at most 10^9, that makes them integral, and
+// Cost is reported in those scaled units. Clauses with a weight of zero or
+// less carry no cost.
 func (m *MAXSATSolverImpl) SolveWeightedMAXSAT(cnf *CNF, weights []float64) *MAXSATResult {
-	totalWeight := 0.0
-	for _, w := range weights {
-		totalWeight += w
+	if len(weights) != len(cnf.Clauses) {
+		return &MAXSATResult{
+			Error: core.NewLogicError("sat", "MAXSATSolver.SolveWeightedMAXSAT",
+				fmt.Sprintf("%d weights for %d clauses", len(weights), len(cnf.Clauses))),
+			Reason: ReasonError,
+		}
+	}
+	scaled, err := scaleWeights(weights)
+	if err != nil {
+		return &MAXSATResult{Error: err, Reason: ReasonError}
 	}
 
-	// Binary search on weight threshold
-	low := 0.0
-	high := totalWeight
-	bestAssignment := make(Assignment)
-	bestWeight := 0.0
-	bestUnsatisfied := memory.MustPoolSlice[int](satPool, 0)
-
-	for high-low > 0.01 { // Precision threshold
-		mid := (low + high) / 2
+	wcnf := &WeightedCNF{Hard: NewCNF(), Soft: cnf, Weights: scaled}
+	result := m.SolvePartialMAXSAT(wcnf)
+	if result.Error == nil {
+		result.TotalWeight = 0
+		for i, clause := range cnf.Clauses {
+			if result.Assignment.Satisfies(clause) {
+				result.TotalWeight += weights[i]
+			}
+		}
+	}
+	return result
+}
 
-		// Create SAT instance for this threshold
-		testCNF := NewCNF()
+// scaleWeights converts weights to integers by the smallest power of ten
+// that makes them all integral to within rounding error
+func scaleWeights(weights []float64) ([]int64, error) {
+	scale := 1.0
+	for exp := 0; exp <= 9; exp++ {
+		integral := true
+		for _, w := range weights {
+			if w > 0 && math.Abs(w*scale-math.Round(w*scale)) > 1e-6*w*scale {
+				integral = false
+				break
+			}
+		}
+		if integral {
+			break
+		}
+		if exp < 9 {
+			scale *= 10
+		}
+	}
 
-		// Add original clauses with relaxation variables
-		relaxVars := memory.MustPoolSlice[string](satPool, len(cnf.Clauses))[:len(cnf.Clauses)]
-		for i, clause := range cnf.Clauses {
-			if weights[i] >= mid {
-				// Hard clause - must be satisfied
-				testCNF.AddClause(clause)
-			} else {
-				// Soft clause - add relaxation variable
-				relaxVar := fmt.Sprintf("__relax_%d", i)
-				relaxVars[i] = relaxVar
-
-				// Add clause with relaxation: (original_clause ∨ relaxVar)
-				relaxedLiterals := memory.MustPoolSlice[Literal](satPool, len(clause.Literals)+1)[:len(clause.Literals)+1]
-				copy(relaxedLiterals, clause.Literals)
-				relaxedLiterals[len(clause.Literals)] = Literal{
-					Variable: relaxVar,
-					Negated:  false,
-				}
+	scaled := make([]int64, len(weights))
+	total := 0.0
+	for i, w := range weights {
+		if w > 0 {
+			scaled[i] = int64(math.Max(1, math.Round(w*scale)))
+			total += float64(scaled[i])
+		}
+	}
+	if total >= math.MaxInt64/2 {
+		return nil, core.NewLogicError("sat", "MAXSATSolver.SolveWeightedMAXSAT", "weights are too large to scale to integers")
+	}
+	return scaled, nil
+}
 
-				testCNF.AddClause(NewClause(relaxedLiterals...))
+// SolvePartialMAXSAT finds an assignment that satisfies every hard clause
+// of wcnf and minimises the total weight of the violated soft clauses. The
+// result is optimal unless an error is reported; it is an error for the
+// hard clauses to be unsatisfiable or for a weight to be negative.
+func (m *MAXSATSolverImpl) SolvePartialMAXSAT(wcnf *WeightedCNF) *MAXSATResult {
+	if len(wcnf.Weights) != len(wcnf.Soft.Clauses) {
+		return &MAXSATResult{
+			Error: core.NewLogicError("sat", "MAXSATSolver.SolvePartialMAXSAT",
+				fmt.Sprintf("%d weights for %d soft clauses", len(wcnf.Weights), len(wcnf.Soft.Clauses))),
+			Reason: ReasonError,
+		}
+	}
+	var total int64
+	for i, w := range wcnf.Weights {
+		if w < 0 {
+			return &MAXSATResult{
+				Error: core.NewLogicError("sat", "MAXSATSolver.SolvePartialMAXSAT",
+					fmt.Sprintf("soft clause %d has weight %d", wcnf.Soft.Clauses[i].ID, w)),
+				Reason: ReasonError,
 			}
 		}
+		if total += w; total < 0 {
+			return &MAXSATResult{
+				Error:  core.NewLogicError("sat", "MAXSATSolver.SolvePartialMAXSAT", "total soft weight overflows int64"),
+				Reason: ReasonError,
+			}
+		}
+	}
+
+	oll := newOLLSearch(m.baseSolver)
+	model, err := oll.solve(wcnf)
+	if err != nil {
+		return &MAXSATResult{
+			Error:      err,
+			Status:     oll.status,
+			Reason:     oll.reason,
+			Statistics: m.baseSolver.GetStatistics(),
+		}
+	}
+
+	result := &MAXSATResult{
+		Assignment:         model,
+		UnsatisfiedClauses: make([]int, 0),
+		Optimal:            true,
+		Status:             StatusSAT,
+		Statistics:         m.baseSolver.GetStatistics(),
+	}
+	for i, clause := range wcnf.Soft.Clauses {
+		if model.Satisfies(clause) {
+			result.SatisfiedCount++
+			result.TotalWeight += float64(wcnf.Weights[i])
+		} else {
+			result.Cost += wcnf.Weights[i]
+			result.UnsatisfiedClauses = append(result.UnsatisfiedClauses, clause.ID)
+		}
+	}
+	return result
+}
+
+// ollSearch holds the state of one OLL run
+type ollSearch struct {
+	solver IncrementalSolver
+
+	// Assumption literals in creation order, with their remaining weights.
+	// An assumption is dropped from search once its weight reaches zero.
+	assumptions []Literal
+	weights     map[Literal]int64
+
+	sums  map[Literal]*ollSum // Totalizer output assumptions
+	aux   map[string]bool     // Auxiliary variables, hidden from the model
+	names []string            // Auxiliary variable names, kept reachable
+
+	status SolverStatus  // Outcome when solve returns an error
+	reason UnknownReason // Why a StatusUnknown search stopped
+}
+
+// ollSum is the assumption ¬Outputs[bound] of a totalizer over a relaxed
+// core: at most bound of the core's assumptions are violated
+type ollSum struct {
+	totalizer *Totalizer
+	bound     int
+}
+
+func newOLLSearch(solver IncrementalSolver) *ollSearch {
+	return &ollSearch{
+		solver:  solver,
+		weights: make(map[Literal]int64),
+		sums:    make(map[Literal]*ollSum),
+		aux:     make(map[string]bool),
+	}
+}
+
+// newVar returns a fresh auxiliary variable
+func (o *ollSearch) newVar() string {
+	name := fmt.Sprintf("__oll_%d", len(o.names))
+	o.names = append(o.names, name)
+	o.aux[name] = true
+	return name
+}
+
+// addAssumption adds weight to the assumption lit
+func (o *ollSearch) addAssumption(lit Literal, weight int64) {
+	if _, ok := o.weights[lit]; !ok {
+		o.assumptions = append(o.assumptions, lit)
+	}
+	o.weights[lit] += weight
+}
+
+// solve returns an optimal model of wcnf
+func (o *ollSearch) solve(wcnf *WeightedCNF) (Assignment, error) {
+	o.solver.Reset()
+	copyLiveClauses(nil, wcnf.Hard.Clauses, nil, func(_ int, clause *Clause) {
+		o.solver.AddClause(clause)
+	})
+	// Unit soft clauses are assumed directly; longer ones through a
+	// selector s with the clause C ∨ ¬s
+	for i, clause := range wcnf.Soft.Clauses {
+		switch {
+		case isTautology(clause.Literals):
+			// Never violated
+		case len(clause.Literals) == 0:
+			// Always violated; it adds to the cost but not to the search
+		case len(clause.Literals) == 1:
+			o.addAssumption(clause.Literals[0], wcnf.Weights[i])
+		default:
+			selector := Literal{Variable: o.newVar()}
+			lits := make([]Literal, len(clause.Literals), len(clause.Literals)+1)
+			copy(lits, clause.Literals)
+			o.solver.AddClause(NewClause(append(lits, selector.Negate())...))
+			o.addAssumption(selector, wcnf.Weights[i])
+		}
+	}
 
-		// Try to solve
-		result := m.baseSolver.Solve(testCNF)
+	var threshold int64
+	for _, w := range o.weights {
+		threshold = max(threshold, w)
+	}
+	for {
+		active := make([]Literal, 0, len(o.assumptions))
+		for _, lit := range o.assumptions {
+			if w := o.weights[lit]; w > 0 && w >= threshold {
+				active = append(active, lit)
+			}
+		}
 
+		result := o.solver.SolveAssuming(active)
+		if result.Error != nil {
+			o.reason = result.Reason
+			return nil, result.Error
+		}
 		if result.Satisfiable {
-			// Can achieve this weight - try higher
-			bestAssignment = result.Assignment
-			bestWeight = mid
-
-			// Calculate actual satisfied weight
-			actualWeight := 0.0
-			unsatisfied := memory.MustPoolSlice[int](satPool, 0)
-
-			for i, clause := range cnf.Clauses {
-				if result.Assignment.Satisfies(clause) {
-					actualWeight += weights[i]
-				} else {
-					unsatisfied = append(unsatisfied, clause.ID)
+			if threshold = o.nextStratum(threshold); threshold > 0 {
+				continue
+			}
+			model := result.Assignment
+			for name := range o.aux {
+				delete(model, name)
+			}
+			// Soft variables the solver never saw are free; fix them
+			for _, variable := range wcnf.Soft.Variables {
+				if _, ok := model[variable]; !ok {
+					model[variable] = false
 				}
 			}
+			return model, nil
+		}
 
-			bestWeight = actualWeight
-			bestUnsatisfied = unsatisfied
-			low = mid
-		} else {
-			// Cannot achieve this weight - try lower
-			high = mid
+		unsat := o.trim(result.FailedAssumptions)
+		if len(unsat) == 0 {
+			o.status = StatusUNSAT
+			return nil, core.NewLogicError("sat", "MAXSATSolver.SolvePartialMAXSAT", "hard clauses are unsatisfiable")
 		}
+		o.relax(unsat)
 	}
+}
 
-	return &MAXSATResult{
-		Assignment:         bestAssignment,
-		SatisfiedCount:     len(cnf.Clauses) - len(bestUnsatisfied),
-		TotalWeight:        bestWeight,
-		UnsatisfiedClauses: bestUnsatisfied,
-		Statistics:         m.baseSolver.GetStatistics(),
+// nextStratum returns the largest remaining weight below threshold, or 0 if
+// every assumption with weight left is already in search
+func (o *ollSearch) nextStratum(threshold int64) int64 {
+	var next int64
+	for _, w := range o.weights {
+		if w < threshold && w > next {
+			next = w
+		}
 	}
+	return next
+}
+
+// trim re-solves under a core until it stops shrinking, at most a few
+// times, since smaller cores give tighter totalizers
+func (o *ollSearch) trim(unsat []Literal) []Literal {
+	for round := 0; round < 5 && len(unsat) > 1; round++ {
+		result := o.solver.SolveAssuming(unsat)
+		if result.Error != nil || result.Satisfiable || len(result.FailedAssumptions) >= len(unsat) {
+			break
+		}
+		unsat = result.FailedAssumptions
+	}
+	return unsat
+}
+
+// relax charges the core's minimum weight to each of its assumptions and
+// allows one more of them to be violated, by a new totalizer over the core
+// and by loosening the bound of each totalizer already in the core
+func (o *ollSearch) relax(unsat []Literal) {
+	sort.Slice(unsat, func(i, j int) bool {
+		return o.weights[unsat[i]] < o.weights[unsat[j]]
+	})
+	minWeight := o.weights[unsat[0]]
+
+	for _, lit := range unsat {
+		o.weights[lit] -= minWeight
+		if sum, ok := o.sums[lit]; ok && sum.bound+1 < len(sum.totalizer.Outputs) {
+			o.addSum(sum.totalizer, sum.bound+1, minWeight)
+		}
+	}
+	if len(unsat) > 1 {
+		violated := make([]Literal, len(unsat))
+		for i, lit := range unsat {
+			violated[i] = lit.Negate()
+		}
+		totalizer := NewTotalizer(violated, o.newVar)
+		for _, clause := range totalizer.Clauses {
+			o.solver.AddClause(clause)
+		}
+		o.addSum(totalizer, 1, minWeight)
+	}
+}
+
+// addSum assumes that at most bound inputs of totalizer are true
+func (o *ollSearch) addSum(totalizer *Totalizer, bound int, weight int64) {
+	lit := totalizer.Outputs[bound].Negate()
+	o.sums[lit] = &ollSum{totalizer: totalizer, bound: bound}
+	o.addAssumption(lit, weight)
 }
@@ -0,0 +1,244 @@
+package sat
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+func TestTotalizer(t *testing.T) {
+	inputs := []Literal{L("A", false), L("B", true), L("C", false), L("D", false), L("E", false)}
+	next := 0
+	totalizer := NewTotalizer(inputs, func() string {
+		next++
+		return "__tot_" + varName(next)
+	})
+	if len(totalizer.Outputs) != len(inputs) {
+		t.Fatalf("Expected %d outputs, got %d", len(inputs), len(totalizer.Outputs))
+	}
+
+	// With k inputs true, ¬Outputs[j] is consistent exactly when j ≥ k
+	for mask := 0; mask < 1<<len(inputs); mask++ {
+		count := 0
+		solver := NewCDCLSolver()
+		for _, clause := range totalizer.Clauses {
+			solver.AddClause(clause)
+		}
+		var assumptions []Literal
+		for i, lit := range inputs {
+			if mask>>i&1 == 1 {
+				count++
+				assumptions = append(assumptions, lit)
+			} else {
+				assumptions = append(assumptions, lit.Negate())
+			}
+		}
+		for j, output := range totalizer.Outputs {
+			result := solver.SolveAssuming(append(assumptions, output.Negate()))
+			if result.Satisfiable != (j >= count) {
+				t.Fatalf("%d inputs true: expected ¬Outputs[%d] satisfiable=%v", count, j, j >= count)
+			}
+		}
+	}
+}
+
+func TestSolvePartialMAXSAT(t *testing.T) {
+	// Schedule A, B and C; A and B clash, and C needs A
+	wcnf := NewWeightedCNF()
+	wcnf.AddHard(NewClause(L("A", true), L("B", true)))
+	wcnf.AddHard(NewClause(L("C", true), L("A", false)))
+	wcnf.AddSoft(NewClause(L("A", false)), 3)
+	wcnf.AddSoft(NewClause(L("B", false)), 5)
+	wcnf.AddSoft(NewClause(L("C", false)), 4)
+	wcnf.AddSoft(NewClause(L("A", false), L("C", false)), 2)
+
+	var solver PartialMAXSATSolver = NewMAXSATSolver()
+	result := solver.SolvePartialMAXSAT(wcnf)
+	if result.Error != nil {
+		t.Fatalf("SolvePartialMAXSAT failed: %v", result.Error)
+	}
+	// Either B alone (cost 3+4+2) or A and C (cost 5)
+	if result.Status != StatusSAT {
+		t.Errorf("Expected StatusSAT, got %v", result.Status)
+	}
+	if !result.Optimal || result.Cost != 5 {
+		t.Errorf("Expected optimal cost 5, got %d", result.Cost)
+	}
+	if !result.Assignment["A"] || result.Assignment["B"] || !result.Assignment["C"] {
+		t.Errorf("Expected A and C, got %v", result.Assignment)
+	}
+	if len(result.UnsatisfiedClauses) != 1 || result.UnsatisfiedClauses[0] != 2 {
+		t.Errorf("Expected soft clause 2 unsatisfied, got %v", result.UnsatisfiedClauses)
+	}
+	if result.SatisfiedCount != 3 || result.TotalWeight != 9 {
+		t.Errorf("Expected 3 satisfied soft clauses of weight 9, got %d of %v", result.SatisfiedCount, result.TotalWeight)
+	}
+	for name := range result.Assignment {
+		if len(name) > 2 && name[:2] == "__" {
+			t.Errorf("Auxiliary variable %s leaked into the model", name)
+		}
+	}
+}
+
+func TestSolvePartialMAXSAT_Tautology(t *testing.T) {
+	wcnf := NewWeightedCNF()
+	wcnf.AddHard(&Clause{Literals: []Literal{L("A", false), L("A", true), L("B", false)}})
+	wcnf.AddSoft(NewClause(L("A", true)), 2)
+	wcnf.AddSoft(&Clause{Literals: []Literal{L("B", false), L("B", true), L("C", false)}}, 3)
+	wcnf.AddSoft(NewClause(L("C", true)), 1)
+
+	result := NewMAXSATSolver().SolvePartialMAXSAT(wcnf)
+	if result.Error != nil {
+		t.Fatalf("SolvePartialMAXSAT failed: %v", result.Error)
+	}
+	if result.Cost != 0 {
+		t.Errorf("Expected cost 0 with tautologies, got %d", result.Cost)
+	}
+}
+
+func TestSolvePartialMAXSAT_Errors(t *testing.T) {
+	var logicErr *core.LogicError
+
+	wcnf := NewWeightedCNF()
+	wcnf.AddHard(NewClause(L("A", false)))
+	wcnf.AddHard(NewClause(L("A", true)))
+	wcnf.AddSoft(NewClause(L("B", false)), 1)
+	if result := NewMAXSATSolver().SolvePartialMAXSAT(wcnf); !errors.As(result.Error, &logicErr) || result.Status != StatusUNSAT {
+		t.Errorf("Expected UNSAT LogicError for unsatisfiable hard clauses, got %v (%v)", result.Status, result.Error)
+	}
+
+	wcnf = NewWeightedCNF()
+	wcnf.AddSoft(NewClause(L("B", false)), -1)
+	if result := NewMAXSATSolver().SolvePartialMAXSAT(wcnf); !errors.As(result.Error, &logicErr) || result.Reason != ReasonError {
+		t.Errorf("Expected LogicError for a negative weight, got %v (%v)", result.Error, result.Reason)
+	}
+
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false)))
+	if result := NewMAXSATSolver().SolveWeightedMAXSAT(cnf, []float64{1, 2}); !errors.As(result.Error, &logicErr) {
+		t.Errorf("Expected LogicError for mismatched weights, got %v", result.Error)
+	}
+}
+
+func TestSolveWeightedMAXSAT_Fractional(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false)))
+	cnf.AddClause(NewClause(L("A", true)))
+	cnf.AddClause(NewClause(L("A", true), L("B", false)))
+	cnf.AddClause(NewClause(L("B", true)))
+
+	// Keeping A costs 0.75 plus 0.25 or 1.5; dropping it costs 0.5
+	result := NewMAXSATSolver().SolveWeightedMAXSAT(cnf, []float64{0.5, 0.75, 0.25, 1.5})
+	if result.Error != nil {
+		t.Fatalf("SolveWeightedMAXSAT failed: %v", result.Error)
+	}
+	if !result.Optimal || result.Cost != 50 || result.TotalWeight != 2.5 {
+		t.Errorf("Expected cost 0.5 (50 scaled) and weight 2.5, got %d and %v", result.Cost, result.TotalWeight)
+	}
+	if result.Assignment["A"] {
+		t.Errorf("Expected A false, got %v", result.Assignment)
+	}
+}
+
+func TestSolvePartialMAXSAT_Cases(t *testing.T) {
+	a, b, c, d := L("A", false), L("B", false), L("C", false), L("D", false)
+	na, nb, nc, nd := L("A", true), L("B", true), L("C", true), L("D", true)
+	type soft struct {
+		clause []Literal
+		weight int64
+	}
+	testCases := []struct {
+		description  string
+		hard         [][]Literal
+		soft         []soft
+		expectedCost int64
+	}{
+		{
+			description:  "no soft clauses",
+			hard:         [][]Literal{{a, b}, {na}},
+			expectedCost: 0,
+		},
+		{
+			description:  "opposite unit soft clauses",
+			soft:         []soft{{[]Literal{a}, 3}, {[]Literal{na}, 5}},
+			expectedCost: 3,
+		},
+		{
+			description:  "at most one of three",
+			hard:         [][]Literal{{na, nb}, {na, nc}, {nb, nc}},
+			soft:         []soft{{[]Literal{a}, 1}, {[]Literal{b}, 1}, {[]Literal{c}, 1}},
+			expectedCost: 2,
+		},
+		{
+			description:  "at least one of three",
+			hard:         [][]Literal{{a, b, c}},
+			soft:         []soft{{[]Literal{na}, 2}, {[]Literal{nb}, 3}, {[]Literal{nc}, 5}},
+			expectedCost: 2,
+		},
+		{
+			description:  "weight classes choose the heavier pair",
+			hard:         [][]Literal{{na, nb}, {nb, nc}},
+			soft:         []soft{{[]Literal{a}, 30}, {[]Literal{b}, 7}, {[]Literal{c}, 2}},
+			expectedCost: 7,
+		},
+		{
+			description: "disjoint cores add up",
+			soft: []soft{
+				{[]Literal{a}, 1}, {[]Literal{na}, 2},
+				{[]Literal{b}, 7}, {[]Literal{nb}, 7},
+				{[]Literal{c}, 2}, {[]Literal{nc}, 30},
+			},
+			expectedCost: 10,
+		},
+		{
+			description: "hard clauses force soft violations",
+			hard:        [][]Literal{{a}, {na, b}},
+			soft: []soft{
+				{[]Literal{nb}, 4}, {[]Literal{na, c}, 1}, {[]Literal{nc}, 2},
+			},
+			expectedCost: 5,
+		},
+		{
+			description: "at most two of four",
+			hard:        [][]Literal{{na, nb, nc}, {na, nb, nd}, {na, nc, nd}, {nb, nc, nd}},
+			soft: []soft{
+				{[]Literal{a}, 1}, {[]Literal{b}, 2}, {[]Literal{c}, 7}, {[]Literal{d}, 30},
+			},
+			expectedCost: 3,
+		},
+	}
+
+	solver := NewMAXSATSolver()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			wcnf := NewWeightedCNF()
+			for _, clause := range tc.hard {
+				wcnf.AddHard(NewClause(clause...))
+			}
+			for _, s := range tc.soft {
+				wcnf.AddSoft(NewClause(s.clause...), s.weight)
+			}
+
+			result := solver.SolvePartialMAXSAT(wcnf)
+			if result.Error != nil {
+				t.Fatalf("SolvePartialMAXSAT failed: %v", result.Error)
+			}
+			if result.Cost != tc.expectedCost || !result.Optimal {
+				t.Errorf("Expected optimal cost %d, got %d", tc.expectedCost, result.Cost)
+			}
+			if !satisfiesAll(result.Assignment, wcnf.Hard.Clauses) {
+				t.Errorf("Model %v violates the hard clauses", result.Assignment)
+			}
+			var cost int64
+			for i, clause := range wcnf.Soft.Clauses {
+				if !result.Assignment.Satisfies(clause) {
+					cost += wcnf.Weights[i]
+				}
+			}
+			if cost != result.Cost {
+				t.Errorf("Model %v costs %d, reported %d", result.Assignment, cost, result.Cost)
+			}
+		})
+	}
+}
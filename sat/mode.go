@@ -42,17 +42,23 @@ type ReluctantDoubling struct {
 	limit    int
 	count    int
 	enabled  bool
+	pool     *memory.Pool
 }
 
 // newLubySeq creates a Pool-backed Luby sequence for reluctant doubling.
-func newLubySeq() []int {
-	s := memory.MustPoolSlice[int](satPool, 15)[:15]
+func newLubySeq(pool *memory.Pool) []int {
+	s := memory.MustPoolSlice[int](pool, 15)[:15]
 	copy(s, []int{1, 1, 2, 1, 1, 2, 4, 1, 1, 2, 1, 1, 2, 4, 8})
 	return s
 }
 
 // NewModeSwitcher creates a mode switcher starting in focused mode.
 func NewModeSwitcher() *ModeSwitcher {
+	return newModeSwitcher(nil)
+}
+
+func newModeSwitcher(pool *memory.Pool) *ModeSwitcher {
+	pool = poolOr(pool)
 	return &ModeSwitcher{
 		mode:          ModeFocused,
 		baseConflict:  1000,
@@ -60,8 +66,9 @@ func NewModeSwitcher() *ModeSwitcher {
 		conflictLimit: 1000,
 		tickLimit:     500,
 		reluctant: ReluctantDoubling{
-			sequence: newLubySeq(),
+			sequence: newLubySeq(pool),
 			limit:    1,
+			pool:     pool,
 		},
 	}
 }
@@ -143,7 +150,7 @@ func (ms *ModeSwitcher) Reset() {
 	ms.switches = 0
 	ms.conflictsAtMode = 0
 	ms.decisionsAtMode = 0
-	ms.reluctant.sequence = newLubySeq()
+	ms.reluctant.sequence = newLubySeq(ms.reluctant.pool)
 	ms.reluctant.limit = 1
 	ms.reluctant.Reset()
 }
@@ -161,7 +168,7 @@ func (rd *ReluctantDoubling) Reset() {
 func (rd *ReluctantDoubling) extend() {
 	cur := len(rd.sequence)
 	newCap := cur*2 + 1
-	newSeq := memory.MustPoolSlice[int](satPool, newCap)[:newCap]
+	newSeq := memory.MustPoolSlice[int](poolOr(rd.pool), newCap)[:newCap]
 	copy(newSeq, rd.sequence)
 	copy(newSeq[cur:], rd.sequence)
 	newSeq[newCap-1] = 1 << (newCap / cur)
@@ -144,7 +144,7 @@ func TestModeSwitcherOnConflict(t *testing.T) {
 
 func TestReluctantSequenceExtension(t *testing.T) {
 	rd := &ReluctantDoubling{
-		sequence: newLubySeq(),
+		sequence: newLubySeq(satPool),
 		limit:    1,
 		enabled:  true,
 	}
@@ -0,0 +1,446 @@
+package sat
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xDarkicex/logic/modal"
+	"github.com/xDarkicex/memory"
+)
+
+// ModelCountStatistics reports the work done by a ModelCounter
+type ModelCountStatistics struct {
+	Decisions  int64 // Branches on projection variables
+	Components int64 // Components with projection variables counted
+	CacheHits  int64 // Components whose count was found in the cache
+	SATChecks  int64 // Satisfiability checks of components without projection variables
+}
+
+// ModelCounter counts the models of a CNF formula projected onto a set of
+// variables exactly, in the style of sharpSAT: a DPLL search that branches
+// only on projection variables, unit propagates, splits the residual
+// formula into independent components with modal.ComponentDecomposer,
+// multiplies their counts and caches each component's count by its
+// clauses. A component without projection variables counts one if it is
+// satisfiable, which an incremental CDCLSolver decides.
+//
+// A counter can be reused but not shared between goroutines.
+type ModelCounter struct {
+	vars       map[string]int32
+	clauses    []countClause // Original clauses
+	projected  []bool        // Variable -> in the projection
+	values     []int8        // Variable -> 1 true, -1 false, 0 unassigned
+	trail      []int32       // Assigned literals, in order
+	cache      map[string]*big.Int
+	solver     *CDCLSolver
+	selectors  []string // Original clause -> selector in solver
+	decomposer *modal.ComponentDecomposer
+	pool       *memory.Pool
+	statistics ModelCountStatistics
+}
+
+// countClause is a clause over variable indices, its literals sorted, with
+// the original clause it derives from
+type countClause struct {
+	lits   []int32
+	origin int
+}
+
+// NewModelCounter creates a new model counter
+func NewModelCounter() *ModelCounter {
+	return &ModelCounter{}
+}
+
+// CountModels returns the number of models of cnf projected onto
+// projection; see ModelCounter.Count
+func CountModels(cnf *CNF, projection []string) (*big.Int, error) {
+	return NewModelCounter().Count(cnf, projection)
+}
+
+// Count returns the number of assignments to the projection variables
+// that extend to a model of cnf. A nil projection means every variable of
+// cnf, which counts the models themselves. Projection variables that do
+// not occur in cnf are free and double the count.
+func (mc *ModelCounter) Count(cnf *CNF, projection []string) (*big.Int, error) {
+	mc.load(cnf)
+	mc.pool = mustCreatePool()
+	mc.decomposer = modal.NewComponentDecomposer(mc.pool)
+	defer func() {
+		mc.pool.Free()
+		mc.pool, mc.decomposer = nil, nil
+	}()
+
+	if projection == nil {
+		projection = cnf.Variables
+	}
+	seen := make(map[string]bool, len(projection))
+	for _, name := range projection {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if v, ok := mc.vars[name]; ok {
+			mc.projected[v] = true
+		}
+	}
+
+	formula := make([]countClause, len(mc.clauses))
+	copy(formula, mc.clauses)
+	for _, clause := range formula {
+		if len(clause.lits) == 0 {
+			return new(big.Int), nil
+		}
+	}
+	count, err := mc.count(formula)
+	if err != nil {
+		return nil, err
+	}
+	// Projection variables that occur in no clause are free
+	return count.Lsh(count, uint(len(seen)-mc.projectedIn(formula))), nil
+}
+
+// Statistics returns the work done by the last Count call
+func (mc *ModelCounter) Statistics() ModelCountStatistics {
+	return mc.statistics
+}
+
+// load numbers the variables of cnf and loads its clauses, each guarded by
+// a selector, into a fresh solver for the satisfiability checks
+func (mc *ModelCounter) load(cnf *CNF) {
+	mc.vars = make(map[string]int32, len(cnf.Variables))
+	for _, name := range cnf.Variables {
+		mc.vars[name] = int32(len(mc.vars) + 1)
+	}
+	n := len(mc.vars) + 1
+	mc.projected = make([]bool, n)
+	mc.values = make([]int8, n)
+	mc.trail = mc.trail[:0]
+	mc.cache = make(map[string]*big.Int)
+	mc.statistics = ModelCountStatistics{}
+	mc.solver = NewCDCLSolver()
+
+	mc.clauses = make([]countClause, 0, len(cnf.Clauses))
+	mc.selectors = make([]string, len(cnf.Clauses))
+	copyLiveClauses(nil, cnf.Clauses, func(i int, lits []Literal) []Literal {
+		mc.selectors[i] = fmt.Sprintf("__count_%d", i)
+		return guardedBy(lits, mc.selectors[i])
+	}, func(i int, clause *Clause) {
+		lits := make([]int32, 0, len(cnf.Clauses[i].Literals))
+		for _, lit := range cnf.Clauses[i].Literals {
+			l := mc.vars[lit.Variable]
+			if lit.Negated {
+				l = -l
+			}
+			lits = append(lits, l)
+		}
+		sortLiterals(lits)
+		mc.clauses = append(mc.clauses, countClause{lits: lits, origin: i})
+		mc.solver.AddClause(clause)
+	})
+}
+
+// sortLiterals orders literals by variable, so equal clauses compare equal
+func sortLiterals(lits []int32) {
+	sort.Slice(lits, func(i, j int) bool {
+		a, b := lits[i], lits[j]
+		if a < 0 {
+			a = -a
+		}
+		if b < 0 {
+			b = -b
+		}
+		if a != b {
+			return a < b
+		}
+		return lits[i] < lits[j]
+	})
+}
+
+// count returns the number of assignments to the projection variables of
+// formula that extend to a model of it
+func (mc *ModelCounter) count(formula []countClause) (*big.Int, error) {
+	before := mc.projectedIn(formula)
+	mark := len(mc.trail)
+	defer mc.undo(mark)
+
+	formula, ok := mc.propagate(formula)
+	if !ok {
+		return new(big.Int), nil
+	}
+	fixed := 0
+	for _, lit := range mc.trail[mark:] {
+		if mc.projected[abs32(lit)] {
+			fixed++
+		}
+	}
+	// Projection variables that dropped out without being assigned are free
+	result := new(big.Int).Lsh(big.NewInt(1), uint(before-fixed-mc.projectedIn(formula)))
+	if len(formula) == 0 {
+		return result, nil
+	}
+
+	var counted [][]countClause
+	for _, component := range mc.components(formula) {
+		if mc.projectedIn(component) > 0 {
+			counted = append(counted, component)
+			continue
+		}
+		sat, err := mc.satisfiable(component)
+		if err != nil {
+			return nil, err
+		}
+		if !sat {
+			return new(big.Int), nil
+		}
+	}
+	for _, component := range counted {
+		n, err := mc.countComponent(component)
+		if err != nil {
+			return nil, err
+		}
+		if n.Sign() == 0 {
+			return n, nil
+		}
+		result.Mul(result, n)
+	}
+	return result, nil
+}
+
+// countComponent counts a component with projection variables by
+// branching on its most frequent one
+func (mc *ModelCounter) countComponent(component []countClause) (*big.Int, error) {
+	mc.statistics.Components++
+	key := componentKey(component)
+	if n, ok := mc.cache[key]; ok {
+		mc.statistics.CacheHits++
+		return new(big.Int).Set(n), nil
+	}
+
+	occurrences := make(map[int32]int)
+	var branch int32
+	for _, clause := range component {
+		for _, lit := range clause.lits {
+			v := abs32(lit)
+			if !mc.projected[v] {
+				continue
+			}
+			occurrences[v]++
+			if branch == 0 || occurrences[v] > occurrences[branch] ||
+				(occurrences[v] == occurrences[branch] && v < branch) {
+				branch = v
+			}
+		}
+	}
+
+	before := mc.projectedIn(component)
+	total := new(big.Int)
+	for _, lit := range []int32{branch, -branch} {
+		mc.statistics.Decisions++
+		mark := len(mc.trail)
+		mc.assign(lit)
+		residual, ok := mc.simplify(component)
+		var n *big.Int
+		if ok {
+			var err error
+			n, err = mc.count(residual)
+			if err != nil {
+				mc.undo(mark)
+				return nil, err
+			}
+			n.Lsh(n, uint(before-1-mc.projectedIn(residual)))
+			total.Add(total, n)
+		}
+		mc.undo(mark)
+	}
+	mc.cache[key] = new(big.Int).Set(total)
+	return total, nil
+}
+
+// propagate assigns the unit clauses of formula until none is left and
+// returns the simplified formula, or false on a conflict
+func (mc *ModelCounter) propagate(formula []countClause) ([]countClause, bool) {
+	for {
+		units := 0
+		for _, clause := range formula {
+			if len(clause.lits) == 1 {
+				lit := clause.lits[0]
+				if mc.value(lit) < 0 {
+					return nil, false
+				}
+				if mc.value(lit) == 0 {
+					mc.assign(lit)
+					units++
+				}
+			}
+		}
+		if units == 0 {
+			return formula, true
+		}
+		var ok bool
+		if formula, ok = mc.simplify(formula); !ok {
+			return nil, false
+		}
+	}
+}
+
+// simplify drops satisfied clauses and false literals under the current
+// assignment. It reports false if a clause becomes empty.
+func (mc *ModelCounter) simplify(formula []countClause) ([]countClause, bool) {
+	result := make([]countClause, 0, len(formula))
+	for _, clause := range formula {
+		satisfied := false
+		kept := 0
+		for _, lit := range clause.lits {
+			switch mc.value(lit) {
+			case 1:
+				satisfied = true
+			case 0:
+				kept++
+			}
+			if satisfied {
+				break
+			}
+		}
+		if satisfied {
+			continue
+		}
+		if kept == 0 {
+			return nil, false
+		}
+		if kept == len(clause.lits) {
+			result = append(result, clause)
+			continue
+		}
+		lits := make([]int32, 0, kept)
+		for _, lit := range clause.lits {
+			if mc.value(lit) == 0 {
+				lits = append(lits, lit)
+			}
+		}
+		result = append(result, countClause{lits: lits, origin: clause.origin})
+	}
+	return result, true
+}
+
+// components splits formula into its connected components
+func (mc *ModelCounter) components(formula []countClause) [][]countClause {
+	lits := make([][]int32, len(formula))
+	for i, clause := range formula {
+		lits[i] = clause.lits
+	}
+	split := mc.decomposer.Decompose(lits)
+
+	// The decomposer's result lives in the pool, so only the component of
+	// each variable is kept before releasing it. A clause belongs to the
+	// component of its first variable.
+	part := make([]int, len(mc.values))
+	for c, clauses := range split {
+		for _, clause := range clauses {
+			for _, lit := range clause {
+				part[abs32(lit)] = c + 1
+			}
+		}
+	}
+	mc.pool.Reset()
+	components := make([][]countClause, len(split))
+	for _, clause := range formula {
+		c := part[abs32(clause.lits[0])] - 1
+		if c < 0 {
+			panic(fmt.Errorf("model counter: variable %d in no component", abs32(clause.lits[0])))
+		}
+		components[c] = append(components[c], clause)
+	}
+	return components
+}
+
+// satisfiable reports whether a component is satisfiable: its original
+// clauses, with the current values of their variables, have a model
+func (mc *ModelCounter) satisfiable(component []countClause) (bool, error) {
+	mc.statistics.SATChecks++
+	var assumptions []Literal
+	assumed := make(map[int32]bool)
+	names := mc.names()
+	for _, clause := range component {
+		assumptions = append(assumptions, Literal{Variable: mc.selectors[clause.origin]})
+		for _, lit := range mc.clauses[clause.origin].lits {
+			v := abs32(lit)
+			if mc.values[v] != 0 && !assumed[v] {
+				assumed[v] = true
+				assumptions = append(assumptions, Literal{Variable: names[v], Negated: mc.values[v] < 0})
+			}
+		}
+	}
+	result := mc.solver.SolveAssuming(assumptions)
+	return result.Satisfiable, result.Error
+}
+
+// names returns variable names by index
+func (mc *ModelCounter) names() []string {
+	names := make([]string, len(mc.values))
+	for name, v := range mc.vars {
+		names[v] = name
+	}
+	return names
+}
+
+// componentKey is a canonical string for a component's clauses
+func componentKey(component []countClause) string {
+	clauses := make([]string, len(component))
+	for i, clause := range component {
+		var b strings.Builder
+		for _, lit := range clause.lits {
+			b.WriteString(strconv.Itoa(int(lit)))
+			b.WriteByte(' ')
+		}
+		clauses[i] = b.String()
+	}
+	sort.Strings(clauses)
+	return strings.Join(clauses, "0 ")
+}
+
+// projectedIn returns the number of projection variables in formula
+func (mc *ModelCounter) projectedIn(formula []countClause) int {
+	seen := make(map[int32]bool)
+	for _, clause := range formula {
+		for _, lit := range clause.lits {
+			if v := abs32(lit); mc.projected[v] {
+				seen[v] = true
+			}
+		}
+	}
+	return len(seen)
+}
+
+func (mc *ModelCounter) value(lit int32) int8 {
+	if lit < 0 {
+		return -mc.values[-lit]
+	}
+	return mc.values[lit]
+}
+
+func (mc *ModelCounter) assign(lit int32) {
+	if lit < 0 {
+		mc.values[-lit] = -1
+	} else {
+		mc.values[lit] = 1
+	}
+	mc.trail = append(mc.trail, lit)
+}
+
+// undo unassigns the literals assigned since the trail had length mark
+func (mc *ModelCounter) undo(mark int) {
+	for _, lit := range mc.trail[mark:] {
+		mc.values[abs32(lit)] = 0
+	}
+	mc.trail = mc.trail[:mark]
+}
+
+func abs32(x int32) int32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,231 @@
+package sat
+
+import (
+	"io"
+	"math/big"
+	"testing"
+)
+
+func TestModelEnumerator(t *testing.T) {
+	// (A ∨ B) ∧ (¬A ∨ C): four models, three distinct on {A, B}
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	cnf.AddClause(NewClause(L("A", true), L("C", false)))
+
+	for _, tc := range []struct {
+		projection []string
+		want       int
+	}{
+		{nil, 4},
+		{[]string{"A", "B"}, 3},
+		{[]string{"A"}, 2},
+		{[]string{"A", "A", "D"}, 4},
+		{[]string{}, 1},
+	} {
+		enumerator := NewModelEnumerator(cnf, tc.projection)
+		seen := make(map[string]bool)
+		for {
+			model, err := enumerator.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Projection %v: Next failed: %v", tc.projection, err)
+			}
+			key := ""
+			for _, name := range []string{"A", "B", "C", "D"} {
+				if value, ok := model[name]; ok {
+					key += name + map[bool]string{true: "1", false: "0"}[value]
+				}
+			}
+			if seen[key] {
+				t.Fatalf("Projection %v: model %s returned twice", tc.projection, key)
+			}
+			seen[key] = true
+		}
+		if enumerator.Count() != tc.want {
+			t.Errorf("Projection %v: expected %d models, got %d", tc.projection, tc.want, enumerator.Count())
+		}
+		if _, err := enumerator.Next(); err != io.EOF {
+			t.Errorf("Projection %v: expected io.EOF after the last model, got %v", tc.projection, err)
+		}
+	}
+}
+
+func TestCountModels(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	cnf.AddClause(NewClause(L("A", true), L("C", false)))
+
+	for _, tc := range []struct {
+		projection []string
+		want       int64
+	}{
+		{nil, 4},
+		{[]string{"A", "B"}, 3},
+		{[]string{"C"}, 2},
+		{[]string{"A", "D", "E"}, 8},
+		{[]string{}, 1},
+	} {
+		count, err := CountModels(cnf, tc.projection)
+		if err != nil {
+			t.Fatalf("Projection %v: CountModels failed: %v", tc.projection, err)
+		}
+		if count.Cmp(big.NewInt(tc.want)) != 0 {
+			t.Errorf("Projection %v: expected %d models, got %v", tc.projection, tc.want, count)
+		}
+	}
+
+	cnf.AddClause(NewClause(L("A", false)))
+	cnf.AddClause(NewClause(L("C", true)))
+	if count, err := CountModels(cnf, nil); err != nil || count.Sign() != 0 {
+		t.Errorf("Expected no models of an unsatisfiable formula, got %v (%v)", count, err)
+	}
+}
+
+func TestCountModels_Tautology(t *testing.T) {
+	// (A ∨ ¬A ∨ B) ∧ C: A and B are free
+	cnf := NewCNF()
+	cnf.AddClause(&Clause{Literals: []Literal{L("A", false), L("A", true), L("B", false)}})
+	cnf.AddClause(NewClause(L("C", false)))
+
+	if count, err := CountModels(cnf, nil); err != nil || count.Cmp(big.NewInt(4)) != 0 {
+		t.Errorf("Expected 4 models, got %v (%v)", count, err)
+	}
+	enumerator := NewModelEnumerator(cnf, nil)
+	for {
+		if _, err := enumerator.Next(); err != nil {
+			break
+		}
+	}
+	if enumerator.Count() != 4 {
+		t.Errorf("Expected 4 enumerated models, got %d", enumerator.Count())
+	}
+}
+
+func TestCountModels_Components(t *testing.T) {
+	// 40 independent copies of (x ∨ y): 3^40 models, beyond int64
+	cnf := NewCNF()
+	for i := 0; i < 40; i++ {
+		cnf.AddClause(NewClause(L(varName(2*i), false), L(varName(2*i+1), false)))
+	}
+	counter := NewModelCounter()
+	count, err := counter.Count(cnf, nil)
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	want := new(big.Int).Exp(big.NewInt(3), big.NewInt(40), nil)
+	if count.Cmp(want) != 0 {
+		t.Errorf("Expected %v models, got %v", want, count)
+	}
+	if stats := counter.Statistics(); stats.Components != 40 {
+		t.Errorf("Expected each copy to be counted as its own component, got %+v", stats)
+	}
+}
+
+func TestCountModels_Cases(t *testing.T) {
+	a, b, c, d, e := L("A", false), L("B", false), L("C", false), L("D", false), L("E", false)
+	na, nb, nc, nd := L("A", true), L("B", true), L("C", true), L("D", true)
+	testCases := []struct {
+		description string
+		clauses     [][]Literal
+		projection  []string
+		expected    int64
+	}{
+		{
+			description: "exactly one of four",
+			clauses:     [][]Literal{{a, b, c, d}, {na, nb}, {na, nc}, {na, nd}, {nb, nc}, {nb, nd}, {nc, nd}},
+			expected:    4,
+		},
+		{
+			description: "exactly one of four projected onto two",
+			clauses:     [][]Literal{{a, b, c, d}, {na, nb}, {na, nc}, {na, nd}, {nb, nc}, {nb, nd}, {nc, nd}},
+			projection:  []string{"A", "B"},
+			expected:    3,
+		},
+		{
+			description: "implication chain",
+			clauses:     [][]Literal{{na, b}, {nb, c}, {nc, d}},
+			expected:    5,
+		},
+		{
+			description: "implication chain projected onto its tail",
+			clauses:     [][]Literal{{na, b}, {nb, c}, {nc, d}},
+			projection:  []string{"D"},
+			expected:    2,
+		},
+		{
+			description: "projection onto a variable outside the formula",
+			clauses:     [][]Literal{{na, b}, {nb, c}, {nc, d}},
+			projection:  []string{"A", "Z"},
+			expected:    4,
+		},
+		{
+			description: "odd parity of three",
+			clauses:     [][]Literal{{a, b, c}, {a, nb, nc}, {na, b, nc}, {na, nb, c}},
+			expected:    4,
+		},
+		{
+			description: "odd parity projected onto two",
+			clauses:     [][]Literal{{a, b, c}, {a, nb, nc}, {na, b, nc}, {na, nb, c}},
+			projection:  []string{"A", "B"},
+			expected:    4,
+		},
+		{
+			description: "two components",
+			clauses:     [][]Literal{{a, b}, {c, d, e}},
+			expected:    21,
+		},
+		{
+			description: "no two adjacent variables false",
+			clauses:     [][]Literal{{a, b}, {b, c}, {c, d}},
+			expected:    8,
+		},
+		{
+			description: "no two adjacent variables false projected onto the ends",
+			clauses:     [][]Literal{{a, b}, {b, c}, {c, d}},
+			projection:  []string{"A", "D"},
+			expected:    4,
+		},
+		{
+			description: "pigeonhole",
+			clauses:     pigeonholeClauses(3, 2),
+			expected:    0,
+		},
+	}
+
+	counter := NewModelCounter()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cnf := buildCNF(tc.clauses)
+			count, err := counter.Count(cnf, tc.projection)
+			if err != nil {
+				t.Fatalf("Count failed: %v", err)
+			}
+			if count.Cmp(big.NewInt(tc.expected)) != 0 {
+				t.Errorf("Expected %d models, got %v", tc.expected, count)
+			}
+
+			full := tc.projection
+			if full == nil {
+				full = cnf.Variables
+			}
+			enumerator := NewModelEnumerator(cnf, tc.projection)
+			for {
+				model, err := enumerator.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Next failed: %v", err)
+				}
+				if len(model) != len(full) {
+					t.Fatalf("Expected a model of %d variables, got %v", len(full), model)
+				}
+			}
+			if int64(enumerator.Count()) != tc.expected {
+				t.Errorf("Expected %d enumerated models, got %d", tc.expected, enumerator.Count())
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package sat
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+// MUSResult reports a minimal unsatisfiable subset of a formula
+type MUSResult struct {
+	// ClauseIDs lists the Clause.ID of every clause in the subset, in
+	// ascending order. For a group MUS these are the clauses of Groups;
+	// clauses outside every group are not listed.
+	ClauseIDs []int
+
+	// Groups names the groups of a group MUS, sorted. It is nil for a
+	// clause-level MUS.
+	Groups []string
+
+	SolverCalls int // SolveAssuming calls made during extraction
+	Rotated     int // Groups shown necessary by model rotation, without a solver call
+	Refined     int // Groups dropped by clause-set refinement
+}
+
+// MUSExtractor computes minimal unsatisfiable subsets by deletion: each
+// candidate clause is left out in turn, and it belongs to the MUS exactly
+// when the rest becomes satisfiable. Two standard techniques cut the
+// number of solver calls:
+//
+//   - Clause-set refinement: after an UNSAT call, every candidate outside
+//     the failed-assumption core is dropped at once.
+//   - Recursive model rotation (Belov & Marques-Silva, 2011): a model that
+//     falsifies only the necessary clause c is changed one variable of c at
+//     a time; when the changed model falsifies exactly one other candidate,
+//     that candidate is necessary as well.
+//
+// Every clause gets a selector variable and the queries share one
+// incremental CDCLSolver. An extractor can be reused but not shared
+// between goroutines.
+type MUSExtractor struct {
+	solver *CDCLSolver
+
+	clauses   []*Clause
+	groupOf   []int       // Clause index -> group index, -1 for hard clauses
+	members   [][]int     // Group index -> clause indices
+	status    []musStatus // Group index -> extraction state
+	selectors []string    // Group index -> selector variable
+	selected  map[string]int
+	occurs    map[Literal][]int // Literal -> clause indices containing it
+	deadline  time.Time         // Bounds every solve if not zero
+
+	result *MUSResult
+}
+
+type musStatus uint8
+
+const (
+	musCandidate musStatus = iota
+	musNecessary
+	musRemoved
+)
+
+// NewMUSExtractor creates a new MUS extractor
+func NewMUSExtractor() *MUSExtractor {
+	return &MUSExtractor{
+		solver: NewCDCLSolver(),
+	}
+}
+
+// ExtractMUS returns a minimal unsatisfiable subset of cnf's clauses
+func ExtractMUS(cnf *CNF) (*MUSResult, error) {
+	return NewMUSExtractor().Extract(cnf)
+}
+
+// Extract returns a minimal unsatisfiable subset of cnf's clauses: removing
+// any clause of the subset makes it satisfiable. It fails if cnf is
+// satisfiable.
+func (m *MUSExtractor) Extract(cnf *CNF) (*MUSResult, error) {
+	groupOf := make([]int, len(cnf.Clauses))
+	for i := range groupOf {
+		groupOf[i] = i
+	}
+	if err := m.extract(cnf, groupOf, len(cnf.Clauses)); err != nil {
+		return nil, err
+	}
+	return m.result, nil
+}
+
+// ExtractGroups returns a minimal unsatisfiable set of clause groups. Each
+// group names the IDs of its clauses. Clauses outside every group are hard:
+// they are always kept and never reported. The result is empty if the hard
+// clauses alone are unsatisfiable. It fails if the whole formula is
+// satisfiable, or if a group names an unknown clause or one that already
+// belongs to another group.
+func (m *MUSExtractor) ExtractGroups(cnf *CNF, groups map[string][]int) (*MUSResult, error) {
+	index := make(map[int]int, len(cnf.Clauses))
+	for i, clause := range cnf.Clauses {
+		index[clause.ID] = i
+	}
+	groupOf := make([]int, len(cnf.Clauses))
+	for i := range groupOf {
+		groupOf[i] = -1
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for g, name := range names {
+		for _, id := range groups[name] {
+			i, ok := index[id]
+			if !ok {
+				return nil, core.NewLogicError("sat", "MUSExtractor.ExtractGroups",
+					fmt.Sprintf("group %q names unknown clause %d", name, id))
+			}
+			if groupOf[i] >= 0 && groupOf[i] != g {
+				return nil, core.NewLogicError("sat", "MUSExtractor.ExtractGroups",
+					fmt.Sprintf("clause %d is in groups %q and %q", id, names[groupOf[i]], name))
+			}
+			groupOf[i] = g
+		}
+	}
+
+	if err := m.extract(cnf, groupOf, len(names)); err != nil {
+		return nil, err
+	}
+	m.result.Groups = make([]string, 0)
+	for g, name := range names {
+		if m.status[g] == musNecessary {
+			m.result.Groups = append(m.result.Groups, name)
+		}
+	}
+	return m.result, nil
+}
+
+// extract runs deletion-based extraction over numGroups groups
+func (m *MUSExtractor) extract(cnf *CNF, groupOf []int, numGroups int) error {
+	m.load(cnf, groupOf, numGroups)
+
+	result := m.solve(-1)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.Satisfiable {
+		return core.NewLogicError("sat", "MUSExtractor.Extract", "formula is satisfiable")
+	}
+	if err := m.shrink(result.FailedAssumptions); err != nil {
+		return err
+	}
+	m.result.ClauseIDs = m.necessaryIDs()
+	return nil
+}
+
+// shrink reduces the candidate groups, which together with the necessary
+// groups and the hard clauses are unsatisfiable with the given core, until
+// every remaining group is necessary
+func (m *MUSExtractor) shrink(core []Literal) error {
+	m.refine(core)
+	for g := range m.status {
+		if m.status[g] != musCandidate {
+			continue
+		}
+		result := m.solve(g)
+		if result.Error != nil {
+			return result.Error
+		}
+		if !result.Satisfiable {
+			m.status[g] = musRemoved
+			m.refine(result.FailedAssumptions)
+			continue
+		}
+
+		// Without g the rest is satisfiable, so g is necessary and the
+		// model falsifies only clauses of g
+		m.status[g] = musNecessary
+		model := result.Assignment
+		m.rotate(model, m.falsified(model, m.members[g]))
+	}
+	return nil
+}
+
+// necessaryIDs returns the IDs of the clauses in necessary groups, sorted
+func (m *MUSExtractor) necessaryIDs() []int {
+	ids := make([]int, 0)
+	for i, clause := range m.clauses {
+		if g := m.groupOf[i]; g >= 0 && m.status[g] == musNecessary {
+			ids = append(ids, clause.ID)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// load adds cnf to a fresh solver, each group guarded by its selector
+func (m *MUSExtractor) load(cnf *CNF, groupOf []int, numGroups int) {
+	m.solver.Reset()
+	m.clauses = cnf.Clauses
+	m.groupOf = groupOf
+	m.members = make([][]int, numGroups)
+	m.status = make([]musStatus, numGroups)
+	m.selectors = make([]string, numGroups)
+	m.selected = make(map[string]int, numGroups)
+	m.occurs = make(map[Literal][]int)
+	m.result = &MUSResult{}
+
+	for g := range m.selectors {
+		m.selectors[g] = fmt.Sprintf("__mus_%d", g)
+		m.selected[m.selectors[g]] = g
+	}
+	for i, clause := range cnf.Clauses {
+		for _, lit := range clause.Literals {
+			m.occurs[lit] = append(m.occurs[lit], i)
+		}
+		g := groupOf[i]
+		if g >= 0 {
+			m.members[g] = append(m.members[g], i)
+		}
+	}
+	copyLiveClauses(nil, cnf.Clauses, func(i int, lits []Literal) []Literal {
+		if g := groupOf[i]; g >= 0 {
+			return guardedBy(lits, m.selectors[g])
+		}
+		return lits
+	}, func(_ int, clause *Clause) {
+		m.solver.AddClause(clause)
+	})
+}
+
+// solve checks the candidate groups other than skip, with the necessary
+// groups and hard clauses. Removed groups are left unassumed, so their
+// selectors are free and the guarded clauses can always be satisfied.
+func (m *MUSExtractor) solve(skip int) *SolverResult {
+	assumptions := make([]Literal, 0, len(m.status))
+	for g, status := range m.status {
+		if status != musRemoved && g != skip {
+			assumptions = append(assumptions, Literal{Variable: m.selectors[g]})
+		}
+	}
+	if !m.deadline.IsZero() && !time.Now().Before(m.deadline) {
+		return &SolverResult{
+			Error:  core.NewLogicError("sat", "MUSExtractor.solve", "timeout exceeded"),
+			Status: StatusUnknown,
+			Reason: ReasonTimeout,
+		}
+	}
+	m.result.SolverCalls++
+	return m.solver.solveAssuming(nil, assumptions, untilDeadline(m.deadline))
+}
+
+// refine drops every candidate group whose selector is not in core
+func (m *MUSExtractor) refine(core []Literal) {
+	inCore := make(map[int]bool, len(core))
+	for _, lit := range core {
+		if g, ok := m.selected[lit.Variable]; ok {
+			inCore[g] = true
+		}
+	}
+	for g, status := range m.status {
+		if status == musCandidate && !inCore[g] {
+			m.status[g] = musRemoved
+			m.result.Refined++
+		}
+	}
+}
+
+// rotate changes model, which falsifies only the given clauses of a
+// necessary group, one variable at a time. A changed model that falsifies
+// only clauses of a single candidate group shows that group necessary, and
+// rotation continues from there.
+func (m *MUSExtractor) rotate(model Assignment, falsified []int) {
+	for _, i := range falsified {
+		for _, lit := range m.clauses[i].Literals {
+			// lit is false in model; flipping it makes its negation false
+			model[lit.Variable] = !lit.Negated
+			next := m.falsified(model, falsified)
+			next = append(next, m.falsified(model, m.occurs[lit.Negate()])...)
+
+			if g := m.soleCandidate(next); g >= 0 {
+				m.status[g] = musNecessary
+				m.result.Rotated++
+				m.rotate(model, next)
+			}
+			model[lit.Variable] = lit.Negated
+		}
+	}
+}
+
+// soleCandidate returns the group of the given clauses if they all belong
+// to the same candidate group, and -1 otherwise
+func (m *MUSExtractor) soleCandidate(clauses []int) int {
+	if len(clauses) == 0 {
+		return -1
+	}
+	g := m.groupOf[clauses[0]]
+	if g < 0 || m.status[g] != musCandidate {
+		return -1
+	}
+	for _, i := range clauses[1:] {
+		if m.groupOf[i] != g {
+			return -1
+		}
+	}
+	return g
+}
+
+// falsified returns the clauses among the given ones that are still part
+// of the formula and false under model
+func (m *MUSExtractor) falsified(model Assignment, clauses []int) []int {
+	var result []int
+	for _, i := range clauses {
+		if g := m.groupOf[i]; g >= 0 && m.status[g] == musRemoved {
+			continue
+		}
+		if !m.satisfied(model, i) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// satisfied reports whether model, with unassigned variables false,
+// satisfies clause i
+func (m *MUSExtractor) satisfied(model Assignment, i int) bool {
+	for _, lit := range m.clauses[i].Literals {
+		if model[lit.Variable] != lit.Negated {
+			return true
+		}
+	}
+	return false
+}
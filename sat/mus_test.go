@@ -0,0 +1,234 @@
+package sat
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+// checkMUS fails t unless ids name an unsatisfiable subset of cnf that
+// becomes satisfiable when any clause is removed
+func checkMUS(t *testing.T, cnf *CNF, ids []int) {
+	t.Helper()
+	byID := make(map[int]*Clause)
+	for _, clause := range cnf.Clauses {
+		byID[clause.ID] = clause
+	}
+	subset := make([]*Clause, 0, len(ids))
+	for _, id := range ids {
+		subset = append(subset, byID[id])
+	}
+	if bruteForceSAT(subset) {
+		t.Fatalf("Subset %v is satisfiable", ids)
+	}
+	for i := range subset {
+		rest := append(append([]*Clause{}, subset[:i]...), subset[i+1:]...)
+		if !bruteForceSAT(rest) {
+			t.Fatalf("Subset %v is not minimal: clause %d is redundant", ids, ids[i])
+		}
+	}
+}
+
+func TestExtractMUS_Basic(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false)))                // 1
+	cnf.AddClause(NewClause(L("C", false), L("D", false))) // 2
+	cnf.AddClause(NewClause(L("A", true), L("B", false)))  // 3
+	cnf.AddClause(NewClause(L("C", true)))                 // 4
+	cnf.AddClause(NewClause(L("B", true)))                 // 5
+	cnf.AddClause(NewClause(L("A", false), L("D", false))) // 6
+
+	result, err := ExtractMUS(cnf)
+	if err != nil {
+		t.Fatalf("ExtractMUS failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.ClauseIDs, []int{1, 3, 5}) {
+		t.Errorf("Expected MUS [1 3 5], got %v", result.ClauseIDs)
+	}
+	if result.Groups != nil {
+		t.Errorf("Expected no groups, got %v", result.Groups)
+	}
+	if result.Refined == 0 {
+		t.Error("Expected clause-set refinement to drop clauses outside the core")
+	}
+}
+
+func TestExtractMUS_Tautology(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(&Clause{Literals: []Literal{L("A", false), L("A", true), L("B", false)}}) // 1
+	cnf.AddClause(NewClause(L("A", true)))                                                  // 2
+	cnf.AddClause(NewClause(L("C", false)))                                                 // 3
+	cnf.AddClause(NewClause(L("C", true)))                                                  // 4
+
+	result, err := ExtractMUS(cnf)
+	if err != nil {
+		t.Fatalf("ExtractMUS failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.ClauseIDs, []int{3, 4}) {
+		t.Errorf("Expected MUS [3 4], got %v", result.ClauseIDs)
+	}
+}
+
+func TestExtractMUS_EmptyClause(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false)))
+	cnf.AddClause(NewClause())
+	cnf.AddClause(NewClause(L("A", true)))
+
+	result, err := ExtractMUS(cnf)
+	if err != nil {
+		t.Fatalf("ExtractMUS failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.ClauseIDs, []int{2}) {
+		t.Errorf("Expected MUS [2], got %v", result.ClauseIDs)
+	}
+}
+
+func TestExtractMUS_Satisfiable(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+
+	_, err := ExtractMUS(cnf)
+	var logicErr *core.LogicError
+	if !errors.As(err, &logicErr) {
+		t.Errorf("Expected LogicError for a satisfiable formula, got %v", err)
+	}
+}
+
+func TestExtractMUS_ModelRotation(t *testing.T) {
+	// A0, A0 → A1, ..., A(n-2) → A(n-1), ¬A(n-1): every clause is needed,
+	// and model rotation walks the chain without further solver calls
+	const n = 30
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L(varName(0), false)))
+	for i := 1; i < n; i++ {
+		cnf.AddClause(NewClause(L(varName(i-1), true), L(varName(i), false)))
+	}
+	cnf.AddClause(NewClause(L(varName(n-1), true)))
+
+	result, err := ExtractMUS(cnf)
+	if err != nil {
+		t.Fatalf("ExtractMUS failed: %v", err)
+	}
+	if len(result.ClauseIDs) != n+1 {
+		t.Fatalf("Expected all %d clauses, got %v", n+1, result.ClauseIDs)
+	}
+	if result.Rotated == 0 || result.SolverCalls >= n {
+		t.Errorf("Expected model rotation to save solver calls: %d calls, %d rotated",
+			result.SolverCalls, result.Rotated)
+	}
+}
+
+func TestExtractMUS_Cases(t *testing.T) {
+	a, b, c, d := L("A", false), L("B", false), L("C", false), L("D", false)
+	na, nb, nc, nd := L("A", true), L("B", true), L("C", true), L("D", true)
+	testCases := []struct {
+		description string
+		clauses     [][]Literal
+		expectedIDs []int // nil when several subsets are minimal
+		expectedLen int
+	}{
+		{
+			description: "all four sign patterns over two variables",
+			clauses:     [][]Literal{{c, d}, {a, b}, {a, nb}, {nc, d}, {na, b}, {na, nb}},
+			expectedIDs: []int{2, 3, 5, 6},
+			expectedLen: 4,
+		},
+		{
+			description: "chain with satisfiable distractors",
+			clauses:     [][]Literal{{a}, {na, c, d}, {na, b}, {nb, c}, {c, nd}, {nc}},
+			expectedIDs: []int{1, 3, 4, 6},
+			expectedLen: 4,
+		},
+		{
+			description: "two disjoint cores",
+			clauses:     [][]Literal{{a, b}, {na}, {c}, {nb}, {nc, d}, {nd}},
+			expectedLen: 3,
+		},
+		{
+			description: "two cores sharing a unit clause",
+			clauses:     [][]Literal{{a}, {na, b}, {nb}, {na, c}, {nc, d}, {nc}},
+			expectedLen: 3,
+		},
+		{
+			description: "pigeonhole with three pigeons",
+			clauses:     pigeonholeClauses(3, 2),
+			expectedIDs: []int{1, 2, 3, 4, 5, 6, 7, 8, 9},
+			expectedLen: 9,
+		},
+	}
+
+	extractor := NewMUSExtractor()
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cnf := buildCNF(tc.clauses)
+			result, err := extractor.Extract(cnf)
+			if err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			if tc.expectedIDs != nil && !reflect.DeepEqual(result.ClauseIDs, tc.expectedIDs) {
+				t.Errorf("Expected MUS %v, got %v", tc.expectedIDs, result.ClauseIDs)
+			}
+			if len(result.ClauseIDs) != tc.expectedLen {
+				t.Errorf("Expected %d clauses, got %v", tc.expectedLen, result.ClauseIDs)
+			}
+			checkMUS(t, cnf, result.ClauseIDs)
+		})
+	}
+}
+
+func TestExtractGroups(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("admin", true), L("write", false))) // 1 hard
+	cnf.AddClause(NewClause(L("admin", false)))                   // 2
+	cnf.AddClause(NewClause(L("guest", false)))                   // 3
+	cnf.AddClause(NewClause(L("guest", true), L("write", true)))  // 4
+	cnf.AddClause(NewClause(L("read", false)))                    // 5
+	cnf.AddClause(NewClause(L("guest", true), L("admin", true)))  // 6
+
+	groups := map[string][]int{
+		"roles":     {2, 3},
+		"readonly":  {4},
+		"logging":   {5},
+		"exclusive": {6},
+	}
+	extractor := NewMUSExtractor()
+	result, err := extractor.ExtractGroups(cnf, groups)
+	if err != nil {
+		t.Fatalf("ExtractGroups failed: %v", err)
+	}
+	// Both {roles, readonly} and {roles, exclusive} are group MUSes
+	if len(result.Groups) != 2 || result.Groups[len(result.Groups)-1] != "roles" {
+		t.Fatalf("Expected roles and one other group, got %v", result.Groups)
+	}
+	var subset []*Clause
+	for _, id := range append([]int{1}, result.ClauseIDs...) {
+		subset = append(subset, cnf.Clauses[id-1])
+	}
+	if bruteForceSAT(subset) {
+		t.Errorf("Groups %v with the hard clauses are satisfiable", result.Groups)
+	}
+
+	// Hard clauses that are unsatisfiable by themselves need no group
+	hard := NewCNF()
+	hard.AddClause(NewClause(L("A", false)))
+	hard.AddClause(NewClause(L("A", true)))
+	hard.AddClause(NewClause(L("B", false)))
+	result, err = extractor.ExtractGroups(hard, map[string][]int{"b": {3}})
+	if err != nil {
+		t.Fatalf("ExtractGroups failed: %v", err)
+	}
+	if len(result.Groups) != 0 || len(result.ClauseIDs) != 0 {
+		t.Errorf("Expected an empty group MUS, got %v %v", result.Groups, result.ClauseIDs)
+	}
+
+	var logicErr *core.LogicError
+	if _, err := extractor.ExtractGroups(cnf, map[string][]int{"a": {1, 2}, "b": {2}}); !errors.As(err, &logicErr) {
+		t.Errorf("Expected LogicError for overlapping groups, got %v", err)
+	}
+	if _, err := extractor.ExtractGroups(cnf, map[string][]int{"a": {42}}); !errors.As(err, &logicErr) {
+		t.Errorf("Expected LogicError for an unknown clause, got %v", err)
+	}
+}
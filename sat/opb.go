@@ -0,0 +1,175 @@
+package sat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+// PBProblem is a pseudo-Boolean satisfaction or optimisation instance
+type PBProblem struct {
+	Formula   *ExtendedCNF
+	Objective []PBTerm // Minimised; empty for a satisfaction instance
+}
+
+// OPBReader parses the linear OPB format of the Pseudo-Boolean
+// Competition:
+//
+//	min: +2 x1 -1 x3 ;
+//	+1 x1 +2 ~x2 >= 2 ;
+//	+1 x2 +1 x3 = 1 ;
+//
+// Statements end with ";" and may span lines, and lines starting with "*"
+// are comments. Variable xN is named as DIMACSReader names variable N and
+// "~" negates it. "<=" is accepted besides ">=" and "="; non-linear terms
+// are rejected.
+type OPBReader struct {
+	scanner *bufio.Scanner
+	vars    *VariableMap
+	line    int
+}
+
+// NewOPBReader creates an OPB reader over r
+func NewOPBReader(r io.Reader) *OPBReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	return &OPBReader{
+		scanner: scanner,
+		vars:    NewVariableMap(),
+	}
+}
+
+// Variables returns the mapping between OPB indices and variable names
+func (d *OPBReader) Variables() *VariableMap {
+	return d.vars
+}
+
+// Read reads every remaining statement. Constraints go to the formula
+// through ExtendedCNF.AddPB.
+func (d *OPBReader) Read() (*PBProblem, error) {
+	problem := &PBProblem{Formula: NewExtendedCNF()}
+	objective := false
+	var tokens []string
+	for d.scanner.Scan() {
+		d.line++
+		text := strings.TrimSpace(d.scanner.Text())
+		if text == "" || text[0] == '*' {
+			continue
+		}
+		for _, tok := range strings.Fields(strings.ReplaceAll(text, ";", " ; ")) {
+			if tok != ";" {
+				tokens = append(tokens, tok)
+				continue
+			}
+			if len(tokens) == 0 {
+				return nil, d.errorf("OPBReader.Read", "empty statement")
+			}
+			if tokens[0] == "min:" {
+				if objective {
+					return nil, d.errorf("OPBReader.Read", "second objective")
+				}
+				terms, err := d.parseTerms(tokens[1:])
+				if err != nil {
+					return nil, err
+				}
+				problem.Objective, objective = terms, true
+			} else if err := d.parseConstraint(problem.Formula, tokens); err != nil {
+				return nil, err
+			}
+			tokens = tokens[:0]
+		}
+	}
+	if err := d.scanner.Err(); err != nil {
+		e := core.NewLogicError("sat", "OPBReader.Read", err.Error())
+		e.Position = d.line
+		return nil, e
+	}
+	if len(tokens) > 0 {
+		return nil, d.errorf("OPBReader.Read", "unterminated statement at end of input")
+	}
+	return problem, nil
+}
+
+// parseConstraint parses "terms relation bound" and adds it to ecnf
+func (d *OPBReader) parseConstraint(ecnf *ExtendedCNF, tokens []string) error {
+	if len(tokens) < 2 {
+		return d.errorf("OPBReader.Read", "missing relation or bound")
+	}
+	var comparison PBComparison
+	switch tokens[len(tokens)-2] {
+	case ">=":
+		comparison = PBGreaterEqual
+	case "<=":
+		comparison = PBLessEqual
+	case "=":
+		comparison = PBEqual
+	default:
+		return d.errorf("OPBReader.Read", fmt.Sprintf("expected >=, <= or = before the bound, got %q", tokens[len(tokens)-2]))
+	}
+	bound, err := strconv.ParseInt(tokens[len(tokens)-1], 10, 64)
+	if err != nil {
+		return d.errorf("OPBReader.Read", fmt.Sprintf("invalid bound %q", tokens[len(tokens)-1]))
+	}
+	terms, err := d.parseTerms(tokens[:len(tokens)-2])
+	if err != nil {
+		return err
+	}
+	return ecnf.AddPB(terms, comparison, bound)
+}
+
+// parseTerms parses a sequence of "coefficient literal" pairs
+func (d *OPBReader) parseTerms(tokens []string) ([]PBTerm, error) {
+	terms := make([]PBTerm, 0, len(tokens)/2)
+	for i := 0; i < len(tokens); i += 2 {
+		coefficient, err := strconv.ParseInt(tokens[i], 10, 64)
+		if err != nil {
+			if _, ok := d.parseLiteral(tokens[i]); ok && i > 0 {
+				return nil, d.errorf("OPBReader.Read", "non-linear terms are not supported")
+			}
+			return nil, d.errorf("OPBReader.Read", fmt.Sprintf("invalid coefficient %q", tokens[i]))
+		}
+		if i+1 == len(tokens) {
+			return nil, d.errorf("OPBReader.Read", fmt.Sprintf("coefficient %d without a literal", coefficient))
+		}
+		lit, ok := d.parseLiteral(tokens[i+1])
+		if !ok {
+			return nil, d.errorf("OPBReader.Read", fmt.Sprintf("invalid literal %q", tokens[i+1]))
+		}
+		terms = append(terms, PBTerm{Coefficient: coefficient, Literal: lit})
+	}
+	return terms, nil
+}
+
+// parseLiteral parses "xN" or "~xN"
+func (d *OPBReader) parseLiteral(tok string) (Literal, bool) {
+	negated := strings.HasPrefix(tok, "~")
+	tok = strings.TrimPrefix(tok, "~")
+	if len(tok) < 2 || tok[0] != 'x' {
+		return Literal{}, false
+	}
+	n, err := strconv.Atoi(tok[1:])
+	if err != nil || n <= 0 {
+		return Literal{}, false
+	}
+	lit := d.vars.FromDIMACS(n)
+	if negated {
+		lit = lit.Negate()
+	}
+	return lit, true
+}
+
+// errorf builds a LogicError carrying the current line number
+func (d *OPBReader) errorf(op, msg string) error {
+	e := core.NewLogicError("sat", op, fmt.Sprintf("line %d: %s", d.line, msg))
+	e.Position = d.line
+	return e
+}
+
+// ReadOPB parses an OPB stream
+func ReadOPB(r io.Reader) (*PBProblem, error) {
+	return NewOPBReader(r).Read()
+}
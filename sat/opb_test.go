@@ -0,0 +1,71 @@
+package sat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReadOPB(t *testing.T) {
+	input := `* #variable= 4 #constraint= 3
+* a small knapsack
+min: -3 x1 -4 x2 -5 x3 -2 x4 ;
++2 x1 +3 x2 +4 x3
+  +1 x4 <= 6 ;
++1 x1 +1 ~x3 >= 1 ;
++1 x2 +1 x4 = 1;
+`
+	problem, err := ReadOPB(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problem.Objective) != 4 || problem.Objective[2] != (PBTerm{-5, L("v3", false)}) {
+		t.Errorf("Unexpected objective %v", problem.Objective)
+	}
+	if len(problem.Formula.Variables) != 4 {
+		t.Errorf("Expected 4 variables, got %v", problem.Formula.Variables)
+	}
+
+	// Weight at most 6, x1 or not x3, exactly one of x2 and x4: the best
+	// is x1, x2 and not x3 for a value of 7
+	result := NewPBSolver().Minimize(problem)
+	if result.Error != nil || !result.Optimal || result.Cost != -7 {
+		t.Fatalf("Expected an optimum of -7, got %d (%v, %v)", result.Cost, result.Status, result.Error)
+	}
+	if !result.Assignment["v1"] || !result.Assignment["v2"] || result.Assignment["v3"] || result.Assignment["v4"] {
+		t.Errorf("Unexpected model %v", result.Assignment)
+	}
+}
+
+func TestReadOPB_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		line  int
+	}{
+		{"+1 x1 +1 x2 >= 1", 1},
+		{"+1 x1 x2 >= 1 ;", 1},
+		{"* comment\n+1 x1 > 1 ;", 2},
+		{"+1 y1 >= 1 ;", 1},
+		{"+1 x1 >= one ;", 1},
+		{"min: +1 x1 ;\nmin: +1 x2 ;", 2},
+		{";", 1},
+	} {
+		_, err := ReadOPB(strings.NewReader(tc.input))
+		if err == nil {
+			t.Errorf("Expected an error for %q", tc.input)
+			continue
+		}
+		if !strings.Contains(err.Error(), fmt.Sprintf("line %d:", tc.line)) {
+			t.Errorf("Error for %q is not on line %d: %v", tc.input, tc.line, err)
+		}
+	}
+
+	// Without an objective the problem is a satisfaction instance
+	problem, err := ReadOPB(strings.NewReader("+1 x1 +1 x2 >= 2 ;\n+1 ~x1 >= 1 ;"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := NewPBSolver().Minimize(problem); result.Status != StatusUNSAT {
+		t.Errorf("Expected UNSAT, got %v", result.Status)
+	}
+}
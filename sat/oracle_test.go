@@ -0,0 +1,180 @@
+package sat
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// The brute-force oracles below are shared by the tests. They try every
+// total assignment, so formulas must stay small.
+
+// enumerateModels calls fn with every total assignment of names until fn
+// returns false
+func enumerateModels(names []string, fn func(Assignment) bool) {
+	for mask := 0; mask < 1<<len(names); mask++ {
+		model := make(Assignment, len(names))
+		for i, name := range names {
+			model[name] = mask>>i&1 == 1
+		}
+		if !fn(model) {
+			return
+		}
+	}
+}
+
+// allModels calls fn with every total assignment of names
+func allModels(names []string, fn func(Assignment)) {
+	enumerateModels(names, func(model Assignment) bool {
+		fn(model)
+		return true
+	})
+}
+
+// anyModel reports whether some total assignment of names satisfies holds
+func anyModel(names []string, holds func(Assignment) bool) bool {
+	found := false
+	enumerateModels(names, func(model Assignment) bool {
+		found = holds(model)
+		return !found
+	})
+	return found
+}
+
+// variablesOf returns the variables of clauses in order of first occurrence
+func variablesOf(clauses []*Clause) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, clause := range clauses {
+		for _, lit := range clause.Literals {
+			if !seen[lit.Variable] {
+				seen[lit.Variable] = true
+				names = append(names, lit.Variable)
+			}
+		}
+	}
+	return names
+}
+
+// satisfiesAll reports whether model satisfies every clause
+func satisfiesAll(model Assignment, clauses []*Clause) bool {
+	for _, clause := range clauses {
+		if !model.Satisfies(clause) {
+			return false
+		}
+	}
+	return true
+}
+
+// holdsClauses reports whether model makes a literal of every clause true
+func holdsClauses(model Assignment, clauses [][]Literal) bool {
+	for _, clause := range clauses {
+		holds := false
+		for _, lit := range clause {
+			if value, ok := model[lit.Variable]; ok && value != lit.Negated {
+				holds = true
+				break
+			}
+		}
+		if !holds {
+			return false
+		}
+	}
+	return true
+}
+
+// holdsExtended reports whether model satisfies every clause, XOR,
+// cardinality and PB constraint of ecnf
+func holdsExtended(model Assignment, ecnf *ExtendedCNF) bool {
+	if !satisfiesAll(model, ecnf.Clauses) {
+		return false
+	}
+	for _, xor := range ecnf.XORClauses {
+		if decided, holds := xor.IsSatisfied(model); !decided || !holds {
+			return false
+		}
+	}
+	for _, card := range ecnf.CardinalityClauses {
+		if decided, holds := card.IsSatisfied(model); !decided || !holds {
+			return false
+		}
+	}
+	for _, pb := range ecnf.PBConstraints {
+		if decided, holds := pb.IsSatisfied(model); !decided || !holds {
+			return false
+		}
+	}
+	return true
+}
+
+// holdsPB reports whether model satisfies Σ terms <comparison> bound
+func holdsPB(model Assignment, terms []PBTerm, comparison PBComparison, bound int64) bool {
+	sum := objectiveValue(terms, model)
+	switch comparison {
+	case PBLessEqual:
+		return sum <= bound
+	case PBGreaterEqual:
+		return sum >= bound
+	default:
+		return sum == bound
+	}
+}
+
+// bruteForceSAT reports whether the clauses have a model
+func bruteForceSAT(clauses []*Clause) bool {
+	return anyModel(variablesOf(clauses), func(model Assignment) bool {
+		return satisfiesAll(model, clauses)
+	})
+}
+
+// randomDIMACS returns a random k-CNF instance in DIMACS text
+func randomDIMACS(seed int64, numVars, numClauses, k int) string {
+	rng := rand.New(rand.NewSource(seed))
+	var b strings.Builder
+	fmt.Fprintf(&b, "p cnf %d %d\n", numVars, numClauses)
+	for i := 0; i < numClauses; i++ {
+		for _, v := range rng.Perm(numVars)[:k] {
+			lit := v + 1
+			if rng.Intn(2) == 0 {
+				lit = -lit
+			}
+			fmt.Fprintf(&b, "%d ", lit)
+		}
+		b.WriteString("0\n")
+	}
+	return b.String()
+}
+
+// TestSolvers_RandomAgainstOracle checks the complete solvers against the
+// brute-force oracle on random 3-CNF near the satisfiability threshold,
+// where both answers are common.
+func TestSolvers_RandomAgainstOracle(t *testing.T) {
+	solvers := []struct {
+		name  string
+		solve func(*CNF) *SolverResult
+	}{
+		{"CDCL", func(cnf *CNF) *SolverResult { return NewCDCLSolver().Solve(cnf) }},
+		{"Portfolio", func(cnf *CNF) *SolverResult { return NewPortfolioSolver(4).Solve(cnf) }},
+		{"Cube", func(cnf *CNF) *SolverResult { return NewCubeSolver(4).Solve(cnf) }},
+	}
+
+	for round := 0; round < 20; round++ {
+		input := randomDIMACS(int64(round), 12, 51, 3)
+		cnf := mustReadDIMACS(t, input)
+		expected := bruteForceSAT(cnf.Clauses)
+		for _, s := range solvers {
+			// Solvers may rewrite the formula they are given
+			result := s.solve(mustReadDIMACS(t, input))
+			if result.Error != nil {
+				t.Fatalf("%s, round %d: %v", s.name, round, result.Error)
+			}
+			if result.Satisfiable != expected {
+				t.Fatalf("%s, round %d: got %v, the oracle says satisfiable=%v", s.name, round, result.Status, expected)
+			}
+			if result.Satisfiable && !satisfiesAll(result.Assignment, cnf.Clauses) {
+				t.Fatalf("%s, round %d: model %v violates the formula", s.name, round, result.Assignment)
+			}
+		}
+	}
+}
@@ -0,0 +1,469 @@
+package sat
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xDarkicex/logic/core"
+	"github.com/xDarkicex/memory"
+)
+
+// PBConstraint is the linear constraint Σ aᵢ·lᵢ ≥ Bound in the normal form
+// the CDCL solver propagates: every coefficient is positive and at most
+// Bound, and Bound is positive.
+type PBConstraint struct {
+	Terms []PBTerm
+	Bound int64
+	ID    int // Unique identifier
+}
+
+// NewPBConstraints normalises Σ aᵢ·lᵢ ⋈ bound. Coefficients may be
+// negative. An equality gives two constraints and a constraint that every
+// assignment satisfies gives none. An error is returned if the sum of the
+// coefficients' magnitudes overflows int64.
+func NewPBConstraints(terms []PBTerm, comparison PBComparison, bound int64) ([]*PBConstraint, error) {
+	var constraints []*PBConstraint
+	for _, flip := range []bool{false, true} {
+		// Σ aᵢ·lᵢ ≤ b  ⇔  Σ -aᵢ·lᵢ ≥ -b
+		if (!flip && comparison == PBLessEqual) || (flip && comparison == PBGreaterEqual) {
+			continue
+		}
+		pb, err := normalisePB(terms, bound, flip)
+		if err != nil {
+			return nil, err
+		}
+		if pb != nil {
+			constraints = append(constraints, pb)
+		}
+	}
+	return constraints, nil
+}
+
+// normalisePB turns Σ aᵢ·lᵢ ≥ bound, or Σ aᵢ·lᵢ ≤ bound if flip is set,
+// into a PBConstraint. It returns nil if the constraint always holds.
+func normalisePB(terms []PBTerm, bound int64, flip bool) (*PBConstraint, error) {
+	overflow := core.NewLogicError("sat", "NewPBConstraints", "coefficients overflow int64")
+	if flip {
+		if bound == math.MinInt64 {
+			return nil, overflow
+		}
+		bound = -bound
+	}
+
+	// a·l = a + (-a)·¬l for a < 0
+	normal := make([]PBTerm, 0, len(terms))
+	var total int64
+	for _, term := range terms {
+		a, lit := term.Coefficient, term.Literal
+		if a == math.MinInt64 {
+			return nil, overflow
+		}
+		if flip {
+			a = -a
+		}
+		if a == 0 {
+			continue
+		}
+		if a < 0 {
+			if bound > 0 && bound > math.MaxInt64+a {
+				// The bound is beyond any reachable sum; it stays so
+				bound = math.MaxInt64
+			} else {
+				bound -= a
+			}
+			a, lit = -a, lit.Negate()
+		}
+		if total > math.MaxInt64-a {
+			return nil, overflow
+		}
+		total += a
+		normal = append(normal, PBTerm{Coefficient: a, Literal: lit})
+	}
+	if bound <= 0 {
+		return nil, nil
+	}
+
+	// A term heavier than the bound satisfies it on its own
+	for i := range normal {
+		if normal[i].Coefficient > bound {
+			normal[i].Coefficient = bound
+		}
+	}
+	return &PBConstraint{Terms: normal, Bound: bound}, nil
+}
+
+// AddPB adds the linear constraint Σ aᵢ·lᵢ ⋈ bound to the formula.
+// Normalised constraints that are clauses or cardinality constraints are
+// added as such, since those propagate faster.
+func (ecnf *ExtendedCNF) AddPB(terms []PBTerm, comparison PBComparison, bound int64) error {
+	constraints, err := NewPBConstraints(terms, comparison, bound)
+	if err != nil {
+		return err
+	}
+	for _, pb := range constraints {
+		uniform := true
+		for _, term := range pb.Terms {
+			uniform = uniform && term.Coefficient == pb.Terms[0].Coefficient
+		}
+		if !uniform {
+			ecnf.AddPBConstraint(pb)
+			continue
+		}
+
+		lits := make([]Literal, len(pb.Terms))
+		for i, term := range pb.Terms {
+			lits[i] = term.Literal
+		}
+		if len(lits) == 0 || pb.Terms[0].Coefficient == pb.Bound {
+			if !isTautology(lits) {
+				ecnf.AddClause(ecnf.NewClause(lits...))
+			}
+			continue
+		}
+		a := pb.Terms[0].Coefficient
+		ecnf.AddCardinalityClause(NewAtLeastK(lits, int((pb.Bound+a-1)/a)))
+	}
+	return nil
+}
+
+// Slack returns the largest sum the constraint's literals can still reach
+// under assignment, less the bound. A negative slack means a violation.
+func (pb *PBConstraint) Slack(assignment Assignment) int64 {
+	slack := -pb.Bound
+	for _, term := range pb.Terms {
+		if value, assigned := assignment[term.Literal.Variable]; !assigned || value != term.Literal.Negated {
+			slack += term.Coefficient
+		}
+	}
+	return slack
+}
+
+// IsSatisfied checks the constraint against a possibly partial assignment.
+// The first result reports whether every extension of assignment agrees,
+// the second whether the constraint then holds.
+func (pb *PBConstraint) IsSatisfied(assignment Assignment) (bool, bool) {
+	var sum int64
+	for _, term := range pb.Terms {
+		if value, assigned := assignment[term.Literal.Variable]; assigned && value != term.Literal.Negated {
+			sum += term.Coefficient
+		}
+	}
+	if sum >= pb.Bound {
+		return true, true
+	}
+	if pb.Slack(assignment) < 0 {
+		return true, false
+	}
+	return false, false
+}
+
+// String returns string representation of PB constraint
+func (pb *PBConstraint) String() string {
+	terms := make([]string, len(pb.Terms))
+	for i, term := range pb.Terms {
+		terms[i] = fmt.Sprintf("%d %s", term.Coefficient, term.Literal)
+	}
+	return fmt.Sprintf("(%s ≥ %d)", strings.Join(terms, " + "), pb.Bound)
+}
+
+// propagateCounting runs one round of cardinality and PB propagation
+func (c *CDCLSolver) propagateCounting() (*Clause, bool) {
+	conflict, propagated := c.propagateCardinality()
+	if conflict != nil {
+		return conflict, propagated
+	}
+	conflict, pbPropagated := c.propagatePB()
+	return conflict, propagated || pbPropagated
+}
+
+// pbSlack keeps the slack of each PB constraint, lowered as its literals
+// become false and raised again as they are backtracked, so that
+// propagation only visits the constraints whose slack changed.
+type pbSlack struct {
+	constraintQueue // Constraints whose slack changed since their visit
+	constraints     []*PBConstraint
+	occurs          [][]pbOccurrence // Literal -> terms it makes false
+	slack           []int64
+	heaviest        []int64 // Largest coefficient of each constraint
+	head            int     // Trail literals counted
+}
+
+// pbOccurrence is a term of constraint weighing coefficient
+type pbOccurrence struct {
+	constraint  int32
+	coefficient int64
+}
+
+// newPBSlack numbers the variables of constraints in trail and indexes the
+// terms by the literal that makes them false. It returns nil without
+// constraints.
+func newPBSlack(trail *literalTrail, constraints []*PBConstraint) *pbSlack {
+	if len(constraints) == 0 {
+		return nil
+	}
+	for _, pb := range constraints {
+		for _, term := range pb.Terms {
+			trail.varOf(term.Literal.Variable)
+		}
+	}
+	s := &pbSlack{
+		constraintQueue: constraintQueue{queued: make([]bool, len(constraints))},
+		constraints:     constraints,
+		occurs:          make([][]pbOccurrence, len(trail.values)),
+		slack:           make([]int64, len(constraints)),
+		heaviest:        make([]int64, len(constraints)),
+	}
+	for i, pb := range constraints {
+		s.slack[i] = -pb.Bound
+		for _, term := range pb.Terms {
+			p := trail.lit(term.Literal).Not()
+			s.occurs[p] = append(s.occurs[p], pbOccurrence{int32(i), term.Coefficient})
+			s.slack[i] += term.Coefficient
+			s.heaviest[i] = max(s.heaviest[i], term.Coefficient)
+		}
+		// Every constraint is visited once, as one may force literals
+		// before any assignment
+		s.touch(int32(i))
+	}
+	return s
+}
+
+// add subtracts sign times their coefficients from the slack of the
+// terms that p makes false
+func (s *pbSlack) add(p Lit, sign int64) {
+	// occurs holds both literals of every variable it numbers
+	if int(p) >= len(s.occurs) {
+		return
+	}
+	for _, o := range s.occurs[p] {
+		s.slack[o.constraint] -= sign * o.coefficient
+		s.touch(o.constraint)
+	}
+}
+
+// count counts the trail literals assigned since the last call
+func (s *pbSlack) count(trail *literalTrail) {
+	for ; s.head < len(trail.lits); s.head++ {
+		s.add(trail.lits[s.head], 1)
+	}
+}
+
+// undo uncounts the trail literals from position cut on. It runs before
+// the trail drops them.
+func (s *pbSlack) undo(trail *literalTrail, cut int) {
+	for ; s.head > cut; s.head-- {
+		s.add(trail.lits[s.head-1], -1)
+	}
+}
+
+// propagatePB enforces the PB constraints of the extended formula by their
+// slack, the most the reachable sum exceeds the bound by. A negative slack
+// is a conflict, and an unassigned term heavier than the slack is forced
+// true. Both are explained by clauses over enough of the constraint's
+// false literals to account for the missing weight:
+//
+//	forced l:  (l ∨ f1 ∨ ... ∨ fm)
+//	conflict:  (f1 ∨ ... ∨ fm)
+//
+// Only the constraints whose slack changed are visited, and only those
+// with a slack below their heaviest term are scanned. Literals forced in
+// this round are counted in the next, which the caller runs whenever a
+// literal was forced. It returns the conflict clause, if any, and whether
+// a literal was forced.
+func (c *CDCLSolver) propagatePB() (*Clause, bool) {
+	counter := c.pbSlack
+	if counter == nil {
+		return nil, false
+	}
+	counter.count(c.trail)
+	propagated := false
+	for len(counter.dirty) > 0 {
+		i := counter.pop()
+		if counter.slack[i] >= counter.heaviest[i] {
+			continue
+		}
+		pb := counter.constraints[i]
+
+		var total, heaviest int64
+		slack := -pb.Bound
+		var falseTerms []PBTerm
+		for _, term := range pb.Terms {
+			total += term.Coefficient
+			value, assigned := c.assignment[term.Literal.Variable]
+			if assigned && value == term.Literal.Negated {
+				falseTerms = append(falseTerms, term)
+				continue
+			}
+			slack += term.Coefficient
+			if !assigned && term.Coefficient > heaviest {
+				heaviest = term.Coefficient
+			}
+		}
+		if slack >= heaviest {
+			continue
+		}
+
+		if slack < 0 {
+			// The most recent false literals first, so that the conflict
+			// has one on the current level
+			sort.SliceStable(falseTerms, func(i, j int) bool {
+				return c.trail.GetLevel(falseTerms[i].Literal.Variable) > c.trail.GetLevel(falseTerms[j].Literal.Variable)
+			})
+			clause := c.pbExplanation(falseTerms, total-pb.Bound)
+			clause.ConflictType = "PB_CONFLICT"
+			return clause, propagated
+		}
+
+		// The heaviest false literals first give the shortest reasons
+		sort.SliceStable(falseTerms, func(i, j int) bool {
+			return falseTerms[i].Coefficient > falseTerms[j].Coefficient
+		})
+		for _, term := range pb.Terms {
+			if term.Coefficient <= slack || c.assignment.IsAssigned(term.Literal.Variable) {
+				continue
+			}
+			reason := c.pbExplanation(falseTerms, total-pb.Bound-term.Coefficient, term.Literal)
+			reason.ConflictType = "PB_REASON"
+			c.assign(term.Literal.Variable, !term.Literal.Negated, reason)
+			c.statistics.Propagations++
+			propagated = true
+		}
+	}
+	return nil, propagated
+}
+
+// pbExplanation builds the learned clause of extra and a prefix of
+// falseTerms weighing more than excess, the weight the constraint can
+// lose while still holding
+func (c *CDCLSolver) pbExplanation(falseTerms []PBTerm, excess int64, extra ...Literal) *Clause {
+	literals := make([]Literal, 0, len(falseTerms)+len(extra))
+	literals = append(literals, extra...)
+	var lost int64
+	for _, term := range falseTerms {
+		if lost > excess {
+			break
+		}
+		literals = append(literals, term.Literal)
+		lost += term.Coefficient
+	}
+	clause := NewClauseIn(c.pool, literals...)
+	clause.Learned = true
+	c.setXORClauseLBD(clause)
+	return clause
+}
+
+// PBSolver minimises a linear objective over a formula with
+// pseudo-Boolean constraints by iterated strengthening: each model of cost
+// c adds the constraint that the objective is at most c-1, until the
+// formula becomes unsatisfiable and the last model is optimal.
+type PBSolver struct {
+	solver  *CDCLSolver
+	timeout time.Duration
+}
+
+// NewPBSolver creates a PB solver
+func NewPBSolver() *PBSolver {
+	return &PBSolver{solver: NewCDCLSolver()}
+}
+
+// SetTimeout limits each solve call of Minimize; zero means no limit
+func (s *PBSolver) SetTimeout(timeout time.Duration) {
+	s.timeout = timeout
+}
+
+// Minimize finds a model of problem.Formula with the least objective
+// value. The formula is not modified. If the search stops early, the best
+// model found so far is returned with Optimal unset.
+func (s *PBSolver) Minimize(problem *PBProblem) *PBResult {
+	if problem == nil || problem.Formula == nil {
+		return &PBResult{
+			Error:  core.NewLogicError("sat", "PBSolver.Minimize", "nil problem"),
+			Reason: ReasonError,
+		}
+	}
+	formula := copyExtendedCNF(nil, problem.Formula)
+	for _, term := range problem.Objective {
+		if !formula.containsVariable(term.Literal.Variable) {
+			formula.Variables = append(formula.Variables, term.Literal.Variable)
+		}
+	}
+	base := len(formula.PBConstraints)
+	best := &PBResult{}
+	for {
+		result := s.solver.SolveExtendedWithTimeout(formula, s.timeout)
+		best.Statistics = result.Statistics
+		switch {
+		case result.Error != nil:
+			if best.Assignment == nil {
+				best.Status, best.Reason, best.Error = StatusUnknown, result.Reason, result.Error
+			}
+			return best
+		case !result.Satisfiable:
+			if best.Assignment == nil {
+				best.Status = StatusUNSAT
+			} else {
+				best.Optimal = true
+			}
+			return best
+		}
+
+		cost := objectiveValue(problem.Objective, result.Assignment)
+		if best.Assignment != nil && cost >= best.Cost {
+			best.Reason = ReasonError
+			best.Error = core.NewLogicError("sat", "PBSolver.Minimize",
+				fmt.Sprintf("model of cost %d violates the bound %d", cost, best.Cost-1))
+			return best
+		}
+		best.Assignment, best.Cost, best.Status = result.Assignment, cost, StatusSAT
+		if len(problem.Objective) == 0 {
+			best.Optimal = true
+			return best
+		}
+
+		// Replace the previous bound on the objective with a tighter one
+		formula.PBConstraints = formula.PBConstraints[:base]
+		bounds, err := NewPBConstraints(problem.Objective, PBLessEqual, best.Cost-1)
+		if err != nil {
+			best.Reason, best.Error = ReasonError, err
+			return best
+		}
+		for _, pb := range bounds {
+			formula.AddPBConstraint(pb)
+		}
+	}
+}
+
+// objectiveValue returns Σ aᵢ·lᵢ under model
+func objectiveValue(objective []PBTerm, model Assignment) int64 {
+	var value int64
+	for _, term := range objective {
+		if model[term.Literal.Variable] != term.Literal.Negated {
+			value += term.Coefficient
+		}
+	}
+	return value
+}
+
+// copyExtendedCNF returns a copy of ecnf that solving can rewrite without
+// touching ecnf, its clauses allocated from pool (nil for the package pools)
+func copyExtendedCNF(pool *memory.Pool, ecnf *ExtendedCNF) *ExtendedCNF {
+	out := NewExtendedCNF()
+	out.CNF = NewCNFWithPool(pool)
+	copyLiveClauses(pool, ecnf.Clauses, nil, func(_ int, clause *Clause) {
+		out.AddClause(clause)
+	})
+	for _, xor := range ecnf.XORClauses {
+		out.AddXORClause(NewXORClause(append([]string(nil), xor.Variables...), xor.Parity))
+	}
+	for _, card := range ecnf.CardinalityClauses {
+		out.AddCardinalityClause(NewAtMostK(card.Literals, card.K))
+	}
+	for _, pb := range ecnf.PBConstraints {
+		out.AddPBConstraint(&PBConstraint{Terms: append([]PBTerm(nil), pb.Terms...), Bound: pb.Bound})
+	}
+	out.Variables = append(out.Variables[:0], ecnf.Variables...)
+	return out
+}
@@ -0,0 +1,302 @@
+package sat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewPBConstraints(t *testing.T) {
+	A, B, C, D := L("A", false), L("B", false), L("C", false), L("D", false)
+	testCases := []struct {
+		description string
+		terms       []PBTerm
+		comparison  PBComparison
+		bound       int64
+		expected    int
+	}{
+		{"at least with positive coefficients",
+			[]PBTerm{{2, A}, {3, B}, {1, C}}, PBGreaterEqual, 3, 1},
+		{"negative coefficients negate their literals",
+			[]PBTerm{{-2, A}, {3, B}, {-1, C}}, PBGreaterEqual, 1, 1},
+		{"at most is flipped",
+			[]PBTerm{{2, A}, {2, B}, {3, C}}, PBLessEqual, 4, 1},
+		{"equality gives both directions",
+			[]PBTerm{{1, A}, {2, B}, {3, C}, {1, D}}, PBEqual, 3, 2},
+		{"negated literals",
+			[]PBTerm{{2, A.Negate()}, {1, B}, {2, C.Negate()}}, PBGreaterEqual, 3, 1},
+		{"heavy coefficients saturate",
+			[]PBTerm{{5, A}, {1, B}, {1, C}}, PBGreaterEqual, 2, 1},
+		{"zero coefficients are dropped",
+			[]PBTerm{{0, A}, {2, B}, {1, C}}, PBGreaterEqual, 2, 1},
+		{"a non-positive bound always holds",
+			[]PBTerm{{1, A}, {1, B}}, PBGreaterEqual, -1, 0},
+		{"an unreachable bound never holds",
+			[]PBTerm{{1, A}, {1, B}}, PBGreaterEqual, 5, 1},
+		{"at most over negative coefficients",
+			[]PBTerm{{-3, A}, {-1, B}, {2, C}}, PBLessEqual, -2, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			constraints, err := NewPBConstraints(tc.terms, tc.comparison, tc.bound)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(constraints) != tc.expected {
+				t.Fatalf("Expected %d constraints, got %v", tc.expected, constraints)
+			}
+			for _, pb := range constraints {
+				for _, term := range pb.Terms {
+					if term.Coefficient <= 0 || term.Coefficient > pb.Bound {
+						t.Fatalf("%v is not normalised", pb)
+					}
+				}
+			}
+			allModels([]string{"A", "B", "C", "D"}, func(model Assignment) {
+				holds := true
+				for _, pb := range constraints {
+					decided, ok := pb.IsSatisfied(model)
+					holds = holds && decided && ok
+				}
+				if holds != holdsPB(model, tc.terms, tc.comparison, tc.bound) {
+					t.Fatalf("Normalised to %v, which disagrees on %v", constraints, model)
+				}
+			})
+		})
+	}
+
+	if _, err := NewPBConstraints([]PBTerm{{math.MaxInt64, L("A", false)}, {1, L("B", false)}}, PBLessEqual, 1); err == nil {
+		t.Error("Expected an overflow error")
+	}
+	pb := &PBConstraint{Terms: []PBTerm{{2, L("A", false)}, {1, L("B", true)}}, Bound: 2}
+	if got := pb.String(); got != "(2 A + 1 ¬B ≥ 2)" {
+		t.Errorf("Unexpected string %q", got)
+	}
+	if slack := pb.Slack(Assignment{"B": true}); slack != 0 {
+		t.Errorf("Expected slack 0, got %d", slack)
+	}
+}
+
+func TestExtendedCNF_AddPB(t *testing.T) {
+	ecnf := NewExtendedCNF()
+	// Saturates to the clause (A ∨ B)
+	ecnf.AddPB([]PBTerm{{3, L("A", false)}, {5, L("B", false)}}, PBGreaterEqual, 2)
+	// Uniform coefficients: at least two of A, B, C
+	ecnf.AddPB([]PBTerm{{2, L("A", false)}, {2, L("B", false)}, {2, L("C", false)}}, PBGreaterEqual, 3)
+	// Always true
+	ecnf.AddPB([]PBTerm{{1, L("A", false)}}, PBLessEqual, 1)
+	ecnf.AddPB([]PBTerm{{3, L("A", false)}, {2, L("B", false)}, {1, L("C", false)}}, PBLessEqual, 4)
+	if len(ecnf.Clauses) != 1 || len(ecnf.CardinalityClauses) != 1 || len(ecnf.PBConstraints) != 1 {
+		t.Errorf("Expected a clause, a cardinality and a PB constraint, got %d, %d and %d",
+			len(ecnf.Clauses), len(ecnf.CardinalityClauses), len(ecnf.PBConstraints))
+	}
+	if card := ecnf.CardinalityClauses[0]; card.K != 1 {
+		t.Errorf("Expected at most one of the negations, got %v", card)
+	}
+}
+
+// pbCase is a pseudo-Boolean constraint as given to AddPB
+type pbCase struct {
+	terms      []PBTerm
+	comparison PBComparison
+	bound      int64
+}
+
+func TestCDCLSolver_PB(t *testing.T) {
+	A, B, C, D := L("A", false), L("B", false), L("C", false), L("D", false)
+	testCases := []struct {
+		description string
+		clauses     [][]Literal
+		constraints []pbCase
+		expectedSat bool
+	}{
+		{"weighted at least",
+			[][]Literal{{A.Negate()}},
+			[]pbCase{{[]PBTerm{{3, A}, {2, B}, {2, C}}, PBGreaterEqual, 4}}, true},
+		{"weighted at least with its heavy term false",
+			[][]Literal{{A.Negate()}, {B.Negate()}},
+			[]pbCase{{[]PBTerm{{3, A}, {2, B}, {2, C}}, PBGreaterEqual, 4}}, false},
+		{"at most forces the heavy term false",
+			[][]Literal{{A, B}, {A, C}},
+			[]pbCase{{[]PBTerm{{4, A}, {1, B}, {1, C}}, PBLessEqual, 3}}, true},
+		{"at most against units",
+			[][]Literal{{A}, {C}},
+			[]pbCase{{[]PBTerm{{2, A}, {2, B}, {2, C}}, PBLessEqual, 3}}, false},
+		{"negative coefficients",
+			[][]Literal{{B}},
+			[]pbCase{{[]PBTerm{{-2, A}, {3, B}, {-2, C}}, PBGreaterEqual, 1}}, true},
+		{"equality with a single solution",
+			[][]Literal{{A, D}},
+			[]pbCase{{[]PBTerm{{1, A}, {2, B}, {4, C}, {8, D}}, PBEqual, 9}}, true},
+		{"equality no subset reaches",
+			nil,
+			[]pbCase{{[]PBTerm{{2, A}, {4, B}, {6, C}}, PBEqual, 5}}, false},
+		{"two constraints leave one choice",
+			[][]Literal{{A, B, C, D}},
+			[]pbCase{
+				{[]PBTerm{{2, A}, {3, B}, {1, C}, {4, D}}, PBLessEqual, 3},
+				{[]PBTerm{{1, A}, {1, B}, {1, C}, {1, D}}, PBGreaterEqual, 2},
+			}, true},
+		{"two constraints contradict",
+			nil,
+			[]pbCase{
+				{[]PBTerm{{2, A}, {3, B}, {1, C}, {4, D}}, PBLessEqual, 2},
+				{[]PBTerm{{1, A}, {1, B}, {1, C}, {1, D}}, PBGreaterEqual, 2},
+			}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ecnf := NewExtendedCNF()
+			for _, clause := range tc.clauses {
+				ecnf.AddClause(NewClause(clause...))
+			}
+			for _, pb := range tc.constraints {
+				if err := ecnf.AddPB(pb.terms, pb.comparison, pb.bound); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result := NewCDCLSolver().SolveExtended(ecnf)
+			if result.Error != nil {
+				t.Fatalf("Solver error: %v", result.Error)
+			}
+			if result.Satisfiable != tc.expectedSat {
+				t.Fatalf("Expected satisfiable=%v, got %v", tc.expectedSat, result.Status)
+			}
+			if !result.Satisfiable {
+				return
+			}
+			for _, name := range []string{"A", "B", "C", "D"} {
+				if _, ok := result.Assignment[name]; !ok {
+					result.Assignment[name] = false
+				}
+			}
+			for _, pb := range tc.constraints {
+				if !holdsPB(result.Assignment, pb.terms, pb.comparison, pb.bound) {
+					t.Errorf("Model %v violates %v %v %d", result.Assignment, pb.terms, pb.comparison, pb.bound)
+				}
+			}
+			if !holdsExtended(result.Assignment, ecnf) {
+				t.Errorf("Model %v violates the formula", result.Assignment)
+			}
+		})
+	}
+}
+
+func TestPBSlack(t *testing.T) {
+	// 3A + 2¬B + C ≥ 4 has slack 2
+	trail := newLiteralTrail()
+	pb := &PBConstraint{Terms: []PBTerm{{3, L("A", false)}, {2, L("B", true)}, {1, L("C", false)}}, Bound: 4}
+	slack := newPBSlack(trail, []*PBConstraint{pb})
+	if slack.slack[0] != 2 || slack.heaviest[0] != 3 {
+		t.Fatalf("Expected slack 2 and heaviest term 3, got %d and %d", slack.slack[0], slack.heaviest[0])
+	}
+	slack.pop()
+
+	// A true leaves the slack; B true and C false lower it
+	trail.Assign("A", true, 1, nil)
+	trail.Assign("B", true, 2, nil)
+	trail.Assign("C", false, 2, nil)
+	slack.count(trail)
+	if slack.slack[0] != -1 || len(slack.dirty) != 1 {
+		t.Fatalf("Expected slack -1 with the constraint queued, got %d", slack.slack[0])
+	}
+	slack.pop()
+
+	slack.undo(trail, trail.levelStart(1))
+	trail.Backtrack(1)
+	if slack.slack[0] != 2 || slack.head != 1 || len(slack.dirty) != 1 {
+		t.Errorf("Expected slack 2 after backtracking, got %d", slack.slack[0])
+	}
+}
+
+func TestPBSolver_Minimize(t *testing.T) {
+	A, B, C, D := L("A", false), L("B", false), L("C", false), L("D", false)
+	testCases := []struct {
+		description  string
+		objective    []PBTerm
+		clauses      [][]Literal
+		constraints  []pbCase
+		expectedSat  bool
+		expectedCost int64
+	}{
+		{"cheapest literal of a clause",
+			[]PBTerm{{5, A}, {2, B}, {3, C}},
+			[][]Literal{{A, B, C}}, nil, true, 2},
+		{"negative weights reward true literals",
+			[]PBTerm{{-3, A}, {2, B}, {-1, C}},
+			[][]Literal{{A.Negate(), B}}, nil, true, -2},
+		{"negated objective literals",
+			[]PBTerm{{4, A.Negate()}, {1, B}},
+			[][]Literal{{A.Negate(), B}}, nil, true, 1},
+		{"covering with a constraint",
+			[]PBTerm{{3, A}, {2, B}, {2, C}, {4, D}},
+			nil,
+			[]pbCase{{[]PBTerm{{2, A}, {1, B}, {1, C}, {3, D}}, PBGreaterEqual, 3}}, true, 4},
+		{"clauses and a constraint together",
+			[]PBTerm{{1, A}, {1, B}, {1, C}, {1, D}},
+			[][]Literal{{A, B}, {C, D}, {A.Negate(), C.Negate()}},
+			[]pbCase{{[]PBTerm{{1, B}, {1, D}}, PBLessEqual, 1}}, true, 2},
+		{"infeasible formula",
+			[]PBTerm{{1, A}},
+			[][]Literal{{A, B}},
+			[]pbCase{{[]PBTerm{{1, A}, {1, B}}, PBLessEqual, 0}}, false, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			problem := &PBProblem{Formula: NewExtendedCNF(), Objective: tc.objective}
+			for _, clause := range tc.clauses {
+				problem.Formula.AddClause(NewClause(clause...))
+			}
+			for _, pb := range tc.constraints {
+				if err := problem.Formula.AddPB(pb.terms, pb.comparison, pb.bound); err != nil {
+					t.Fatal(err)
+				}
+			}
+			clauses := len(problem.Formula.Clauses)
+
+			result := NewPBSolver().Minimize(problem)
+			if result.Error != nil {
+				t.Fatalf("Solver error: %v", result.Error)
+			}
+			if len(problem.Formula.Clauses) != clauses {
+				t.Fatal("Minimize changed the formula")
+			}
+			if !tc.expectedSat {
+				if result.Status != StatusUNSAT {
+					t.Errorf("Expected UNSAT, got %v", result.Status)
+				}
+				return
+			}
+			if !result.Optimal || result.Cost != tc.expectedCost {
+				t.Fatalf("Expected optimal cost %d, got %d (optimal %v)", tc.expectedCost, result.Cost, result.Optimal)
+			}
+			for _, name := range []string{"A", "B", "C", "D"} {
+				if _, ok := result.Assignment[name]; !ok {
+					result.Assignment[name] = false
+				}
+			}
+			if !holdsExtended(result.Assignment, problem.Formula) || objectiveValue(tc.objective, result.Assignment) != tc.expectedCost {
+				t.Errorf("Model %v does not match its cost", result.Assignment)
+			}
+		})
+	}
+
+	if result := NewPBSolver().Minimize(nil); result.Error == nil || result.Status != StatusUnknown {
+		t.Errorf("Expected an error for a nil problem, got %+v", result)
+	}
+}
+
+func TestPBSolver_MinimizeTautology(t *testing.T) {
+	problem := &PBProblem{Formula: NewExtendedCNF(), Objective: []PBTerm{{Coefficient: 1, Literal: L("A", false)}}}
+	problem.Formula.AddClause(&Clause{Literals: []Literal{L("A", false), L("A", true), L("B", false)}})
+	result := NewPBSolver().Minimize(problem)
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if !result.Optimal || result.Cost != 0 {
+		t.Errorf("Expected cost 0, got %d (optimal %v)", result.Cost, result.Optimal)
+	}
+}
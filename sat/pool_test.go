@@ -0,0 +1,177 @@
+package sat
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// pigeonholeClauses returns the clauses of pigeonhole(pigeons, holes)
+func pigeonholeClauses(pigeons, holes int) [][]Literal {
+	var clauses [][]Literal
+	for _, clause := range pigeonhole(pigeons, holes).Clauses {
+		clauses = append(clauses, append([]Literal(nil), clause.Literals...))
+	}
+	return clauses
+}
+
+func TestNewCDCLSolverWithPool_Concurrent(t *testing.T) {
+	A, B, C := L("A", false), L("B", false), L("C", false)
+	testCases := []struct {
+		description string
+		clauses     [][]Literal
+		expectedSat bool
+	}{
+		{"implication chain", [][]Literal{{A.Negate(), B}, {B.Negate(), C}, {A}}, true},
+		{"refuted by units", [][]Literal{{A, B}, {A.Negate()}, {B.Negate()}}, false},
+		{"four pigeons in four holes", pigeonholeClauses(4, 4), true},
+		{"five pigeons in four holes", pigeonholeClauses(5, 4), false},
+	}
+
+	const tenants = 4
+	errs := make([]error, tenants)
+	var wg sync.WaitGroup
+	for tenant := 0; tenant < tenants; tenant++ {
+		pool := testPool(t)
+		wg.Add(1)
+		go func(tenant int) {
+			defer wg.Done()
+			for round := 0; round < 10; round++ {
+				for _, tc := range testCases {
+					cnf := NewCNFWithPool(pool)
+					for _, lits := range tc.clauses {
+						cnf.AddClause(cnf.NewClause(lits...))
+					}
+					result := NewCDCLSolverWithPool(pool).Solve(cnf)
+					switch {
+					case result.Error != nil:
+						errs[tenant] = result.Error
+					case result.Satisfiable != tc.expectedSat:
+						errs[tenant] = fmt.Errorf("round %d, %s: got %v", round, tc.description, result.Status)
+					case result.Satisfiable && !holdsClauses(result.Assignment, tc.clauses):
+						errs[tenant] = fmt.Errorf("round %d, %s: model %v violates the formula", round, tc.description, result.Assignment)
+					}
+					if errs[tenant] != nil {
+						return
+					}
+				}
+				// Reclaim this tenant's memory while the others keep solving
+				pool.Reset()
+			}
+		}(tenant)
+	}
+	wg.Wait()
+	for tenant, err := range errs {
+		if err != nil {
+			t.Errorf("Tenant %d: %v", tenant, err)
+		}
+	}
+}
+
+func TestNewCDCLSolverWithPool_SurvivesResetPool(t *testing.T) {
+	pool := testPool(t)
+	solver := NewCDCLSolverWithPool(pool)
+	cnf := NewCNFWithPool(pool)
+	cnf.AddClause(cnf.NewClause(L("A", false), L("B", false)))
+	cnf.AddClause(cnf.NewClause(L("A", true), L("C", false)))
+	if result := solver.Solve(cnf); !result.Satisfiable {
+		t.Fatalf("Expected SAT, got %v (%v)", result.Status, result.Error)
+	}
+
+	// Wiping the package pool must not touch the solver's state
+	ResetPool()
+	solver.AddClause(cnf.NewClause(L("C", true)))
+	solver.AddClause(cnf.NewClause(L("B", true)))
+	if result := solver.Solve(cnf); result.Satisfiable {
+		t.Errorf("Expected UNSAT, got %v", result.Assignment)
+	}
+}
+
+func TestNewCDCLSolverWithPool_PackagePoolUntouched(t *testing.T) {
+	pool := testPool(t)
+	clauses := pigeonholeClauses(6, 5)
+	initAllocators()
+	before := satPool.Stats().Allocated
+
+	// Pigeonhole formulas need conflicts to refute
+	cnf := NewCNFWithPool(pool)
+	for _, lits := range clauses {
+		cnf.AddClause(cnf.NewClause(lits...))
+	}
+	result := NewCDCLSolverWithPool(pool).Solve(cnf)
+	if result.Status != StatusUNSAT || result.Statistics.Conflicts == 0 {
+		t.Fatalf("Expected UNSAT after conflicts, got %v", result.Status)
+	}
+	if after := satPool.Stats().Allocated; after != before {
+		t.Errorf("The solve allocated %d bytes from the package pool", after-before)
+	}
+}
+
+func TestNewCDCLSolverWithPool_RebuildReusesWatches(t *testing.T) {
+	pool := testPool(t)
+	cnf := NewCNFWithPool(pool)
+	for i := 0; i < 300; i++ {
+		cnf.AddClause(cnf.NewClause(L(varName(i), false), L(varName(i+1), true), L(varName(i+2), false)))
+	}
+	solver := NewCDCLSolverWithPool(pool)
+	if result := solver.Solve(cnf); !result.Satisfiable {
+		t.Fatalf("Expected SAT, got %v (%v)", result.Status, result.Error)
+	}
+	if len(solver.watched) == 0 {
+		t.Fatal("Expected watched clauses")
+	}
+	before := pool.Stats().Allocated
+	for i := 0; i < 20; i++ {
+		solver.rebuildWatchLists()
+	}
+	if after := pool.Stats().Allocated; after != before {
+		t.Errorf("Rebuilding the watch lists allocated %d bytes", after-before)
+	}
+}
+
+func TestNewClauseIn(t *testing.T) {
+	pool := testPool(t)
+	clause := NewClauseIn(pool, L("B", false), L("A", true), L("B", false))
+	if !clause.owned || len(clause.Literals) != 2 || clause.Literals[0] != L("A", true) {
+		t.Fatalf("Unexpected clause %v", clause)
+	}
+	// Owned clauses are reclaimed with their pool, not one at a time, so
+	// the package allocator never hands out their memory
+	FreeClause(clause)
+	if other := NewClause(L("C", false)); other == clause || clause.Literals[1] != L("B", false) {
+		t.Errorf("FreeClause released an owned clause")
+	}
+
+	if NewClauseIn(nil, L("A", false)).owned || NewClauseIn(satPool, L("A", false)).owned {
+		t.Error("Clauses from the package pools are not owned")
+	}
+	if NewCNF().Pool() != nil || NewCNFWithPool(pool).Pool() != pool {
+		t.Error("Unexpected formula pool")
+	}
+}
+
+func TestNewCNFConverterWithPool(t *testing.T) {
+	pool := testPool(t)
+	converter := NewCNFConverterWithPool(pool)
+	cnf, err := converter.ConvertExpression("(A & B) | (!A & C)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cnf.Pool() != pool {
+		t.Fatal("Expected the formula to use the converter's pool")
+	}
+	for _, clause := range cnf.Clauses {
+		if !clause.owned {
+			t.Fatalf("Clause %v is not in the converter's pool", clause)
+		}
+	}
+	ResetPool()
+	result := NewCDCLSolverWithPool(pool).Solve(cnf)
+	if !result.Satisfiable {
+		t.Fatalf("Expected SAT, got %v (%v)", result.Status, result.Error)
+	}
+	a, b, c := result.Assignment["A"], result.Assignment["B"], result.Assignment["C"]
+	if !(a && b || !a && c) {
+		t.Errorf("Model %v violates the expression", result.Assignment)
+	}
+}
@@ -0,0 +1,375 @@
+package sat
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xDarkicex/logic/core"
+	"github.com/xDarkicex/memory"
+)
+
+// PortfolioConfig diversifies one portfolio worker. The zero value is a
+// plain NewCDCLSolver without WalkSAT.
+type PortfolioConfig struct {
+	Seed        uint64 // Randomises initial activities, phases and WalkSAT; 0 keeps the defaults
+	StableMode  bool   // Start in stable instead of focused mode
+	RestartUnit int    // Conflicts per Luby unit; 0 keeps 100
+	WalkSAT     bool   // Run the WalkSAT pre-solver
+}
+
+// DefaultPortfolio returns n configurations that differ in seed, starting
+// mode, restart unit and WalkSAT use. The first is the default CDCLSolver.
+func DefaultPortfolio(n int) []PortfolioConfig {
+	units := []int{100, 50, 200, 400}
+	configs := make([]PortfolioConfig, n)
+	for i := range configs {
+		configs[i] = PortfolioConfig{
+			Seed:        uint64(i) * 0x9e3779b97f4a7c15,
+			StableMode:  i%2 == 1,
+			RestartUnit: units[(i/2)%len(units)],
+			WalkSAT:     i%3 != 2,
+		}
+	}
+	return configs
+}
+
+// apply configures a fresh solver for the variables names
+func (p PortfolioConfig) apply(c *CDCLSolver, names []string) {
+	if p.StableMode {
+		c.modeSwitcher.Switch(0, 0)
+	}
+	if p.RestartUnit > 0 {
+		luby := newLubyRestartStrategy(c.pool)
+		luby.baseUnit = p.RestartUnit
+		c.restartStrategy = luby
+	}
+	if !p.WalkSAT {
+		c.walkSolver = nil
+	}
+	if p.Seed == 0 {
+		return
+	}
+	if c.walkSolver != nil {
+		c.walkSolver.rng = p.Seed
+	}
+	vsids, ok := c.heuristic.(*VSIDSHeuristic)
+	if !ok {
+		return
+	}
+	// Activities below one bump only order variables no conflict has
+	// touched yet
+	rng := p.Seed
+	for _, name := range names {
+		rng ^= rng << 13
+		rng ^= rng >> 7
+		rng ^= rng << 17
+		idx := vsids.ensureVar(name)
+		vsids.activity[idx] = float64(rng>>11) / (1 << 53) * 1e-3
+		vsids.phases[idx] = int8(rng >> 63)
+	}
+}
+
+// PortfolioSolver runs diversified CDCLSolver workers on the same formula,
+// each in its own goroutine, and returns the first definitive answer. The
+// other workers are then cancelled. Workers share learned clauses that are
+// short or glue through a lock-free exchange; each imports the others'
+// clauses at its restarts.
+//
+// PortfolioSolver is not incremental, and like CDCLSolver is not safe for
+// concurrent Solve calls.
+type PortfolioSolver struct {
+	isSolving atomic.Bool // atomic guard for concurrent Solve calls
+	configs   []PortfolioConfig
+	shareSize int // Clauses of at most this many literals are shared
+	progress  ProgressFunc
+	interval  time.Duration
+
+	statistics SolverStatistics
+	winner     int
+	shared     int64
+}
+
+// NewPortfolioSolver creates a portfolio of n workers from DefaultPortfolio,
+// one per CPU if n is zero or less
+func NewPortfolioSolver(n int) *PortfolioSolver {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	return NewPortfolioSolverWithConfigs(DefaultPortfolio(n))
+}
+
+// NewPortfolioSolverWithConfigs creates a portfolio with one worker per
+// configuration
+func NewPortfolioSolverWithConfigs(configs []PortfolioConfig) *PortfolioSolver {
+	return &PortfolioSolver{configs: configs, shareSize: 8, winner: -1}
+}
+
+// Name returns solver name
+func (p *PortfolioSolver) Name() string {
+	return "Portfolio"
+}
+
+// Solve solves cnf with every worker
+func (p *PortfolioSolver) Solve(cnf *CNF) *SolverResult {
+	return p.solve(context.Background(), cnf, 0)
+}
+
+// SolveWithTimeout solves cnf with every worker under a time limit
+func (p *PortfolioSolver) SolveWithTimeout(cnf *CNF, timeout time.Duration) *SolverResult {
+	return p.solve(context.Background(), cnf, timeout)
+}
+
+// SolveContext solves cnf with every worker, stopping them all once ctx is
+// done
+func (p *PortfolioSolver) SolveContext(ctx context.Context, cnf *CNF) *SolverResult {
+	return p.solve(ctx, cnf, 0)
+}
+
+// SetProgress reports each worker's statistics to fn about once per
+// interval. Calls from different workers are serialised.
+func (p *PortfolioSolver) SetProgress(fn ProgressFunc, interval time.Duration) {
+	p.progress, p.interval = fn, interval
+}
+
+// solve runs the workers and waits for all of them to stop
+func (p *PortfolioSolver) solve(parent context.Context, cnf *CNF, timeout time.Duration) (result *SolverResult) {
+	defer func() { result.setStatus() }()
+	if !p.isSolving.CompareAndSwap(false, true) {
+		return &SolverResult{
+			Error: core.NewLogicError("sat", "PortfolioSolver.Solve", "concurrent Solve calls on the same solver instance are not allowed"),
+		}
+	}
+	defer p.isSolving.Store(false)
+	p.statistics, p.winner, p.shared = SolverStatistics{}, -1, 0
+	if len(p.configs) == 0 {
+		return &SolverResult{
+			Error: core.NewLogicError("sat", "PortfolioSolver.Solve", "portfolio has no workers"),
+		}
+	}
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	exchange := newClauseExchange(cnf.Variables, p.shareSize)
+	var progressMu sync.Mutex
+	report := func(stats SolverStatistics) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		p.progress(stats)
+	}
+
+	type outcome struct {
+		worker int
+		result *SolverResult
+	}
+	outcomes := make(chan outcome, len(p.configs))
+	for i, config := range p.configs {
+		// A worker's solver, formula and imported clauses live in a pool
+		// freed with the solve
+		pool := mustCreatePool()
+		defer pool.Free()
+		worker := NewCDCLSolverWithPool(pool)
+		config.apply(worker, cnf.Variables)
+		worker.exchange, worker.exchangeID = exchange, i
+		if p.progress != nil {
+			worker.SetProgress(report, p.interval)
+		}
+		formula := exchange.copyCNF(pool, cnf)
+		go func(i int) {
+			outcomes <- outcome{i, worker.solve(ctx, formula, nil, timeout)}
+		}(i)
+	}
+
+	var answer, stopped *SolverResult
+	for range p.configs {
+		o := <-outcomes
+		switch {
+		case answer == nil && o.result.Status != StatusUnknown:
+			answer, p.winner = o.result, o.worker
+			cancel()
+		case stopped == nil && o.result.Status == StatusUnknown:
+			stopped = o.result
+		}
+	}
+	p.shared = exchange.published.Load()
+	if answer == nil {
+		answer = stopped
+	}
+	p.statistics = answer.Statistics
+	return answer
+}
+
+// AddClause is not supported by the portfolio
+func (p *PortfolioSolver) AddClause(clause *Clause) error {
+	return core.NewLogicError("sat", "PortfolioSolver.AddClause",
+		"incremental solving not supported by the portfolio")
+}
+
+// GetStatistics returns the statistics of the worker that answered
+func (p *PortfolioSolver) GetStatistics() SolverStatistics {
+	return p.statistics
+}
+
+// Winner returns the index of the configuration that answered the last
+// solve, or -1 if none did
+func (p *PortfolioSolver) Winner() int {
+	return p.winner
+}
+
+// SharedClauses returns the number of clauses the workers published during
+// the last solve
+func (p *PortfolioSolver) SharedClauses() int64 {
+	return p.shared
+}
+
+// Reset clears solver state
+func (p *PortfolioSolver) Reset() {
+	p.statistics, p.winner, p.shared = SolverStatistics{}, -1, 0
+}
+
+// exchangeSlots is the capacity of the clause exchange ring
+const exchangeSlots = 1 << 12
+
+// sharedClause is a learned clause published by worker from
+type sharedClause struct {
+	seq  uint64
+	from int
+	lits []Literal
+}
+
+// clauseExchange is a ring of shared clauses. A writer claims a slot with
+// an atomic counter and publishes with an atomic pointer store; a reader
+// keeps its own cursor and skips clauses that were overwritten before it
+// got to them, so no worker ever waits for another.
+type clauseExchange struct {
+	slots     [exchangeSlots]atomic.Pointer[sharedClause]
+	head      atomic.Uint64
+	published atomic.Int64
+	maxSize   int
+
+	// Canonical variable names. Clauses live in pool memory that the
+	// collector does not scan, so shared literals use the caller's strings,
+	// which its CNF keeps alive. Read-only once built.
+	names map[string]string
+}
+
+func newClauseExchange(variables []string, maxSize int) *clauseExchange {
+	x := &clauseExchange{maxSize: maxSize, names: make(map[string]string, len(variables))}
+	for _, name := range variables {
+		x.names[name] = name
+	}
+	return x
+}
+
+// copyCNF gives a worker its own copy of cnf in pool, which solving
+// modifies. The variables are those of cnf, in its order.
+func (x *clauseExchange) copyCNF(pool *memory.Pool, cnf *CNF) *CNF {
+	formula := NewCNFWithPool(pool)
+	copyLiveClauses(pool, cnf.Clauses, func(_ int, lits []Literal) []Literal {
+		return x.canonical(lits)
+	}, func(_ int, clause *Clause) {
+		clause.ID = formula.nextID
+		formula.nextID++
+		formula.Clauses = append(formula.Clauses, clause)
+	})
+	formula.Variables = append(formula.Variables, cnf.Variables...)
+	return formula
+}
+
+// canonical copies lits onto the canonical variable names
+func (x *clauseExchange) canonical(lits []Literal) []Literal {
+	out := make([]Literal, len(lits))
+	for i, lit := range lits {
+		out[i] = Literal{Variable: x.names[lit.Variable], Negated: lit.Negated}
+		if out[i].Variable == "" {
+			out[i].Variable = lit.Variable
+		}
+	}
+	return out
+}
+
+// publish shares clause if it is short or glue
+func (x *clauseExchange) publish(from int, clause *Clause) {
+	if len(clause.Literals) == 0 || (len(clause.Literals) > x.maxSize && !clause.Glue) {
+		return
+	}
+	seq := x.head.Add(1) - 1
+	x.slots[seq%exchangeSlots].Store(&sharedClause{seq: seq, from: from, lits: x.canonical(clause.Literals)})
+	x.published.Add(1)
+}
+
+// collect calls fn on every clause published by other workers since
+// *cursor, and advances the cursor
+func (x *clauseExchange) collect(self int, cursor *uint64, fn func([]Literal) bool) bool {
+	head := x.head.Load()
+	if head-*cursor > exchangeSlots {
+		*cursor = head - exchangeSlots
+	}
+	for ; *cursor < head; *cursor++ {
+		shared := x.slots[*cursor%exchangeSlots].Load()
+		if shared == nil || shared.seq != *cursor || shared.from == self {
+			continue // Overwritten, not yet stored, or our own
+		}
+		if !fn(shared.lits) {
+			return false
+		}
+	}
+	return true
+}
+
+// importShared adds the clauses other workers shared since the last call.
+// It must run at decision level 0, and returns false if an imported clause
+// is falsified there, refuting the formula.
+func (c *CDCLSolver) importShared() bool {
+	return c.exchange.collect(c.exchangeID, &c.exchangeCursor, c.importClause)
+}
+
+// importClause adds one shared clause as a learned clause at level 0
+func (c *CDCLSolver) importClause(lits []Literal) bool {
+	var free []int
+	for i, lit := range lits {
+		value, assigned := c.assignment[lit.Variable]
+		if !assigned {
+			free = append(free, i)
+		} else if value != lit.Negated {
+			return true // Satisfied at the root
+		}
+	}
+	if len(free) == 0 {
+		return false
+	}
+
+	// NewClause sorts the literals, so watches are chosen afterwards
+	clause := NewClauseIn(c.pool, lits...)
+	clause.Learned = true
+	clause.ID = c.cnf.nextID
+	c.cnf.nextID++
+	clause.LBD = len(clause.Literals)
+	c.clauseActivity[clause.ID] = 1.0
+	if c.clauseDatabase != nil {
+		c.clauseDatabase.AddClause(clause, c.conflicts)
+	}
+
+	watch1, watch2 := -1, -1
+	for i, lit := range clause.Literals {
+		if c.assignment.IsAssigned(lit.Variable) {
+			continue
+		}
+		if watch1 == -1 {
+			watch1 = i
+		} else if watch2 == -1 {
+			watch2 = i
+		}
+	}
+	if watch2 == -1 && len(clause.Literals) > 1 {
+		watch2 = (watch1 + 1) % len(clause.Literals) // A false literal
+	}
+	c.watchClauseAt(clause, watch1, watch2)
+	if len(free) == 1 {
+		lit := clause.Literals[watch1]
+		c.assign(lit.Variable, !lit.Negated, clause)
+	}
+	return true
+}
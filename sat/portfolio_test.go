@@ -0,0 +1,171 @@
+package sat
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPortfolioSolver_Solve(t *testing.T) {
+	testCases := []struct {
+		description string
+		cnf         *CNF
+		expectedSat bool
+	}{
+		{"implication chain", buildCNF([][]Literal{
+			{L("A", false)},
+			{L("A", true), L("B", false)},
+			{L("B", true), L("C", false)},
+		}), true},
+		{"refuted by units", buildCNF([][]Literal{
+			{L("A", false)},
+			{L("A", true), L("B", false)},
+			{L("B", true)},
+		}), false},
+		{"every sign pattern of two variables", buildCNF([][]Literal{
+			{L("A", false), L("B", false)},
+			{L("A", false), L("B", true)},
+			{L("A", true), L("B", false)},
+			{L("A", true), L("B", true)},
+		}), false},
+		{"four pigeons in four holes", pigeonhole(4, 4), true},
+		{"five pigeons in four holes", pigeonhole(5, 4), false},
+	}
+
+	p := NewPortfolioSolver(4)
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result := p.Solve(tc.cnf)
+			if result.Error != nil {
+				t.Fatalf("Solver error: %v", result.Error)
+			}
+			if result.Satisfiable != tc.expectedSat {
+				t.Fatalf("Expected satisfiable=%v, got %v", tc.expectedSat, result.Status)
+			}
+			if result.Satisfiable && !satisfiesAll(result.Assignment, tc.cnf.Clauses) {
+				t.Errorf("Model %v does not satisfy the formula", result.Assignment)
+			}
+			if w := p.Winner(); w < 0 || w >= 4 {
+				t.Errorf("Winner %d out of range", w)
+			}
+		})
+	}
+}
+
+func TestPortfolioSolver_Unsat(t *testing.T) {
+	p := NewPortfolioSolver(4)
+	result := p.Solve(pigeonhole(7, 6))
+	if result.Status != StatusUNSAT {
+		t.Fatalf("Expected UNSAT, got %v (%v)", result.Status, result.Error)
+	}
+	if p.SharedClauses() == 0 {
+		t.Error("Expected the workers to share clauses")
+	}
+}
+
+func TestPortfolioSolver_Tautology(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(&Clause{Literals: []Literal{L("A", false), L("A", true), L("B", false)}})
+	cnf.AddClause(NewClause(L("A", true)))
+	cnf.AddClause(NewClause(L("B", true)))
+	result := NewPortfolioSolver(2).Solve(cnf)
+	if result.Status != StatusSAT {
+		t.Fatalf("Expected SAT, got %v (%v)", result.Status, result.Error)
+	}
+}
+
+func TestPortfolioSolver_Cancel(t *testing.T) {
+	p := NewPortfolioSolver(4)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result := p.SolveContext(ctx, pigeonhole(10, 9))
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Took %v to stop", elapsed)
+	}
+	if result.Status != StatusUnknown || result.Reason != ReasonCancelled || p.Winner() != -1 {
+		t.Errorf("Expected UNKNOWN (cancelled) and no winner, got %v (%v), winner %d", result.Status, result.Reason, p.Winner())
+	}
+
+	if result := p.SolveWithTimeout(pigeonhole(10, 9), 20*time.Millisecond); result.Reason != ReasonTimeout {
+		t.Errorf("Expected UNKNOWN (timeout), got %v (%v)", result.Status, result.Reason)
+	}
+}
+
+func TestPortfolioSolver_PackagePoolUntouched(t *testing.T) {
+	// The workers' formulas and imported clauses are freed with the solve
+	cnf := pigeonhole(7, 6)
+	initAllocators()
+	before, lits := satPool.Stats().Allocated, litPool.Stats().Allocated
+	if result := NewPortfolioSolver(4).Solve(cnf); result.Status != StatusUNSAT {
+		t.Fatalf("Expected UNSAT, got %v (%v)", result.Status, result.Error)
+	}
+	if after := satPool.Stats().Allocated; after != before {
+		t.Errorf("The solve allocated %d bytes from the package pool", after-before)
+	}
+	if after := litPool.Stats().Allocated; after != lits {
+		t.Errorf("The solve allocated %d bytes from the literal pool", after-lits)
+	}
+}
+
+func TestClauseExchange(t *testing.T) {
+	x := newClauseExchange([]string{"A", "B", "C"}, 2)
+	x.publish(0, NewClause(L("A", false), L("B", false), L("C", false))) // Too long
+	x.publish(0, NewClause(L("A", true)))
+	x.publish(1, NewClause(L("A", false), L("B", false)))
+
+	var cursor uint64
+	var got [][]Literal
+	x.collect(1, &cursor, func(lits []Literal) bool {
+		got = append(got, lits)
+		return true
+	})
+	if len(got) != 1 || len(got[0]) != 1 || got[0][0] != L("A", true) {
+		t.Errorf("Expected only worker 0's unit ¬A, got %v", got)
+	}
+	if cursor != 2 {
+		t.Errorf("Expected the cursor past both clauses, got %d", cursor)
+	}
+
+	// A reader that falls behind skips what was overwritten
+	for i := 0; i < exchangeSlots+10; i++ {
+		x.publish(0, NewClause(L("B", false)))
+	}
+	n := 0
+	x.collect(1, &cursor, func([]Literal) bool { n++; return true })
+	if n != exchangeSlots {
+		t.Errorf("Expected %d clauses after lapping, got %d", exchangeSlots, n)
+	}
+}
+
+func TestCDCLSolver_ImportClause(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	cnf.AddClause(NewClause(L("B", true), L("C", false)))
+	c := NewCDCLSolver()
+	c.cnf = cnf
+
+	// ¬A is unit at the root; with it A ∨ B propagates B, then C
+	if !c.importClause([]Literal{L("A", true)}) {
+		t.Fatal("Unit import refuted a satisfiable formula")
+	}
+	c.initializeWatchLists()
+	if conflict := c.propagate(); conflict != nil {
+		t.Fatalf("Unexpected conflict %v", conflict)
+	}
+	if c.assignment["A"] || !c.assignment["B"] || !c.assignment["C"] {
+		t.Errorf("Expected ¬A, B and C at the root, got %v", c.assignment)
+	}
+	if !c.importClause([]Literal{L("B", false), L("D", false)}) {
+		t.Error("A satisfied clause should be skipped")
+	}
+	if c.importClause([]Literal{L("C", true)}) {
+		t.Error("A falsified clause should refute the formula")
+	}
+}
+
+func TestPortfolioSolver_AddClause(t *testing.T) {
+	if err := NewPortfolioSolver(2).AddClause(NewClause(L("A", false))); err == nil {
+		t.Error("Expected AddClause to fail")
+	}
+}
@@ -6,20 +6,22 @@ import "github.com/xDarkicex/memory"
 type SATPreprocessor struct {
 	originalVars   []string
 	eliminatedVars map[string]bool
-	substitutions  map[string]Literal
+	reconstruction *ReconstructionStack // Removed clauses, replayed by PostProcess
 	eliminated     int
 }
 
 func NewSATPreprocessor() *SATPreprocessor {
 	return &SATPreprocessor{
 		eliminatedVars: make(map[string]bool),
-		substitutions:  make(map[string]Literal),
+		reconstruction: NewReconstructionStack(),
 	}
 }
 
 func (p *SATPreprocessor) Preprocess(cnf *CNF) (*CNF, error) {
-	p.originalVars = memory.MustPoolSlice[string](satPool, len(cnf.Variables))[:len(cnf.Variables)]
-	copy(p.originalVars, cnf.Variables)
+	// On the heap: the names must stay reachable until PostProcess
+	p.originalVars = append([]string(nil), cnf.Variables...)
+	p.eliminatedVars = make(map[string]bool)
+	p.reconstruction.Reset()
 
 	result := &CNF{
 		Clauses:   memory.MustPoolSlice[*Clause](satPool, len(cnf.Clauses)),
@@ -114,10 +116,10 @@ func (p *SATPreprocessor) unitPropagation(cnf *CNF) bool {
 			}
 
 			if len(newLiterals) == 0 {
-				// Empty clause - contradiction
+				// Empty clause - contradiction. Keep it, so that the
+				// result is unsatisfiable rather than empty.
 				FreeClause(clause)
-				// Clean up remaining newClauses to avoid leak? It will be handled later or solver aborts.
-				cnf.Clauses = []*Clause{}
+				cnf.Clauses = []*Clause{NewClause()}
 				return changed
 			}
 
@@ -134,7 +136,7 @@ func (p *SATPreprocessor) unitPropagation(cnf *CNF) bool {
 		}
 
 		cnf.Clauses = newClauses
-		p.substitutions[unit.Variable] = unit
+		p.reconstruction.Push(unit, []Literal{unit})
 		p.eliminatedVars[unit.Variable] = true
 		p.eliminated++
 	}
@@ -143,27 +145,31 @@ func (p *SATPreprocessor) unitPropagation(cnf *CNF) bool {
 }
 
 func (p *SATPreprocessor) pureLiteralElimination(cnf *CNF) bool {
-	literalCount := make(map[string]int)
+	positive := make(map[string]int)
+	negative := make(map[string]int)
 
-	// Count occurrences
+	// Count occurrences of each polarity
 	for _, clause := range cnf.Clauses {
 		for _, lit := range clause.Literals {
 			if !p.eliminatedVars[lit.Variable] {
 				if lit.Negated {
-					literalCount[lit.Variable]--
+					negative[lit.Variable]++
 				} else {
-					literalCount[lit.Variable]++
+					positive[lit.Variable]++
 				}
 			}
 		}
 	}
 
-	// Find pure literals
-	pureLiterals := memory.MustPoolSlice[Literal](satPool, len(literalCount))
-	for variable, count := range literalCount {
-		if count > 0 {
+	// Find pure literals: variables that occur in one polarity only
+	var pureLiterals []Literal
+	for variable, count := range positive {
+		if count > 0 && negative[variable] == 0 {
 			pureLiterals = append(pureLiterals, Literal{Variable: variable, Negated: false})
-		} else if count < 0 {
+		}
+	}
+	for variable, count := range negative {
+		if count > 0 && positive[variable] == 0 {
 			pureLiterals = append(pureLiterals, Literal{Variable: variable, Negated: true})
 		}
 	}
@@ -179,6 +185,7 @@ func (p *SATPreprocessor) pureLiteralElimination(cnf *CNF) bool {
 		satisfied := false
 		for _, pureLit := range pureLiterals {
 			if p.clauseContains(clause, pureLit) {
+				p.reconstruction.Push(pureLit, clause.Literals)
 				satisfied = true
 				break
 			}
@@ -193,7 +200,6 @@ func (p *SATPreprocessor) pureLiteralElimination(cnf *CNF) bool {
 
 	// Mark variables as eliminated
 	for _, lit := range pureLiterals {
-		p.substitutions[lit.Variable] = lit
 		p.eliminatedVars[lit.Variable] = true
 		p.eliminated++
 	}
@@ -231,23 +237,19 @@ func (p *SATPreprocessor) subsumption(cnf *CNF) bool {
 	return changed
 }
 
-
+// PostProcess extends a model of the preprocessed formula to a model of
+// the formula passed to Preprocess, assigning every one of its variables
 func (p *SATPreprocessor) PostProcess(assignment Assignment) Assignment {
-	result := make(Assignment)
+	result := assignment.Clone()
 
-	// Copy non-eliminated variables
-	for variable, value := range assignment {
-		if !p.eliminatedVars[variable] {
-			result[variable] = value
+	// Variables the preprocessed formula lost may take any value, except
+	// where a removed clause needs one
+	for _, variable := range p.originalVars {
+		if _, ok := result[variable]; !ok {
+			result[variable] = false
 		}
 	}
-
-	// Add eliminated variables with their forced values
-	for variable, literal := range p.substitutions {
-		result[variable] = !literal.Negated
-	}
-
-	return result
+	return p.reconstruction.Extend(result)
 }
 
 // Helper methods
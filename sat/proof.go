@@ -0,0 +1,373 @@
+package sat
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+
+	"github.com/xDarkicex/logic/core"
+	"github.com/xDarkicex/memory"
+)
+
+// ProofFormat selects the encoding of an emitted clausal proof
+type ProofFormat int
+
+const (
+	// ProofDRAT is the textual DRAT format understood by drat-trim
+	ProofDRAT ProofFormat = iota
+	// ProofBinaryDRAT is the compact binary DRAT encoding
+	ProofBinaryDRAT
+	// ProofLRAT is textual LRAT: DRAT plus clause IDs and unit-propagation hints
+	ProofLRAT
+	// ProofBinaryLRAT is the binary LRAT encoding
+	ProofBinaryLRAT
+)
+
+// String returns the format name
+func (f ProofFormat) String() string {
+	switch f {
+	case ProofDRAT:
+		return "DRAT"
+	case ProofBinaryDRAT:
+		return "binary DRAT"
+	case ProofLRAT:
+		return "LRAT"
+	case ProofBinaryLRAT:
+		return "binary LRAT"
+	default:
+		return "unknown"
+	}
+}
+
+// IsLRAT reports whether the format carries clause IDs and hints
+func (f ProofFormat) IsLRAT() bool {
+	return f == ProofLRAT || f == ProofBinaryLRAT
+}
+
+// IsBinary reports whether the format uses the binary encoding
+func (f ProofFormat) IsBinary() bool {
+	return f == ProofBinaryDRAT || f == ProofBinaryLRAT
+}
+
+// ProofWriter emits a clausal proof for a formula. Variables are numbered
+// exactly as WriteDIMACS numbers them for the same formula, and LRAT clause
+// IDs are Clause.IDs, so the input clauses of a CNF built with AddClause or
+// ReadDIMACS are 1..n in file order. Fresh lemma IDs are drawn from the
+// formula's clause counter.
+//
+// All methods are no-ops on a nil *ProofWriter, which lets call sites emit
+// unconditionally. The first write error is kept and reported by Flush.
+type ProofWriter struct {
+	w      *bufio.Writer
+	format ProofFormat
+	vars   *VariableMap
+	cnf    *CNF
+	buf    []byte
+	lastID int
+	err    error
+
+	lemmas    int64
+	deletions int64
+}
+
+// NewProofWriter creates a proof writer for cnf in the given format
+func NewProofWriter(w io.Writer, format ProofFormat, cnf *CNF) *ProofWriter {
+	return newProofWriter(nil, w, format, cnf)
+}
+
+func newProofWriter(pool *memory.Pool, w io.Writer, format ProofFormat, cnf *CNF) *ProofWriter {
+	lastID := 0
+	for _, clause := range cnf.Clauses {
+		if clause != nil && clause.ID > lastID {
+			lastID = clause.ID
+		}
+	}
+	return &ProofWriter{
+		w:      bufio.NewWriter(w),
+		format: format,
+		vars:   NewVariableMapForCNF(cnf),
+		cnf:    cnf,
+		buf:    memory.MustPoolSlice[byte](poolOr(pool), 256),
+		lastID: lastID,
+	}
+}
+
+// Format returns the proof encoding
+func (p *ProofWriter) Format() ProofFormat {
+	if p == nil {
+		return ProofDRAT
+	}
+	return p.format
+}
+
+// Variables returns the variable numbering used by the proof
+func (p *ProofWriter) Variables() *VariableMap {
+	if p == nil {
+		return nil
+	}
+	return p.vars
+}
+
+// NextID reserves a fresh clause ID for a lemma that is not stored in the
+// formula (derived units, the final empty clause).
+func (p *ProofWriter) NextID() int {
+	if p == nil {
+		return 0
+	}
+	id := p.cnf.nextID
+	p.cnf.nextID++
+	return id
+}
+
+// AddClause emits a lemma. hints are the LRAT antecedent IDs in
+// unit-propagation order and are ignored by the DRAT formats.
+func (p *ProofWriter) AddClause(id int, lits []Literal, hints []int) {
+	if p == nil || p.err != nil {
+		return
+	}
+	p.lemmas++
+	p.buf = p.buf[:0]
+	lrat := p.format.IsLRAT()
+	if p.format.IsBinary() {
+		p.buf = append(p.buf, 'a')
+		if lrat {
+			p.buf = appendProofVarint(p.buf, uint64(2*id))
+		}
+		for _, lit := range lits {
+			p.buf = appendProofVarint(p.buf, p.binaryLiteral(lit))
+		}
+		p.buf = append(p.buf, 0)
+		if lrat {
+			for _, h := range hints {
+				p.buf = appendProofVarint(p.buf, uint64(2*h))
+			}
+			p.buf = append(p.buf, 0)
+		}
+	} else {
+		if lrat {
+			p.buf = strconv.AppendInt(p.buf, int64(id), 10)
+			p.buf = append(p.buf, ' ')
+		}
+		for _, lit := range lits {
+			p.buf = strconv.AppendInt(p.buf, int64(p.vars.ToDIMACS(lit)), 10)
+			p.buf = append(p.buf, ' ')
+		}
+		p.buf = append(p.buf, '0')
+		if lrat {
+			for _, h := range hints {
+				p.buf = append(p.buf, ' ')
+				p.buf = strconv.AppendInt(p.buf, int64(h), 10)
+			}
+			p.buf = append(p.buf, ' ', '0')
+		}
+		p.buf = append(p.buf, '\n')
+	}
+	if id > p.lastID {
+		p.lastID = id
+	}
+	_, p.err = p.w.Write(p.buf)
+}
+
+// DeleteClause emits the deletion of a clause. DRAT identifies the clause
+// by its literals, LRAT by its ID.
+func (p *ProofWriter) DeleteClause(id int, lits []Literal) {
+	if p == nil || p.err != nil {
+		return
+	}
+	p.deletions++
+	p.buf = p.buf[:0]
+	switch p.format {
+	case ProofDRAT:
+		p.buf = append(p.buf, 'd', ' ')
+		for _, lit := range lits {
+			p.buf = strconv.AppendInt(p.buf, int64(p.vars.ToDIMACS(lit)), 10)
+			p.buf = append(p.buf, ' ')
+		}
+		p.buf = append(p.buf, '0', '\n')
+	case ProofBinaryDRAT:
+		p.buf = append(p.buf, 'd')
+		for _, lit := range lits {
+			p.buf = appendProofVarint(p.buf, p.binaryLiteral(lit))
+		}
+		p.buf = append(p.buf, 0)
+	case ProofLRAT:
+		p.buf = strconv.AppendInt(p.buf, int64(p.lastID), 10)
+		p.buf = append(p.buf, ' ', 'd', ' ')
+		p.buf = strconv.AppendInt(p.buf, int64(id), 10)
+		p.buf = append(p.buf, ' ', '0', '\n')
+	case ProofBinaryLRAT:
+		p.buf = append(p.buf, 'd')
+		p.buf = appendProofVarint(p.buf, uint64(2*id))
+		p.buf = append(p.buf, 0)
+	}
+	_, p.err = p.w.Write(p.buf)
+}
+
+// Strengthen records that clause has been replaced in place by a subset of
+// its old literals: the new clause is added as a lemma and the old one is
+// deleted. In LRAT mode the clause receives a fresh ID, since IDs may not
+// be reused.
+func (p *ProofWriter) Strengthen(clause *Clause, old []Literal, hints []int) {
+	if p == nil {
+		return
+	}
+	oldID := clause.ID
+	if p.format.IsLRAT() {
+		clause.ID = p.NextID()
+	}
+	p.AddClause(clause.ID, clause.Literals, hints)
+	p.DeleteClause(oldID, old)
+}
+
+// Flush writes buffered proof data and returns the first error seen
+func (p *ProofWriter) Flush() error {
+	if p == nil {
+		return nil
+	}
+	if p.err == nil {
+		p.err = p.w.Flush()
+	}
+	if p.err != nil {
+		return core.NewLogicError("sat", "ProofWriter.Flush", p.err.Error())
+	}
+	return nil
+}
+
+// GetStatistics returns the number of emitted lemmas and deletions
+func (p *ProofWriter) GetStatistics() map[string]int64 {
+	if p == nil {
+		return map[string]int64{}
+	}
+	return map[string]int64{
+		"lemmas":    p.lemmas,
+		"deletions": p.deletions,
+	}
+}
+
+// binaryLiteral maps a literal to the 2v+sign binary proof encoding
+func (p *ProofWriter) binaryLiteral(lit Literal) uint64 {
+	v := uint64(p.vars.Index(lit.Variable))
+	if lit.Negated {
+		return 2*v + 1
+	}
+	return 2 * v
+}
+
+// appendProofVarint appends x as a 7-bit little-endian varint. Binary
+// formats encode every number, IDs included, as 2|n| + sign.
+func appendProofVarint(buf []byte, x uint64) []byte {
+	for x > 0x7f {
+		buf = append(buf, byte(x&0x7f)|0x80)
+		x >>= 7
+	}
+	return append(buf, byte(x))
+}
+
+// proofTracing is implemented by inprocessors that can log their formula
+// changes to a proof.
+type proofTracing interface {
+	SetProofWriter(p *ProofWriter)
+}
+
+// SetProofOutput makes subsequent Solve calls write a clausal proof of
+// their run to w. An UNSAT answer ends the proof with the empty clause.
+// The proof refers to the formula as it was passed to Solve, so dump it
+// with WriteDIMACS before solving: inprocessing rewrites the CNF in place.
+// Proofs are only produced for plain CNF; XOR reasoning is not logged.
+// Passing a nil writer disables proof output.
+func (c *CDCLSolver) SetProofOutput(w io.Writer, format ProofFormat) {
+	c.proofOutput = w
+	c.proofFormat = format
+}
+
+// beginProof opens the proof stream for a new solve call
+func (c *CDCLSolver) beginProof(cnf *CNF) {
+	c.proof = nil
+	c.proofUnits = make(map[string]int)
+	if c.proofOutput != nil {
+		c.proof = newProofWriter(c.pool, c.proofOutput, c.proofFormat, cnf)
+	}
+	if tracer, ok := c.inprocessor.(proofTracing); ok {
+		tracer.SetProofWriter(c.proof)
+	}
+}
+
+// finishProof flushes the proof and reports write failures in result
+func (c *CDCLSolver) finishProof(result *SolverResult) {
+	if c.proof == nil {
+		return
+	}
+	if err := c.proof.Flush(); err != nil && result != nil && result.Error == nil {
+		result.Error = err
+	}
+	if tracer, ok := c.inprocessor.(proofTracing); ok {
+		tracer.SetProofWriter(nil)
+	}
+	c.proof = nil
+}
+
+// proofLearn emits a learned clause. Its LRAT hints are the resolution
+// chain of the analysis replayed backwards: under the negated lemma the
+// last resolved reason becomes unit first and the conflict clause is
+// falsified last.
+func (c *CDCLSolver) proofLearn(clause, conflict *Clause) {
+	if c.proof == nil {
+		return
+	}
+	var hints []int
+	if c.proof.Format().IsLRAT() {
+		if fuip, ok := c.analyzer.(*FirstUIPAnalyzer); ok {
+			reasons := fuip.Antecedents()
+			hints = memory.MustPoolSlice[int](c.pool, len(reasons)+1)
+			for i := len(reasons) - 1; i >= 0; i-- {
+				hints = append(hints, reasons[i].ID)
+			}
+		}
+		hints = append(hints, conflict.ID)
+	}
+	c.proof.AddClause(clause.ID, clause.Literals, hints)
+}
+
+// proofUnit records a root-level assignment as a unit lemma. Keeping the
+// unit in the proof makes later steps independent of the reason clause,
+// which inprocessing or clause deletion may remove.
+func (c *CDCLSolver) proofUnit(variable string, value bool, reason *Clause) {
+	if c.proof == nil || reason == nil {
+		return
+	}
+	if _, done := c.proofUnits[variable]; done {
+		return
+	}
+	if len(reason.Literals) == 1 {
+		c.proofUnits[variable] = reason.ID
+		return
+	}
+	hints := c.rootHints(reason)
+	id := c.proof.NextID()
+	c.proof.AddClause(id, []Literal{{Variable: variable, Negated: !value}}, hints)
+	c.proofUnits[variable] = id
+}
+
+// proofRefute ends the proof with the empty clause, derived from a clause
+// falsified at the root level.
+func (c *CDCLSolver) proofRefute(conflict *Clause) {
+	if c.proof == nil || conflict == nil {
+		return
+	}
+	c.proof.AddClause(c.proof.NextID(), nil, c.rootHints(conflict))
+}
+
+// rootHints returns the unit lemmas falsifying the other literals of
+// clause followed by the clause itself.
+func (c *CDCLSolver) rootHints(clause *Clause) []int {
+	if !c.proof.Format().IsLRAT() {
+		return nil
+	}
+	hints := memory.MustPoolSlice[int](c.pool, len(clause.Literals)+1)
+	for _, lit := range clause.Literals {
+		if id, ok := c.proofUnits[lit.Variable]; ok {
+			hints = append(hints, id)
+		}
+	}
+	return append(hints, clause.ID)
+}
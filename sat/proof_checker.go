@@ -0,0 +1,737 @@
+package sat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/xDarkicex/logic/core"
+	"github.com/xDarkicex/memory"
+)
+
+// ProofCheckResult reports the outcome of checking a clausal proof
+type ProofCheckResult struct {
+	Verified bool
+
+	// FailedLemma is the earliest lemma, in proof order, that is neither
+	// RUP nor RAT with respect to the clauses active before it. It is nil
+	// when the proof verifies or when it fails for another reason.
+	FailedLemma []Literal
+	FailedStep  int    // 1-based index of FailedLemma among the proof's lemmas
+	Reason      string // Why verification failed
+
+	Lemmas      int // Lemmas read from the proof
+	Deletions   int // Deletions read from the proof
+	CoreLemmas  int // Lemmas needed for the refutation, all of them checked
+	RATChecks   int // Lemmas that needed the RAT check
+	IgnoredDels int // Deletions of unit or unknown clauses, which are skipped
+}
+
+// DRATChecker verifies DRAT and LRAT refutations by backward checking, as
+// drat-trim does: the proof is replayed forward up to the empty clause,
+// then lemmas are checked from last to first, and only those that some
+// later check actually used. Each lemma must be RUP, or RAT on its first
+// literal. LRAT hints are read but not trusted; every lemma is re-derived.
+//
+// Variables are numbered as WriteDIMACS and ProofWriter number them for
+// the formula. Deletions of unit clauses are ignored, matching drat-trim.
+type DRATChecker struct {
+	cnf  *CNF
+	vars *VariableMap
+
+	// Clause store: literals of clause i are lits[start[i]:start[i+1]]
+	lits   []int32
+	start  []int32
+	pivots []int32 // First literal as written, -1 for the empty clause
+	active []bool
+	core   []bool
+	ids    map[int]int32 // LRAT clause ID -> clause index
+
+	// Proof steps in order: a clause index, negated for deletions
+	steps []int32
+	empty int32 // Index of the first empty lemma, -1 if none
+
+	// Unit propagation with two watched literals
+	watches [][]int32 // Literal -> watching clause indices
+	values  []int8    // Literal -> 1 true, -1 false, 0 unassigned
+	reasons []int32   // Variable -> reason clause, -1 for assumptions
+	trail   []int32
+	units   []int32 // Unit and empty clauses, assigned before every check
+}
+
+// NewDRATChecker creates a checker for proofs of cnf
+func NewDRATChecker(cnf *CNF) *DRATChecker {
+	return &DRATChecker{
+		cnf:   cnf,
+		vars:  NewVariableMapForCNF(cnf),
+		lits:  memory.MustPoolSlice[int32](satPool, 1024),
+		start: append(memory.MustPoolSlice[int32](satPool, len(cnf.Clauses)+1), 0),
+		ids:   make(map[int]int32),
+		empty: -1,
+	}
+}
+
+// CheckProof verifies that proof, in the given format, refutes cnf
+func CheckProof(cnf *CNF, proof io.Reader, format ProofFormat) (*ProofCheckResult, error) {
+	return NewDRATChecker(cnf).Check(proof, format)
+}
+
+// Check reads the proof and verifies it. Read and syntax errors are
+// returned as errors; a proof that reads fine but does not refute the
+// formula gives a result with Verified false.
+func (d *DRATChecker) Check(proof io.Reader, format ProofFormat) (*ProofCheckResult, error) {
+	result := &ProofCheckResult{}
+
+	for _, clause := range d.cnf.Clauses {
+		if clause == nil || clause.Deleted {
+			continue
+		}
+		idx := d.addClause(clause.Literals)
+		d.ids[clause.ID] = idx
+	}
+	originals := d.numClauses()
+
+	var err error
+	if format.IsBinary() {
+		err = d.readBinary(bufio.NewReader(proof), format.IsLRAT(), result)
+	} else {
+		err = d.readText(proof, format.IsLRAT(), result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if d.empty < 0 {
+		// No explicit empty clause: the final formula must be refuted by
+		// unit propagation alone
+		d.empty = d.addClause(nil)
+		d.steps = append(d.steps, d.empty)
+	}
+
+	d.buildWatches()
+	d.verify(originals, result)
+	return result, nil
+}
+
+// numClauses returns the number of stored clauses
+func (d *DRATChecker) numClauses() int32 {
+	return int32(len(d.start) - 1)
+}
+
+// clause returns the literals of clause i
+func (d *DRATChecker) clause(i int32) []int32 {
+	return d.lits[d.start[i]:d.start[i+1]]
+}
+
+// addClause stores a clause, dropping duplicate literals, and returns its index
+func (d *DRATChecker) addClause(lits []Literal) int32 {
+	first := int32(len(d.lits))
+	for _, lit := range lits {
+		d.lits = d.appendUnique(d.lits, first, d.encode(lit))
+	}
+	return d.finishClause()
+}
+
+// appendUnique appends lit to the clause starting at first unless present
+func (d *DRATChecker) appendUnique(lits []int32, first int32, lit int32) []int32 {
+	for _, l := range lits[first:] {
+		if l == lit {
+			return lits
+		}
+	}
+	return append(lits, lit)
+}
+
+// finishClause closes the clause whose literals were appended last
+func (d *DRATChecker) finishClause() int32 {
+	pivot := int32(-1)
+	if first := d.start[len(d.start)-1]; int(first) < len(d.lits) {
+		pivot = d.lits[first]
+	}
+	d.pivots = append(d.pivots, pivot)
+	d.start = append(d.start, int32(len(d.lits)))
+	d.active = append(d.active, true)
+	d.core = append(d.core, false)
+	return d.numClauses() - 1
+}
+
+// encode maps a literal to 2v+sign
+func (d *DRATChecker) encode(lit Literal) int32 {
+	v := int32(d.vars.Index(lit.Variable))
+	if lit.Negated {
+		return 2*v + 1
+	}
+	return 2 * v
+}
+
+// encodeDIMACS maps a signed DIMACS literal to 2v+sign
+func encodeDIMACS(lit int) int32 {
+	if lit < 0 {
+		return int32(-2*lit + 1)
+	}
+	return int32(2 * lit)
+}
+
+// decode maps an encoded literal back to a Literal
+func (d *DRATChecker) decode(lit int32) Literal {
+	return Literal{Variable: d.vars.Name(int(lit >> 1)), Negated: lit&1 == 1}
+}
+
+// lemma records a lemma whose literals were appended last
+func (d *DRATChecker) lemma(id int, lrat bool, result *ProofCheckResult) {
+	idx := d.finishClause()
+	result.Lemmas++
+	if lrat {
+		d.ids[id] = idx
+	}
+	if d.empty < 0 {
+		d.steps = append(d.steps, idx)
+		if d.start[idx] == d.start[idx+1] {
+			d.empty = idx
+		}
+	}
+}
+
+// deletion records the deletion of clause idx, or of nothing if idx < 0
+func (d *DRATChecker) deletion(idx int32, result *ProofCheckResult) {
+	result.Deletions++
+	if idx < 0 || len(d.clause(idx)) == 1 {
+		result.IgnoredDels++
+		return
+	}
+	if d.empty < 0 {
+		d.steps = append(d.steps, -idx-1)
+	}
+}
+
+// readText parses a textual DRAT or LRAT proof
+func (d *DRATChecker) readText(r io.Reader, lrat bool, result *ProofCheckResult) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(memory.MustPoolSlice[byte](satPool, 64*1024), 64*1024*1024)
+	scanner.Split(bufio.ScanWords)
+
+	tokens := 0
+	next := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		tokens++
+		return scanner.Text(), true
+	}
+	number := func(tok string) (int, error) {
+		n, err := strconv.Atoi(tok)
+		if err != nil {
+			return 0, d.errorf(tokens, fmt.Sprintf("invalid token %q", tok))
+		}
+		return n, nil
+	}
+	// readList reads numbers up to the terminating 0
+	readList := func(each func(n int)) error {
+		for {
+			tok, ok := next()
+			if !ok {
+				return d.errorf(tokens, "unterminated clause")
+			}
+			n, err := number(tok)
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return nil
+			}
+			each(n)
+		}
+	}
+
+	for {
+		tok, ok := next()
+		if !ok {
+			break
+		}
+		id := 0
+		if lrat {
+			n, err := number(tok)
+			if err != nil {
+				return err
+			}
+			id = n
+			if tok, ok = next(); !ok {
+				return d.errorf(tokens, "unterminated step")
+			}
+		}
+
+		if tok == "d" {
+			if lrat {
+				if err := readList(func(n int) { d.deletion(d.lookupID(n), result) }); err != nil {
+					return err
+				}
+				continue
+			}
+			first := int32(len(d.lits))
+			if err := readList(func(n int) { d.lits = append(d.lits, encodeDIMACS(n)) }); err != nil {
+				return err
+			}
+			idx := d.findClause(first)
+			d.lits = d.lits[:first]
+			d.deletion(idx, result)
+			continue
+		}
+
+		first := int32(len(d.lits))
+		n, err := number(tok)
+		if err != nil {
+			return err
+		}
+		if n != 0 {
+			d.lits = append(d.lits, encodeDIMACS(n))
+			if err := readList(func(n int) { d.lits = d.appendUnique(d.lits, first, encodeDIMACS(n)) }); err != nil {
+				return err
+			}
+		}
+		if lrat {
+			if err := readList(func(int) {}); err != nil {
+				return err
+			}
+		}
+		d.lemma(id, lrat, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return core.NewLogicError("sat", "DRATChecker.Check", err.Error())
+	}
+	return nil
+}
+
+// readBinary parses a binary DRAT or LRAT proof
+func (d *DRATChecker) readBinary(r *bufio.Reader, lrat bool, result *ProofCheckResult) error {
+	offset := 0
+	readNumber := func() (uint64, error) {
+		var x uint64
+		for shift := uint(0); ; shift += 7 {
+			b, err := r.ReadByte()
+			if err != nil {
+				return 0, d.errorf(offset, "truncated number")
+			}
+			offset++
+			x |= uint64(b&0x7f) << shift
+			if b&0x80 == 0 {
+				return x, nil
+			}
+			if shift > 56 {
+				return 0, d.errorf(offset, "number too large")
+			}
+		}
+	}
+	readList := func(each func(x uint64)) error {
+		for {
+			x, err := readNumber()
+			if err != nil {
+				return err
+			}
+			if x == 0 {
+				return nil
+			}
+			each(x)
+		}
+	}
+
+	for {
+		kind, err := r.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return core.NewLogicError("sat", "DRATChecker.Check", err.Error())
+		}
+		offset++
+
+		id := 0
+		switch kind {
+		case 'a':
+			if lrat {
+				x, err := readNumber()
+				if err != nil {
+					return err
+				}
+				id = int(x / 2)
+			}
+			first := int32(len(d.lits))
+			if err := readList(func(x uint64) { d.lits = d.appendUnique(d.lits, first, int32(x)) }); err != nil {
+				return err
+			}
+			if lrat {
+				if err := readList(func(uint64) {}); err != nil {
+					return err
+				}
+			}
+			d.lemma(id, lrat, result)
+		case 'd':
+			if lrat {
+				if err := readList(func(x uint64) { d.deletion(d.lookupID(int(x/2)), result) }); err != nil {
+					return err
+				}
+				continue
+			}
+			first := int32(len(d.lits))
+			if err := readList(func(x uint64) { d.lits = append(d.lits, int32(x)) }); err != nil {
+				return err
+			}
+			idx := d.findClause(first)
+			d.lits = d.lits[:first]
+			d.deletion(idx, result)
+		default:
+			return d.errorf(offset, fmt.Sprintf("unexpected byte 0x%02x", kind))
+		}
+	}
+}
+
+// lookupID returns the clause with the given LRAT ID, or -1
+func (d *DRATChecker) lookupID(id int) int32 {
+	if idx, ok := d.ids[id]; ok {
+		delete(d.ids, id)
+		return idx
+	}
+	return -1
+}
+
+// findClause returns the most recent active clause with the literals
+// stored at lits[first:], or -1. The match is consumed so that a repeated
+// deletion removes another copy.
+func (d *DRATChecker) findClause(first int32) int32 {
+	target := d.lits[first:]
+	sort.Slice(target, func(i, j int) bool { return target[i] < target[j] })
+	n := 0
+	for i, lit := range target {
+		if i == 0 || lit != target[n-1] {
+			target[n] = lit
+			n++
+		}
+	}
+	target = target[:n]
+
+	for i := d.numClauses() - 1; i >= 0; i-- {
+		if !d.active[i] || int(d.start[i+1]-d.start[i]) != len(target) {
+			continue
+		}
+		if d.sameLiterals(d.clause(i), target) {
+			d.active[i] = false
+			return i
+		}
+	}
+	return -1
+}
+
+// sameLiterals reports whether clause holds exactly the sorted literals
+func (d *DRATChecker) sameLiterals(clause, sorted []int32) bool {
+	for _, lit := range clause {
+		k := sort.Search(len(sorted), func(j int) bool { return sorted[j] >= lit })
+		if k == len(sorted) || sorted[k] != lit {
+			return false
+		}
+	}
+	return true
+}
+
+// buildWatches sets up watch lists for every stored clause. Watches are
+// never moved between active and inactive states, since checks always
+// start from an empty assignment.
+func (d *DRATChecker) buildWatches() {
+	maxLit := int32(2*d.vars.Len() + 2)
+	for _, lit := range d.lits {
+		if lit >= maxLit {
+			maxLit = lit | 1 + 1
+		}
+	}
+	d.watches = make([][]int32, maxLit)
+	d.values = memory.MustPoolSlice[int8](satPool, int(maxLit))[:maxLit]
+	d.reasons = memory.MustPoolSlice[int32](satPool, int(maxLit/2))[:maxLit/2]
+	d.trail = memory.MustPoolSlice[int32](satPool, int(maxLit/2))
+
+	for i := int32(0); i < d.numClauses(); i++ {
+		clause := d.clause(i)
+		if len(clause) >= 2 {
+			d.watches[clause[0]^1] = append(d.watches[clause[0]^1], i)
+			d.watches[clause[1]^1] = append(d.watches[clause[1]^1], i)
+		}
+		// Everything starts active and is replayed by verify
+		d.active[i] = true
+	}
+}
+
+// verify replays the proof forward to the empty clause and then checks
+// the marked lemmas backwards
+func (d *DRATChecker) verify(originals int32, result *ProofCheckResult) {
+	// Forward: clauses added after the empty clause never become active
+	for i := originals; i < d.numClauses(); i++ {
+		d.active[i] = false
+	}
+	for _, step := range d.steps {
+		if step >= 0 {
+			d.active[step] = true
+		} else {
+			d.active[-step-1] = false
+		}
+	}
+	d.collectUnits()
+
+	lemmaNumber := make(map[int32]int, len(d.steps))
+	n := 0
+	for _, step := range d.steps {
+		if step >= 0 {
+			n++
+			lemmaNumber[step] = n
+		}
+	}
+
+	d.core[d.empty] = true
+	result.Verified = true
+	for s := len(d.steps) - 1; s >= 0; s-- {
+		step := d.steps[s]
+		if step < 0 {
+			d.active[-step-1] = true
+			if len(d.clause(-step-1)) <= 1 {
+				d.units = append(d.units, -step-1)
+			}
+			continue
+		}
+
+		d.active[step] = false
+		if !d.core[step] {
+			continue
+		}
+		result.CoreLemmas++
+		if d.checkLemma(step, result) {
+			continue
+		}
+
+		result.Verified = false
+		result.FailedStep = lemmaNumber[step]
+		result.FailedLemma = make([]Literal, 0, len(d.clause(step)))
+		for _, lit := range d.clause(step) {
+			result.FailedLemma = append(result.FailedLemma, d.decode(lit))
+		}
+		if step == d.empty {
+			result.Reason = "formula is not refuted by the proof"
+		} else {
+			result.Reason = fmt.Sprintf("lemma %d is neither RUP nor RAT", result.FailedStep)
+		}
+	}
+}
+
+// collectUnits gathers the active unit clauses
+func (d *DRATChecker) collectUnits() {
+	d.units = d.units[:0]
+	for i := int32(0); i < d.numClauses(); i++ {
+		if d.active[i] && len(d.clause(i)) <= 1 {
+			d.units = append(d.units, i)
+		}
+	}
+}
+
+// checkLemma checks a lemma as RUP, falling back to RAT on its first
+// literal, and marks the clauses the successful check used as core
+func (d *DRATChecker) checkLemma(idx int32, result *ProofCheckResult) bool {
+	lemma := d.clause(idx)
+	pivot := d.pivots[idx]
+	if d.checkRUP(lemma, nil, pivot) {
+		return true
+	}
+	if pivot < 0 {
+		return false
+	}
+
+	result.RATChecks++
+	for i := int32(0); i < d.numClauses(); i++ {
+		if !d.active[i] || !d.contains(d.clause(i), pivot^1) {
+			continue
+		}
+		if d.tautologicalResolvent(lemma, d.clause(i), pivot) {
+			continue
+		}
+		if !d.checkRUP(lemma, d.clause(i), pivot) {
+			return false
+		}
+		d.core[i] = true
+	}
+	return true
+}
+
+// tautologicalResolvent reports whether the resolvent of lemma and other
+// on pivot contains a complementary pair
+func (d *DRATChecker) tautologicalResolvent(lemma, other []int32, pivot int32) bool {
+	for _, lit := range other {
+		if lit != pivot^1 && d.contains(lemma, lit^1) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether clause holds lit
+func (d *DRATChecker) contains(clause []int32, lit int32) bool {
+	for _, l := range clause {
+		if l == lit {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRUP assumes the negation of lemma and of extra, minus the
+// complement of pivot, and propagates over the active clauses. On
+// conflict the clauses involved are marked core.
+func (d *DRATChecker) checkRUP(lemma, extra []int32, pivot int32) bool {
+	defer d.undo()
+
+	for _, unit := range d.units {
+		if !d.active[unit] {
+			continue
+		}
+		if len(d.clause(unit)) == 0 {
+			d.core[unit] = true
+			return true
+		}
+		if conflict := d.assume(d.clause(unit)[0], unit); conflict >= 0 {
+			d.markCore(conflict)
+			return true
+		}
+	}
+	for _, lit := range lemma {
+		if conflict := d.assume(lit^1, -1); conflict >= 0 {
+			d.markCore(conflict)
+			return true
+		}
+	}
+	for _, lit := range extra {
+		if lit == pivot^1 {
+			continue
+		}
+		if conflict := d.assume(lit^1, -1); conflict >= 0 {
+			d.markCore(conflict)
+			return true
+		}
+	}
+
+	if conflict := d.propagate(); conflict >= 0 {
+		d.markCore(conflict)
+		return true
+	}
+	return false
+}
+
+// assume makes lit true with the given reason. If lit is already false it
+// returns the clause to start conflict analysis from: the reason of its
+// complement, or -2 when both sides are assumptions.
+func (d *DRATChecker) assume(lit int32, reason int32) int32 {
+	switch d.values[lit] {
+	case 1:
+		return -1
+	case -1:
+		if reason >= 0 {
+			d.core[reason] = true
+		}
+		if r := d.reasons[lit>>1]; r >= 0 {
+			return r
+		}
+		return -2
+	}
+	d.values[lit] = 1
+	d.values[lit^1] = -1
+	d.reasons[lit>>1] = reason
+	d.trail = append(d.trail, lit)
+	return -1
+}
+
+// propagate runs unit propagation over active clauses and returns a
+// conflicting clause or -1
+func (d *DRATChecker) propagate() int32 {
+	for head := 0; head < len(d.trail); head++ {
+		falseLit := d.trail[head] ^ 1
+		watchers := d.watches[d.trail[head]]
+		kept := 0
+		for w := 0; w < len(watchers); w++ {
+			ci := watchers[w]
+			if !d.active[ci] {
+				watchers[kept] = ci
+				kept++
+				continue
+			}
+			clause := d.clause(ci)
+			if clause[0] == falseLit {
+				clause[0], clause[1] = clause[1], clause[0]
+			}
+			if d.values[clause[0]] == 1 {
+				watchers[kept] = ci
+				kept++
+				continue
+			}
+
+			moved := false
+			for k := 2; k < len(clause); k++ {
+				if d.values[clause[k]] != -1 {
+					clause[1], clause[k] = clause[k], clause[1]
+					d.watches[clause[1]^1] = append(d.watches[clause[1]^1], ci)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+
+			watchers[kept] = ci
+			kept++
+			if d.values[clause[0]] == -1 {
+				copy(watchers[kept:], watchers[w+1:])
+				d.watches[d.trail[head]] = watchers[:kept+len(watchers)-w-1]
+				return ci
+			}
+			d.assume(clause[0], ci)
+		}
+		d.watches[d.trail[head]] = watchers[:kept]
+	}
+	return -1
+}
+
+// markCore marks the conflict clause and, transitively, the reasons of
+// its literals as core. -2 means a clash between assumptions.
+func (d *DRATChecker) markCore(conflict int32) {
+	if conflict < 0 {
+		return
+	}
+	seen := make(map[int32]bool)
+	stack := append(memory.MustPoolSlice[int32](satPool, 16), conflict)
+	for len(stack) > 0 {
+		ci := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		d.core[ci] = true
+		for _, lit := range d.clause(ci) {
+			v := lit >> 1
+			if seen[v] || d.values[lit] != -1 {
+				continue
+			}
+			seen[v] = true
+			if r := d.reasons[v]; r >= 0 && r != ci {
+				stack = append(stack, r)
+			}
+		}
+	}
+}
+
+// undo clears the assignment made by the last check
+func (d *DRATChecker) undo() {
+	for _, lit := range d.trail {
+		d.values[lit] = 0
+		d.values[lit^1] = 0
+		d.reasons[lit>>1] = -1
+	}
+	d.trail = d.trail[:0]
+}
+
+// errorf builds a LogicError at the given token or byte offset
+func (d *DRATChecker) errorf(pos int, msg string) error {
+	err := core.NewLogicError("sat", "DRATChecker.Check", fmt.Sprintf("proof position %d: %s", pos, msg))
+	err.Position = pos
+	return err
+}
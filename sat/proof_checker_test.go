@@ -0,0 +1,161 @@
+package sat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+func mustReadDIMACS(t *testing.T, input string) *CNF {
+	t.Helper()
+	cnf, err := ReadDIMACS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadDIMACS failed: %v", err)
+	}
+	return cnf
+}
+
+func TestCheckProof_SolverProofs(t *testing.T) {
+	formats := []ProofFormat{ProofDRAT, ProofBinaryDRAT, ProofLRAT, ProofBinaryLRAT}
+	for _, format := range formats {
+		t.Run(format.String(), func(t *testing.T) {
+			result, proof := solveWithProof(t, unsatDIMACS, format)
+			if result.Satisfiable {
+				t.Fatal("Expected UNSAT")
+			}
+			check, err := CheckProof(mustReadDIMACS(t, unsatDIMACS), strings.NewReader(proof), format)
+			if err != nil {
+				t.Fatalf("CheckProof failed: %v", err)
+			}
+			if !check.Verified {
+				t.Errorf("Expected proof to verify: %s (lemma %v)", check.Reason, check.FailedLemma)
+			}
+			if check.CoreLemmas == 0 || check.Lemmas < check.CoreLemmas {
+				t.Errorf("Unexpected lemma counts: %+v", check)
+			}
+		})
+	}
+}
+
+func TestCheckProof_FailingLemma(t *testing.T) {
+	cnf := mustReadDIMACS(t, "p cnf 2 3\n1 2 0\n-1 2 0\n1 -2 0\n")
+	check, err := CheckProof(cnf, strings.NewReader("-2 0\n0\n"), ProofDRAT)
+	if err != nil {
+		t.Fatalf("CheckProof failed: %v", err)
+	}
+	if check.Verified {
+		t.Fatal("Expected proof of a satisfiable formula to fail")
+	}
+	if check.FailedStep != 1 || len(check.FailedLemma) != 1 || !check.FailedLemma[0].Equals(L("v2", true)) {
+		t.Errorf("Expected lemma 1 (¬v2) to fail, got step %d %v", check.FailedStep, check.FailedLemma)
+	}
+	if check.RATChecks != 1 {
+		t.Errorf("Expected the failing lemma to be tried as RAT, got %d RAT checks", check.RATChecks)
+	}
+}
+
+func TestCheckProof_RAT(t *testing.T) {
+	// Extended resolution on the fresh variable 4: none of the first four
+	// lemmas is RUP, all are RAT, and all are needed
+	proof := "4 1 0\n4 -1 0\n-4 2 0\n-4 -2 0\n4 0\n0\n"
+	check, err := CheckProof(mustReadDIMACS(t, unsatDIMACS), strings.NewReader(proof), ProofDRAT)
+	if err != nil {
+		t.Fatalf("CheckProof failed: %v", err)
+	}
+	if !check.Verified {
+		t.Fatalf("Expected RAT proof to verify: %s (lemma %v)", check.Reason, check.FailedLemma)
+	}
+	if check.RATChecks != 4 || check.CoreLemmas != 6 {
+		t.Errorf("Expected 4 RAT checks over 6 core lemmas, got %+v", check)
+	}
+}
+
+func TestCheckProof_Deletions(t *testing.T) {
+	input := "p cnf 2 4\n1 2 0\n1 -2 0\n-1 2 0\n-1 -2 0\n"
+
+	check, err := CheckProof(mustReadDIMACS(t, input), strings.NewReader("1 0\n0\n"), ProofDRAT)
+	if err != nil || !check.Verified {
+		t.Fatalf("Expected proof to verify, got %+v, %v", check, err)
+	}
+
+	check, err = CheckProof(mustReadDIMACS(t, input), strings.NewReader("d 2 1 0\n1 0\n0\n"), ProofDRAT)
+	if err != nil {
+		t.Fatalf("CheckProof failed: %v", err)
+	}
+	if check.Verified || check.FailedStep != 1 {
+		t.Errorf("Expected lemma 1 to fail after deleting (v1 ∨ v2), got %+v", check)
+	}
+
+	// LRAT deletes by ID; unit deletions are ignored
+	lrat := "5 1 0 1 2 0\n5 d 1 5 0\n6 0 5 3 4 0\n"
+	check, err = CheckProof(mustReadDIMACS(t, input), strings.NewReader(lrat), ProofLRAT)
+	if err != nil || !check.Verified {
+		t.Fatalf("Expected LRAT proof to verify, got %+v, %v", check, err)
+	}
+	if check.Deletions != 2 || check.IgnoredDels != 1 {
+		t.Errorf("Expected 2 deletions with 1 ignored, got %+v", check)
+	}
+}
+
+func TestCheckProof_MissingEmptyClause(t *testing.T) {
+	// The last lemma makes the formula refutable by propagation alone
+	check, err := CheckProof(mustReadDIMACS(t, unsatDIMACS), strings.NewReader("1 2 0\n1 0\n2 0\n"), ProofDRAT)
+	if err != nil || !check.Verified {
+		t.Fatalf("Expected implicit refutation to verify, got %+v, %v", check, err)
+	}
+
+	check, err = CheckProof(mustReadDIMACS(t, unsatDIMACS), strings.NewReader("1 2 0\n"), ProofDRAT)
+	if err != nil {
+		t.Fatalf("CheckProof failed: %v", err)
+	}
+	if check.Verified || check.Reason == "" || check.FailedLemma == nil {
+		t.Errorf("Expected the final refutation to fail, got %+v", check)
+	}
+}
+
+func TestCheckProof_Errors(t *testing.T) {
+	cnf := mustReadDIMACS(t, unsatDIMACS)
+	tests := []struct {
+		proof  string
+		format ProofFormat
+	}{
+		{"1 x 0\n", ProofDRAT},
+		{"1 2\n", ProofDRAT},
+		{"9 1 0 1\n", ProofLRAT},
+		{"a\x02", ProofBinaryDRAT},
+		{"z\x00", ProofBinaryDRAT},
+	}
+	for _, tc := range tests {
+		_, err := NewDRATChecker(cnf).Check(strings.NewReader(tc.proof), tc.format)
+		var logicErr *core.LogicError
+		if !errors.As(err, &logicErr) {
+			t.Errorf("Proof %q: expected LogicError, got %v", tc.proof, err)
+		}
+	}
+}
+
+func TestCheckProof_PigeonholeProofs(t *testing.T) {
+	formats := []ProofFormat{ProofDRAT, ProofBinaryDRAT, ProofLRAT, ProofBinaryLRAT}
+	for _, size := range [][2]int{{4, 3}, {5, 4}, {6, 5}} {
+		input := pigeonholeDIMACS(size[0], size[1])
+		for _, format := range formats {
+			t.Run(fmt.Sprintf("%d pigeons %v", size[0], format), func(t *testing.T) {
+				result, proof := solveWithProof(t, input, format)
+				if result.Satisfiable {
+					t.Fatal("Expected UNSAT")
+				}
+				check, err := CheckProof(mustReadDIMACS(t, input), bytes.NewReader([]byte(proof)), format)
+				if err != nil {
+					t.Fatalf("CheckProof failed: %v", err)
+				}
+				if !check.Verified {
+					t.Errorf("%s (lemma %v)", check.Reason, check.FailedLemma)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package sat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// unsatDIMACS is every clause over three variables: UNSAT, and too large
+// to be refuted by propagation alone.
+const unsatDIMACS = `p cnf 3 8
+1 2 3 0
+1 2 -3 0
+1 -2 3 0
+1 -2 -3 0
+-1 2 3 0
+-1 2 -3 0
+-1 -2 3 0
+-1 -2 -3 0
+`
+
+// checkLRATText replays the hints of a textual LRAT proof against cnf. Each
+// hint must be unit under the negated lemma, and the last one falsified.
+// It returns whether the empty clause was derived.
+func checkLRATText(t *testing.T, cnf *CNF, proof string) bool {
+	t.Helper()
+	vars := NewVariableMapForCNF(cnf)
+	clauses := make(map[int][]int)
+	for _, clause := range cnf.Clauses {
+		var lits []int
+		for _, lit := range clause.Literals {
+			lits = append(lits, vars.ToDIMACS(lit))
+		}
+		clauses[clause.ID] = lits
+	}
+
+	refuted := false
+	for n, line := range strings.Split(strings.TrimSpace(proof), "\n") {
+		fields := strings.Fields(line)
+		nums := make([]int, 0, len(fields))
+		for _, f := range fields {
+			if f == "d" {
+				continue
+			}
+			x, err := strconv.Atoi(f)
+			if err != nil {
+				t.Fatalf("proof line %d: bad token %q", n+1, f)
+			}
+			nums = append(nums, x)
+		}
+		if len(fields) > 1 && fields[1] == "d" {
+			for _, id := range nums[1 : len(nums)-1] {
+				if _, ok := clauses[id]; !ok {
+					t.Errorf("proof line %d: deleting unknown clause %d", n+1, id)
+				}
+				delete(clauses, id)
+			}
+			continue
+		}
+
+		id, i := nums[0], 1
+		var lemma []int
+		for ; nums[i] != 0; i++ {
+			lemma = append(lemma, nums[i])
+		}
+		assigned := make(map[int]bool)
+		for _, lit := range lemma {
+			assigned[-lit] = true
+		}
+		conflict := false
+		for i++; nums[i] != 0 && !conflict; i++ {
+			hint, ok := clauses[nums[i]]
+			if !ok {
+				t.Fatalf("proof line %d: unknown hint %d", n+1, nums[i])
+			}
+			open := 0
+			var unit int
+			for _, lit := range hint {
+				if assigned[lit] {
+					t.Fatalf("proof line %d: hint %d is satisfied", n+1, nums[i])
+				}
+				if !assigned[-lit] {
+					open++
+					unit = lit
+				}
+			}
+			switch open {
+			case 0:
+				conflict = true
+			case 1:
+				assigned[unit] = true
+			default:
+				t.Fatalf("proof line %d: hint %d is not unit", n+1, nums[i])
+			}
+		}
+		if !conflict {
+			t.Fatalf("proof line %d: hints do not refute lemma %v", n+1, lemma)
+		}
+		if _, ok := clauses[id]; ok {
+			t.Fatalf("proof line %d: clause ID %d reused", n+1, id)
+		}
+		clauses[id] = lemma
+		if len(lemma) == 0 {
+			refuted = true
+		}
+	}
+	return refuted
+}
+
+func solveWithProof(t *testing.T, input string, format ProofFormat) (*SolverResult, string) {
+	t.Helper()
+	cnf, err := ReadDIMACS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadDIMACS failed: %v", err)
+	}
+	var proof bytes.Buffer
+	solver := NewCDCLSolver()
+	solver.SetProofOutput(&proof, format)
+	result := solver.Solve(cnf)
+	if result.Error != nil {
+		t.Fatalf("Solve failed: %v", result.Error)
+	}
+	return result, proof.String()
+}
+
+func TestProofDRAT_Text(t *testing.T) {
+	result, proof := solveWithProof(t, unsatDIMACS, ProofDRAT)
+	if result.Satisfiable {
+		t.Fatal("Expected UNSAT")
+	}
+	lines := strings.Split(strings.TrimSpace(proof), "\n")
+	if lines[len(lines)-1] != "0" {
+		t.Errorf("Expected proof to end with the empty clause, got %q", lines[len(lines)-1])
+	}
+	for _, line := range lines {
+		if !strings.HasSuffix(line, "0") || strings.Contains(line, " 0 ") {
+			t.Errorf("Malformed DRAT line %q", line)
+		}
+	}
+}
+
+func TestProofDRAT_Binary(t *testing.T) {
+	result, proof := solveWithProof(t, unsatDIMACS, ProofBinaryDRAT)
+	if result.Satisfiable {
+		t.Fatal("Expected UNSAT")
+	}
+	if len(proof) == 0 || proof[0] != 'a' {
+		t.Fatalf("Expected binary proof to start with 'a', got %q", proof)
+	}
+	if !strings.HasSuffix(proof, "a\x00") {
+		t.Errorf("Expected binary proof to end with the empty clause, got %q", proof)
+	}
+}
+
+func TestProofLRAT_Text(t *testing.T) {
+	result, proof := solveWithProof(t, unsatDIMACS, ProofLRAT)
+	if result.Satisfiable {
+		t.Fatal("Expected UNSAT")
+	}
+	cnf, _ := ReadDIMACS(strings.NewReader(unsatDIMACS))
+	if !checkLRATText(t, cnf, proof) {
+		t.Errorf("LRAT proof does not derive the empty clause:\n%s", proof)
+	}
+	if !strings.HasPrefix(proof, "9 ") {
+		t.Errorf("Expected first lemma ID to follow the 8 input clauses, got %q", proof)
+	}
+}
+
+func TestProofLRAT_Binary(t *testing.T) {
+	result, proof := solveWithProof(t, unsatDIMACS, ProofBinaryLRAT)
+	if result.Satisfiable {
+		t.Fatal("Expected UNSAT")
+	}
+	// First lemma: 'a', ID 9 encoded as 2*9
+	if len(proof) < 2 || proof[0] != 'a' || proof[1] != 18 {
+		t.Errorf("Unexpected binary LRAT prefix %q", proof)
+	}
+}
+
+func TestProofSatisfiableHasNoEmptyClause(t *testing.T) {
+	result, proof := solveWithProof(t, "p cnf 2 2\n1 2 0\n-1 2 0\n", ProofDRAT)
+	if !result.Satisfiable {
+		t.Fatal("Expected SAT")
+	}
+	for _, line := range strings.Split(proof, "\n") {
+		if line == "0" {
+			t.Error("SAT run must not derive the empty clause")
+		}
+	}
+}
+
+func TestProofRootLevelConflict(t *testing.T) {
+	input := "p cnf 2 3\n1 0\n-1 2 0\n-2 0\n"
+	result, proof := solveWithProof(t, input, ProofLRAT)
+	if result.Satisfiable {
+		t.Fatal("Expected UNSAT")
+	}
+	cnf, _ := ReadDIMACS(strings.NewReader(input))
+	if !checkLRATText(t, cnf, proof) {
+		t.Errorf("LRAT proof does not derive the empty clause:\n%s", proof)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestProofWriteErrorIsReported(t *testing.T) {
+	cnf, _ := ReadDIMACS(strings.NewReader(unsatDIMACS))
+	solver := NewCDCLSolver()
+	solver.SetProofOutput(failingWriter{}, ProofDRAT)
+	result := solver.Solve(cnf)
+	if result.Error == nil {
+		t.Error("Expected proof write error to be reported")
+	}
+
+	// Disabling the proof makes the solver usable again
+	solver.SetProofOutput(nil, ProofDRAT)
+	cnf, _ = ReadDIMACS(strings.NewReader(unsatDIMACS))
+	if result := solver.Solve(cnf); result.Error != nil {
+		t.Errorf("Unexpected error without proof output: %v", result.Error)
+	}
+}
+
+func TestProofWriterNil(t *testing.T) {
+	var p *ProofWriter
+	p.AddClause(1, []Literal{L("A", false)}, nil)
+	p.DeleteClause(1, []Literal{L("A", false)})
+	p.Strengthen(NewClause(L("A", false)), nil, nil)
+	if p.NextID() != 0 {
+		t.Error("Expected nil writer to hand out ID 0")
+	}
+	if err := p.Flush(); err != nil {
+		t.Errorf("Expected nil writer to flush cleanly, got %v", err)
+	}
+}
+
+func TestProofFormatString(t *testing.T) {
+	formats := map[ProofFormat]string{
+		ProofDRAT:       "DRAT",
+		ProofBinaryDRAT: "binary DRAT",
+		ProofLRAT:       "LRAT",
+		ProofBinaryLRAT: "binary LRAT",
+	}
+	for format, name := range formats {
+		if format.String() != name {
+			t.Errorf("Expected %q, got %q", name, format.String())
+		}
+	}
+	if !ProofBinaryLRAT.IsLRAT() || !ProofBinaryLRAT.IsBinary() || ProofDRAT.IsBinary() {
+		t.Error("Unexpected format predicates")
+	}
+}
+
+// pigeonholeDIMACS returns pigeonhole(pigeons, holes) in DIMACS text
+func pigeonholeDIMACS(pigeons, holes int) string {
+	variable := func(p, h int) int { return p*holes + h + 1 }
+	var clauses []string
+	for p := 0; p < pigeons; p++ {
+		var clause []string
+		for h := 0; h < holes; h++ {
+			clause = append(clause, strconv.Itoa(variable(p, h)))
+		}
+		clauses = append(clauses, strings.Join(clause, " ")+" 0")
+	}
+	for h := 0; h < holes; h++ {
+		for p1 := 0; p1 < pigeons; p1++ {
+			for p2 := p1 + 1; p2 < pigeons; p2++ {
+				clauses = append(clauses, fmt.Sprintf("%d %d 0", -variable(p1, h), -variable(p2, h)))
+			}
+		}
+	}
+	return fmt.Sprintf("p cnf %d %d\n%s\n", pigeons*holes, len(clauses), strings.Join(clauses, "\n"))
+}
+
+func TestProofLRAT_WithInprocessing(t *testing.T) {
+	testCases := []struct {
+		description string
+		input       string
+	}{
+		{"every clause over three variables", unsatDIMACS},
+		// 1, 2, 3 and 4 are equivalent, so the last eight clauses are
+		// every clause over 1, 5 and 6
+		{"equivalent variables hide every clause", `p cnf 6 12
+-1 2 0
+-2 3 0
+-3 4 0
+-4 1 0
+1 5 6 0
+2 5 -6 0
+3 -5 6 0
+4 -5 -6 0
+-1 5 6 0
+-2 5 -6 0
+-3 -5 6 0
+-4 -5 -6 0
+`},
+		{"four pigeons in three holes", pigeonholeDIMACS(4, 3)},
+		{"five pigeons in four holes", pigeonholeDIMACS(5, 4)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var proof bytes.Buffer
+			solver := NewCDCLSolver()
+			solver.inprocessConfig.EnableInitialInprocess = true
+			solver.inprocessGap = 10
+			solver.SetProofOutput(&proof, ProofLRAT)
+			result := solver.Solve(mustReadDIMACS(t, tc.input))
+			if result.Error != nil || result.Satisfiable {
+				t.Fatalf("Expected UNSAT, got %v (%v)", result.Status, result.Error)
+			}
+			if !checkLRATText(t, mustReadDIMACS(t, tc.input), proof.String()) {
+				t.Error("Proof does not derive the empty clause")
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package sat
+
+import (
+	"fmt"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+// ReconstructionStack records the clauses that satisfiability-preserving
+// simplifications remove, so that a model of the simplified formula can be
+// extended to a model of the original one. Every entry is a removed clause
+// with a witness literal in it. Extend replays the entries newest first and
+// makes the witness of every clause the model falsifies true, as the
+// extension stack of Kissat's extend.c does.
+//
+// What to push:
+//   - variable elimination: every irredundant clause of the variable,
+//     witnessed by its literal of the variable
+//   - blocked clause elimination: the clause, witnessed by the blocking
+//     literal
+//   - pure literals and root units: the removed clauses, witnessed by the
+//     pure or unit literal
+type ReconstructionStack struct {
+	entries []reconstructionEntry
+	names   map[string]bool // Input variables and those of removed clauses
+}
+
+// reconstructionEntry is one removed clause. Literals are copied onto the
+// heap, since the removed clause may be freed.
+type reconstructionEntry struct {
+	witness Literal
+	clause  []Literal
+}
+
+// NewReconstructionStack creates an empty stack
+func NewReconstructionStack() *ReconstructionStack {
+	return &ReconstructionStack{}
+}
+
+// Push records that clause was removed and that making witness true
+// satisfies it. A nil stack ignores the call.
+func (r *ReconstructionStack) Push(witness Literal, clause []Literal) {
+	if r == nil {
+		return
+	}
+	r.entries = append(r.entries, reconstructionEntry{
+		witness: witness,
+		clause:  append([]Literal(nil), clause...),
+	})
+	for _, lit := range clause {
+		r.reserve(lit.Variable)
+	}
+}
+
+// reserve marks variables as belonging to the original formula
+func (r *ReconstructionStack) reserve(variables ...string) {
+	if r.names == nil {
+		r.names = make(map[string]bool, len(variables))
+	}
+	for _, variable := range variables {
+		r.names[variable] = true
+	}
+}
+
+// taken reports whether variable is reserved, so a simplifier must not
+// introduce it as a fresh variable. A nil stack reserves nothing.
+func (r *ReconstructionStack) taken(variable string) bool {
+	return r != nil && r.names[variable]
+}
+
+// Extend turns model, a model of the simplified formula, into a model of
+// the original one in place and returns it. Variables of removed clauses
+// that model leaves unassigned start out false.
+func (r *ReconstructionStack) Extend(model Assignment) Assignment {
+	if r == nil {
+		return model
+	}
+	for _, entry := range r.entries {
+		for _, lit := range entry.clause {
+			if _, ok := model[lit.Variable]; !ok {
+				model[lit.Variable] = false
+			}
+		}
+	}
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		entry := r.entries[i]
+		satisfied := false
+		for _, lit := range entry.clause {
+			if model[lit.Variable] != lit.Negated {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			model[entry.witness.Variable] = !entry.witness.Negated
+		}
+	}
+	return model
+}
+
+// Len returns the number of recorded clauses
+func (r *ReconstructionStack) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.entries)
+}
+
+// Reset drops every recorded clause
+func (r *ReconstructionStack) Reset() {
+	if r != nil {
+		r.entries = r.entries[:0]
+		clear(r.names)
+	}
+}
+
+// reconstructing is implemented by simplifiers that record the clauses
+// they remove
+type reconstructing interface {
+	SetReconstructionStack(r *ReconstructionStack)
+}
+
+// CheckModel verifies that model assigns every variable of cnf and makes
+// a literal of every clause true. Unlike Assignment.Satisfies, unassigned
+// literals do not count.
+func CheckModel(cnf *CNF, model Assignment) error {
+	for _, variable := range cnf.Variables {
+		if _, ok := model[variable]; !ok {
+			return core.NewLogicError("sat", "CheckModel", fmt.Sprintf("variable %s is unassigned", variable))
+		}
+	}
+	for _, clause := range cnf.Clauses {
+		if clause == nil || clause.Deleted || clause.Learned {
+			continue
+		}
+		if !modelSatisfies(model, clause.Literals) {
+			return core.NewLogicError("sat", "CheckModel", fmt.Sprintf("clause %d %s is falsified", clause.ID, clause))
+		}
+	}
+	return nil
+}
+
+// modelSatisfies reports whether model makes one of lits true
+func modelSatisfies(model Assignment, lits []Literal) bool {
+	for _, lit := range lits {
+		if value, ok := model[lit.Variable]; ok && value != lit.Negated {
+			return true
+		}
+	}
+	return false
+}
+
+// SetModelCheck turns a debug mode on or off. In it every model a solve
+// returns is checked against the formula as it was passed in, before
+// inprocessing rewrote it, and a model that falsifies a clause is reported
+// as an error instead. Each solve then keeps a copy of the input clauses.
+func (c *CDCLSolver) SetModelCheck(enabled bool) {
+	c.modelCheck = enabled
+}
+
+// beginReconstruction starts recording eliminated clauses for a solve of
+// cnf, and copies what the model check needs. A fresh Solve starts an
+// empty stack; SolveAssuming keeps what earlier calls recorded, since the
+// formula it solves still lacks those clauses.
+func (c *CDCLSolver) beginReconstruction(cnf *CNF, fresh bool) {
+	if c.reconstruction == nil {
+		c.reconstruction = NewReconstructionStack()
+	}
+	if fresh {
+		c.reconstruction.Reset()
+		c.variables = c.variables[:0]
+	}
+	if r, ok := c.inprocessor.(reconstructing); ok {
+		r.SetReconstructionStack(c.reconstruction)
+	}
+	known := make(map[string]bool, len(c.variables))
+	for _, variable := range c.variables {
+		known[variable] = true
+	}
+	for _, variable := range cnf.Variables {
+		if !known[variable] {
+			known[variable] = true
+			c.variables = append(c.variables, variable)
+		}
+	}
+	c.reconstruction.reserve(c.variables...)
+	c.original = c.original[:0]
+	if !c.modelCheck {
+		return
+	}
+	for _, clause := range cnf.Clauses {
+		if clause != nil && !clause.Deleted && !clause.Learned {
+			c.original = append(c.original, append([]Literal(nil), clause.Literals...))
+		}
+	}
+}
+
+// finishModel extends a satisfying assignment to every variable of the
+// input formula, eliminated ones included, and checks it in model-check
+// mode
+func (c *CDCLSolver) finishModel(result *SolverResult) {
+	if result == nil || !result.Satisfiable || result.Error != nil {
+		return
+	}
+	model := result.Assignment
+	if model == nil {
+		model = make(Assignment, len(c.variables))
+	}
+	for _, variable := range c.variables {
+		if _, ok := model[variable]; !ok {
+			model[variable] = false
+		}
+	}
+	result.Assignment = c.reconstruction.Extend(model)
+	if !c.modelCheck {
+		return
+	}
+	for _, clause := range c.original {
+		if !modelSatisfies(model, clause) {
+			result.Satisfiable = false
+			result.Assignment = nil
+			result.Reason = ReasonError
+			result.Error = core.NewLogicError("sat", "CDCLSolver.Solve",
+				fmt.Sprintf("model check failed: clause %v is falsified", clause))
+			return
+		}
+	}
+}
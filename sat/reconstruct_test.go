@@ -0,0 +1,252 @@
+package sat
+
+import (
+	"testing"
+)
+
+// copyClauses returns an independent copy of cnf, since simplification
+// rewrites and frees the clauses it is given
+func copyClauses(cnf *CNF) *CNF {
+	out := NewCNF()
+	for _, clause := range cnf.Clauses {
+		out.AddClause(NewClause(clause.Literals...))
+	}
+	return out
+}
+
+func TestReconstructionStack_Extend(t *testing.T) {
+	r := NewReconstructionStack()
+	// x eliminated from (x ∨ a) ∧ (¬x ∨ b), leaving the resolvent (a ∨ b)
+	r.Push(L("x", false), []Literal{L("x", false), L("a", false)})
+	r.Push(L("x", true), []Literal{L("x", true), L("b", false)})
+	for _, model := range []Assignment{{"a": true, "b": false}, {"a": false, "b": true}, {"a": true, "b": true}} {
+		original := model.Clone()
+		r.Extend(model)
+		if _, ok := model["x"]; !ok {
+			t.Fatalf("x was not assigned for %v", original)
+		}
+		if !modelSatisfies(model, []Literal{L("x", false), L("a", false)}) || !modelSatisfies(model, []Literal{L("x", true), L("b", false)}) {
+			t.Errorf("Extended %v to %v, which falsifies a removed clause", original, model)
+		}
+	}
+	if r.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", r.Len())
+	}
+	r.Reset()
+	if r.Len() != 0 {
+		t.Error("Reset kept entries")
+	}
+
+	var none *ReconstructionStack
+	none.Push(L("x", false), nil)
+	if model := none.Extend(Assignment{"a": true}); len(model) != 1 {
+		t.Errorf("A nil stack changed the model: %v", model)
+	}
+}
+
+// eliminationCases are small formulas with variables that bounded
+// variable elimination, pure literals and equivalence reasoning remove
+var eliminationCases = []struct {
+	description string
+	clauses     [][]Literal
+	expectedSat bool
+}{
+	{"resolvent of two clauses", [][]Literal{
+		{L("X", false), L("A", false)},
+		{L("X", true), L("B", false)},
+		{L("A", true), L("B", true)},
+	}, true},
+	{"implication chain", [][]Literal{
+		{L("A", false), L("B", false)},
+		{L("B", true), L("C", false)},
+		{L("C", true), L("D", false)},
+		{L("D", true), L("E", true)},
+		{L("A", true), L("E", false)},
+	}, true},
+	{"equivalent variables", [][]Literal{
+		{L("A", false), L("B", true)},
+		{L("A", true), L("B", false)},
+		{L("B", false), L("C", true)},
+		{L("B", true), L("C", false)},
+		{L("A", false), L("D", false), L("E", false)},
+		{L("C", true), L("D", true), L("E", true)},
+		{L("D", false), L("F", false)},
+	}, true},
+	{"pure literals", [][]Literal{
+		{L("A", false), L("B", false), L("C", true)},
+		{L("A", false), L("C", false)},
+		{L("B", true), L("C", false), L("D", false)},
+	}, true},
+	{"refuted by resolution", [][]Literal{
+		{L("A", false), L("B", false)},
+		{L("A", false), L("B", true)},
+		{L("A", true), L("C", false)},
+		{L("A", true), L("C", true)},
+	}, false},
+	{"refuted by units", [][]Literal{
+		{L("A", false)},
+		{L("A", true), L("B", false)},
+		{L("B", true), L("C", false)},
+		{L("C", true)},
+	}, false},
+}
+
+func TestBoundedVariableElimination_Reconstruction(t *testing.T) {
+	eliminated := 0
+	for _, tc := range eliminationCases {
+		t.Run(tc.description, func(t *testing.T) {
+			original := buildCNF(tc.clauses)
+			reduced := buildCNF(tc.clauses)
+			bve := NewBoundedVariableElimination()
+			stack := NewReconstructionStack()
+			bve.SetReconstructionStack(stack)
+			eliminated += bve.EliminateVariables(reduced, make(Assignment))
+
+			result := NewDenseSolver().Solve(reduced)
+			if result.Satisfiable != tc.expectedSat {
+				t.Fatalf("Expected satisfiable=%v after elimination, got %v", tc.expectedSat, result.Status)
+			}
+			if result.Satisfiable {
+				if err := CheckModel(original, stack.Extend(result.Assignment)); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+	if eliminated == 0 {
+		t.Error("Expected some variables to be eliminated")
+	}
+}
+
+func TestSATPreprocessor_PostProcess(t *testing.T) {
+	for _, tc := range eliminationCases {
+		t.Run(tc.description, func(t *testing.T) {
+			p := NewSATPreprocessor()
+			processed, err := p.Preprocess(buildCNF(tc.clauses))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := NewCDCLSolver().Solve(processed)
+			if result.Satisfiable != tc.expectedSat {
+				t.Fatalf("Expected satisfiable=%v after preprocessing, got %v", tc.expectedSat, result.Status)
+			}
+			if result.Satisfiable {
+				if err := CheckModel(buildCNF(tc.clauses), p.PostProcess(result.Assignment)); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}
+
+func TestSATPreprocessor_MixedPolarity(t *testing.T) {
+	// A occurs positively twice and negatively once, so it is not pure
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	cnf.AddClause(NewClause(L("A", false), L("C", false)))
+	cnf.AddClause(NewClause(L("A", true), L("D", false)))
+	cnf.AddClause(NewClause(L("D", true), L("B", true)))
+	p := NewSATPreprocessor()
+	processed, _ := p.Preprocess(copyClauses(cnf))
+	result := NewCDCLSolver().Solve(processed)
+	if !result.Satisfiable {
+		t.Fatalf("Expected SAT, got %v", result.Status)
+	}
+	if err := CheckModel(cnf, p.PostProcess(result.Assignment)); err != nil {
+		t.Error(err)
+	}
+
+	// Contradicting units leave the empty clause, not an empty formula
+	cnf = NewCNF()
+	cnf.AddClause(NewClause(L("A", false)))
+	cnf.AddClause(NewClause(L("A", true), L("B", false)))
+	cnf.AddClause(NewClause(L("B", true)))
+	processed, _ = p.Preprocess(cnf)
+	if result := NewCDCLSolver().Solve(processed); result.Status != StatusUNSAT {
+		t.Errorf("Expected UNSAT, got %v (%v)", result.Status, result.Error)
+	}
+}
+
+func TestCDCLSolver_ModelAfterElimination(t *testing.T) {
+	config := DefaultInprocessConfig()
+	config.EnableInitialInprocess = true
+	config.EnableEquivalentLiterals = true
+	recorded := 0
+	for _, tc := range eliminationCases {
+		t.Run(tc.description, func(t *testing.T) {
+			c := NewCDCLSolver()
+			c.inprocessConfig = config
+			c.inprocessor = NewModernInprocessorWithConfig(config)
+			c.walkSolver = nil
+			c.SetModelCheck(true)
+			result := c.Solve(buildCNF(tc.clauses))
+			if result.Error != nil {
+				t.Fatalf("Solver error: %v", result.Error)
+			}
+			if result.Satisfiable != tc.expectedSat {
+				t.Fatalf("Expected satisfiable=%v, got %v", tc.expectedSat, result.Status)
+			}
+			if result.Satisfiable {
+				if err := CheckModel(buildCNF(tc.clauses), result.Assignment); err != nil {
+					t.Error(err)
+				}
+			}
+			recorded += c.reconstruction.Len()
+		})
+	}
+	if recorded == 0 {
+		t.Error("Expected inprocessing to eliminate variables")
+	}
+}
+
+func TestCDCLSolver_ModelCheck(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	c := NewCDCLSolver()
+	c.SetModelCheck(true)
+	if result := c.Solve(cnf); result.Status != StatusSAT {
+		t.Fatalf("Expected SAT, got %v (%v)", result.Status, result.Error)
+	}
+
+	// A bogus recorded clause makes the extended model falsify the input
+	c.beginReconstruction(cnf, true)
+	c.original = append(c.original, []Literal{L("C", false)})
+	result := &SolverResult{Satisfiable: true, Assignment: Assignment{"A": true, "B": true}}
+	c.finishModel(result)
+	if result.Error == nil || result.Satisfiable {
+		t.Errorf("Expected the model check to fail, got %+v", result)
+	}
+}
+
+func TestCDCLSolver_SolveAssumingKeepsReconstruction(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	c := NewCDCLSolver()
+	if result := c.Solve(cnf); result.Status != StatusSAT {
+		t.Fatalf("Expected SAT, got %v (%v)", result.Status, result.Error)
+	}
+
+	// Record E ∨ ¬A as if the solve had eliminated E from the formula
+	c.reconstruction.Push(L("E", false), []Literal{L("E", false), L("A", true)})
+	result := c.SolveAssuming([]Literal{L("A", false)})
+	if result.Status != StatusSAT {
+		t.Fatalf("Expected SAT, got %v (%v)", result.Status, result.Error)
+	}
+	if !result.Assignment["A"] || !result.Assignment["E"] {
+		t.Errorf("Expected A and E true, got %v", result.Assignment)
+	}
+}
+
+func TestCheckModel(t *testing.T) {
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", true)))
+	if err := CheckModel(cnf, Assignment{"A": false, "B": false}); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if err := CheckModel(cnf, Assignment{"A": false}); err == nil {
+		t.Error("Expected an error for an unassigned variable")
+	}
+	if err := CheckModel(cnf, Assignment{"A": false, "B": true}); err == nil {
+		t.Error("Expected an error for a falsified clause")
+	}
+}
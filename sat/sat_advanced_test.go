@@ -376,11 +376,21 @@ func createSimpleSATInstanceAdvanced() *CNF {
 func createPigeonHolePrincipleAdvanced(pigeons, holes int) *CNF {
 	cnf := NewCNF()
 
+	// Clauses keep their literals in pool memory the collector does not
+	// scan, so each name is made once and stays reachable from cnf.Variables
+	names := make([][]string, pigeons+1)
+	for p := 1; p <= pigeons; p++ {
+		names[p] = make([]string, holes+1)
+		for h := 1; h <= holes; h++ {
+			names[p][h] = fmt.Sprintf("p%dh%d", p, h)
+		}
+	}
+
 	for p := 1; p <= pigeons; p++ {
 		clause := memory.MustPoolSlice[Literal](satPool, holes)[:holes]
 		for h := 1; h <= holes; h++ {
 			clause[h-1] = Literal{
-				Variable: fmt.Sprintf("p%dh%d", p, h),
+				Variable: names[p][h],
 				Negated:  false,
 			}
 		}
@@ -391,8 +401,8 @@ func createPigeonHolePrincipleAdvanced(pigeons, holes int) *CNF {
 		for p1 := 1; p1 <= pigeons; p1++ {
 			for p2 := p1 + 1; p2 <= pigeons; p2++ {
 				cnf.AddClause(NewClause(
-					Literal{Variable: fmt.Sprintf("p%dh%d", p1, h), Negated: true},
-					Literal{Variable: fmt.Sprintf("p%dh%d", p2, h), Negated: true},
+					Literal{Variable: names[p1][h], Negated: true},
+					Literal{Variable: names[p2][h], Negated: true},
 				))
 			}
 		}
@@ -404,12 +414,18 @@ func createPigeonHolePrincipleAdvanced(pigeons, holes int) *CNF {
 func createRandom3SATAdvanced(variables, clauses int) *CNF {
 	cnf := NewCNF()
 
+	// One string per variable, as in createPigeonHolePrincipleAdvanced
+	names := make([]string, variables+1)
+	for v := 1; v <= variables; v++ {
+		names[v] = fmt.Sprintf("x%d", v)
+	}
+
 	for i := 0; i < clauses; i++ {
 		literals := memory.MustPoolSlice[Literal](satPool, 3)[:3]
 		for j := 0; j < 3; j++ {
 			varNum := (i*3+j)%variables + 1
 			literals[j] = Literal{
-				Variable: fmt.Sprintf("x%d", varNum),
+				Variable: names[varNum],
 				Negated:  (i+j)%2 == 0,
 			}
 		}
@@ -1,30 +1,54 @@
 package sat
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
 	"github.com/xDarkicex/logic/fuzzy"
 )
 
-// helper: create a solver, solve a CNF, verify satisfiability
+// helper: create a solver, solve a CNF, verify satisfiability. UNSAT
+// answers are cross-checked against the solver's DRAT proof.
 func solve(t *testing.T, clauses [][]Literal, expectSAT bool) *SolverResult {
 	t.Helper()
-	cnf := NewCNF()
-	for _, lits := range clauses {
-		cnf.AddClause(NewClause(lits...))
-	}
+	var proof bytes.Buffer
 	solver := NewCDCLSolver()
-	result := solver.Solve(cnf)
+	solver.SetProofOutput(&proof, ProofDRAT)
+	result := solver.Solve(buildCNF(clauses))
 	if result.Error != nil {
 		t.Fatalf("solve error: %v", result.Error)
 	}
 	if result.Satisfiable != expectSAT {
 		t.Errorf("Satisfiable = %v, want %v", result.Satisfiable, expectSAT)
 	}
+	if !result.Satisfiable {
+		verifyUnsat(t, buildCNF(clauses), &proof)
+	}
 	return result
 }
 
+// helper: build a fresh CNF; the solver rewrites the one it is given
+func buildCNF(clauses [][]Literal) *CNF {
+	cnf := NewCNF()
+	for _, lits := range clauses {
+		cnf.AddClause(NewClause(lits...))
+	}
+	return cnf
+}
+
+// helper: check that proof refutes cnf
+func verifyUnsat(t *testing.T, cnf *CNF, proof *bytes.Buffer) {
+	t.Helper()
+	check, err := CheckProof(cnf, proof, ProofDRAT)
+	if err != nil {
+		t.Fatalf("proof check error: %v", err)
+	}
+	if !check.Verified {
+		t.Errorf("UNSAT proof rejected: %s (lemma %v)", check.Reason, check.FailedLemma)
+	}
+}
+
 func TestIntegrationTrivialSAT(t *testing.T) {
 	result := solve(t, [][]Literal{
 		{{Variable: "A", Negated: false}},
@@ -63,25 +87,17 @@ func TestIntegrationPigeonhole(t *testing.T) {
 	// Pigeonhole: 3 pigeons, 2 holes — UNSAT
 	// Each pigeon in at least one hole: (p1_h1 ∨ p1_h2), (p2_h1 ∨ p2_h2), (p3_h1 ∨ p3_h2)
 	// No two pigeons share a hole: (¬p1_h1 ∨ ¬p2_h1), (¬p1_h2 ∨ ¬p2_h2), (¬p1_h1 ∨ ¬p3_h1), etc.
-	cnf := NewCNF()
-	cnf.AddClause(NewClause(L("P1_H1", false), L("P1_H2", false)))
-	cnf.AddClause(NewClause(L("P2_H1", false), L("P2_H2", false)))
-	cnf.AddClause(NewClause(L("P3_H1", false), L("P3_H2", false)))
-	cnf.AddClause(NewClause(L("P1_H1", true), L("P2_H1", true)))
-	cnf.AddClause(NewClause(L("P1_H2", true), L("P2_H2", true)))
-	cnf.AddClause(NewClause(L("P1_H1", true), L("P3_H1", true)))
-	cnf.AddClause(NewClause(L("P1_H2", true), L("P3_H2", true)))
-	cnf.AddClause(NewClause(L("P2_H1", true), L("P3_H1", true)))
-	cnf.AddClause(NewClause(L("P2_H2", true), L("P3_H2", true)))
-
-	solver := NewCDCLSolver()
-	result := solver.Solve(cnf)
-	if result.Error != nil {
-		t.Fatalf("solve error: %v", result.Error)
-	}
-	if result.Satisfiable {
-		t.Error("pigeonhole(3,2) should be UNSAT")
-	}
+	solve(t, [][]Literal{
+		{L("P1_H1", false), L("P1_H2", false)},
+		{L("P2_H1", false), L("P2_H2", false)},
+		{L("P3_H1", false), L("P3_H2", false)},
+		{L("P1_H1", true), L("P2_H1", true)},
+		{L("P1_H2", true), L("P2_H2", true)},
+		{L("P1_H1", true), L("P3_H1", true)},
+		{L("P1_H2", true), L("P3_H2", true)},
+		{L("P2_H1", true), L("P3_H1", true)},
+		{L("P2_H2", true), L("P3_H2", true)},
+	}, false)
 }
 
 func TestIntegrationRestartAndLearn(t *testing.T) {
@@ -544,12 +560,24 @@ func TestIntegrationGaussianEliminator(t *testing.T) {
 	}
 }
 
+// varNames holds every two-letter name varName returns. Clauses keep their
+// literals in pool memory the collector does not scan, so a name built
+// afresh on each call could be freed while a clause still refers to it.
+var varNames = func() []string {
+	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	names := make([]string, 26*26)
+	for i := range names {
+		names[i] = string(letters[i%26]) + string(letters[i/26])
+	}
+	return names
+}()
+
 func varName(i int) string {
 	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	if i < 26 {
 		return string(letters[i])
 	}
-	return string(letters[i%26]) + string(letters[(i/26)%26])
+	return varNames[i%len(varNames)]
 }
 
 func L(variable string, negated bool) Literal {
@@ -61,7 +61,6 @@ func TestProbeCreateXORConflictClauses(t *testing.T) {
 	solver.assignment["A"] = true
 	solver.assignment["B"] = true
 	solver.decisionLevel = 1
-	solver.trail = NewDecisionTrail()
 	solver.trail.Assign("A", true, 0, nil)
 	solver.trail.Assign("B", true, 1, nil)
 
@@ -90,18 +89,21 @@ func TestProbeAddUnassignedXOR(t *testing.T) {
 
 func TestProbeSortVariablesByActivity(t *testing.T) {
 	solver := NewCDCLSolver()
-	solver.variableActivity["B"] = 5.0
-	solver.variableActivity["A"] = 10.0
-	solver.variableActivity["C"] = 3.0
+	solver.bumpVariableActivity("B")
+	solver.bumpVariableActivity("A")
+	solver.bumpVariableActivity("A")
 
-	solver.sortVariablesByActivity([]string{"A", "B", "C"})
+	variables := []string{"C", "B", "A"}
+	solver.sortVariablesByActivity(variables)
+	if variables[0] != "A" || variables[1] != "B" {
+		t.Errorf("Expected A and B first, got %v", variables)
+	}
 }
 
 func TestProbeSetXORClauseLBD(t *testing.T) {
 	solver := NewCDCLSolver()
 	solver.assignment["A"] = true
 	solver.assignment["B"] = false
-	solver.trail = NewDecisionTrail()
 	solver.trail.Assign("A", true, 0, nil)
 	solver.trail.Assign("B", false, 1, nil)
 
@@ -132,10 +134,9 @@ func TestProbeRebuildWatchLists(t *testing.T) {
 	cnf := NewCNF()
 	cnf.AddClause(NewClause(L("A", false), L("B", false)))
 	solver.cnf = cnf
-	solver.watchLists = make(map[string][]*WatchedClause)
 
 	solver.rebuildWatchLists()
-	if len(solver.watchLists) == 0 {
+	if len(solver.watched) == 0 {
 		t.Error("watch lists should be rebuilt")
 	}
 }
@@ -279,7 +280,6 @@ func TestProbeCDCLRestart(t *testing.T) {
 	solver := NewCDCLSolver()
 	solver.assignment = make(Assignment)
 	solver.assignment["A"] = true
-	solver.trail = NewDecisionTrail()
 	solver.trail.Assign("A", true, 0, nil)
 	solver.heuristic = NewVSIDSHeuristic()
 	solver.restartStrategy = NewLubyRestartStrategy()
@@ -533,7 +533,6 @@ func TestProbeClauseDBStatistics(t *testing.T) {
 
 func TestProbeChronologicalBacktrack(t *testing.T) {
 	solver := NewCDCLSolver()
-	solver.trail = NewDecisionTrail()
 	solver.trail.Assign("A", true, 0, nil)
 	solver.trail.Assign("B", false, 1, nil)
 	solver.assignment = make(Assignment)
@@ -565,11 +564,14 @@ func TestProbeSolveWithTimeout(t *testing.T) {
 
 func TestProbeRescaleVariableActivities(t *testing.T) {
 	solver := NewCDCLSolver()
-	solver.variableActivity["A"] = 1e200
-	solver.variableActivity["B"] = 1e200
 	solver.varActivityInc = 1e200
+	solver.bumpVariableActivity("A")
+	solver.bumpVariableActivity("B")
 
 	solver.rescaleVariableActivities()
+	if solver.activityOf("A") > 1e100 {
+		t.Errorf("Expected rescaled activities, got %v", solver.activity)
+	}
 }
 
 func TestProbeDecayVariableActivities(t *testing.T) {
@@ -761,7 +763,7 @@ func TestProbeCompactSlice(t *testing.T) {
 		nil,
 		NewClause(L("B", false)),
 	}
-	result := compactSlice(clauses)
+	result := compactSlice(satPool, clauses)
 	if len(result) != 2 {
 		t.Errorf("expected 2 non-nil clauses after compact, got %d", len(result))
 	}
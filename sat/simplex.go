@@ -0,0 +1,412 @@
+package sat
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+// LinearTerm is Coefficient·x_Var
+type LinearTerm struct {
+	Var         int
+	Coefficient int64
+}
+
+// linearAtom is Boolean variable v standing for Σ terms ≤ bound, whose
+// left side is the simplex slack variable slack
+type linearAtom struct {
+	v     int32
+	terms []LinearTerm
+	bound *big.Rat
+	slack int
+}
+
+// deltaRat is r + d·δ for an infinitesimal δ > 0, which turns the strict
+// bound of a false atom into a non-strict one
+type deltaRat struct {
+	r, d *big.Rat
+}
+
+func newDeltaRat(r *big.Rat, d int64) deltaRat {
+	return deltaRat{r: new(big.Rat).Set(r), d: big.NewRat(d, 1)}
+}
+
+func (a deltaRat) cmp(b deltaRat) int {
+	if c := a.r.Cmp(b.r); c != 0 {
+		return c
+	}
+	return a.d.Cmp(b.d)
+}
+
+func (a deltaRat) add(b deltaRat) deltaRat {
+	return deltaRat{r: new(big.Rat).Add(a.r, b.r), d: new(big.Rat).Add(a.d, b.d)}
+}
+
+func (a deltaRat) sub(b deltaRat) deltaRat {
+	return deltaRat{r: new(big.Rat).Sub(a.r, b.r), d: new(big.Rat).Sub(a.d, b.d)}
+}
+
+func (a deltaRat) mul(k *big.Rat) deltaRat {
+	return deltaRat{r: new(big.Rat).Mul(a.r, k), d: new(big.Rat).Mul(a.d, k)}
+}
+
+// simplexBound is a bound asserted by an atom
+type simplexBound struct {
+	value deltaRat
+	lit   int32 // Negation of the literal that asserts the bound
+	set   bool
+}
+
+// LinearArithmetic is a TheoryPlugin for linear real arithmetic, using the
+// general simplex of Dutertre and de Moura. Boolean variables registered
+// with AddAtom stand for constraints Σ aᵢxᵢ ≤ b over real variables; a
+// false atom asserts Σ aᵢxᵢ > b. Each atom gets a slack variable equal to
+// its left side, so atoms only bound variables and the tableau of
+// equalities stays fixed between checks; Check asserts the bounds and runs
+// simplex with Bland's rule, in exact rational arithmetic. An infeasible
+// tableau row names the bounds that conflict; the lemma is shrunk to a
+// minimal infeasible subset by deletion, re-running simplex without each
+// atom in turn.
+type LinearArithmetic struct {
+	numVars int
+	atoms   []linearAtom
+	atomOf  map[int32]int // Atom index of each Boolean variable
+	dirty   bool          // The tableau lacks some atoms
+
+	rows   [][]*big.Rat // rows[i][j] is the coefficient of x_j in basic[i]
+	basic  []int
+	rowOf  []int // Row of each basic variable, -1 for nonbasic ones
+	value  []deltaRat
+	lower  []simplexBound
+	upper  []simplexBound
+	values []*big.Rat
+}
+
+// NewLinearArithmetic creates a plugin over real variables 0..n-1
+func NewLinearArithmetic(n int) *LinearArithmetic {
+	return &LinearArithmetic{
+		numVars: n,
+		atomOf:  make(map[int32]int),
+		dirty:   true,
+	}
+}
+
+// AddAtom makes Boolean variable v stand for Σ terms ≤ bound. Σ terms ≥ b
+// is -Σ terms ≤ -b.
+func (la *LinearArithmetic) AddAtom(v int32, terms []LinearTerm, bound int64) error {
+	if v < 0 {
+		return core.NewLogicError("sat", "LinearArithmetic.AddAtom", fmt.Sprintf("invalid Boolean variable %d", v))
+	}
+	if _, ok := la.atomOf[v]; ok {
+		return core.NewLogicError("sat", "LinearArithmetic.AddAtom", fmt.Sprintf("Boolean variable %d is already an atom", v))
+	}
+	for _, t := range terms {
+		if t.Var < 0 || t.Var >= la.numVars {
+			return core.NewLogicError("sat", "LinearArithmetic.AddAtom",
+				fmt.Sprintf("variable %d must be below %d", t.Var, la.numVars))
+		}
+	}
+	la.atomOf[v] = len(la.atoms)
+	la.atoms = append(la.atoms, linearAtom{
+		v:     v,
+		terms: append([]LinearTerm(nil), terms...),
+		bound: big.NewRat(bound, 1),
+		slack: la.numVars + len(la.atoms),
+	})
+	la.dirty = true
+	return nil
+}
+
+// Name returns the plugin identifier
+func (la *LinearArithmetic) Name() string { return "linear-arithmetic" }
+
+// Values returns rational values satisfying the atoms as assigned in the
+// last consistent Check
+func (la *LinearArithmetic) Values() []*big.Rat {
+	return la.values
+}
+
+// String returns the atom of Boolean variable v, such as "2 x0 - x1 ≤ 3"
+func (la *LinearArithmetic) String(v int32) string {
+	i, ok := la.atomOf[v]
+	if !ok {
+		return fmt.Sprintf("b%d", v)
+	}
+	var sb strings.Builder
+	for j, t := range la.atoms[i].terms {
+		c := t.Coefficient
+		switch {
+		case j > 0 && c < 0:
+			sb.WriteString(" - ")
+			c = -c
+		case j > 0:
+			sb.WriteString(" + ")
+		}
+		if c != 1 {
+			fmt.Fprintf(&sb, "%d ", c)
+		}
+		fmt.Fprintf(&sb, "x%d", t.Var)
+	}
+	if len(la.atoms[i].terms) == 0 {
+		sb.WriteString("0")
+	}
+	fmt.Fprintf(&sb, " ≤ %s", la.atoms[i].bound.RatString())
+	return sb.String()
+}
+
+// Check asserts every atom as assign has it and runs simplex
+func (la *LinearArithmetic) Check(assign []int8) (bool, []int32) {
+	if la.dirty {
+		la.build()
+	}
+	lemma := la.solve(assign, nil)
+	if lemma == nil {
+		la.values = la.model()
+		return true, nil
+	}
+	return false, la.minimise(assign, lemma)
+}
+
+// build makes every slack variable basic with its atom's row
+func (la *LinearArithmetic) build() {
+	n := la.numVars + len(la.atoms)
+	la.rows = make([][]*big.Rat, len(la.atoms))
+	la.basic = make([]int, len(la.atoms))
+	la.rowOf = make([]int, n)
+	la.value = make([]deltaRat, n)
+	la.lower = make([]simplexBound, n)
+	la.upper = make([]simplexBound, n)
+	for j := range la.rowOf {
+		la.rowOf[j] = -1
+		la.value[j] = newDeltaRat(new(big.Rat), 0)
+	}
+	for i, atom := range la.atoms {
+		row := make([]*big.Rat, n)
+		for j := range row {
+			row[j] = new(big.Rat)
+		}
+		for _, t := range atom.terms {
+			row[t.Var].Add(row[t.Var], big.NewRat(t.Coefficient, 1))
+		}
+		la.rows[i] = row
+		la.basic[i] = atom.slack
+		la.rowOf[atom.slack] = i
+	}
+	la.dirty = false
+}
+
+// solve asserts the atoms in keep, or all if keep is nil, and returns nil
+// if they are consistent or a lemma excluding a conflicting subset
+func (la *LinearArithmetic) solve(assign []int8, keep map[int32]bool) []int32 {
+	for j := range la.lower {
+		la.lower[j].set, la.upper[j].set = false, false
+	}
+	for _, atom := range la.atoms {
+		if int(atom.v) >= len(assign) || keep != nil && !keep[atom.v] {
+			continue
+		}
+		if assign[atom.v] != 0 {
+			la.upper[atom.slack] = simplexBound{value: newDeltaRat(atom.bound, 0), lit: atom.v*2 + 1, set: true}
+		} else {
+			la.lower[atom.slack] = simplexBound{value: newDeltaRat(atom.bound, 1), lit: atom.v * 2, set: true}
+		}
+	}
+
+	// Nonbasic variables sit within their bounds throughout
+	for j := range la.value {
+		if la.rowOf[j] >= 0 {
+			continue
+		}
+		if la.lower[j].set && la.value[j].cmp(la.lower[j].value) < 0 {
+			la.update(j, la.lower[j].value)
+		} else if la.upper[j].set && la.value[j].cmp(la.upper[j].value) > 0 {
+			la.update(j, la.upper[j].value)
+		}
+	}
+
+	for {
+		// Bland's rule: the violated basic variable and the entering
+		// nonbasic variable of least index
+		b, increase := -1, false
+		for j := range la.value {
+			if la.rowOf[j] < 0 {
+				continue
+			}
+			if la.lower[j].set && la.value[j].cmp(la.lower[j].value) < 0 {
+				b, increase = j, true
+				break
+			}
+			if la.upper[j].set && la.value[j].cmp(la.upper[j].value) > 0 {
+				b, increase = j, false
+				break
+			}
+		}
+		if b < 0 {
+			return nil
+		}
+
+		row := la.rows[la.rowOf[b]]
+		entering := -1
+		for j, a := range row {
+			if a.Sign() == 0 || la.rowOf[j] >= 0 {
+				continue
+			}
+			if increase == (a.Sign() > 0) {
+				if !la.upper[j].set || la.value[j].cmp(la.upper[j].value) < 0 {
+					entering = j
+					break
+				}
+			} else if !la.lower[j].set || la.value[j].cmp(la.lower[j].value) > 0 {
+				entering = j
+				break
+			}
+		}
+		if entering < 0 {
+			return la.explain(b, increase)
+		}
+		target := la.upper[b].value
+		if increase {
+			target = la.lower[b].value
+		}
+		la.pivotAndUpdate(b, entering, target)
+	}
+}
+
+// explain returns the lemma of a row whose basic variable b cannot reach
+// its bound: that bound and the bounds holding every nonbasic variable of
+// the row where it is
+func (la *LinearArithmetic) explain(b int, increase bool) []int32 {
+	lemma := []int32{la.upper[b].lit}
+	if increase {
+		lemma[0] = la.lower[b].lit
+	}
+	for j, a := range la.rows[la.rowOf[b]] {
+		if a.Sign() == 0 || la.rowOf[j] >= 0 {
+			continue
+		}
+		if increase == (a.Sign() > 0) {
+			lemma = append(lemma, la.upper[j].lit)
+		} else {
+			lemma = append(lemma, la.lower[j].lit)
+		}
+	}
+	return lemma
+}
+
+// minimise drops atoms from lemma while the rest still conflict
+func (la *LinearArithmetic) minimise(assign []int8, lemma []int32) []int32 {
+	necessary := make(map[int32]bool)
+	for {
+		candidate := int32(-1)
+		for _, lit := range lemma {
+			if !necessary[lit] {
+				candidate = lit
+				break
+			}
+		}
+		if candidate < 0 {
+			return lemma
+		}
+		keep := make(map[int32]bool, len(lemma))
+		for _, lit := range lemma {
+			if lit != candidate {
+				keep[lit/2] = true
+			}
+		}
+		if smaller := la.solve(assign, keep); smaller != nil {
+			lemma = smaller
+		} else {
+			necessary[candidate] = true
+		}
+	}
+}
+
+// update sets nonbasic variable j to v, keeping every row's equality
+func (la *LinearArithmetic) update(j int, v deltaRat) {
+	change := v.sub(la.value[j])
+	for i, row := range la.rows {
+		if row[j].Sign() != 0 {
+			b := la.basic[i]
+			la.value[b] = la.value[b].add(change.mul(row[j]))
+		}
+	}
+	la.value[j] = v
+}
+
+// pivotAndUpdate sets basic variable b to v by moving nonbasic variable
+// j, then swaps their roles
+func (la *LinearArithmetic) pivotAndUpdate(b, j int, v deltaRat) {
+	r := la.rowOf[b]
+	theta := v.sub(la.value[b]).mul(new(big.Rat).Inv(la.rows[r][j]))
+	la.value[b] = v
+	la.value[j] = la.value[j].add(theta)
+	for i, row := range la.rows {
+		if i != r && row[j].Sign() != 0 {
+			k := la.basic[i]
+			la.value[k] = la.value[k].add(theta.mul(row[j]))
+		}
+	}
+	la.pivot(r, j)
+}
+
+// pivot solves row r for x_j and substitutes it into the other rows
+func (la *LinearArithmetic) pivot(r, j int) {
+	row := la.rows[r]
+	b := la.basic[r]
+	inv := new(big.Rat).Inv(row[j])
+	// x_j = (b - Σ_{k≠j} a_k x_k) / a_j
+	pivoted := make([]*big.Rat, len(row))
+	for k, a := range row {
+		pivoted[k] = new(big.Rat).Neg(new(big.Rat).Mul(a, inv))
+	}
+	pivoted[j].SetInt64(0)
+	pivoted[b].Set(inv)
+	la.rows[r] = pivoted
+
+	for i, other := range la.rows {
+		c := other[j]
+		if i == r || c.Sign() == 0 {
+			continue
+		}
+		for k, a := range pivoted {
+			if a.Sign() != 0 {
+				other[k].Add(other[k], new(big.Rat).Mul(c, a))
+			}
+		}
+		other[j] = new(big.Rat)
+	}
+	la.basic[r] = j
+	la.rowOf[j] = r
+	la.rowOf[b] = -1
+}
+
+// model picks a rational δ small enough that r + d·δ keeps every bound
+func (la *LinearArithmetic) model() []*big.Rat {
+	delta := big.NewRat(1, 1)
+	limit := func(low, high deltaRat) {
+		// low ≤ high needs δ ≤ (high.r - low.r) / (low.d - high.d) when
+		// low.d > high.d
+		if low.r.Cmp(high.r) < 0 && low.d.Cmp(high.d) > 0 {
+			bound := new(big.Rat).Quo(new(big.Rat).Sub(high.r, low.r), new(big.Rat).Sub(low.d, high.d))
+			if bound.Cmp(delta) < 0 {
+				delta = bound
+			}
+		}
+	}
+	for j, v := range la.value {
+		if la.lower[j].set {
+			limit(la.lower[j].value, v)
+		}
+		if la.upper[j].set {
+			limit(v, la.upper[j].value)
+		}
+	}
+	values := make([]*big.Rat, la.numVars)
+	for j := range values {
+		v := la.value[j]
+		values[j] = new(big.Rat).Add(v.r, new(big.Rat).Mul(v.d, delta))
+	}
+	return values
+}
@@ -0,0 +1,164 @@
+package sat
+
+import (
+	"math/big"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// lraHolds reports whether values satisfy Σ terms ≤ bound exactly
+func lraHolds(terms []LinearTerm, bound int64, values []*big.Rat) bool {
+	sum := new(big.Rat)
+	for _, t := range terms {
+		sum.Add(sum, new(big.Rat).Mul(big.NewRat(t.Coefficient, 1), values[t.Var]))
+	}
+	return sum.Cmp(big.NewRat(bound, 1)) <= 0
+}
+
+type lraTestAtom struct {
+	terms []LinearTerm
+	bound int64
+}
+
+// lraCheck runs a fresh plugin over atoms as assign has them and
+// verifies any model it returns
+func lraCheck(t *testing.T, n int, atoms []lraTestAtom, assign []int8) (bool, []int32) {
+	t.Helper()
+	la := NewLinearArithmetic(n)
+	for v, atom := range atoms {
+		if err := la.AddAtom(int32(v), atom.terms, atom.bound); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ok, lemma := la.Check(assign)
+	if ok {
+		for v, atom := range atoms {
+			if lraHolds(atom.terms, atom.bound, la.Values()) != (assign[v] != 0) {
+				t.Fatalf("Values %v violate %s = %d", la.Values(), la.String(int32(v)), assign[v])
+			}
+		}
+	}
+	return ok, lemma
+}
+
+func TestLinearArithmetic_Check(t *testing.T) {
+	x, y, z := 0, 1, 2
+	testCases := []struct {
+		description   string
+		atoms         []lraTestAtom
+		assign        []int8
+		expectedLemma []int32 // nil if the assignment is consistent
+	}{
+		{"bounded region",
+			[]lraTestAtom{{[]LinearTerm{{x, 1}}, 2}, {[]LinearTerm{{x, -1}}, 0}, {[]LinearTerm{{y, 1}, {x, -1}}, 1}},
+			[]int8{1, 1, 1}, nil},
+		{"equal bounds",
+			[]lraTestAtom{{[]LinearTerm{{x, 1}}, 1}, {[]LinearTerm{{x, -1}}, -1}},
+			[]int8{1, 1}, nil},
+		{"contradictory bounds",
+			[]lraTestAtom{{[]LinearTerm{{x, 1}}, 1}, {[]LinearTerm{{x, -1}}, -2}},
+			[]int8{1, 1}, []int32{1, 3}},
+		{"negation is strict",
+			[]lraTestAtom{{[]LinearTerm{{x, 1}}, 1}, {[]LinearTerm{{x, 2}}, 2}},
+			[]int8{1, 0}, []int32{1, 2}},
+		{"sum above its parts",
+			[]lraTestAtom{{[]LinearTerm{{x, 1}, {y, 1}}, 1}, {[]LinearTerm{{x, 1}}, 0}, {[]LinearTerm{{y, 1}}, 1}},
+			[]int8{0, 1, 1}, []int32{0, 3, 5}},
+		{"unrelated atoms stay out of the lemma",
+			[]lraTestAtom{{[]LinearTerm{{x, 1}}, 0}, {[]LinearTerm{{x, -1}}, -1}, {[]LinearTerm{{y, 1}}, 3}, {[]LinearTerm{{x, 1}, {y, -1}}, 2}},
+			[]int8{1, 1, 1, 0}, []int32{1, 3}},
+		{"cycle over three variables",
+			[]lraTestAtom{{[]LinearTerm{{x, 1}, {y, -1}}, 0}, {[]LinearTerm{{y, 1}, {z, -1}}, 0}, {[]LinearTerm{{z, 1}, {x, -1}}, -1}},
+			[]int8{1, 1, 1}, []int32{1, 3, 5}},
+		{"scaled rows cancel",
+			[]lraTestAtom{{[]LinearTerm{{x, -2}, {y, 3}}, 0}, {[]LinearTerm{{x, 4}, {y, -6}}, -1}},
+			[]int8{1, 1}, []int32{1, 3}},
+		{"scaled rows with one negated",
+			[]lraTestAtom{{[]LinearTerm{{x, -2}, {y, 3}}, 0}, {[]LinearTerm{{x, 4}, {y, -6}}, -1}},
+			[]int8{1, 0}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ok, lemma := lraCheck(t, 3, tc.atoms, tc.assign)
+			if ok != (tc.expectedLemma == nil) {
+				t.Fatalf("Expected consistent=%v, got %v with lemma %v", tc.expectedLemma == nil, ok, lemma)
+			}
+			if ok {
+				return
+			}
+			sort.Slice(lemma, func(i, j int) bool { return lemma[i] < lemma[j] })
+			if !reflect.DeepEqual(lemma, tc.expectedLemma) {
+				t.Errorf("Expected lemma %v, got %v", tc.expectedLemma, lemma)
+			}
+		})
+	}
+}
+
+func TestLinearArithmetic_Strict(t *testing.T) {
+	// ¬(x ≤ 0) ∧ x ≤ 1 ∧ ¬(2x ≤ 1) leaves 1/2 < x ≤ 1
+	la := NewLinearArithmetic(1)
+	la.AddAtom(0, []LinearTerm{{0, 1}}, 0)
+	la.AddAtom(1, []LinearTerm{{0, 1}}, 1)
+	la.AddAtom(2, []LinearTerm{{0, 2}}, 1)
+	ok, _ := la.Check([]int8{0, 1, 0})
+	if x := la.Values()[0]; !ok || x.Cmp(big.NewRat(1, 2)) <= 0 || x.Cmp(big.NewRat(1, 1)) > 0 {
+		t.Errorf("Expected 1/2 < x ≤ 1, got %v (%v)", la.Values(), ok)
+	}
+
+	// ¬(x ≤ 1) contradicts x ≤ 1 through the slack variables' rows
+	if ok, lemma := la.Check([]int8{1, 0, 1}); ok || len(lemma) != 2 {
+		t.Errorf("Expected the lemma ¬b0 ∨ b1, got %v", lemma)
+	}
+	if got := la.String(2); got != "2 x0 ≤ 1" {
+		t.Errorf("Unexpected string %q", got)
+	}
+	if err := la.AddAtom(3, []LinearTerm{{1, 1}}, 0); err == nil {
+		t.Error("Expected an error for an unknown variable")
+	}
+	if err := la.AddAtom(2, nil, 0); err == nil {
+		t.Error("Expected an error for a repeated atom")
+	}
+}
+
+func TestTheorySolver_LinearArithmetic(t *testing.T) {
+	// Tasks a and b of lengths 1.5 and 2.5, as 3/2 and 5/2 scaled by 2,
+	// run back to back in either order within [0, deadline]
+	build := func(deadline int64) (*TheorySolver, *LinearArithmetic) {
+		ts := newTheorySolver(t, 6)
+		la := NewLinearArithmetic(2)
+		// 2a - 2b ≤ -3: a ends before b starts
+		la.AddAtom(0, []LinearTerm{{0, 2}, {1, -2}}, -3)
+		// 2b - 2a ≤ -5: b ends before a starts
+		la.AddAtom(1, []LinearTerm{{1, 2}, {0, -2}}, -5)
+		// -a ≤ 0, -b ≤ 0, 2a ≤ 2·deadline - 3, 2b ≤ 2·deadline - 5
+		la.AddAtom(2, []LinearTerm{{0, -1}}, 0)
+		la.AddAtom(3, []LinearTerm{{1, -1}}, 0)
+		la.AddAtom(4, []LinearTerm{{0, 2}}, 2*deadline-3)
+		la.AddAtom(5, []LinearTerm{{1, 2}}, 2*deadline-5)
+		ts.AddClause([]int32{0 * 2, 1 * 2})
+		for v := int32(2); v < 6; v++ {
+			ts.AddClause([]int32{v * 2})
+		}
+		ts.RegisterPlugin(la)
+		return ts, la
+	}
+
+	ts, la := build(4)
+	if _, status := ts.Solve(); status != StatusSAT {
+		t.Fatalf("Expected SAT with deadline 4, got %v", status)
+	}
+	a, b := la.Values()[0], la.Values()[1]
+	aEnd := new(big.Rat).Add(a, big.NewRat(3, 2))
+	bEnd := new(big.Rat).Add(b, big.NewRat(5, 2))
+	if a.Sign() < 0 || b.Sign() < 0 || aEnd.Cmp(big.NewRat(4, 1)) > 0 || bEnd.Cmp(big.NewRat(4, 1)) > 0 ||
+		aEnd.Cmp(b) > 0 && bEnd.Cmp(a) > 0 {
+		t.Errorf("Schedule a=%v b=%v is invalid", a, b)
+	}
+
+	ts, _ = build(3)
+	if _, status := ts.Solve(); status != StatusUNSAT {
+		t.Errorf("Expected UNSAT with deadline 3, got %v", status)
+	}
+}
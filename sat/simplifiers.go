@@ -0,0 +1,693 @@
+package sat
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/xDarkicex/memory"
+)
+
+// EquivalentLiteralSubstitution replaces literals that imply each other by
+// a single representative. Every binary clause (a ∨ b) gives the edges
+// ¬a → b and ¬b → a of the binary implication graph, and the literals of
+// one strongly connected component are equivalent; Tarjan's algorithm
+// finds the components in linear time. The representative of a component
+// is the literal whose variable comes first in the formula, so that the
+// component of the negated literals gets the negated representative.
+//
+// Substituted variables leave the formula. Both halves of each equivalence
+// go on the reconstruction stack, so models get them back.
+type EquivalentLiteralSubstitution struct {
+	// Statistics
+	substitutedVars  int64
+	rewrittenClauses int64
+
+	// Tarjan's algorithm over the binary implication graph
+	graph   map[Literal][]Literal
+	nodes   []Literal
+	index   map[Literal]int
+	lowlink map[Literal]int
+	onStack map[Literal]bool
+	stack   []Literal
+	counter int
+
+	// Proof output (nil when not tracing)
+	proof *ProofWriter
+
+	// Removed equivalences for model reconstruction (nil when not recording)
+	reconstruction *ReconstructionStack
+
+	pool *memory.Pool // Backs new clauses
+}
+
+// NewEquivalentLiteralSubstitution creates a substitution engine
+func NewEquivalentLiteralSubstitution() *EquivalentLiteralSubstitution {
+	return newEquivalentLiteralSubstitution(nil)
+}
+
+func newEquivalentLiteralSubstitution(pool *memory.Pool) *EquivalentLiteralSubstitution {
+	return &EquivalentLiteralSubstitution{pool: poolOr(pool)}
+}
+
+// Substitute finds the equivalent literals of cnf's irredundant binary
+// clauses and rewrites the formula over their representatives. Classes
+// with a variable fixed by assignment are left alone, as is a class that
+// contains a literal and its negation, which makes the formula
+// unsatisfiable and is left to the search. It returns the number of
+// substituted variables.
+func (els *EquivalentLiteralSubstitution) Substitute(cnf *CNF, assignment Assignment) int {
+	els.buildGraph(cnf, assignment)
+	substitution := els.findEquivalences(cnf)
+	if len(substitution) == 0 {
+		return 0
+	}
+
+	// Every rewritten clause follows by unit propagation from the old one
+	// and the binary clauses of the equivalence, so all of them are added
+	// before anything is deleted
+	var replaced []*Clause
+	for _, clause := range cnf.Clauses {
+		if clause == nil || clause.Deleted {
+			continue
+		}
+		lits, changed := substituteLiterals(clause.Literals, substitution)
+		if !changed {
+			continue
+		}
+		replaced = append(replaced, clause)
+		els.rewrittenClauses++
+		if isTautology(lits) {
+			continue
+		}
+		newClause := NewClauseIn(els.pool, lits...)
+		newClause.Learned = clause.Learned
+		cnf.AddClause(newClause)
+		els.proof.AddClause(newClause.ID, newClause.Literals, nil)
+	}
+	for _, clause := range replaced {
+		els.proof.DeleteClause(clause.ID, clause.Literals)
+		clause.Deleted = true
+	}
+
+	// b ≡ r is the clauses (b ∨ ¬r) and (¬b ∨ r)
+	names := make([]string, 0, len(substitution))
+	for variable := range substitution {
+		names = append(names, variable)
+	}
+	sort.Strings(names)
+	for _, variable := range names {
+		rep := substitution[variable]
+		positive := Literal{Variable: variable}
+		els.reconstruction.Push(positive, []Literal{positive, rep.Negate()})
+		els.reconstruction.Push(positive.Negate(), []Literal{positive.Negate(), rep})
+	}
+
+	removeDeletedClauses(els.pool, cnf)
+	kept := cnf.Variables[:0]
+	for _, variable := range cnf.Variables {
+		if _, gone := substitution[variable]; !gone {
+			kept = append(kept, variable)
+		}
+	}
+	cnf.Variables = kept
+	els.substitutedVars += int64(len(substitution))
+	return len(substitution)
+}
+
+// buildGraph collects the implication edges of the binary clauses without
+// fixed variables
+func (els *EquivalentLiteralSubstitution) buildGraph(cnf *CNF, assignment Assignment) {
+	els.graph = make(map[Literal][]Literal)
+	els.nodes = els.nodes[:0]
+	for _, clause := range cnf.Clauses {
+		if clause == nil || clause.Deleted || clause.Learned || len(clause.Literals) != 2 {
+			continue
+		}
+		a, b := clause.Literals[0], clause.Literals[1]
+		if a.Variable == b.Variable || assignment.IsAssigned(a.Variable) || assignment.IsAssigned(b.Variable) {
+			continue
+		}
+		els.addEdge(a.Negate(), b)
+		els.addEdge(b.Negate(), a)
+	}
+}
+
+// addEdge records from → to, keeping the nodes in order of appearance
+func (els *EquivalentLiteralSubstitution) addEdge(from, to Literal) {
+	for _, lit := range []Literal{from, to} {
+		if _, ok := els.graph[lit]; !ok {
+			els.graph[lit] = nil
+			els.nodes = append(els.nodes, lit)
+		}
+	}
+	els.graph[from] = append(els.graph[from], to)
+}
+
+// findEquivalences maps every substituted variable to the literal that
+// replaces its positive literal
+func (els *EquivalentLiteralSubstitution) findEquivalences(cnf *CNF) map[string]Literal {
+	order := make(map[string]int, len(cnf.Variables))
+	for i, variable := range cnf.Variables {
+		order[variable] = i
+	}
+	els.index = make(map[Literal]int, len(els.nodes))
+	els.lowlink = make(map[Literal]int, len(els.nodes))
+	els.onStack = make(map[Literal]bool)
+	els.stack = els.stack[:0]
+	els.counter = 0
+
+	substitution := make(map[string]Literal)
+	for _, node := range els.nodes {
+		if _, visited := els.index[node]; visited {
+			continue
+		}
+		for _, component := range els.strongConnect(node, nil) {
+			if len(component) < 2 || hasComplementary(component) {
+				continue
+			}
+			rep := component[0]
+			for _, lit := range component[1:] {
+				if order[lit.Variable] < order[rep.Variable] {
+					rep = lit
+				}
+			}
+			for _, lit := range component {
+				if lit != rep {
+					substitution[lit.Variable] = Literal{Variable: rep.Variable, Negated: rep.Negated != lit.Negated}
+				}
+			}
+		}
+	}
+	return substitution
+}
+
+// strongConnect is the recursive step of Tarjan's algorithm. It appends
+// the components completed below node to components and returns them.
+func (els *EquivalentLiteralSubstitution) strongConnect(node Literal, components [][]Literal) [][]Literal {
+	els.index[node] = els.counter
+	els.lowlink[node] = els.counter
+	els.counter++
+	els.stack = append(els.stack, node)
+	els.onStack[node] = true
+
+	for _, next := range els.graph[node] {
+		if _, visited := els.index[next]; !visited {
+			components = els.strongConnect(next, components)
+			els.lowlink[node] = min(els.lowlink[node], els.lowlink[next])
+		} else if els.onStack[next] {
+			els.lowlink[node] = min(els.lowlink[node], els.index[next])
+		}
+	}
+
+	if els.lowlink[node] != els.index[node] {
+		return components
+	}
+	var component []Literal
+	for {
+		top := els.stack[len(els.stack)-1]
+		els.stack = els.stack[:len(els.stack)-1]
+		els.onStack[top] = false
+		component = append(component, top)
+		if top == node {
+			break
+		}
+	}
+	return append(components, component)
+}
+
+// GetStatistics returns substitution statistics
+func (els *EquivalentLiteralSubstitution) GetStatistics() map[string]int64 {
+	return map[string]int64{
+		"substitutedVars":  els.substitutedVars,
+		"rewrittenClauses": els.rewrittenClauses,
+	}
+}
+
+// SetReconstructionStack makes substitution record the equivalences it
+// removes on r. A nil stack turns recording off.
+func (els *EquivalentLiteralSubstitution) SetReconstructionStack(r *ReconstructionStack) {
+	els.reconstruction = r
+}
+
+// substituteLiterals rewrites lits by substitution without duplicates and
+// reports whether anything changed
+func substituteLiterals(lits []Literal, substitution map[string]Literal) ([]Literal, bool) {
+	changed := false
+	for _, lit := range lits {
+		if _, ok := substitution[lit.Variable]; ok {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return nil, false
+	}
+	out := make([]Literal, 0, len(lits))
+	for _, lit := range lits {
+		if rep, ok := substitution[lit.Variable]; ok {
+			lit = Literal{Variable: rep.Variable, Negated: rep.Negated != lit.Negated}
+		}
+		if literalIndex(out, lit) < 0 {
+			out = append(out, lit)
+		}
+	}
+	return out, true
+}
+
+// hasComplementary reports whether lits contains a variable in both
+// polarities
+func hasComplementary(lits []Literal) bool {
+	seen := make(map[string]bool, len(lits))
+	for _, lit := range lits {
+		if negated, ok := seen[lit.Variable]; ok && negated != lit.Negated {
+			return true
+		}
+		seen[lit.Variable] = lit.Negated
+	}
+	return false
+}
+
+// removeDeletedClauses drops deleted clauses from cnf and frees them
+func removeDeletedClauses(pool *memory.Pool, cnf *CNF) {
+	validClauses := memory.MustPoolSlice[*Clause](pool, len(cnf.Clauses))
+	for _, clause := range cnf.Clauses {
+		if clause != nil && !clause.Deleted {
+			validClauses = append(validClauses, clause)
+		} else if clause != nil {
+			FreeClause(clause)
+		}
+	}
+	cnf.Clauses = validClauses
+}
+
+// BlockedClauseElimination removes blocked clauses (Järvisalo, Biere &
+// Heule, 2010). A clause C is blocked on its literal l if every resolvent
+// of C on l with a clause containing ¬l is a tautology. Removing C keeps
+// the formula satisfiable: a model of the rest that falsifies C satisfies
+// it, and every clause with ¬l, once l is flipped. Each removed clause
+// goes on the reconstruction stack with l as its witness.
+type BlockedClauseElimination struct {
+	// Configuration
+	maxOccurrences int // Most resolution partners tried for one literal
+	maxRounds      int // Passes over the formula
+
+	// Statistics
+	eliminatedClauses int64
+	checkedLiterals   int64
+
+	occurrences map[Literal][]*Clause
+
+	// Proof output (nil when not tracing)
+	proof *ProofWriter
+
+	// Removed clauses for model reconstruction (nil when not recording)
+	reconstruction *ReconstructionStack
+
+	pool *memory.Pool // Backs scratch arrays
+}
+
+// NewBlockedClauseElimination creates a blocked clause eliminator
+func NewBlockedClauseElimination() *BlockedClauseElimination {
+	return newBlockedClauseElimination(nil)
+}
+
+func newBlockedClauseElimination(pool *memory.Pool) *BlockedClauseElimination {
+	return &BlockedClauseElimination{
+		pool:           poolOr(pool),
+		maxOccurrences: 32,
+		maxRounds:      3,
+	}
+}
+
+// Eliminate removes the blocked irredundant clauses of cnf and returns
+// their number. Removing a clause can block others, so the formula is
+// scanned again until nothing changes or the rounds run out. Clauses with
+// a variable fixed by assignment are kept, since they may be the reasons
+// of root assignments.
+func (bce *BlockedClauseElimination) Eliminate(cnf *CNF, assignment Assignment) int {
+	bce.occurrences = make(map[Literal][]*Clause)
+	for _, clause := range cnf.Clauses {
+		if clause == nil || clause.Deleted || clause.Learned {
+			continue
+		}
+		for _, lit := range clause.Literals {
+			bce.occurrences[lit] = append(bce.occurrences[lit], clause)
+		}
+	}
+
+	eliminated := 0
+	for round, changed := 0, true; changed && round < bce.maxRounds; round++ {
+		changed = false
+		for _, clause := range cnf.Clauses {
+			if clause == nil || clause.Deleted || clause.Learned || bce.fixed(clause, assignment) {
+				continue
+			}
+			for _, lit := range clause.Literals {
+				if !bce.blockedOn(clause, lit) {
+					continue
+				}
+				bce.reconstruction.Push(lit, clause.Literals)
+				bce.proof.DeleteClause(clause.ID, clause.Literals)
+				clause.Deleted = true
+				eliminated++
+				changed = true
+				break
+			}
+		}
+	}
+	if eliminated > 0 {
+		removeDeletedClauses(bce.pool, cnf)
+	}
+	bce.eliminatedClauses += int64(eliminated)
+	return eliminated
+}
+
+// fixed reports whether a variable of clause is assigned
+func (bce *BlockedClauseElimination) fixed(clause *Clause, assignment Assignment) bool {
+	for _, lit := range clause.Literals {
+		if assignment.IsAssigned(lit.Variable) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockedOn reports whether every resolvent of clause on lit is a
+// tautology
+func (bce *BlockedClauseElimination) blockedOn(clause *Clause, lit Literal) bool {
+	partners := bce.occurrences[lit.Negate()]
+	if len(partners) > bce.maxOccurrences {
+		return false
+	}
+	bce.checkedLiterals++
+	for _, partner := range partners {
+		if partner.Deleted {
+			continue
+		}
+		tautology := false
+		for _, other := range partner.Literals {
+			if other.Variable != lit.Variable && literalIndex(clause.Literals, other.Negate()) >= 0 {
+				tautology = true
+				break
+			}
+		}
+		if !tautology {
+			return false
+		}
+	}
+	return true
+}
+
+// GetStatistics returns blocked clause elimination statistics
+func (bce *BlockedClauseElimination) GetStatistics() map[string]int64 {
+	return map[string]int64{
+		"eliminatedClauses": bce.eliminatedClauses,
+		"checkedLiterals":   bce.checkedLiterals,
+	}
+}
+
+// SetReconstructionStack makes elimination record the clauses it removes
+// on r. A nil stack turns recording off.
+func (bce *BlockedClauseElimination) SetReconstructionStack(r *ReconstructionStack) {
+	bce.reconstruction = r
+}
+
+// BoundedVariableAddition factors clause products through fresh variables
+// (Manthey, Heule & Biere, 2012; SimpleBVA, Haberlandt, Green & Heule,
+// 2023). If the formula contains (l ∨ C) for every literal l of a set L
+// and every clause C of a set M, the |L|·|M| clauses are replaced by
+//
+//	(l ∨ x) for l in L  and  (¬x ∨ C) for C in M
+//
+// with x fresh, whose resolvents on x are the old clauses. It pays off
+// when |L|·|M| > |L| + |M|, and helps on at-most-one and similar
+// encodings. The fresh variables, named _bva0, _bva1 and so on, become
+// variables of the formula and are assigned in its models.
+type BoundedVariableAddition struct {
+	// Configuration
+	maxSteps     int // Clause comparisons per run
+	maxVariables int // Fresh variables per run
+
+	// Statistics
+	addedVars    int64
+	savedClauses int64
+	steps        int64
+
+	fresh       int // Suffix of the next fresh variable
+	occurrences map[Literal][]*Clause
+
+	// Reserves the names of the original formula, which fresh variables
+	// must not reuse (nil when not recording)
+	reconstruction *ReconstructionStack
+
+	// Proof output (nil when not tracing)
+	proof *ProofWriter
+
+	pool *memory.Pool // Backs new clauses
+}
+
+// bvaPair is a clause (lit ∨ C) matched to the clause (l ∨ C) it extends
+type bvaPair struct {
+	base    *Clause
+	partner *Clause
+}
+
+// NewBoundedVariableAddition creates a variable addition engine
+func NewBoundedVariableAddition() *BoundedVariableAddition {
+	return newBoundedVariableAddition(nil)
+}
+
+func newBoundedVariableAddition(pool *memory.Pool) *BoundedVariableAddition {
+	return &BoundedVariableAddition{
+		pool:         poolOr(pool),
+		maxSteps:     1000000,
+		maxVariables: 1000,
+	}
+}
+
+// Add applies bounded variable addition to the irredundant clauses of cnf
+// without fixed variables. It returns the number of fresh variables and
+// the net number of clauses saved.
+func (bva *BoundedVariableAddition) Add(cnf *CNF, assignment Assignment) (int, int) {
+	bva.occurrences = make(map[Literal][]*Clause)
+	for _, clause := range cnf.Clauses {
+		if clause == nil || clause.Deleted || clause.Learned || isTautology(clause.Literals) {
+			continue
+		}
+		fixed := false
+		for _, lit := range clause.Literals {
+			fixed = fixed || assignment.IsAssigned(lit.Variable)
+		}
+		if !fixed {
+			for _, lit := range clause.Literals {
+				bva.occurrences[lit] = append(bva.occurrences[lit], clause)
+			}
+		}
+	}
+
+	// The literals with the most occurrences promise the largest products
+	queue := make([]Literal, 0, len(bva.occurrences))
+	for lit, clauses := range bva.occurrences {
+		if len(clauses) > 1 {
+			queue = append(queue, lit)
+		}
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		ni, nj := len(bva.occurrences[queue[i]]), len(bva.occurrences[queue[j]])
+		if ni != nj {
+			return ni < nj
+		}
+		return queue[i].String() > queue[j].String()
+	})
+
+	added, saved, steps := 0, 0, 0
+	for len(queue) > 0 && added < bva.maxVariables && steps < bva.maxSteps {
+		lit := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		lits, matched := bva.match(lit, &steps)
+		if reduction(len(lits), len(matched)) <= 0 {
+			continue
+		}
+		x := bva.replace(cnf, lit, lits, matched)
+		added++
+		saved += reduction(len(lits), len(matched))
+		queue = append(queue, lit, x, x.Negate())
+	}
+	if added > 0 {
+		removeDeletedClauses(bva.pool, cnf)
+	}
+	bva.addedVars += int64(added)
+	bva.savedClauses += int64(saved)
+	bva.steps += int64(steps)
+	return added, saved
+}
+
+// reduction is the number of clauses saved by factoring a product of
+// literals × clauses
+func reduction(literals, clauses int) int {
+	return literals*clauses - literals - clauses
+}
+
+// match grows the literal set {lit} one literal at a time, keeping the
+// clauses of lit that have a partner for every literal of the set, while
+// the reduction grows. matched[i] lists the clauses of the product for
+// the i-th base clause of lit, in the order of the literals.
+func (bva *BoundedVariableAddition) match(lit Literal, steps *int) ([]Literal, [][]*Clause) {
+	lits := []Literal{lit}
+	var matched [][]*Clause
+	for _, clause := range bva.occurrences[lit] {
+		if !clause.Deleted && len(clause.Literals) > 1 {
+			matched = append(matched, []*Clause{clause})
+		}
+	}
+
+	for {
+		// Partners (l' ∨ C) of every remaining (lit ∨ C), by l'
+		candidates := make(map[Literal][]int)
+		var order []Literal
+		for i, row := range matched {
+			base := row[0]
+			for _, pair := range bva.partners(base, lit, steps) {
+				other := bva.extraLiteral(pair.partner, base, lit)
+				if literalIndex(lits, other) >= 0 {
+					continue
+				}
+				rows := candidates[other]
+				if len(rows) > 0 && rows[len(rows)-1] == i {
+					continue // A duplicate clause
+				}
+				if len(rows) == 0 {
+					order = append(order, other)
+				}
+				candidates[other] = append(rows, i)
+			}
+		}
+
+		var best Literal
+		bestRows := 0
+		for _, other := range order {
+			if n := len(candidates[other]); n > bestRows {
+				best, bestRows = other, n
+			}
+		}
+		if bestRows == 0 || reduction(len(lits)+1, bestRows) <= reduction(len(lits), len(matched)) {
+			return lits, matched
+		}
+
+		next := make([][]*Clause, 0, bestRows)
+		for _, i := range candidates[best] {
+			row := matched[i]
+			for _, pair := range bva.partners(row[0], lit, steps) {
+				if bva.extraLiteral(pair.partner, row[0], lit) == best {
+					next = append(next, append(row, pair.partner))
+					break
+				}
+			}
+		}
+		lits = append(lits, best)
+		matched = next
+	}
+}
+
+// partners returns the clauses that equal base with lit replaced by
+// another literal. They are found among the occurrences of the rarest
+// other literal of base.
+func (bva *BoundedVariableAddition) partners(base *Clause, lit Literal, steps *int) []bvaPair {
+	var rarest Literal
+	fewest := -1
+	for _, l := range base.Literals {
+		if l != lit && (fewest < 0 || len(bva.occurrences[l]) < fewest) {
+			rarest, fewest = l, len(bva.occurrences[l])
+		}
+	}
+	var pairs []bvaPair
+	for _, candidate := range bva.occurrences[rarest] {
+		*steps++
+		if candidate == base || candidate.Deleted || len(candidate.Literals) != len(base.Literals) {
+			continue
+		}
+		if bva.extraLiteral(candidate, base, lit) != (Literal{}) {
+			pairs = append(pairs, bvaPair{base: base, partner: candidate})
+		}
+	}
+	return pairs
+}
+
+// extraLiteral returns the literal of candidate outside base, if candidate
+// is base with lit replaced by it, and the zero Literal otherwise
+func (bva *BoundedVariableAddition) extraLiteral(candidate, base *Clause, lit Literal) Literal {
+	var extra Literal
+	found := false
+	for _, l := range candidate.Literals {
+		if l != lit && literalIndex(base.Literals, l) >= 0 {
+			continue
+		}
+		if found || l == lit || l.Variable == lit.Variable && l.Negated != lit.Negated {
+			return Literal{}
+		}
+		extra, found = l, true
+	}
+	return extra
+}
+
+// replace adds the fresh variable x for the product of lits and the base
+// clauses of lit in matched, and deletes the product. The proof adds the
+// clauses with x first, which are RAT on x, then those with ¬x, which are
+// RAT on ¬x since their resolvents are the product.
+func (bva *BoundedVariableAddition) replace(cnf *CNF, lit Literal, lits []Literal, matched [][]*Clause) Literal {
+	name := fmt.Sprintf("_bva%d", bva.fresh)
+	for cnf.containsVariable(name) || bva.reconstruction.taken(name) {
+		bva.fresh++
+		name = fmt.Sprintf("_bva%d", bva.fresh)
+	}
+	bva.fresh++
+	x := Literal{Variable: name}
+
+	var added []*Clause
+	for _, l := range lits {
+		clause := NewClauseIn(bva.pool, x, l)
+		cnf.AddClause(clause)
+		bva.proof.AddClause(clause.ID, []Literal{x, l}, nil)
+		added = append(added, clause)
+	}
+	for _, row := range matched {
+		rest := []Literal{x.Negate()}
+		for _, l := range row[0].Literals {
+			if l != lit {
+				rest = append(rest, l)
+			}
+		}
+		clause := NewClauseIn(bva.pool, rest...)
+		cnf.AddClause(clause)
+		bva.proof.AddClause(clause.ID, rest, nil)
+		added = append(added, clause)
+	}
+	for _, row := range matched {
+		for _, clause := range row {
+			bva.proof.DeleteClause(clause.ID, clause.Literals)
+			clause.Deleted = true
+		}
+	}
+	for _, clause := range added {
+		for _, l := range clause.Literals {
+			bva.occurrences[l] = append(bva.occurrences[l], clause)
+		}
+	}
+	return x
+}
+
+// GetStatistics returns variable addition statistics
+func (bva *BoundedVariableAddition) GetStatistics() map[string]int64 {
+	return map[string]int64{
+		"addedVars":    bva.addedVars,
+		"savedClauses": bva.savedClauses,
+		"steps":        bva.steps,
+	}
+}
+
+// SetReconstructionStack makes fresh variables avoid every variable the
+// stack reserves: those of the input formula and of removed clauses,
+// which models still assign. A nil stack only avoids the current formula.
+func (bva *BoundedVariableAddition) SetReconstructionStack(r *ReconstructionStack) {
+	bva.reconstruction = r
+}
@@ -0,0 +1,281 @@
+package sat
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// pairwiseAtMostOne returns a clause ¬a ∨ ¬b for each pair of lits
+func pairwiseAtMostOne(lits ...Literal) [][]Literal {
+	var clauses [][]Literal
+	for i := range lits {
+		for j := i + 1; j < len(lits); j++ {
+			clauses = append(clauses, []Literal{lits[i].Negate(), lits[j].Negate()})
+		}
+	}
+	return clauses
+}
+
+// simplifierCases have equivalent literals, blocked clauses and
+// at-most-one constraints for the simplifiers to work on
+var simplifierCases = []struct {
+	description string
+	clauses     [][]Literal
+	expectedSat bool
+}{
+	{"equivalence chain", [][]Literal{
+		{L("A", true), L("B", false)},
+		{L("A", false), L("B", true)},
+		{L("B", true), L("C", true)},
+		{L("B", false), L("C", false)},
+		{L("A", false), L("D", false)},
+		{L("D", true), L("C", false), L("E", false)},
+	}, true},
+	{"equivalence with contradicting clauses", [][]Literal{
+		{L("A", true), L("B", false)},
+		{L("A", false), L("B", true)},
+		{L("B", true), L("C", false)},
+		{L("B", false), L("C", true)},
+		{L("A", false), L("C", false)},
+		{L("A", true), L("C", true)},
+	}, false},
+	{"blocked clauses", [][]Literal{
+		{L("A", false), L("B", false)},
+		{L("A", true), L("B", true)},
+		{L("B", false), L("C", false)},
+		{L("C", true), L("D", false)},
+	}, true},
+	{"exactly one of five", append(
+		pairwiseAtMostOne(L("A", false), L("B", false), L("C", false), L("D", false), L("E", false)),
+		[]Literal{L("A", false), L("B", false), L("C", false), L("D", false), L("E", false)},
+	), true},
+	{"at most one against two units", append(
+		pairwiseAtMostOne(L("A", false), L("B", false), L("C", false), L("D", false), L("E", false)),
+		[]Literal{L("A", false)}, []Literal{L("B", false)},
+	), false},
+	{"three pigeons in two holes", pigeonholeClauses(3, 2), false},
+}
+
+// checkSimplifier runs simplify on a copy of each simplifier case and checks
+// that the copy keeps the case's answer and that the stack extends its
+// models to the case. It returns the sum of what simplify returned.
+func checkSimplifier(t *testing.T, simplify func(*CNF, *ReconstructionStack) int) int {
+	t.Helper()
+	total := 0
+	for _, tc := range simplifierCases {
+		t.Run(tc.description, func(t *testing.T) {
+			original := buildCNF(tc.clauses)
+			reduced := copyClauses(original)
+			stack := NewReconstructionStack()
+			total += simplify(reduced, stack)
+
+			result := NewDenseSolver().Solve(reduced)
+			if result.Satisfiable != tc.expectedSat {
+				t.Fatalf("Expected satisfiable=%v, got %v", tc.expectedSat, result.Status)
+			}
+			if result.Satisfiable {
+				if err := CheckModel(original, stack.Extend(result.Assignment)); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+	return total
+}
+
+func TestEquivalentLiteralSubstitution(t *testing.T) {
+	// A ≡ ¬B and B ≡ C leave A with the class of B and C
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	cnf.AddClause(NewClause(L("A", true), L("B", true)))
+	cnf.AddClause(NewClause(L("B", true), L("C", false)))
+	cnf.AddClause(NewClause(L("B", false), L("C", true)))
+	cnf.AddClause(NewClause(L("C", false), L("D", false)))
+	stack := NewReconstructionStack()
+	els := NewEquivalentLiteralSubstitution()
+	els.SetReconstructionStack(stack)
+	if n := els.Substitute(cnf, make(Assignment)); n != 2 {
+		t.Fatalf("Expected 2 substituted variables, got %d", n)
+	}
+	if len(cnf.Clauses) != 1 || fmt.Sprint(cnf.Clauses[0].Literals) != fmt.Sprint([]Literal{L("A", true), L("D", false)}) {
+		t.Fatalf("Expected (¬A ∨ D), got %v", cnf.Clauses)
+	}
+	if strings.Join(cnf.Variables, " ") != "A D" {
+		t.Errorf("Expected variables A D, got %v", cnf.Variables)
+	}
+	model := stack.Extend(Assignment{"A": false, "D": false})
+	if model["B"] != true || model["C"] != true {
+		t.Errorf("Expected B and C true, got %v", model)
+	}
+
+	// A class with a literal and its negation is left to the search
+	cnf = NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	cnf.AddClause(NewClause(L("A", true), L("B", true)))
+	cnf.AddClause(NewClause(L("A", true), L("B", false)))
+	cnf.AddClause(NewClause(L("A", false), L("B", true)))
+	if n := NewEquivalentLiteralSubstitution().Substitute(cnf, make(Assignment)); n != 0 || len(cnf.Clauses) != 4 {
+		t.Errorf("Expected no substitution, got %d and %v", n, cnf.Clauses)
+	}
+}
+
+func TestEquivalentLiteralSubstitution_Equisatisfiable(t *testing.T) {
+	substituted := checkSimplifier(t, func(cnf *CNF, stack *ReconstructionStack) int {
+		els := NewEquivalentLiteralSubstitution()
+		els.SetReconstructionStack(stack)
+		return els.Substitute(cnf, make(Assignment))
+	})
+	if substituted == 0 {
+		t.Error("Expected some variables to be substituted")
+	}
+}
+
+func TestBlockedClauseElimination(t *testing.T) {
+	blocked := checkSimplifier(t, func(cnf *CNF, stack *ReconstructionStack) int {
+		bce := NewBlockedClauseElimination()
+		bce.SetReconstructionStack(stack)
+		return bce.Eliminate(cnf, make(Assignment))
+	})
+	if blocked == 0 {
+		t.Error("Expected some clauses to be blocked")
+	}
+
+	// A fixed variable keeps its clauses
+	cnf := NewCNF()
+	cnf.AddClause(NewClause(L("A", false), L("B", false)))
+	if n := NewBlockedClauseElimination().Eliminate(cnf, Assignment{"B": true}); n != 0 {
+		t.Errorf("Expected no elimination, got %d", n)
+	}
+}
+
+func TestBoundedVariableAddition(t *testing.T) {
+	// The pairwise at-most-one over six literals: 15 clauses become 12
+	// with one fresh variable, and more with further rounds
+	names := []string{"A", "B", "C", "D", "E", "F"}
+	original := NewCNF()
+	for i := range names {
+		for j := i + 1; j < len(names); j++ {
+			original.AddClause(NewClause(L(names[i], true), L(names[j], true)))
+		}
+	}
+	reduced := copyClauses(original)
+	added, saved := NewBoundedVariableAddition().Add(reduced, make(Assignment))
+	if added == 0 || saved <= 0 || len(reduced.Clauses) != len(original.Clauses)-saved {
+		t.Fatalf("Expected fewer clauses, got %d fresh variables and %d clauses", added, len(reduced.Clauses))
+	}
+
+	// The at-most-one constraint is kept, projected on the original names
+	all := append([]string(nil), reduced.Variables...)
+	allModels(names, func(model Assignment) {
+		expected := CheckModel(original, model) == nil
+		found := false
+		allModels(all[len(names):], func(fresh Assignment) {
+			for name, value := range model {
+				fresh[name] = value
+			}
+			found = found || CheckModel(reduced, fresh) == nil
+		})
+		if found != expected {
+			t.Fatalf("Model %v: expected %v, got %v", model, expected, found)
+		}
+	})
+}
+
+func TestBoundedVariableAddition_ReservedNames(t *testing.T) {
+	cnf := NewCNF()
+	names := []string{"A", "B", "C", "D", "E"}
+	for i := range names {
+		for j := i + 1; j < len(names); j++ {
+			cnf.AddClause(NewClause(L(names[i], true), L(names[j], true)))
+		}
+	}
+	// _bva0 was eliminated and _bva1 is an input variable without clauses
+	stack := NewReconstructionStack()
+	stack.Push(L("_bva0", false), []Literal{L("_bva0", false), L("A", false)})
+	stack.reserve("_bva1")
+	bva := NewBoundedVariableAddition()
+	bva.SetReconstructionStack(stack)
+	if added, _ := bva.Add(cnf, make(Assignment)); added == 0 {
+		t.Fatal("Expected a fresh variable")
+	}
+	for _, variable := range cnf.Variables {
+		if variable == "_bva0" || variable == "_bva1" {
+			t.Errorf("Fresh variable reuses the reserved name %s", variable)
+		}
+	}
+}
+
+func TestBoundedVariableAddition_Equisatisfiable(t *testing.T) {
+	added := checkSimplifier(t, func(cnf *CNF, _ *ReconstructionStack) int {
+		n, _ := NewBoundedVariableAddition().Add(cnf, make(Assignment))
+		return n
+	})
+	if added == 0 {
+		t.Error("Expected some variables to be added")
+	}
+}
+
+func TestCDCLSolver_SimplifierInprocessing(t *testing.T) {
+	config := DefaultInprocessConfig()
+	config.EnableInitialInprocess = true
+	config.EnableEquivalentLiterals = true
+	config.EnableBlockedClauseElim = true
+	config.EnableVariableAddition = true
+	var stats InprocessStatistics
+	for _, tc := range simplifierCases {
+		t.Run(tc.description, func(t *testing.T) {
+			original := buildCNF(tc.clauses)
+			c := NewCDCLSolver()
+			c.SetInprocessConfig(config)
+			c.walkSolver = nil
+			c.SetModelCheck(true)
+			result := c.Solve(copyClauses(original))
+			if result.Error != nil {
+				t.Fatalf("Solver error: %v", result.Error)
+			}
+			if result.Satisfiable != tc.expectedSat {
+				t.Fatalf("Expected satisfiable=%v, got %v", tc.expectedSat, result.Status)
+			}
+			if result.Satisfiable {
+				if err := CheckModel(original, result.Assignment); err != nil {
+					t.Error(err)
+				}
+			}
+			s := c.inprocessor.GetStatistics()
+			stats.VariablesSubstituted += s.VariablesSubstituted
+			stats.BlockedClausesEliminated += s.BlockedClausesEliminated
+		})
+	}
+	if stats.VariablesSubstituted == 0 || stats.BlockedClausesEliminated == 0 {
+		t.Errorf("Expected substitution and blocked clauses, got %+v", stats)
+	}
+}
+
+func TestProofDRAT_Simplifiers(t *testing.T) {
+	config := DefaultInprocessConfig()
+	config.EnableInitialInprocess = true
+	config.EnableEquivalentLiterals = true
+	config.EnableBlockedClauseElim = true
+	config.EnableVariableAddition = true
+	for _, tc := range simplifierCases {
+		if tc.expectedSat {
+			continue
+		}
+		t.Run(tc.description, func(t *testing.T) {
+			var proof bytes.Buffer
+			solver := NewCDCLSolver()
+			solver.SetInprocessConfig(config)
+			solver.SetProofOutput(&proof, ProofDRAT)
+			result := solver.Solve(buildCNF(tc.clauses))
+			if result.Error != nil || result.Satisfiable {
+				t.Fatalf("Expected UNSAT, got %v (%v)", result.Status, result.Error)
+			}
+			check, err := CheckProof(buildCNF(tc.clauses), &proof, ProofDRAT)
+			if err != nil || !check.Verified {
+				t.Fatalf("Proof rejected: %+v (%v)", check, err)
+			}
+		})
+	}
+}
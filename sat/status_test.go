@@ -0,0 +1,103 @@
+package sat
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xDarkicex/logic/core"
+	"github.com/xDarkicex/memory"
+)
+
+func TestSolverResult_Timeout(t *testing.T) {
+	for _, s := range []Solver{NewCDCLSolver(), NewDPLLSolver(), NewDenseSolver()} {
+		result := s.SolveWithTimeout(pigeonhole(10, 9), 20*time.Millisecond)
+		if result.Status != StatusUnknown || result.Reason != ReasonTimeout || result.Satisfiable {
+			t.Errorf("%s: expected UNKNOWN (timeout), got %v (%v, %v)", s.Name(), result.Status, result.Reason, result.Error)
+		}
+	}
+}
+
+func TestSolverResult_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	for _, s := range contextSolvers() {
+		result := s.SolveContext(ctx, pigeonhole(10, 9))
+		if result.Status != StatusUnknown || result.Reason != ReasonCancelled {
+			t.Errorf("%s: expected UNKNOWN (cancelled), got %v (%v)", s.Name(), result.Status, result.Reason)
+		}
+	}
+}
+
+func TestCDCLSolver_ConflictLimit(t *testing.T) {
+	s := NewCDCLSolver()
+	s.SetConflictLimit(10)
+	result := s.Solve(pigeonhole(8, 7))
+	if result.Status != StatusUnknown || result.Reason != ReasonConflictLimit || result.Error == nil {
+		t.Errorf("Expected UNKNOWN (conflict limit), got %v (%v, %v)", result.Status, result.Reason, result.Error)
+	}
+
+	// A limit the refutation stays under leaves the answer unchanged
+	s = NewCDCLSolver()
+	s.SetConflictLimit(1000)
+	if result := s.Solve(pigeonhole(3, 2)); result.Status != StatusUNSAT || result.Reason != ReasonNone {
+		t.Errorf("Expected UNSAT, got %v (%v)", result.Status, result.Error)
+	}
+}
+
+func TestRecoverMemory(t *testing.T) {
+	result := recoverMemory("Test", memory.ErrPoolExhausted)
+	result.setStatus()
+	if result.Status != StatusUnknown || result.Reason != ReasonMemory {
+		t.Errorf("Expected UNKNOWN (memory), got %v (%v)", result.Status, result.Reason)
+	}
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("Expected the unrelated panic to propagate, got %v", r)
+		}
+	}()
+	recoverMemory("Test", "boom")
+}
+
+// stubSolver returns a fixed result, like a solver written before Status
+type stubSolver struct {
+	DPLLSolver
+	result SolverResult
+}
+
+func (s *stubSolver) Solve(*CNF) *SolverResult {
+	result := s.result
+	return &result
+}
+
+func TestSATSystem_EvaluateStatus(t *testing.T) {
+	system := NewSATSystemWithSolver(&stubSolver{result: SolverResult{Satisfiable: true}})
+	data, err := system.Evaluate("A | B", core.NewEvaluationContext())
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if status := data.(map[string]interface{})["status"]; status != StatusSAT {
+		t.Errorf("Expected status SAT for a solver without Status, got %v", status)
+	}
+
+	timedOut := SolverResult{Error: errors.New("timeout exceeded"), Reason: ReasonTimeout}
+	system = NewSATSystemWithSolver(&stubSolver{result: timedOut})
+	if _, err := system.Evaluate("A | B", core.NewEvaluationContext()); err == nil {
+		t.Error("Expected an error for an undecided formula")
+	}
+}
+
+func TestTheorySolver_Reason(t *testing.T) {
+	ts := NewTheorySolver(1, testPool(t))
+	ts.AddClause([]int32{0})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, status := ts.SolveContext(ctx); status != StatusUnknown || ts.Reason() != ReasonCancelled {
+		t.Errorf("Expected UNKNOWN (cancelled), got %v (%v)", status, ts.Reason())
+	}
+	if _, status := ts.Solve(); status != StatusSAT || ts.Reason() != ReasonNone {
+		t.Errorf("Expected SAT, got %v (%v)", status, ts.Reason())
+	}
+}
@@ -56,14 +56,18 @@ func (s *SATSystemImpl) Evaluate(expr string, ctx core.EvaluationContext) (inter
 		}
 	}
 
-	// Solve
+	// Solve; an undecided formula is an error, never a false "satisfiable"
 	result := s.solver.Solve(cnf)
+	if result.Error == nil && result.Status == StatusUnknown {
+		result.setStatus() // Custom solvers may leave Status unset
+	}
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, unknownError("SATSystem.Evaluate", result)
 	}
 
 	// Return structured result
 	return map[string]interface{}{
+		"status":      result.Status,
 		"satisfiable": result.Satisfiable,
 		"assignment":  result.Assignment,
 		"statistics":  result.Statistics,
@@ -110,3 +114,13 @@ func (s *SATSystemImpl) VerifySolution(expr string, assignment Assignment) (bool
 	result, err := ast.Evaluate(ctx)
 	return result, err
 }
+
+// unknownError describes why a solve ended with StatusUnknown
+func unknownError(op string, result *SolverResult) error {
+	reason := result.Reason
+	if reason == ReasonNone {
+		reason = reasonOf(result.Error)
+	}
+	return core.NewLogicError("sat", op,
+		fmt.Sprintf("formula undecided (%s): %v", reason, result.Error))
+}
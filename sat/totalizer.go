@@ -0,0 +1,55 @@
+package sat
+
+// Totalizer is the totalizer encoding of the number of true literals among
+// its inputs (Bailleux & Boufkhad, 2003). A binary tree of unary counters
+// sums the inputs; Outputs[j] is forced true whenever at least j+1 inputs
+// are true, so assuming ¬Outputs[k] enforces "at most k inputs are true".
+//
+// Only that upward direction is encoded, which is all that upper bounds
+// need: an output may still be true when fewer inputs are.
+type Totalizer struct {
+	Inputs  []Literal
+	Outputs []Literal
+	Clauses []*Clause // Clauses defining the outputs, to be added to the formula
+}
+
+// NewTotalizer encodes the sum of inputs. newVar is called once for each
+// auxiliary variable and must return a fresh variable name each time.
+func NewTotalizer(inputs []Literal, newVar func() string) *Totalizer {
+	t := &Totalizer{Inputs: inputs}
+	t.Outputs = t.encode(inputs, newVar)
+	return t
+}
+
+// encode returns the unary count of inputs, adding the clauses that define
+// it
+func (t *Totalizer) encode(inputs []Literal, newVar func() string) []Literal {
+	if len(inputs) == 1 {
+		return inputs
+	}
+	left := t.encode(inputs[:len(inputs)/2], newVar)
+	right := t.encode(inputs[len(inputs)/2:], newVar)
+
+	sum := make([]Literal, len(inputs))
+	for i := range sum {
+		sum[i] = Literal{Variable: newVar()}
+	}
+	// left ≥ i ∧ right ≥ j → sum ≥ i+j
+	for i := 0; i <= len(left); i++ {
+		for j := 0; j <= len(right); j++ {
+			if i+j == 0 || (i > 0 && j > 0 && left[i-1].Equals(right[j-1].Negate())) {
+				continue // Nothing to encode, or a tautology
+			}
+			lits := make([]Literal, 0, 3)
+			if i > 0 {
+				lits = append(lits, left[i-1].Negate())
+			}
+			if j > 0 {
+				lits = append(lits, right[j-1].Negate())
+			}
+			lits = append(lits, sum[i+j-1])
+			t.Clauses = append(t.Clauses, NewClause(lits...))
+		}
+	}
+	return sum
+}
@@ -18,7 +18,6 @@ type TrailEntry struct {
 type DecisionTrailImpl struct {
 	// Trail entries in chronological order
 	trail []TrailEntry
-	arena *memory.Arena
 
 	// Fast lookup maps for O(1) operations
 	varToIndex  map[string]int // Variable -> index in trail
@@ -32,17 +31,23 @@ type DecisionTrailImpl struct {
 	// Reason tracking for conflict analysis and CDCL
 	reasons map[string]*Clause
 	levels  map[string]int
+
+	pool *memory.Pool // Backs the trail and the slices returned by queries
 }
 
+// trailInitCap is the number of entries allocated for a new trail
+const trailInitCap = 64
+
 // NewDecisionTrail creates a new advanced decision trail (primary constructor)
 func NewDecisionTrail() *DecisionTrailImpl {
-	arena, err := memory.NewArena(8 * 1024 * 1024) // 8MB starting capacity
-	if err != nil {
-		panic(err)
-	}
+	return newDecisionTrail(nil)
+}
+
+// newDecisionTrail creates a trail allocating from pool
+func newDecisionTrail(pool *memory.Pool) *DecisionTrailImpl {
+	pool = poolOr(pool)
 	return &DecisionTrailImpl{
-		arena:        arena,
-		trail:        memory.MustArenaSlice[TrailEntry](arena, 1000), // Pre-allocate for performance
+		trail:        memory.MustPoolSlice[TrailEntry](pool, trailInitCap),
 		varToIndex:   make(map[string]int),
 		levelStarts:  make(map[int]int),
 		reasons:      make(map[string]*Clause),
@@ -50,6 +55,7 @@ func NewDecisionTrail() *DecisionTrailImpl {
 		currentLevel: 0,
 		trailSize:    0,
 		maxLevel:     0,
+		pool:         pool,
 	}
 }
 
@@ -89,7 +95,11 @@ func (t *DecisionTrailImpl) Assign(variable string, value bool, level int, reaso
 	if t.trailSize < len(t.trail) {
 		t.trail[t.trailSize] = entry
 	} else {
-		t.trail = memory.ArenaAppend(t.arena, t.trail, entry)
+		if len(t.trail) == cap(t.trail) {
+			grown := memory.MustPoolSlice[TrailEntry](t.pool, 2*cap(t.trail)+trailInitCap)
+			t.trail = append(grown, t.trail...)
+		}
+		t.trail = append(t.trail, entry)
 	}
 
 	// Update fast lookup maps for O(1) access
@@ -113,7 +123,7 @@ func (t *DecisionTrailImpl) Backtrack(level int) []string {
 	}
 
 	// Collect unassigned variables efficiently
-	unassigned := memory.MustPoolSlice[string](satPool, t.trailSize-backtrackIndex)
+	unassigned := memory.MustPoolSlice[string](t.pool, t.trailSize-backtrackIndex)
 	for i := backtrackIndex; i < t.trailSize; i++ {
 		variable := t.trail[i].Variable
 		unassigned = append(unassigned, variable)
@@ -196,19 +206,15 @@ func (t *DecisionTrailImpl) Clear() {
 		delete(t.levels, k)
 	}
 
-	// Keep pre-allocated trail slice for reuse
-	if t.arena != nil {
-		t.arena.Reset()
-		t.trail = memory.MustArenaSlice[TrailEntry](t.arena, 1000)
-	}
+	// Keep the trail array for reuse
+	t.trail = t.trail[:0]
 }
 
-// Close releases off-heap memory
+// Close drops the trail's entries. Their memory belongs to the pool and
+// is released with it.
 func (t *DecisionTrailImpl) Close() {
-	if t.arena != nil {
-		t.arena.Free()
-		t.arena = nil
-	}
+	t.Clear()
+	t.trail = nil
 }
 
 // GetTrailAtLevel returns all assignments at given level (advanced feature)
@@ -232,7 +238,7 @@ func (t *DecisionTrailImpl) GetTrailAtLevel(level int) []TrailEntry {
 		return []TrailEntry{}
 	}
 
-	entries := memory.MustPoolSlice[TrailEntry](satPool, endIdx-startIdx)[:endIdx-startIdx]
+	entries := memory.MustPoolSlice[TrailEntry](t.pool, endIdx-startIdx)[:endIdx-startIdx]
 	copy(entries, t.trail[startIdx:endIdx])
 	return entries
 }
@@ -241,7 +247,7 @@ func (t *DecisionTrailImpl) GetTrailAtLevel(level int) []TrailEntry {
 // This is crucial for conflict analysis in CDCL algorithms
 func (t *DecisionTrailImpl) GetDecisionVariablesAtLevel(level int) []string {
 	entries := t.GetTrailAtLevel(level)
-	decisions := memory.MustPoolSlice[string](satPool, len(entries)) // Pre-allocate reasonable capacity
+	decisions := memory.MustPoolSlice[string](t.pool, len(entries)) // Pre-allocate reasonable capacity
 
 	for _, entry := range entries {
 		if entry.Reason == nil { // Decision variables have no reason clause
@@ -255,7 +261,7 @@ func (t *DecisionTrailImpl) GetDecisionVariablesAtLevel(level int) []string {
 // GetImplicationChain returns the implication chain for a variable
 // Useful for debugging, learning, and conflict analysis
 func (t *DecisionTrailImpl) GetImplicationChain(variable string) []TrailEntry {
-	chain := memory.MustPoolSlice[TrailEntry](satPool, 10) // Pre-allocate reasonable chain size
+	chain := memory.MustPoolSlice[TrailEntry](t.pool, 10) // Pre-allocate reasonable chain size
 	visited := make(map[string]bool)   // Prevent infinite loops
 
 	current := variable
@@ -319,7 +325,7 @@ func (t *DecisionTrailImpl) GetLevelSize(level int) int {
 
 // GetAllLevels returns all active decision levels (utility method)
 func (t *DecisionTrailImpl) GetAllLevels() []int {
-	levels := memory.MustPoolSlice[int](satPool, len(t.levelStarts))
+	levels := memory.MustPoolSlice[int](t.pool, len(t.levelStarts))
 	for level := range t.levelStarts {
 		levels = append(levels, level)
 	}
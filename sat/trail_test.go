@@ -107,3 +107,23 @@ func TestDecisionTrailImpl_AdvancedGetters(t *testing.T) {
 		t.Errorf("Clear failed")
 	}
 }
+
+func TestDecisionTrailImpl_GrowsInPool(t *testing.T) {
+	pool := testPool(t)
+	trail := newDecisionTrail(pool)
+	for i := 0; i < 3000; i++ {
+		trail.Assign(varName(i), i%2 == 0, i/100, nil)
+	}
+	if trail.GetTrailSize() != 3000 || trail.GetLevel(varName(2999)) != 29 {
+		t.Fatalf("Expected 3000 entries up to level 29, got %d", trail.GetTrailSize())
+	}
+	trail.Backtrack(10)
+	trail.Clear()
+	before := pool.Stats().Allocated
+	for i := 0; i < 3000; i++ {
+		trail.Assign(varName(i), true, 0, nil)
+	}
+	if after := pool.Stats().Allocated; after != before {
+		t.Errorf("Refilling a cleared trail allocated %d bytes", after-before)
+	}
+}
@@ -1,12 +1,15 @@
 package sat
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"sync"
 	"unsafe"
 
+	"github.com/xDarkicex/logic/core"
 	"github.com/xDarkicex/memory"
 )
 
@@ -44,13 +47,34 @@ func initAllocators() {
 }
 
 // ResetPool releases all SAT pool memory. Safe to call between compaction
-// cycles after all SAT solver results have been consumed.
+// cycles after all SAT solver results have been consumed. It invalidates
+// the arrays of every solver using the package pool; solvers, CNFs and
+// converters constructed with their own pool are unaffected and are
+// reclaimed by resetting or freeing that pool instead.
 func ResetPool() {
 	if satPool != nil {
 		satPool.Reset()
 	}
 }
 
+// literalPool returns where clauses made with pool keep their literals
+func literalPool(pool *memory.Pool) *memory.Pool {
+	if pool == nil || pool == satPool {
+		initAllocators()
+		return litPool
+	}
+	return pool
+}
+
+// poolOr returns pool, or the package pool if pool is nil
+func poolOr(pool *memory.Pool) *memory.Pool {
+	if pool == nil {
+		initAllocators()
+		return satPool
+	}
+	return pool
+}
+
 // Literal represents a boolean variable or its negation
 // Positive literal: Variable = "A", Negated = false
 // Negative literal: Variable = "A", Negated = true
@@ -90,21 +114,38 @@ type Clause struct {
 	Tier         int     // Clause tier classification (0=core, 1=mid, 2=local)
 	ConflictType string
 	Deleted      bool    // True if clause is logically deleted and waiting to be freed
+	owned        bool    // Allocated from a caller's pool by NewClauseIn
 }
 
 func NewClause(literals ...Literal) *Clause {
+	return NewClauseIn(nil, literals...)
+}
+
+// NewClauseIn is NewClause allocating the clause and its literals from
+// pool, which owns them: they stay valid until the pool is reset or freed,
+// and FreeClause leaves them alone. A nil pool or the package pool gives
+// the package allocators, as NewClause does.
+func NewClauseIn(pool *memory.Pool, literals ...Literal) *Clause {
 	initAllocators()
-	buf, err := clauseAlloc.Allocate()
-	if err != nil {
-		panic(fmt.Errorf("failed to allocate clause: %v", err))
-	}
-	// Zero memory just in case, though FreeList should give zeroed memory if needed
-	// memory package does not guarantee zeroing on reuse unless stated.
-	// We explicitly initialize all fields below.
-	c := (*Clause)(unsafe.Pointer(&buf[0]))
+	owned := pool != nil && pool != satPool
+	lp, scratch := litPool, satPool
+	var c *Clause
+	if owned {
+		c = memory.MustPoolAlloc[Clause](pool)
+		lp, scratch = pool, pool
+	} else {
+		buf, err := clauseAlloc.Allocate()
+		if err != nil {
+			panic(fmt.Errorf("failed to allocate clause: %v", err))
+		}
+		// Zero memory just in case, though FreeList should give zeroed memory if needed
+		// memory package does not guarantee zeroing on reuse unless stated.
+		// We explicitly initialize all fields below.
+		c = (*Clause)(unsafe.Pointer(&buf[0]))
+	}
 	
 	// Create off-heap slice
-	lits := memory.MustPoolSlice[Literal](litPool, len(literals))
+	lits := memory.MustPoolSlice[Literal](lp, len(literals))
 	lits = append(lits, literals...)
 	
 	// Sort literals to ensure deterministic behavior
@@ -117,7 +158,7 @@ func NewClause(literals ...Literal) *Clause {
 
 	// Remove duplicates or tautologies
 	if len(lits) > 0 {
-		unique := memory.MustPoolSlice[Literal](satPool, len(lits))[:0]
+		unique := memory.MustPoolSlice[Literal](scratch, len(lits))[:0]
 		unique = append(unique, lits[0])
 		for i := 1; i < len(lits); i++ {
 			prev := unique[len(unique)-1]
@@ -134,7 +175,7 @@ func NewClause(literals ...Literal) *Clause {
 		}
 
 		if len(unique) != len(lits) {
-			newLits := memory.MustPoolSlice[Literal](litPool, len(unique))
+			newLits := memory.MustPoolSlice[Literal](lp, len(unique))
 			newLits = append(newLits, unique...)
 			lits = newLits
 		}
@@ -148,11 +189,12 @@ func NewClause(literals ...Literal) *Clause {
 	c.Tier = 2
 	c.ConflictType = ""
 	c.Deleted = false
+	c.owned = owned
 	return c
 }
 
 func FreeClause(c *Clause) {
-	if c != nil {
+	if c != nil && !c.owned {
 		buf := unsafe.Slice((*byte)(unsafe.Pointer(c)), unsafe.Sizeof(Clause{}))
 		clauseAlloc.Deallocate(buf)
 	}
@@ -281,19 +323,37 @@ func (wc *WatchedClause) GetWatchedLiterals() []Literal {
 // It's a conjunction (AND) of clauses
 type CNF struct {
 	Clauses   []*Clause
-	Variables []string // All variables in the formula
-	nextID    int      // For generating unique clause IDs
+	Variables []string     // All variables in the formula
+	nextID    int          // For generating unique clause IDs
+	pool      *memory.Pool // Owner of the clauses made by NewClause
 }
 
 // NewCNF creates a new CNF formula
 func NewCNF() *CNF {
+	return NewCNFWithPool(nil)
+}
+
+// NewCNFWithPool creates a new CNF formula whose NewClause allocates from
+// pool. A nil pool means the package pools.
+func NewCNFWithPool(pool *memory.Pool) *CNF {
 	return &CNF{
-		Clauses:   memory.MustPoolSlice[*Clause](satPool, 0),
-		Variables: memory.MustPoolSlice[string](satPool, 0),
+		Clauses:   memory.MustPoolSlice[*Clause](poolOr(pool), 0),
+		Variables: memory.MustPoolSlice[string](poolOr(pool), 0),
 		nextID:    1,
+		pool:      pool,
 	}
 }
 
+// NewClause creates a clause in the formula's pool without adding it
+func (cnf *CNF) NewClause(literals ...Literal) *Clause {
+	return NewClauseIn(cnf.pool, literals...)
+}
+
+// Pool returns the pool the formula was created with, or nil
+func (cnf *CNF) Pool() *memory.Pool {
+	return cnf.pool
+}
+
 // AddClause adds a clause to the CNF formula
 func (cnf *CNF) AddClause(clause *Clause) {
 	clause.ID = cnf.nextID
@@ -390,12 +450,119 @@ func (a Assignment) ConflictsWith(clause *Clause) bool {
 	return unassignedCount == 0
 }
 
+// SolverStatus is the outcome of a solve
+type SolverStatus int
+
+const (
+	// StatusUnknown means the solver stopped before deciding the formula,
+	// for example because it was cancelled
+	StatusUnknown SolverStatus = iota
+	StatusSAT
+	StatusUNSAT
+)
+
+// String returns the status name
+func (s SolverStatus) String() string {
+	switch s {
+	case StatusSAT:
+		return "SAT"
+	case StatusUNSAT:
+		return "UNSAT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// UnknownReason says why a solve ended with StatusUnknown
+type UnknownReason int
+
+const (
+	ReasonNone          UnknownReason = iota // The formula was decided
+	ReasonTimeout                            // The SolveWithTimeout limit passed
+	ReasonConflictLimit                      // The solver's conflict limit was reached
+	ReasonMemory                             // A memory pool ran out; Reset the solver before reuse
+	ReasonCancelled                          // The SolveContext context was done
+	ReasonError                              // Any other error, see Error
+)
+
+// String returns the reason name
+func (r UnknownReason) String() string {
+	switch r {
+	case ReasonNone:
+		return "none"
+	case ReasonTimeout:
+		return "timeout"
+	case ReasonConflictLimit:
+		return "conflict limit"
+	case ReasonMemory:
+		return "memory"
+	case ReasonCancelled:
+		return "cancelled"
+	default:
+		return "error"
+	}
+}
+
 // SolverResult represents the result of SAT solving
 type SolverResult struct {
 	Satisfiable bool
 	Assignment  Assignment
 	Statistics  SolverStatistics
 	Error       error
+
+	// Status tells a proof of unsatisfiability apart from a solve that
+	// stopped early; Satisfiable is false in both cases. A StatusUnknown
+	// result always has an Error, and Reason says which kind it is.
+	Status SolverStatus
+	Reason UnknownReason
+
+	// FailedAssumptions is the subset of the assumptions passed to
+	// SolveAssuming that made the formula unsatisfiable. It is empty when
+	// the formula is unsatisfiable on its own.
+	FailedAssumptions []Literal
+}
+
+// setStatus derives a result's Status from Error and Satisfiable. Solvers
+// set Reason where they stop; errors without one are classified here.
+func (r *SolverResult) setStatus() {
+	switch {
+	case r.Error == nil && r.Satisfiable:
+		r.Status, r.Reason = StatusSAT, ReasonNone
+	case r.Error == nil:
+		r.Status, r.Reason = StatusUNSAT, ReasonNone
+	default:
+		r.Status, r.Satisfiable = StatusUnknown, false
+		if r.Reason == ReasonNone {
+			r.Reason = reasonOf(r.Error)
+		}
+	}
+}
+
+// reasonOf classifies an error that ended a solve
+func reasonOf(err error) UnknownReason {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ReasonCancelled
+	case errors.Is(err, memory.ErrPoolExhausted), errors.Is(err, memory.ErrArenaExhausted),
+		errors.Is(err, memory.ErrFreelistExhausted), errors.Is(err, memory.ErrMmapFailed):
+		return ReasonMemory
+	default:
+		return ReasonError
+	}
+}
+
+// recoverMemory turns r, a recovered panic from an exhausted memory pool
+// as raised by memory.MustPoolSlice, into an unknown result. Other panics
+// are raised again.
+func recoverMemory(op string, r any) *SolverResult {
+	err, ok := r.(error)
+	if !ok || reasonOf(err) != ReasonMemory {
+		panic(r)
+	}
+	return &SolverResult{
+		Error:  core.NewLogicError("sat", op, "memory exhausted: "+err.Error()),
+		Reason: ReasonMemory,
+	}
 }
 
 // SolverStatistics tracks solver performance metrics with LBD and inprocessing support
@@ -479,6 +646,9 @@ type InprocessResult struct {
 	SubsumptionsFound    int
 	VivificationsApplied int
 	FailedLiteralsFound  int
+	VariablesSubstituted int
+	BlockedClauses       int
+	VariablesAdded       int
 }
 
 // InprocessConfig holds configuration for inprocessing techniques
@@ -489,6 +659,10 @@ type InprocessConfig struct {
 	EnableFailedLitProbing bool
 	EnableInitialInprocess bool // Run inprocessing at start
 
+	EnableEquivalentLiterals bool // SCC-based equivalent literal substitution
+	EnableBlockedClauseElim  bool // Blocked clause elimination
+	EnableVariableAddition   bool // Bounded variable addition
+
 	VivificationMaxSize  int
 	VarElimMaxResolvent  int
 	ProbingMaxCandidates int
@@ -518,6 +692,14 @@ type InprocessStatistics struct {
 	TimeInVariableElim     int64
 	TimeInFailedLitProbing int64
 	TotalInprocessTime     int64
+
+	VariablesSubstituted     int64
+	BlockedClausesEliminated int64
+	VariablesAdded           int64
+	ClausesSavedByAddition   int64 // Net clause reduction of variable addition
+	TimeInSubstitution       int64
+	TimeInBlockedClauseElim  int64
+	TimeInVariableAddition   int64
 }
 
 // DefaultInprocessConfig returns sensible defaults with integration parameters
@@ -529,6 +711,10 @@ func DefaultInprocessConfig() InprocessConfig {
 		EnableFailedLitProbing: false, // More expensive, disabled by default
 		EnableInitialInprocess: false, // Usually not needed
 
+		EnableEquivalentLiterals: false, // Rewrites clauses, so a later SolveAssuming fails
+		EnableBlockedClauseElim:  false, // Rarely pays off next to BVE
+		EnableVariableAddition:   false, // Pays off on structured encodings only
+
 		VivificationMaxSize:  20,
 		VarElimMaxResolvent:  16,
 		ProbingMaxCandidates: 100,
@@ -546,6 +732,17 @@ func DefaultInprocessConfig() InprocessConfig {
 	}
 }
 
+// withoutElimination returns config with the techniques turned off that
+// remove variables or clauses and rely on model reconstruction. They only
+// preserve satisfiability, which is not enough when XOR, cardinality or PB
+// constraints, assumptions or later clauses still mention what they remove.
+func (config InprocessConfig) withoutElimination() InprocessConfig {
+	config.EnableVariableElim = false
+	config.EnableEquivalentLiterals = false
+	config.EnableBlockedClauseElim = false
+	return config
+}
+
 // ClauseDatabase manages learned clauses in a tiered structure for optimal performance.
 // Note: ClauseDatabase is NOT safe for concurrent use.
 type ClauseDatabase struct {
@@ -560,6 +757,7 @@ type ClauseDatabase struct {
 	maxSize             int           // Maximum database size before cleanup
 	totalClauses        int           // Total across tiers
 	bornAt              map[int]int64 // ClauseID -> conflict index when learned (only for recent)
+	pool                *memory.Pool  // Backs the tier slices
 
 	// Statistics
 	coreCount   int
@@ -570,15 +768,22 @@ type ClauseDatabase struct {
 
 // NewClauseDatabase creates an empty tiered database
 func NewClauseDatabase(maxSize int, recentProtectionAge int64) *ClauseDatabase {
+	return newClauseDatabase(nil, maxSize, recentProtectionAge)
+}
+
+// newClauseDatabase creates a database allocating from pool
+func newClauseDatabase(pool *memory.Pool, maxSize int, recentProtectionAge int64) *ClauseDatabase {
+	pool = poolOr(pool)
 	return &ClauseDatabase{
-		coreClauses:         memory.MustPoolSlice[*Clause](satPool, 1024)[:0],
-		midClauses:          memory.MustPoolSlice[*Clause](satPool, 2048)[:0],
-		localClauses:        memory.MustPoolSlice[*Clause](satPool, 4096)[:0],
-		recentClauses:       memory.MustPoolSlice[*Clause](satPool, 4096)[:0],
+		coreClauses:         memory.MustPoolSlice[*Clause](pool, 64)[:0],
+		midClauses:          memory.MustPoolSlice[*Clause](pool, 128)[:0],
+		localClauses:        memory.MustPoolSlice[*Clause](pool, 256)[:0],
+		recentClauses:       memory.MustPoolSlice[*Clause](pool, 256)[:0],
 		recentProtectionAge: recentProtectionAge,
 		maxSize:             maxSize,
 		totalClauses:        0,
 		bornAt:              make(map[int]int64),
+		pool:                pool,
 	}
 }
 
@@ -616,7 +821,7 @@ func (db *ClauseDatabase) Size() int { return db.totalClauses }
 
 // GetAllClauses returns a flat view over all tiers for stats/debug
 func (db *ClauseDatabase) GetAllClauses() []*Clause {
-	out := memory.MustPoolSlice[*Clause](satPool, db.totalClauses)[:0]
+	out := memory.MustPoolSlice[*Clause](db.pool, db.totalClauses)[:0]
 	out = append(out, db.coreClauses...)
 	out = append(out, db.midClauses...)
 	out = append(out, db.localClauses...)
@@ -742,10 +947,10 @@ func (db *ClauseDatabase) Clear() {
 }
 
 func (db *ClauseDatabase) Compact() {
-	db.coreClauses = compactSlice(db.coreClauses)
-	db.midClauses = compactSlice(db.midClauses)
-	db.localClauses = compactSlice(db.localClauses)
-	db.recentClauses = compactSlice(db.recentClauses)
+	db.coreClauses = compactSlice(db.pool, db.coreClauses)
+	db.midClauses = compactSlice(db.pool, db.midClauses)
+	db.localClauses = compactSlice(db.pool, db.localClauses)
+	db.recentClauses = compactSlice(db.pool, db.recentClauses)
 }
 
 // 4. Validation and Debugging Methods
@@ -802,8 +1007,8 @@ func (db *ClauseDatabase) ForcePromoteToTier(clause *Clause, tier int) bool {
 }
 
 // Helper function
-func compactSlice(clauses []*Clause) []*Clause {
-	result := memory.MustPoolSlice[*Clause](satPool, len(clauses))[:0]
+func compactSlice(pool *memory.Pool, clauses []*Clause) []*Clause {
+	result := memory.MustPoolSlice[*Clause](pool, len(clauses))[:0]
 	for _, clause := range clauses {
 		if clause != nil {
 			result = append(result, clause)
@@ -927,18 +1132,24 @@ func (x *XORClause) ToRegularClauses() []*Clause {
 	return clauses
 }
 
-// ExtendedCNF represents a CNF with both regular and XOR clauses
+// ExtendedCNF represents a CNF with regular, XOR, cardinality and
+// pseudo-Boolean constraints
 type ExtendedCNF struct {
-	*CNF                    // Embed regular CNF
-	XORClauses []*XORClause // XOR constraints
-	nextXORID  int          // For generating unique XOR clause IDs
+	*CNF                                    // Embed regular CNF
+	XORClauses         []*XORClause         // XOR constraints
+	CardinalityClauses []*CardinalityClause // At-most-K constraints
+	PBConstraints      []*PBConstraint      // Linear pseudo-Boolean constraints
+	nextXORID          int                  // For generating unique XOR clause IDs
+	nextCardinalityID  int                  // For generating unique cardinality clause IDs
+	nextPBID           int                  // For generating unique PB constraint IDs
 }
 
 // NewExtendedCNF creates a new extended CNF
 func NewExtendedCNF() *ExtendedCNF {
 	return &ExtendedCNF{
 		CNF:        NewCNF(),
-		XORClauses: memory.MustPoolSlice[*XORClause](satPool, 16)[:0],
+		// On the heap: the collector must see the XOR clauses it points to
+		XORClauses: make([]*XORClause, 0, 16),
 		nextXORID:  1,
 	}
 }
@@ -961,3 +1172,144 @@ func (ecnf *ExtendedCNF) AddXORClause(xorClause *XORClause) {
 func (ecnf *ExtendedCNF) HasXORClauses() bool {
 	return len(ecnf.XORClauses) > 0
 }
+
+// AddCardinalityClause adds a cardinality constraint to the formula
+func (ecnf *ExtendedCNF) AddCardinalityClause(card *CardinalityClause) {
+	ecnf.nextCardinalityID++
+	card.ID = ecnf.nextCardinalityID
+	ecnf.CardinalityClauses = append(ecnf.CardinalityClauses, card)
+
+	// Track variables
+	for _, lit := range card.Literals {
+		if !ecnf.containsVariable(lit.Variable) {
+			ecnf.Variables = append(ecnf.Variables, lit.Variable)
+		}
+	}
+}
+
+// HasCardinalityClauses returns true if formula contains cardinality
+// constraints
+func (ecnf *ExtendedCNF) HasCardinalityClauses() bool {
+	return ecnf != nil && len(ecnf.CardinalityClauses) > 0
+}
+
+// AddPBConstraint adds a normalised pseudo-Boolean constraint to the
+// formula
+func (ecnf *ExtendedCNF) AddPBConstraint(pb *PBConstraint) {
+	ecnf.nextPBID++
+	pb.ID = ecnf.nextPBID
+	ecnf.PBConstraints = append(ecnf.PBConstraints, pb)
+
+	// Track variables
+	for _, term := range pb.Terms {
+		if !ecnf.containsVariable(term.Literal.Variable) {
+			ecnf.Variables = append(ecnf.Variables, term.Literal.Variable)
+		}
+	}
+}
+
+// HasPBConstraints returns true if formula contains pseudo-Boolean
+// constraints
+func (ecnf *ExtendedCNF) HasPBConstraints() bool {
+	return ecnf != nil && len(ecnf.PBConstraints) > 0
+}
+
+// hasConstraints reports whether the formula has constraints besides its
+// clauses. A nil formula has none.
+func (ecnf *ExtendedCNF) hasConstraints() bool {
+	return ecnf != nil && (len(ecnf.XORClauses) > 0 || len(ecnf.CardinalityClauses) > 0 || len(ecnf.PBConstraints) > 0)
+}
+
+// CardinalityClause represents the constraint that at most K of its
+// literals are true. The CDCL solver propagates it natively instead of
+// through a clausal encoding.
+type CardinalityClause struct {
+	Literals []Literal
+	K        int
+	ID       int // Unique identifier
+}
+
+// NewAtMostK creates the constraint that at most k of lits are true
+func NewAtMostK(lits []Literal, k int) *CardinalityClause {
+	return &CardinalityClause{
+		Literals: append([]Literal(nil), lits...),
+		K:        k,
+	}
+}
+
+// NewAtLeastK creates the constraint that at least k of lits are true,
+// stated as at most len(lits)-k of their negations being true
+func NewAtLeastK(lits []Literal, k int) *CardinalityClause {
+	negated := make([]Literal, len(lits))
+	for i, lit := range lits {
+		negated[i] = Literal{Variable: lit.Variable, Negated: !lit.Negated}
+	}
+	return &CardinalityClause{
+		Literals: negated,
+		K:        len(lits) - k,
+	}
+}
+
+// Count returns how many literals assignment makes true and how many it
+// leaves unassigned
+func (card *CardinalityClause) Count(assignment Assignment) (satisfied, unassigned int) {
+	for _, lit := range card.Literals {
+		if value, assigned := assignment[lit.Variable]; !assigned {
+			unassigned++
+		} else if value != lit.Negated {
+			satisfied++
+		}
+	}
+	return satisfied, unassigned
+}
+
+// IsSatisfied checks the constraint against a possibly partial assignment.
+// The first result reports whether every extension of assignment agrees,
+// the second whether the constraint then holds.
+func (card *CardinalityClause) IsSatisfied(assignment Assignment) (bool, bool) {
+	satisfied, unassigned := card.Count(assignment)
+	if satisfied > card.K {
+		return true, false
+	}
+	if satisfied+unassigned <= card.K {
+		return true, true
+	}
+	return false, false
+}
+
+// String returns string representation of cardinality clause
+func (card *CardinalityClause) String() string {
+	terms := make([]string, len(card.Literals))
+	for i, lit := range card.Literals {
+		terms[i] = lit.String()
+	}
+	return fmt.Sprintf("(%s ≤ %d)", strings.Join(terms, " + "), card.K)
+}
+
+// WeightedCNF represents a weighted partial MAX-SAT instance: every hard
+// clause must be satisfied, and each violated soft clause costs its weight
+type WeightedCNF struct {
+	Hard    *CNF
+	Soft    *CNF
+	Weights []int64 // Weights[i] is the cost of violating Soft.Clauses[i]
+}
+
+// NewWeightedCNF creates a new weighted CNF with no clauses
+func NewWeightedCNF() *WeightedCNF {
+	return &WeightedCNF{
+		Hard:    NewCNF(),
+		Soft:    NewCNF(),
+		Weights: memory.MustPoolSlice[int64](satPool, 0),
+	}
+}
+
+// AddHard adds a clause that must be satisfied
+func (w *WeightedCNF) AddHard(clause *Clause) {
+	w.Hard.AddClause(clause)
+}
+
+// AddSoft adds a clause that costs weight when violated
+func (w *WeightedCNF) AddSoft(clause *Clause, weight int64) {
+	w.Soft.AddClause(clause)
+	w.Weights = append(w.Weights, weight)
+}
@@ -1,11 +1,16 @@
 package sat
 
-import "github.com/xDarkicex/memory"
+import (
+	"context"
+
+	"github.com/xDarkicex/memory"
+)
 
 const (
 	walkMaxScoreTable = 20
 	walkDefaultCB     = 2.0
 	walkDefaultFlips  = 10000
+	walkCheckInterval = 256 // Flips between cancellation checks
 )
 
 // walkCounter tracks per-clause satisfaction state during WalkSAT.
@@ -40,12 +45,21 @@ type WalkSolver struct {
 
 	maxFlips int64
 	flips    int64
+
+	ctx context.Context // Context of the running SolveContext call
+
+	pool *memory.Pool
 }
 
 // NewWalkSolver creates a WalkSAT solver with default parameters.
 // Backing arrays are allocated lazily from satPool on the first Solve call.
 func NewWalkSolver() *WalkSolver {
+	return newWalkSolver(nil)
+}
+
+func newWalkSolver(pool *memory.Pool) *WalkSolver {
 	return &WalkSolver{
+		pool:     poolOr(pool),
 		varIndex: make(map[string]int),
 		maxFlips: walkDefaultFlips,
 		rng:      0x9e3779b97f4a7c15,
@@ -74,11 +88,21 @@ func (w *WalkSolver) Solve(clauses []*Clause) bool {
 		if w.unsatSz == 0 {
 			return true
 		}
+		if w.flips%walkCheckInterval == 0 && contextDone(w.ctx) {
+			return false
+		}
 		w.walkStep()
 	}
 	return false
 }
 
+// setContext makes Solve give up once ctx is done; nil never stops it
+func (w *WalkSolver) setContext(ctx context.Context) {
+	if w != nil {
+		w.ctx = ctx
+	}
+}
+
 // initFromClauses builds the variable name→index mapping.
 // Uses varIndex as a temporary set then rebuilds with proper indices.
 // CC=3.
@@ -92,7 +116,7 @@ func (w *WalkSolver) initFromClauses(clauses []*Clause) {
 	}
 
 	w.numVars = len(w.varIndex)
-	w.varNames = memory.MustPoolSlice[string](satPool, w.numVars)[:w.numVars]
+	w.varNames = memory.MustPoolSlice[string](w.pool, w.numVars)[:w.numVars]
 
 	for k := range w.varIndex {
 		delete(w.varIndex, k)
@@ -109,14 +133,14 @@ func (w *WalkSolver) initFromClauses(clauses []*Clause) {
 	}
 
 	n := w.numVars
-	w.values = memory.MustPoolSlice[int8](satPool, n)[:n]
-	w.bestValues = memory.MustPoolSlice[int8](satPool, n)[:n]
+	w.values = memory.MustPoolSlice[int8](w.pool, n)[:n]
+	w.bestValues = memory.MustPoolSlice[int8](w.pool, n)[:n]
 	for i := range w.values {
 		w.values[i] = -1
 		w.bestValues[i] = -1
 	}
 
-	w.scoreTable = memory.MustPoolSlice[float64](satPool, walkMaxScoreTable)[:walkMaxScoreTable]
+	w.scoreTable = memory.MustPoolSlice[float64](w.pool, walkMaxScoreTable)[:walkMaxScoreTable]
 	w.buildScoreTable()
 }
 
@@ -134,7 +158,7 @@ func (w *WalkSolver) buildScoreTable() {
 // CC=3.
 func (w *WalkSolver) buildOccurrences(clauses []*Clause) {
 	numLits := 2 * w.numVars
-	counts := memory.MustPoolSlice[int](satPool, numLits)[:numLits]
+	counts := memory.MustPoolSlice[int](w.pool, numLits)[:numLits]
 
 	for ci, c := range clauses {
 		for _, lit := range c.Literals {
@@ -143,10 +167,10 @@ func (w *WalkSolver) buildOccurrences(clauses []*Clause) {
 		_ = ci
 	}
 
-	w.occurrences = memory.MustPoolSlice[[]int](satPool, numLits)[:numLits]
+	w.occurrences = memory.MustPoolSlice[[]int](w.pool, numLits)[:numLits]
 	for i := range w.occurrences {
 		if counts[i] > 0 {
-			w.occurrences[i] = memory.MustPoolSlice[int](satPool, counts[i])[:0]
+			w.occurrences[i] = memory.MustPoolSlice[int](w.pool, counts[i])[:0]
 		}
 	}
 
@@ -165,9 +189,9 @@ func (w *WalkSolver) buildOccurrences(clauses []*Clause) {
 func (w *WalkSolver) initCounters(clauses []*Clause) {
 	nc := len(clauses)
 	w.clauses = clauses
-	w.counters = memory.MustPoolSlice[walkCounter](satPool, nc)[:nc]
-	w.unsat = memory.MustPoolSlice[int](satPool, nc)[:0]
-	w.scores = memory.MustPoolSlice[float64](satPool, 32)[:0]
+	w.counters = memory.MustPoolSlice[walkCounter](w.pool, nc)[:nc]
+	w.unsat = memory.MustPoolSlice[int](w.pool, nc)[:0]
+	w.scores = memory.MustPoolSlice[float64](w.pool, 32)[:0]
 
 	for _, c := range clauses {
 		for _, lit := range c.Literals {
@@ -208,7 +232,7 @@ func (w *WalkSolver) walkStep() {
 	clause := w.clauses[ci]
 
 	if cap(w.scores) < len(clause.Literals) {
-		w.scores = memory.MustPoolSlice[float64](satPool, len(clause.Literals))[:0]
+		w.scores = memory.MustPoolSlice[float64](w.pool, len(clause.Literals))[:0]
 	}
 	w.scores = w.scores[:0]
 
@@ -1,6 +1,7 @@
 package sat
 
 import (
+	"context"
 	"testing"
 )
 
@@ -60,6 +61,21 @@ func TestWalkSolverUnsatHitsLimit(t *testing.T) {
 	}
 }
 
+func TestWalkSolverStopsWhenCancelled(t *testing.T) {
+	w := NewWalkSolver()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w.setContext(ctx)
+	c1 := NewClause(Literal{Variable: "A", Negated: false})
+	c2 := NewClause(Literal{Variable: "A", Negated: true})
+	if w.Solve([]*Clause{c1, c2}) {
+		t.Error("unsatisfiable should not be solved")
+	}
+	if w.FlipCount() >= walkCheckInterval {
+		t.Errorf("cancelled walk made %d flips", w.FlipCount())
+	}
+}
+
 func TestWalkSolverExportPhases(t *testing.T) {
 	w := NewWalkSolver()
 	// (A ∨ B) — satisfiable
@@ -0,0 +1,267 @@
+package sat
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+// WCNFFormat selects one of the MaxSAT Evaluation input formats
+type WCNFFormat int
+
+const (
+	// WCNF2022 is the header-less format used since the 2022 MaxSAT
+	// Evaluation: hard clauses start with "h", soft clauses with their
+	// weight, as in "h 1 -2 0" and "5 2 3 0".
+	WCNF2022 WCNFFormat = iota
+	// WCNFLegacy is the older format with a "p wcnf <vars> <clauses> <top>"
+	// problem line, where every clause starts with its weight and clauses
+	// weighing top or more are hard. Without top, every clause is soft.
+	WCNFLegacy
+)
+
+// String returns the format name
+func (f WCNFFormat) String() string {
+	switch f {
+	case WCNF2022:
+		return "WCNF 2022"
+	case WCNFLegacy:
+		return "legacy WCNF"
+	default:
+		return "unknown"
+	}
+}
+
+// WCNFReader is a streaming parser for weighted MaxSAT instances in either
+// WCNF format, told apart by the presence of a problem line. Variables are
+// named as DIMACSReader names them.
+type WCNFReader struct {
+	dimacsTokenizer
+
+	format   WCNFFormat
+	detected bool
+	numVars  int   // Declared by the legacy problem line
+	top      int64 // Legacy hard weight, 0 if every clause is soft
+
+	weight  int64 // Weight of the clause being read, 0 for hard
+	started bool  // A weight or "h" has been read for the current clause
+}
+
+// NewWCNFReader creates a streaming WCNF reader over r
+func NewWCNFReader(r io.Reader) *WCNFReader {
+	return &WCNFReader{dimacsTokenizer: newDIMACSTokenizer(r)}
+}
+
+// Variables returns the mapping between WCNF indices and variable names
+func (d *WCNFReader) Variables() *VariableMap {
+	return d.vars
+}
+
+// Format reads up to the first clause or problem line and reports which
+// format the stream uses. An empty stream is reported as WCNF2022.
+func (d *WCNFReader) Format() (WCNFFormat, error) {
+	if !d.detected {
+		if _, err := d.nextLine(); err != nil {
+			return 0, err
+		}
+	}
+	return d.format, nil
+}
+
+// Next returns the next clause and its weight, which is 0 for hard
+// clauses. Tautological clauses are skipped. io.EOF is returned once the
+// stream is exhausted.
+func (d *WCNFReader) Next() (*Clause, int64, error) {
+	for {
+		if d.pos >= len(d.fields) {
+			more, err := d.nextLine()
+			if err != nil {
+				return nil, 0, err
+			}
+			if !more {
+				if d.started {
+					return nil, 0, d.errorf("WCNFReader.Next", "unterminated clause at end of input")
+				}
+				return nil, 0, io.EOF
+			}
+			continue
+		}
+
+		tok := d.fields[d.pos]
+		d.pos++
+		if !d.started {
+			if err := d.parseWeight(tok); err != nil {
+				return nil, 0, err
+			}
+			d.started = true
+			continue
+		}
+		numVars := -1
+		if d.format == WCNFLegacy {
+			numVars = d.numVars
+		}
+		lit, err := d.parseLiteral("WCNFReader.Next", tok, numVars)
+		if err != nil {
+			return nil, 0, err
+		}
+		if lit != 0 {
+			d.pending = append(d.pending, lit)
+			continue
+		}
+
+		clause, tautology := d.buildClause()
+		d.started = false
+		if tautology {
+			continue
+		}
+		return clause, d.weight, nil
+	}
+}
+
+// nextLine advances to the next line carrying clause data and splits it
+// into fields. It reports false at the end of the stream.
+func (d *WCNFReader) nextLine() (bool, error) {
+	for {
+		text, ok, err := d.scanLine("WCNFReader.Next")
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			d.detected = true
+			return false, nil
+		}
+		if text[0] == 'p' {
+			if err := d.parseHeader(text); err != nil {
+				return false, err
+			}
+			continue
+		}
+		if !d.detected {
+			d.format, d.detected = WCNF2022, true
+		}
+		d.fields = strings.Fields(text)
+		return true, nil
+	}
+}
+
+// parseHeader parses a "p wcnf <vars> <clauses> [<top>]" problem line
+func (d *WCNFReader) parseHeader(text string) error {
+	if d.detected {
+		return d.errorf("WCNFReader.Next", "problem line after clauses or a previous problem line")
+	}
+	fields := strings.Fields(text)
+	if (len(fields) != 4 && len(fields) != 5) || fields[0] != "p" || fields[1] != "wcnf" {
+		return d.errorf("WCNFReader.Next", fmt.Sprintf("malformed problem line %q", text))
+	}
+	vars, _, err := d.parseCounts("WCNFReader.Next", text, fields)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 5 {
+		top, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil || top < 1 {
+			return d.errorf("WCNFReader.Next", fmt.Sprintf("malformed problem line %q", text))
+		}
+		d.top = top
+	}
+	d.format, d.detected = WCNFLegacy, true
+	d.numVars = vars
+	return nil
+}
+
+// parseWeight parses the token that starts a clause
+func (d *WCNFReader) parseWeight(tok string) error {
+	if tok == "h" && d.format == WCNF2022 {
+		d.weight = 0
+		return nil
+	}
+	weight, err := strconv.ParseInt(tok, 10, 64)
+	if err != nil || weight < 1 {
+		return d.errorf("WCNFReader.Next", fmt.Sprintf("invalid clause weight %q", tok))
+	}
+	if d.top > 0 && weight >= d.top {
+		weight = 0
+	}
+	d.weight = weight
+	return nil
+}
+
+// ReadWeightedCNF reads every remaining clause into a new WeightedCNF
+func (d *WCNFReader) ReadWeightedCNF() (*WeightedCNF, error) {
+	wcnf := NewWeightedCNF()
+	for {
+		clause, weight, err := d.Next()
+		if err == io.EOF {
+			return wcnf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if weight == 0 {
+			wcnf.AddHard(clause)
+		} else {
+			wcnf.AddSoft(clause, weight)
+		}
+	}
+}
+
+// ReadWCNF parses a MaxSAT instance in either WCNF format
+func ReadWCNF(r io.Reader) (*WeightedCNF, error) {
+	return NewWCNFReader(r).ReadWeightedCNF()
+}
+
+// WriteWCNF writes wcnf in the given format. Clauses marked Deleted and
+// soft clauses of weight zero, which cost nothing, are skipped. In the
+// legacy format top is one more than the total soft weight.
+func WriteWCNF(w io.Writer, wcnf *WeightedCNF, format WCNFFormat) error {
+	dw := NewDIMACSWriter(w, newVariableMapForCNFs(wcnf.Hard, wcnf.Soft))
+	if err := dw.writeNameComments(); err != nil {
+		return err
+	}
+
+	top := int64(1)
+	count := countLiveClauses(wcnf.Hard.Clauses)
+	for i, clause := range wcnf.Soft.Clauses {
+		if clause.Deleted || wcnf.Weights[i] == 0 {
+			continue
+		}
+		if top > math.MaxInt64-wcnf.Weights[i] {
+			return core.NewLogicError("sat", "WriteWCNF", "total soft weight overflows int64")
+		}
+		top += wcnf.Weights[i]
+		count++
+	}
+
+	hard := []byte("h ")
+	if format == WCNFLegacy {
+		if _, err := fmt.Fprintf(dw.w, "p wcnf %d %d %d\n", dw.vars.Len(), count, top); err != nil {
+			return err
+		}
+		hard = strconv.AppendInt(nil, top, 10)
+		hard = append(hard, ' ')
+	}
+	for _, clause := range wcnf.Hard.Clauses {
+		if clause.Deleted {
+			continue
+		}
+		if err := dw.writePrefixedClause(hard, clause); err != nil {
+			return err
+		}
+	}
+	var prefix []byte
+	for i, clause := range wcnf.Soft.Clauses {
+		if clause.Deleted || wcnf.Weights[i] == 0 {
+			continue
+		}
+		prefix = strconv.AppendInt(prefix[:0], wcnf.Weights[i], 10)
+		prefix = append(prefix, ' ')
+		if err := dw.writePrefixedClause(prefix, clause); err != nil {
+			return err
+		}
+	}
+	return dw.Flush()
+}
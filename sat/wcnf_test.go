@@ -0,0 +1,166 @@
+package sat
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/xDarkicex/logic/core"
+)
+
+func TestReadWCNF_2022(t *testing.T) {
+	input := `c scheduling example
+h 1 2 0
+h -1 -2 0
+3 1 0
+c soft clauses may span lines
+5 2
+  0
+4 -1 3 0
+`
+	reader := NewWCNFReader(strings.NewReader(input))
+	if format, err := reader.Format(); err != nil || format != WCNF2022 {
+		t.Fatalf("Expected WCNF 2022 format, got %v, %v", format, err)
+	}
+	wcnf, err := reader.ReadWeightedCNF()
+	if err != nil {
+		t.Fatalf("ReadWeightedCNF failed: %v", err)
+	}
+	if len(wcnf.Hard.Clauses) != 2 || len(wcnf.Soft.Clauses) != 3 {
+		t.Fatalf("Expected 2 hard and 3 soft clauses, got %d and %d", len(wcnf.Hard.Clauses), len(wcnf.Soft.Clauses))
+	}
+	if wcnf.Weights[0] != 3 || wcnf.Weights[1] != 5 || wcnf.Weights[2] != 4 {
+		t.Errorf("Unexpected weights %v", wcnf.Weights)
+	}
+	if !wcnf.Soft.Clauses[2].Contains(L("v1", true)) || !wcnf.Soft.Clauses[2].Contains(L("v3", false)) {
+		t.Errorf("Unexpected soft clause %s", wcnf.Soft.Clauses[2])
+	}
+
+	result := NewMAXSATSolver().SolvePartialMAXSAT(wcnf)
+	if result.Error != nil || result.Cost != 3 {
+		t.Errorf("Expected optimal cost 3, got %d (%v)", result.Cost, result.Error)
+	}
+}
+
+func TestReadWCNF_Legacy(t *testing.T) {
+	input := `c old-style instance
+p wcnf 3 5 100
+100 1 2 0
+100 -1 -2 0
+3 1 0
+5 2 0
+4 -1 3 0
+`
+	reader := NewWCNFReader(strings.NewReader(input))
+	if format, err := reader.Format(); err != nil || format != WCNFLegacy {
+		t.Fatalf("Expected legacy format, got %v, %v", format, err)
+	}
+	wcnf, err := reader.ReadWeightedCNF()
+	if err != nil {
+		t.Fatalf("ReadWeightedCNF failed: %v", err)
+	}
+	if len(wcnf.Hard.Clauses) != 2 || len(wcnf.Soft.Clauses) != 3 {
+		t.Fatalf("Expected 2 hard and 3 soft clauses, got %d and %d", len(wcnf.Hard.Clauses), len(wcnf.Soft.Clauses))
+	}
+
+	// Without top every clause is soft
+	wcnf, err = ReadWCNF(strings.NewReader("p wcnf 2 2\n7 1 0\n2 -1 2 0\n"))
+	if err != nil {
+		t.Fatalf("ReadWCNF failed: %v", err)
+	}
+	if len(wcnf.Hard.Clauses) != 0 || len(wcnf.Soft.Clauses) != 2 || wcnf.Weights[0] != 7 {
+		t.Errorf("Expected 2 soft clauses, got %d hard and weights %v", len(wcnf.Hard.Clauses), wcnf.Weights)
+	}
+}
+
+func TestReadWCNF_Errors(t *testing.T) {
+	cases := map[string]string{
+		"bad weight":            "x 1 0\n",
+		"zero weight":           "0 1 0\n",
+		"h in legacy":           "p wcnf 2 1 10\nh 1 0\n",
+		"late problem line":     "h 1 0\np wcnf 1 1 10\n",
+		"variable out of range": "p wcnf 2 1 10\n10 3 0\n",
+		"bad problem line":      "p cnf 2 1\n",
+		"unterminated":          "h 1 2\n",
+		"bad literal":           "3 1 a 0\n",
+	}
+	for name, input := range cases {
+		_, err := ReadWCNF(strings.NewReader(input))
+		var logicErr *core.LogicError
+		if !errors.As(err, &logicErr) {
+			t.Errorf("%s: expected LogicError, got %v", name, err)
+		}
+	}
+}
+
+func TestWCNFReader_Streaming(t *testing.T) {
+	reader := NewWCNFReader(strings.NewReader("h 1 -1 0\nh 1 0\n2 -1 0\n"))
+	clause, weight, err := reader.Next()
+	if err != nil || weight != 0 || !clause.IsUnit() {
+		t.Fatalf("Expected the hard unit clause after the tautology, got %v %d %v", clause, weight, err)
+	}
+	if _, weight, err = reader.Next(); err != nil || weight != 2 {
+		t.Fatalf("Expected a soft clause of weight 2, got %d %v", weight, err)
+	}
+	if _, _, err = reader.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+func TestReadWCNF_Empty(t *testing.T) {
+	wcnf, err := ReadWCNF(strings.NewReader("p wcnf 0 0 1\n"))
+	if err != nil {
+		t.Fatalf("ReadWCNF failed: %v", err)
+	}
+	if len(wcnf.Hard.Clauses) != 0 || len(wcnf.Soft.Clauses) != 0 {
+		t.Errorf("Expected an empty formula, got %d hard and %d soft clauses", len(wcnf.Hard.Clauses), len(wcnf.Soft.Clauses))
+	}
+}
+
+func TestWriteWCNF_RoundTrip(t *testing.T) {
+	wcnf := NewWeightedCNF()
+	wcnf.AddHard(NewClause(L("shift_a", false), L("shift_b", false)))
+	wcnf.AddHard(NewClause(L("shift_a", true), L("shift_b", true)))
+	wcnf.AddSoft(NewClause(L("shift_a", false)), 3)
+	wcnf.AddSoft(NewClause(L("shift_b", false), L("overtime", false)), 5)
+	wcnf.AddSoft(NewClause(L("overtime", true)), 2)
+	wcnf.AddSoft(NewClause(L("overtime", false)), 0)
+
+	for _, format := range []WCNFFormat{WCNF2022, WCNFLegacy} {
+		var buf bytes.Buffer
+		if err := WriteWCNF(&buf, wcnf, format); err != nil {
+			t.Fatalf("%v: WriteWCNF failed: %v", format, err)
+		}
+		if format == WCNFLegacy && !strings.Contains(buf.String(), "p wcnf 3 5 11\n") {
+			t.Errorf("Expected header with top 11, got:\n%s", buf.String())
+		}
+		if !strings.Contains(buf.String(), "c var 1 shift_a") {
+			t.Errorf("%v: expected variable name comments, got:\n%s", format, buf.String())
+		}
+
+		reader := NewWCNFReader(&buf)
+		if got, _ := reader.Format(); got != format {
+			t.Errorf("Expected %v to be detected, got %v", format, got)
+		}
+		back, err := reader.ReadWeightedCNF()
+		if err != nil {
+			t.Fatalf("%v: ReadWeightedCNF failed: %v", format, err)
+		}
+		if len(back.Hard.Clauses) != 2 || len(back.Soft.Clauses) != 3 {
+			t.Fatalf("%v: expected 2 hard and 3 soft clauses, got %d and %d", format, len(back.Hard.Clauses), len(back.Soft.Clauses))
+		}
+		for i, w := range []int64{3, 5, 2} {
+			if back.Weights[i] != w || len(back.Soft.Clauses[i].Literals) != len(wcnf.Soft.Clauses[i].Literals) {
+				t.Errorf("%v: soft clause %d differs: %s weight %d", format, i, back.Soft.Clauses[i], back.Weights[i])
+			}
+		}
+
+		original := NewMAXSATSolver().SolvePartialMAXSAT(wcnf)
+		reread := NewMAXSATSolver().SolvePartialMAXSAT(back)
+		if original.Error != nil || reread.Error != nil || original.Cost != reread.Cost {
+			t.Errorf("%v: costs differ after round trip: %d and %d", format, original.Cost, reread.Cost)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package sat
+
+import (
+	"math/bits"
+	"sort"
+
+	"github.com/xDarkicex/memory"
+)
+
+// XORRecovery finds XOR constraints that a formula encodes as clauses and
+// adds them to its XORClauses, where Gaussian elimination sees them.
+//
+// x1 ⊕ ... ⊕ xk = p is the 2^(k-1) clauses over x1..xk with an even number
+// of negated literals if p is true, and an odd number otherwise; each
+// clause rules out one assignment of the wrong parity. A shorter clause
+// over some of the variables rules out several of them at once, so it can
+// stand in for the full clauses it subsumes. Long XORs are usually encoded
+// as a chain of short ones through auxiliary variables, such as
+// x1 ⊕ x2 ⊕ t1 = 0 and t1 ⊕ x3 ⊕ x4 = p. Each link is recovered on its own,
+// and Gaussian elimination sums the links, eliminating the auxiliary
+// variables.
+type XORRecovery struct {
+	// Configuration
+	minSize         int  // Fewest variables of a recovered XOR
+	maxSize         int  // Most variables of a recovered XOR
+	removeOriginals bool // Delete the clauses a recovered XOR implies
+
+	// Statistics
+	recovered int64
+	removed   int64
+
+	pool *memory.Pool // Backs the clause list
+}
+
+// NewXORRecovery creates an XOR recovery engine
+func NewXORRecovery() *XORRecovery {
+	return newXORRecovery(nil)
+}
+
+func newXORRecovery(pool *memory.Pool) *XORRecovery {
+	return &XORRecovery{
+		pool:    poolOr(pool),
+		minSize: 3,
+		maxSize: 8,
+	}
+}
+
+// RecoverXORs adds the XOR constraints ecnf encodes as clauses to its
+// XORClauses and returns their number. With removeOriginals the clauses
+// that only restate a recovered XOR are deleted from ecnf.
+func RecoverXORs(ecnf *ExtendedCNF, removeOriginals bool) int {
+	x := NewXORRecovery()
+	x.SetRemoveOriginals(removeOriginals)
+	return x.Recover(ecnf)
+}
+
+// SetSizeLimits bounds the number of variables of recovered XORs. Two
+// variable XORs are equivalences, which clauses propagate as well; a k
+// variable XOR takes 2^(k-1) clauses, so large ones are rare in practice.
+func (x *XORRecovery) SetSizeLimits(minSize, maxSize int) {
+	x.minSize = max(minSize, 2)
+	x.maxSize = min(maxSize, 16)
+}
+
+// SetRemoveOriginals makes Recover delete the full-length clauses of each
+// recovered XOR. The formula keeps its models, since the XOR implies the
+// deleted clauses. Shorter clauses are kept: the XOR does not imply them.
+func (x *XORRecovery) SetRemoveOriginals(remove bool) {
+	x.removeOriginals = remove
+}
+
+// Recover adds the XOR constraints encoded by the irredundant clauses of
+// ecnf to its XORClauses and returns their number. XORs the formula
+// already has are not added again.
+func (x *XORRecovery) Recover(ecnf *ExtendedCNF) int {
+	occurrences := make(map[string][]*Clause)
+	for _, clause := range ecnf.Clauses {
+		if !x.usable(clause) {
+			continue
+		}
+		for _, lit := range clause.Literals {
+			occurrences[lit.Variable] = append(occurrences[lit.Variable], clause)
+		}
+	}
+
+	known := make(map[string]bool, len(ecnf.XORClauses))
+	for _, xor := range ecnf.XORClauses {
+		known[xorKey(xor)] = true
+	}
+
+	// Recover everything before deleting anything, so that a clause
+	// implied by one XOR still helps to find another
+	var implied []*Clause
+	recovered := 0
+	tried := make(map[string]bool)
+	for _, clause := range ecnf.Clauses {
+		size := len(clause.Literals)
+		if !x.usable(clause) || size < x.minSize || size > x.maxSize {
+			continue
+		}
+		variables := make([]string, size)
+		for i, lit := range clause.Literals {
+			variables[i] = lit.Variable
+		}
+		sort.Strings(variables)
+		xor := NewXORClause(variables, negatedCount(clause.Literals)%2 == 0)
+		key := xorKey(xor)
+		if tried[key] {
+			continue
+		}
+		tried[key] = true
+
+		encoding, ok := x.encoding(xor, occurrences)
+		if !ok {
+			continue
+		}
+		if !known[key] {
+			known[key] = true
+			ecnf.AddXORClause(xor)
+			recovered++
+		}
+		implied = append(implied, encoding...)
+	}
+
+	if x.removeOriginals && len(implied) > 0 {
+		for _, clause := range implied {
+			if !clause.Deleted {
+				clause.Deleted = true
+				x.removed++
+			}
+		}
+		removeDeletedClauses(x.pool, ecnf.CNF)
+	}
+	x.recovered += int64(recovered)
+	return recovered
+}
+
+// usable reports whether clause can be part of an XOR encoding
+func (x *XORRecovery) usable(clause *Clause) bool {
+	if clause == nil || clause.Deleted || clause.Learned || len(clause.Literals) == 0 {
+		return false
+	}
+	seen := make(map[string]bool, len(clause.Literals))
+	for _, lit := range clause.Literals {
+		if seen[lit.Variable] {
+			return false
+		}
+		seen[lit.Variable] = true
+	}
+	return true
+}
+
+// encoding checks whether the clauses over the variables of xor rule out
+// every assignment of the wrong parity. If so, it returns the full-length
+// clauses among them, which xor implies.
+func (x *XORRecovery) encoding(xor *XORClause, occurrences map[string][]*Clause) ([]*Clause, bool) {
+	column := make(map[string]int, len(xor.Variables))
+	for i, variable := range xor.Variables {
+		column[variable] = i
+	}
+
+	// Bit i of an assignment is the value of xor.Variables[i]
+	covered := make([]bool, 1<<len(xor.Variables))
+	seen := make(map[*Clause]bool)
+	var full []*Clause
+	for _, variable := range xor.Variables {
+		for _, clause := range occurrences[variable] {
+			if seen[clause] || clause.Deleted {
+				continue
+			}
+			seen[clause] = true
+			var mask, values uint
+			inside := true
+			for _, lit := range clause.Literals {
+				i, ok := column[lit.Variable]
+				if !ok {
+					inside = false
+					break
+				}
+				mask |= 1 << i
+				if lit.Negated {
+					values |= 1 << i // The clause is false when lit's variable is true
+				}
+			}
+			if !inside {
+				continue
+			}
+			for assignment := range covered {
+				if uint(assignment)&mask == values {
+					covered[assignment] = true
+				}
+			}
+			if len(clause.Literals) == len(xor.Variables) && (bits.OnesCount(values)%2 == 1) != xor.Parity {
+				full = append(full, clause)
+			}
+		}
+	}
+
+	for assignment, ruledOut := range covered {
+		if (bits.OnesCount(uint(assignment))%2 == 1) != xor.Parity && !ruledOut {
+			return nil, false
+		}
+	}
+	return full, true
+}
+
+// negatedCount returns the number of negated literals of lits
+func negatedCount(lits []Literal) int {
+	n := 0
+	for _, lit := range lits {
+		if lit.Negated {
+			n++
+		}
+	}
+	return n
+}
+
+// GetStatistics returns XOR recovery statistics
+func (x *XORRecovery) GetStatistics() map[string]int64 {
+	return map[string]int64{
+		"recoveredXORs":  x.recovered,
+		"removedClauses": x.removed,
+	}
+}
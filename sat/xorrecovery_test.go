@@ -0,0 +1,191 @@
+package sat
+
+import (
+	"fmt"
+	"testing"
+)
+
+// addXOREncoding adds the 2^(k-1) clauses of x1 ⊕ ... ⊕ xk = parity
+func addXOREncoding(cnf *CNF, variables []string, parity bool) {
+	allModels(variables, func(model Assignment) {
+		lits := make([]Literal, len(variables))
+		odd := false
+		for i, variable := range variables {
+			odd = odd != model[variable]
+			lits[i] = L(variable, model[variable])
+		}
+		if odd != parity {
+			cnf.AddClause(NewClause(lits...))
+		}
+	})
+}
+
+func TestXORRecovery_Basic(t *testing.T) {
+	ecnf := NewExtendedCNF()
+	addXOREncoding(ecnf.CNF, []string{"C", "A", "B"}, true)
+	ecnf.AddClause(NewClause(L("A", false), L("D", false)))
+	if n := RecoverXORs(ecnf, false); n != 1 || len(ecnf.Clauses) != 5 {
+		t.Fatalf("Expected 1 XOR and 5 clauses, got %d and %d", n, len(ecnf.Clauses))
+	}
+	if xor := ecnf.XORClauses[0]; xor.String() != "(A ⊕ B ⊕ C = 1)" {
+		t.Errorf("Expected A ⊕ B ⊕ C = 1, got %v", xor)
+	}
+
+	// Recovering again finds nothing new but removes the encoding
+	if n := RecoverXORs(ecnf, true); n != 0 || len(ecnf.XORClauses) != 1 || len(ecnf.Clauses) != 1 {
+		t.Fatalf("Expected only (A ∨ D) left, got %d XORs and %v", len(ecnf.XORClauses), ecnf.Clauses)
+	}
+
+	// (A ∨ B) rules out 000 and 001, standing in for (A ∨ B ∨ C) of
+	// A ⊕ B ⊕ C = 1; it is not implied by the XOR and stays
+	ecnf = NewExtendedCNF()
+	ecnf.AddClause(NewClause(L("A", false), L("B", false)))
+	ecnf.AddClause(NewClause(L("A", false), L("B", true), L("C", true)))
+	ecnf.AddClause(NewClause(L("A", true), L("B", false), L("C", true)))
+	ecnf.AddClause(NewClause(L("A", true), L("B", true), L("C", false)))
+	x := NewXORRecovery()
+	x.SetRemoveOriginals(true)
+	if n := x.Recover(ecnf); n != 1 || len(ecnf.Clauses) != 1 || len(ecnf.Clauses[0].Literals) != 2 {
+		t.Fatalf("Expected 1 XOR and (A ∨ B), got %d and %v", n, ecnf.Clauses)
+	}
+	if stats := x.GetStatistics(); stats["recoveredXORs"] != 1 || stats["removedClauses"] != 3 {
+		t.Errorf("Unexpected statistics %v", stats)
+	}
+
+	// An incomplete encoding is not an XOR, and neither is one beyond the
+	// size limits
+	ecnf = NewExtendedCNF()
+	addXOREncoding(ecnf.CNF, []string{"A", "B", "C", "D"}, false)
+	ecnf.Clauses = ecnf.Clauses[1:]
+	if n := RecoverXORs(ecnf, true); n != 0 || len(ecnf.Clauses) != 7 {
+		t.Errorf("Expected no XOR, got %d", n)
+	}
+	ecnf = NewExtendedCNF()
+	addXOREncoding(ecnf.CNF, []string{"A", "B", "C", "D"}, false)
+	x = NewXORRecovery()
+	x.SetSizeLimits(2, 3)
+	if n := x.Recover(ecnf); n != 0 {
+		t.Errorf("Expected no XOR beyond the size limit, got %d", n)
+	}
+}
+
+// xorEncoding is an XOR constraint to encode as clauses
+type xorEncoding struct {
+	variables []string
+	parity    bool
+}
+
+func TestXORRecovery_Models(t *testing.T) {
+	testCases := []struct {
+		description string
+		clauses     [][]Literal
+		xors        []xorEncoding
+		dropped     []int // Encoding clauses removed, by index
+		expected    int
+	}{
+		{"single ternary XOR",
+			nil, []xorEncoding{{[]string{"A", "B", "C"}, true}}, nil, 1},
+		{"two XORs sharing a variable",
+			nil, []xorEncoding{{[]string{"A", "B", "C"}, true}, {[]string{"C", "D"}, false}}, nil, 2},
+		{"XOR beside other clauses",
+			[][]Literal{{L("A", false), L("E", false)}, {L("E", true), L("B", false)}},
+			[]xorEncoding{{[]string{"A", "B", "C", "D"}, false}}, nil, 1},
+		{"even parity of five",
+			nil, []xorEncoding{{[]string{"A", "B", "C", "D", "E"}, false}}, nil, 1},
+		{"broken encoding",
+			nil, []xorEncoding{{[]string{"A", "B", "C"}, true}}, []int{0}, 0},
+		{"one of two encodings broken",
+			nil, []xorEncoding{{[]string{"A", "B", "C"}, true}, {[]string{"D", "E"}, true}}, []int{1}, 1},
+	}
+
+	for _, tc := range testCases {
+		for _, remove := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s removing originals %v", tc.description, remove), func(t *testing.T) {
+				original := buildCNF(tc.clauses)
+				for _, xor := range tc.xors {
+					addXOREncoding(original, xor.variables, xor.parity)
+				}
+				for i := len(tc.dropped) - 1; i >= 0; i-- {
+					j := len(tc.clauses) + tc.dropped[i]
+					original.Clauses = append(original.Clauses[:j], original.Clauses[j+1:]...)
+				}
+
+				ecnf := extendedFrom(original)
+				x := NewXORRecovery()
+				x.SetSizeLimits(2, 8)
+				x.SetRemoveOriginals(remove)
+				if n := x.Recover(ecnf); n != tc.expected {
+					t.Errorf("Expected %d XORs, got %d", tc.expected, n)
+				}
+
+				// The recovered formula has exactly the original models
+				allModels(variablesOf(original.Clauses), func(model Assignment) {
+					if holdsExtended(model, ecnf) != (CheckModel(original, model) == nil) {
+						t.Fatalf("Recovery changed the models at %v", model)
+					}
+				})
+			})
+		}
+	}
+}
+
+func TestXORRecovery_Chain(t *testing.T) {
+	names := make([]string, 12)
+	for i := range names {
+		names[i] = fmt.Sprintf("x%d", i)
+	}
+	units := func(count int) [][]Literal {
+		clauses := make([][]Literal, count)
+		for i := range clauses {
+			clauses[i] = []Literal{L(names[i], false)}
+		}
+		return clauses
+	}
+	testCases := []struct {
+		description string
+		clauses     [][]Literal
+		parity      bool
+		expectedSat bool
+	}{
+		{"odd parity alone", nil, true, true},
+		{"even parity alone", nil, false, true},
+		{"units leave the last variable to the parity", units(11), false, true},
+		{"units fix every variable against the parity", units(12), true, false},
+		{"clauses beside the chain", [][]Literal{
+			{L(names[0], false), L(names[5], true), L(names[9], false)},
+			{L(names[2], true), L(names[5], false)},
+			{L(names[11], true)},
+		}, true, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			// x0 ⊕ ... ⊕ x11 = parity through the chain tᵢ = tᵢ₋₁ ⊕ xᵢ
+			original := buildCNF(tc.clauses)
+			previous := names[0]
+			for i, name := range names[1:] {
+				aux := fmt.Sprintf("t%d", i)
+				addXOREncoding(original, []string{previous, name, aux}, false)
+				previous = aux
+			}
+			original.AddClause(NewClause(L(previous, !tc.parity)))
+
+			ecnf := extendedFrom(original)
+			if n := RecoverXORs(ecnf, true); n < len(names)-1 {
+				t.Fatalf("Expected at least %d links, got %d", len(names)-1, n)
+			}
+			result := NewCDCLSolver().SolveExtended(ecnf)
+			if result.Error != nil {
+				t.Fatalf("Solver error: %v", result.Error)
+			}
+			if result.Satisfiable != tc.expectedSat {
+				t.Fatalf("Expected satisfiable=%v, got %v", tc.expectedSat, result.Status)
+			}
+			if result.Satisfiable {
+				if err := CheckModel(original, result.Assignment); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}